@@ -0,0 +1,376 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// numberComparePrecision 断言中比较数字时使用的二进制精度，足以精确表示数十位的十进制数
+const numberComparePrecision = 256
+
+// AssertionResult 单个断言的求值结果
+type AssertionResult struct {
+	Expression string `json:"expression"`
+	Passed     bool   `json:"passed"`
+	Actual     string `json:"actual,omitempty"`  // 左侧操作数的实际值
+	Message    string `json:"message,omitempty"` // 失败原因
+}
+
+// AssertionContext 断言求值上下文
+type AssertionContext struct {
+	Result   interface{} // 解析后的调用结果，$ 指向它
+	Duration int64       // 调用耗时，单位毫秒，可通过 duration 引用
+}
+
+// assertionOperators 支持的比较运算符，按长度优先匹配
+var assertionOperators = []string{"==", "!=", ">=", "<=", "=~", ">", "<", " contains ", " matches "}
+
+// undefinedValue 路径不存在时的操作数值
+type undefinedValue struct{}
+
+// EvaluateAssertions 依次求值断言表达式
+// 表达式形如 `$.success == true`、`len($.data) > 0`、`duration < 500`、`$.msg contains "ok"`、`$.code =~ "^2"`，
+// 没有运算符时检查值是否为真（存在且不是false、null、0或空字符串）
+func EvaluateAssertions(expressions []string, ctx AssertionContext) []AssertionResult {
+	results := make([]AssertionResult, 0, len(expressions))
+	for _, expression := range expressions {
+		expression = strings.TrimSpace(expression)
+		if expression == "" {
+			continue
+		}
+		results = append(results, evaluateAssertion(expression, ctx))
+	}
+	return results
+}
+
+// AssertionsFailed 返回失败的断言数量
+func AssertionsFailed(results []AssertionResult) int {
+	failed := 0
+	for _, result := range results {
+		if !result.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// printAssertionReport 输出断言报告
+func printAssertionReport(results []AssertionResult) {
+	failed := AssertionsFailed(results)
+	if failed == 0 {
+		color.Green("断言全部通过 (%d/%d)", len(results), len(results))
+	} else {
+		color.Red("断言失败 (%d/%d):", failed, len(results))
+	}
+	for _, result := range results {
+		if result.Passed {
+			color.Green("  ✔ %s", result.Expression)
+			continue
+		}
+		color.Red("  ✘ %s", result.Expression)
+		color.Red("      %s", result.Message)
+	}
+}
+
+// evaluateAssertion 求值单个断言
+func evaluateAssertion(expression string, ctx AssertionContext) AssertionResult {
+	result := AssertionResult{Expression: expression}
+
+	left, operator, right := splitAssertion(expression)
+	leftValue, err := evaluateOperand(left, ctx)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	result.Actual = describeOperand(leftValue)
+
+	// 没有运算符时检查真值
+	if operator == "" {
+		result.Passed = isTruthy(leftValue)
+		if !result.Passed {
+			result.Message = fmt.Sprintf("期望 %s 为真，实际为 %s", left, result.Actual)
+		}
+		return result
+	}
+
+	rightValue, err := evaluateOperand(right, ctx)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	passed, err := compareOperands(leftValue, operator, rightValue)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	result.Passed = passed
+	if !passed {
+		result.Message = fmt.Sprintf("期望 %s %s %s，实际为 %s", left, operator, right, result.Actual)
+	}
+	return result
+}
+
+// splitAssertion 在引号和括号之外查找比较运算符，拆分为左右操作数
+func splitAssertion(expression string) (string, string, string) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(expression); i++ {
+		ch := expression[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		case ch == '"' || ch == '\'':
+			quote = ch
+			continue
+		case ch == '(' || ch == '[':
+			depth++
+			continue
+		case ch == ')' || ch == ']':
+			depth--
+			continue
+		}
+		if depth != 0 {
+			continue
+		}
+		for _, operator := range assertionOperators {
+			if strings.HasPrefix(expression[i:], operator) {
+				return strings.TrimSpace(expression[:i]), strings.TrimSpace(operator), strings.TrimSpace(expression[i+len(operator):])
+			}
+		}
+	}
+	return strings.TrimSpace(expression), "", ""
+}
+
+// evaluateOperand 求值操作数：路径、函数调用、duration或字面量
+func evaluateOperand(operand string, ctx AssertionContext) (interface{}, error) {
+	switch {
+	case operand == "":
+		return nil, fmt.Errorf("缺少操作数")
+	case operand == "duration":
+		return ctx.Duration, nil
+	case operand == "true":
+		return true, nil
+	case operand == "false":
+		return false, nil
+	case operand == "null":
+		return nil, nil
+	case strings.HasPrefix(operand, "$") || strings.HasPrefix(operand, "."):
+		value, err := queryResult(ctx.Result, operand)
+		if err != nil {
+			return undefinedValue{}, nil
+		}
+		return value, nil
+	case strings.HasPrefix(operand, "\"") && strings.HasSuffix(operand, "\"") && len(operand) >= 2:
+		value, err := strconv.Unquote(operand)
+		if err != nil {
+			return nil, fmt.Errorf("无效的字符串 %s: %v", operand, err)
+		}
+		return value, nil
+	case strings.HasPrefix(operand, "'") && strings.HasSuffix(operand, "'") && len(operand) >= 2:
+		return operand[1 : len(operand)-1], nil
+	}
+
+	// 函数调用
+	if open := strings.Index(operand, "("); open > 0 && strings.HasSuffix(operand, ")") {
+		name := strings.TrimSpace(operand[:open])
+		argument, err := evaluateOperand(strings.TrimSpace(operand[open+1:len(operand)-1]), ctx)
+		if err != nil {
+			return nil, err
+		}
+		return callAssertionFunction(name, argument)
+	}
+
+	// 数字字面量
+	if _, err := strconv.ParseFloat(operand, 64); err == nil {
+		return json.Number(operand), nil
+	}
+	return nil, fmt.Errorf("无法解析的操作数: %s", operand)
+}
+
+// callAssertionFunction 执行断言中的函数：len、exists、type
+func callAssertionFunction(name string, argument interface{}) (interface{}, error) {
+	_, undefined := argument.(undefinedValue)
+	switch name {
+	case "exists":
+		return !undefined, nil
+	case "len":
+		switch v := argument.(type) {
+		case string:
+			return int64(len([]rune(v))), nil
+		case []interface{}:
+			return int64(len(v)), nil
+		case map[string]interface{}:
+			return int64(len(v)), nil
+		case nil, undefinedValue:
+			return int64(0), nil
+		}
+		return nil, fmt.Errorf("len() 不支持 %s 类型", jsonKind(argument))
+	case "type":
+		if undefined {
+			return "undefined", nil
+		}
+		return jsonKind(argument), nil
+	}
+	return nil, fmt.Errorf("不支持的函数: %s (可选: len, exists, type)", name)
+}
+
+// compareOperands 按运算符比较两个值
+func compareOperands(left interface{}, operator string, right interface{}) (bool, error) {
+	if _, undefined := left.(undefinedValue); undefined {
+		return false, fmt.Errorf("路径不存在")
+	}
+
+	switch operator {
+	case "==":
+		return valuesEqual(left, right), nil
+	case "!=":
+		return !valuesEqual(left, right), nil
+	case ">", ">=", "<", "<=":
+		if leftNumber, rightNumber, ok := numericOperands(left, right); ok {
+			return compareOrdered(leftNumber.Cmp(rightNumber), 0, operator), nil
+		}
+		leftString, leftIsString := left.(string)
+		rightString, rightIsString := right.(string)
+		if leftIsString && rightIsString {
+			return compareOrdered(strings.Compare(leftString, rightString), 0, operator), nil
+		}
+		return false, fmt.Errorf("无法比较 %s 和 %s", jsonKind(left), jsonKind(right))
+	case "contains":
+		switch v := left.(type) {
+		case string:
+			return strings.Contains(v, cellText(right)), nil
+		case []interface{}:
+			for _, item := range v {
+				if valuesEqual(item, right) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]interface{}:
+			_, exists := v[cellText(right)]
+			return exists, nil
+		}
+		return false, fmt.Errorf("contains 不支持 %s 类型", jsonKind(left))
+	case "=~", "matches":
+		pattern, err := regexp.Compile(cellText(right))
+		if err != nil {
+			return false, fmt.Errorf("无效的正则表达式: %v", err)
+		}
+		return pattern.MatchString(cellText(left)), nil
+	}
+	return false, fmt.Errorf("不支持的运算符: %s", operator)
+}
+
+// compareOrdered 比较有序值
+func compareOrdered(left, right int, operator string) bool {
+	switch operator {
+	case ">":
+		return left > right
+	case ">=":
+		return left >= right
+	case "<":
+		return left < right
+	default:
+		return left <= right
+	}
+}
+
+// valuesEqual 比较两个值是否相等，数字按数值比较，如 -1.50 与 -1.5 相等
+func valuesEqual(left, right interface{}) bool {
+	if leftNumber, rightNumber, ok := numericOperands(left, right); ok {
+		return leftNumber.Cmp(rightNumber) == 0
+	}
+	return reflect.DeepEqual(normalizeForCompare(left), normalizeForCompare(right))
+}
+
+// normalizeForCompare 通过JSON往返统一值的表示
+func normalizeForCompare(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// numericOperands 将两个操作数转换为高精度数字，两侧都是数字时才按数值比较
+// 超过15位的整数在解析结果时会转为字符串，因此另一侧为数字时，这类字符串同样按数字处理（与diffKind一致），
+// 较短的数字字符串仍按字符串比较，$.code == "7" 不会匹配 "007"
+func numericOperands(left, right interface{}) (*big.Float, *big.Float, bool) {
+	leftNumber, leftOk := exactNumber(left)
+	rightNumber, rightOk := exactNumber(right)
+	if leftOk && !rightOk {
+		rightNumber, rightOk = largeIntegerNumber(right)
+	} else if rightOk && !leftOk {
+		leftNumber, leftOk = largeIntegerNumber(left)
+	}
+	return leftNumber, rightNumber, leftOk && rightOk
+}
+
+// exactNumber 将JSON数字按十进制文本转换为高精度数字，不会因转换为float64而丢失精度
+func exactNumber(value interface{}) (*big.Float, bool) {
+	switch value.(type) {
+	case json.Number, int, int32, int64, float32, float64:
+		return new(big.Float).SetPrec(numberComparePrecision).SetString(cellText(value))
+	}
+	return nil, false
+}
+
+// largeIntegerNumber 将大整数字符串转换为高精度数字
+func largeIntegerNumber(value interface{}) (*big.Float, bool) {
+	if text, ok := value.(string); ok && isLargeIntegerString(text) {
+		return new(big.Float).SetPrec(numberComparePrecision).SetString(text)
+	}
+	return nil, false
+}
+
+// isTruthy 判断值是否为真
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil, undefinedValue:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+	if number, ok := exactNumber(value); ok {
+		return number.Sign() != 0
+	}
+	return true
+}
+
+// describeOperand 格式化操作数的值用于报告，过长时截断
+func describeOperand(value interface{}) string {
+	if _, undefined := value.(undefinedValue); undefined {
+		return "<不存在>"
+	}
+	var text string
+	if str, ok := value.(string); ok {
+		text = strconv.Quote(str)
+	} else if value == nil {
+		text = "null"
+	} else {
+		text = cellText(value)
+	}
+	if len([]rune(text)) > 200 {
+		text = string([]rune(text)[:200]) + "..."
+	}
+	return text
+}
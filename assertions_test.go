@@ -0,0 +1,79 @@
+package main
+
+import "testing"
+
+func TestEvaluateAssertion(t *testing.T) {
+	result := decodeDiffInput(t, `{
+		"success": true,
+		"code": "007",
+		"status": 200,
+		"statusText": "200",
+		"price": 1.50,
+		"id": "9007199254740993",
+		"negative": "-12345678901234567890",
+		"msg": "操作成功",
+		"data": [{"name": "张三"}, {"name": "李四"}],
+		"empty": ""
+	}`)
+	ctx := AssertionContext{Result: result, Duration: 120}
+
+	tests := []struct {
+		expression string
+		passed     bool
+	}{
+		{`$.success == true`, true},
+		{`$.success`, true},
+		{`$.empty`, false},
+		{`$.missing`, false},
+		{`exists($.missing)`, false},
+		{`len($.data) == 2`, true},
+		{`type($.data) == "array"`, true},
+		{`$.data[0].name =~ "^张"`, true},
+		{`$.msg contains "成功"`, true},
+		{`$.data contains "张三"`, false},
+		{`duration < 500`, true},
+		{`duration >= 500`, false},
+
+		// 数字按数值比较
+		{`$.price == 1.5`, true},
+		{`$.status == 200.0`, true},
+		{`$.status > 199`, true},
+
+		// 字符串不会当作数字
+		{`$.code == "7"`, false},
+		{`$.code == "007"`, true},
+		{`$.code == 7`, false},
+		{`$.status == "200"`, false},
+		{`$.statusText == 200`, false},
+		{`$.statusText == "200"`, true},
+
+		// 超过15位的整数字符串与数字按数值精确比较
+		{`$.id == 9007199254740993`, true},
+		{`$.id == 9007199254740992`, false},
+		{`$.id > 9007199254740992`, true},
+		{`$.id <= 9007199254740992`, false},
+		{`$.negative < -12345678901234567889`, true},
+		{`$.id == "9007199254740993"`, true},
+	}
+
+	for _, tt := range tests {
+		got := evaluateAssertion(tt.expression, ctx)
+		if got.Passed != tt.passed {
+			t.Errorf("%s: 结果 %t，期望 %t (实际值 %s，%s)", tt.expression, got.Passed, tt.passed, got.Actual, got.Message)
+		}
+	}
+}
+
+func TestEvaluateAssertionErrors(t *testing.T) {
+	ctx := AssertionContext{Result: decodeDiffInput(t, `{"code": "007", "data": {"a": 1}}`)}
+	for _, expression := range []string{
+		`$.data > 1`,
+		`$.code =~ "("`,
+		`unknown($.code)`,
+		`$.code == `,
+	} {
+		if got := evaluateAssertion(expression, ctx); got.Passed || got.Message == "" {
+			t.Errorf("%s 应失败并说明原因，实际 %+v", expression, got)
+		}
+	}
+}
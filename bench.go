@@ -0,0 +1,813 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// 压测错误分类
+const (
+	BenchErrorTimeout    = "timeout"           // 超过--timeout未收到完整响应
+	BenchErrorConnection = "connection"        // 建立连接或读写失败
+	BenchErrorNotFound   = "service_not_found" // 提供者上不存在该服务或方法
+	BenchErrorInvoke     = "invoke_error"      // 提供者返回调用异常
+)
+
+// benchHistogramBounds 延迟直方图的桶上界，单位毫秒
+var benchHistogramBounds = []float64{0.5, 1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 2000, 5000}
+
+// BenchOptions 压测参数
+type BenchOptions struct {
+	Concurrency int           // 并发数
+	QPS         float64       // 目标QPS，0表示不限速
+	Duration    time.Duration // 压测时长，不含预热
+	Requests    int64         // 请求数，不含预热，0表示只按时长结束
+	Warmup      time.Duration // 预热时长，预热期间的请求不计入统计
+	Timeout     time.Duration // 单次请求超时
+}
+
+// LatencyStats 延迟统计，单位毫秒
+type LatencyStats struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	Max  float64 `json:"max"`
+	P50  float64 `json:"p50"`
+	P75  float64 `json:"p75"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	P999 float64 `json:"p999"`
+}
+
+// HistogramBucket 延迟直方图的桶
+type HistogramBucket struct {
+	UpperBound float64 `json:"le"` // 桶上界(毫秒)，-1表示无上界
+	Count      int64   `json:"count"`
+	Percent    float64 `json:"percent"`
+}
+
+// BenchErrorStat 按分类统计的错误
+type BenchErrorStat struct {
+	Class  string `json:"class"`
+	Count  int64  `json:"count"`
+	Sample string `json:"sample"` // 一条错误信息示例
+}
+
+// ProviderBenchStat 单个服务提供者的压测统计
+type ProviderBenchStat struct {
+	Address    string       `json:"address"`
+	Requests   int64        `json:"requests"`
+	Errors     int64        `json:"errors"`
+	Throughput float64      `json:"throughput"` // 每秒成功请求数
+	Latency    LatencyStats `json:"latency"`
+}
+
+// BenchReport 压测报告
+type BenchReport struct {
+	ServiceName string              `json:"serviceName"`
+	MethodName  string              `json:"methodName"`
+	Registry    string              `json:"registry"`
+	StartedAt   time.Time           `json:"startedAt"`
+	Concurrency int                 `json:"concurrency"`
+	TargetQPS   float64             `json:"targetQps,omitempty"`
+	Warmup      float64             `json:"warmup"`   // 预热时长，单位秒
+	Duration    float64             `json:"duration"` // 统计时长，单位秒
+	ParamSets   int                 `json:"paramSets"`
+	Requests    int64               `json:"requests"`
+	Successes   int64               `json:"successes"`
+	Errors      int64               `json:"errors"`
+	SuccessRate float64             `json:"successRate"` // 百分比
+	Throughput  float64             `json:"throughput"`  // 每秒成功请求数
+	Latency     LatencyStats        `json:"latency"`     // 成功请求的延迟
+	Histogram   []HistogramBucket   `json:"histogram"`
+	ErrorStats  []BenchErrorStat    `json:"errorStats"`
+	Providers   []ProviderBenchStat `json:"providers"`
+}
+
+// benchProviderStats 单个提供者的原始统计
+type benchProviderStats struct {
+	latencies []float64
+	requests  int64
+	errors    int64
+}
+
+// benchCollector 收集压测结果，预热期间的请求不会被记录
+type benchCollector struct {
+	mu        sync.Mutex
+	latencies []float64 // 成功请求的延迟，单位毫秒
+	requests  int64
+	errors    map[string]*BenchErrorStat
+	providers map[string]*benchProviderStats
+}
+
+// record 记录一次请求的结果
+func (bc *benchCollector) record(provider string, latency float64, class, message string) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	stats, ok := bc.providers[provider]
+	if !ok {
+		stats = &benchProviderStats{}
+		bc.providers[provider] = stats
+	}
+	bc.requests++
+	stats.requests++
+
+	if class != "" {
+		stats.errors++
+		errorStat, ok := bc.errors[class]
+		if !ok {
+			errorStat = &BenchErrorStat{Class: class, Sample: message}
+			bc.errors[class] = errorStat
+		}
+		errorStat.Count++
+		return
+	}
+	bc.latencies = append(bc.latencies, latency)
+	stats.latencies = append(stats.latencies, latency)
+}
+
+// snapshot 返回当前的请求数、错误数和自offset之后成功请求的延迟
+func (bc *benchCollector) snapshot(offset int) (int64, int64, []float64) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	var errors int64
+	for _, errorStat := range bc.errors {
+		errors += errorStat.Count
+	}
+	window := make([]float64, len(bc.latencies)-offset)
+	copy(window, bc.latencies[offset:])
+	return bc.requests, errors, window
+}
+
+// rateLimiter 按固定间隔放行请求，控制总QPS
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// Wait 等待下一个可发送的时间点
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BenchRunner 压测执行器
+// 提供者列表和调用命令在开始前解析和编码好，压测期间直接通过连接池中的telnet会话发送，
+// 不再访问注册中心，也不输出每次调用的日志
+type BenchRunner struct {
+	// 原子操作的64位字段放在最前面，保证在32位平台上8字节对齐
+	seq      uint64
+	measured int64
+
+	opts      BenchOptions
+	providers []string
+	payloads  [][]byte
+	pool      *ConnectionPool
+	collector *benchCollector
+	progress  io.Writer
+}
+
+// NewBenchRunner 创建压测执行器
+func NewBenchRunner(opts BenchOptions, providers []string, payloads [][]byte, progress io.Writer) *BenchRunner {
+	// 每个提供者的连接数与并发数一致，避免请求在连接池中排队
+	return &BenchRunner{
+		opts:      opts,
+		providers: providers,
+		payloads:  payloads,
+		pool:      NewConnectionPool(opts.Concurrency, time.Minute),
+		collector: &benchCollector{
+			errors:    make(map[string]*BenchErrorStat),
+			providers: make(map[string]*benchProviderStats),
+		},
+		progress: progress,
+	}
+}
+
+// Run 执行压测，ctx取消时立即停止
+func (br *BenchRunner) Run(ctx context.Context) (time.Time, time.Duration) {
+	defer br.pool.Close()
+
+	start := time.Now()
+	measureStart := start.Add(br.opts.Warmup)
+
+	// 按时长结束时，进行中的请求仍会完成
+	stopCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	if br.opts.Duration > 0 {
+		var cancel context.CancelFunc
+		stopCtx, cancel = context.WithDeadline(stopCtx, measureStart.Add(br.opts.Duration))
+		defer cancel()
+	}
+
+	var limiter *rateLimiter
+	if br.opts.QPS > 0 {
+		limiter = &rateLimiter{interval: time.Duration(float64(time.Second) / br.opts.QPS)}
+	}
+
+	reporterDone := make(chan struct{})
+	go func() {
+		defer close(reporterDone)
+		br.reportProgress(stopCtx, start, measureStart)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < br.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for stopCtx.Err() == nil {
+				if limiter != nil && limiter.Wait(stopCtx) != nil {
+					return
+				}
+
+				warmup := time.Now().Before(measureStart)
+				if !warmup && br.opts.Requests > 0 && atomic.AddInt64(&br.measured, 1) > br.opts.Requests {
+					stop()
+					return
+				}
+
+				seq := atomic.AddUint64(&br.seq, 1) - 1
+				provider := br.providers[seq%uint64(len(br.providers))]
+				payload := br.payloads[seq%uint64(len(br.payloads))]
+
+				latency, class, message := br.invokeOnce(ctx, provider, payload)
+				if ctx.Err() != nil {
+					return
+				}
+				if !warmup {
+					br.collector.record(provider, latency, class, message)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	stop()
+	<-reporterDone
+
+	measured := time.Since(measureStart)
+	if measured < 0 {
+		measured = 0
+	}
+	return start, measured
+}
+
+// invokeOnce 通过连接池中的会话发送一次调用，返回延迟(毫秒)和错误分类
+func (br *BenchRunner) invokeOnce(ctx context.Context, provider string, payload []byte) (float64, string, string) {
+	reqCtx, cancel := context.WithTimeout(ctx, br.opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	pc, err := br.pool.Acquire(reqCtx, "dubbo", provider)
+	if err != nil {
+		if reqCtx.Err() == context.DeadlineExceeded {
+			return 0, BenchErrorTimeout, err.Error()
+		}
+		return 0, BenchErrorConnection, err.Error()
+	}
+
+	deadline, _ := reqCtx.Deadline()
+	pc.SetDeadline(deadline)
+	if _, err := pc.Write(payload); err != nil {
+		pc.MarkBroken()
+		br.pool.Release(pc)
+		return 0, classifyNetError(err), fmt.Sprintf("发送invoke命令失败: %v", err)
+	}
+	response, err := readUntilPrompt(pc, deadline)
+	latency := msSince(start)
+	if err != nil {
+		// 响应未对齐到提示符，会话不可复用
+		pc.MarkBroken()
+		br.pool.Release(pc)
+		return latency, classifyNetError(err), err.Error()
+	}
+	br.pool.Release(pc)
+
+	// 错误关键字均为ASCII，无需转换GBK编码即可判断
+	text := string(response)
+	if isTelnetErrorResponse(text) {
+		if isTelnetNotFoundResponse(text) {
+			return latency, BenchErrorNotFound, firstLine(text)
+		}
+		return latency, BenchErrorInvoke, firstLine(text)
+	}
+	return latency, "", ""
+}
+
+// reportProgress 每秒输出一次实时统计
+func (br *BenchRunner) reportProgress(ctx context.Context, start, measureStart time.Time) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var lastRequests int64
+	offset := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			elapsed := now.Sub(start).Round(time.Second)
+			if now.Before(measureStart) {
+				fmt.Fprintf(br.progress, "[%5s] 预热中...\n", elapsed)
+				continue
+			}
+
+			requests, errors, window := br.collector.snapshot(offset)
+			offset += len(window)
+			sort.Float64s(window)
+			fmt.Fprintf(br.progress, "[%5s] 请求 %-8d 错误 %-6d QPS %-9.1f p50 %-9s p99 %s\n",
+				elapsed, requests, errors, float64(requests-lastRequests),
+				formatMs(percentile(window, 50)), formatMs(percentile(window, 99)))
+			lastRequests = requests
+		}
+	}
+}
+
+// Report 汇总压测结果
+func (br *BenchRunner) Report(serviceName, methodName, registry string, startedAt time.Time, measured time.Duration) *BenchReport {
+	bc := br.collector
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	seconds := measured.Seconds()
+	report := &BenchReport{
+		ServiceName: serviceName,
+		MethodName:  methodName,
+		Registry:    registry,
+		StartedAt:   startedAt,
+		Concurrency: br.opts.Concurrency,
+		TargetQPS:   br.opts.QPS,
+		Warmup:      br.opts.Warmup.Seconds(),
+		Duration:    round3(seconds),
+		ParamSets:   len(br.payloads),
+		Requests:    bc.requests,
+		Successes:   int64(len(bc.latencies)),
+		Latency:     latencyStats(bc.latencies),
+		Histogram:   latencyHistogram(bc.latencies),
+		ErrorStats:  []BenchErrorStat{},
+		Providers:   []ProviderBenchStat{},
+	}
+	report.Errors = report.Requests - report.Successes
+	if report.Requests > 0 {
+		report.SuccessRate = round3(float64(report.Successes) * 100 / float64(report.Requests))
+	}
+	if seconds > 0 {
+		report.Throughput = round3(float64(report.Successes) / seconds)
+	}
+
+	for _, errorStat := range bc.errors {
+		report.ErrorStats = append(report.ErrorStats, *errorStat)
+	}
+	sort.Slice(report.ErrorStats, func(i, j int) bool {
+		return report.ErrorStats[i].Count > report.ErrorStats[j].Count
+	})
+
+	for _, address := range br.providers {
+		stats, ok := bc.providers[address]
+		if !ok {
+			stats = &benchProviderStats{}
+		}
+		providerStat := ProviderBenchStat{
+			Address:  address,
+			Requests: stats.requests,
+			Errors:   stats.errors,
+			Latency:  latencyStats(stats.latencies),
+		}
+		if seconds > 0 {
+			providerStat.Throughput = round3(float64(len(stats.latencies)) / seconds)
+		}
+		report.Providers = append(report.Providers, providerStat)
+	}
+	return report
+}
+
+// latencyStats 计算延迟统计
+func latencyStats(latencies []float64) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+	sorted := make([]float64, len(latencies))
+	copy(sorted, latencies)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, latency := range sorted {
+		sum += latency
+	}
+	return LatencyStats{
+		Min:  sorted[0],
+		Mean: round3(sum / float64(len(sorted))),
+		Max:  sorted[len(sorted)-1],
+		P50:  percentile(sorted, 50),
+		P75:  percentile(sorted, 75),
+		P90:  percentile(sorted, 90),
+		P95:  percentile(sorted, 95),
+		P99:  percentile(sorted, 99),
+		P999: percentile(sorted, 99.9),
+	}
+}
+
+// percentile 返回已排序延迟的百分位数(最近秩法)
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// latencyHistogram 按固定的桶上界统计延迟分布
+func latencyHistogram(latencies []float64) []HistogramBucket {
+	buckets := make([]HistogramBucket, len(benchHistogramBounds)+1)
+	for i, bound := range benchHistogramBounds {
+		buckets[i].UpperBound = bound
+	}
+	buckets[len(buckets)-1].UpperBound = -1
+
+	for _, latency := range latencies {
+		index := sort.SearchFloat64s(benchHistogramBounds, latency)
+		buckets[index].Count++
+	}
+	for i := range buckets {
+		if len(latencies) > 0 {
+			buckets[i].Percent = round3(float64(buckets[i].Count) * 100 / float64(len(latencies)))
+		}
+	}
+	return buckets
+}
+
+// classifyNetError 区分超时和其他网络错误
+func classifyNetError(err error) string {
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return BenchErrorTimeout
+	}
+	if strings.Contains(err.Error(), "timeout") {
+		return BenchErrorTimeout
+	}
+	return BenchErrorConnection
+}
+
+// firstLine 返回响应的第一行，用作错误示例
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if index := strings.IndexAny(text, "\r\n"); index >= 0 {
+		text = text[:index]
+	}
+	if len(text) > 200 {
+		text = previewBytes([]byte(text), 200) + "..."
+	}
+	return text
+}
+
+// formatMs 格式化毫秒数
+func formatMs(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.2fs", ms/1000)
+	}
+	return fmt.Sprintf("%.2fms", ms)
+}
+
+// printBenchReport 输出压测报告
+func printBenchReport(w io.Writer, report *BenchReport) {
+	fmt.Fprintln(w)
+	color.New(color.FgGreen).Fprintf(w, "压测结果: %s.%s\n", report.ServiceName, report.MethodName)
+	fmt.Fprintf(w, "  并发: %d  目标QPS: %s  预热: %.1fs  统计时长: %.2fs  参数组: %d\n",
+		report.Concurrency, targetQPSText(report.TargetQPS), report.Warmup, report.Duration, report.ParamSets)
+	fmt.Fprintf(w, "  请求: %d  成功: %d  失败: %d  成功率: %.2f%%  吞吐量: %.1f req/s\n",
+		report.Requests, report.Successes, report.Errors, report.SuccessRate, report.Throughput)
+
+	latency := report.Latency
+	fmt.Fprintln(w)
+	color.New(color.FgCyan).Fprintln(w, "延迟:")
+	fmt.Fprintf(w, "  min %s  mean %s  max %s\n", formatMs(latency.Min), formatMs(latency.Mean), formatMs(latency.Max))
+	fmt.Fprintf(w, "  p50 %s  p75 %s  p90 %s  p95 %s  p99 %s  p99.9 %s\n",
+		formatMs(latency.P50), formatMs(latency.P75), formatMs(latency.P90),
+		formatMs(latency.P95), formatMs(latency.P99), formatMs(latency.P999))
+
+	// 只输出第一个到最后一个非空桶之间的部分
+	first, last := -1, -1
+	var maxCount int64
+	for i, bucket := range report.Histogram {
+		if bucket.Count > 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+		if bucket.Count > maxCount {
+			maxCount = bucket.Count
+		}
+	}
+	if first >= 0 {
+		fmt.Fprintln(w)
+		color.New(color.FgCyan).Fprintln(w, "延迟分布:")
+		for i := first; i <= last; i++ {
+			bucket := report.Histogram[i]
+			label := "> " + formatMs(benchHistogramBounds[len(benchHistogramBounds)-1])
+			if bucket.UpperBound >= 0 {
+				label = "<= " + formatMs(bucket.UpperBound)
+			}
+			bar := strings.Repeat("█", int(float64(bucket.Count)*40/float64(maxCount)))
+			fmt.Fprintf(w, "  %-10s %-40s %d (%.2f%%)\n", label, bar, bucket.Count, bucket.Percent)
+		}
+	}
+
+	if len(report.ErrorStats) > 0 {
+		fmt.Fprintln(w)
+		color.New(color.FgRed).Fprintln(w, "错误分类:")
+		for _, errorStat := range report.ErrorStats {
+			fmt.Fprintf(w, "  %-18s %d  例: %s\n", errorStat.Class, errorStat.Count, errorStat.Sample)
+		}
+	}
+
+	fmt.Fprintln(w)
+	color.New(color.FgCyan).Fprintln(w, "服务提供者:")
+	for _, provider := range report.Providers {
+		fmt.Fprintf(w, "  %-22s 请求 %-8d 失败 %-6d 吞吐量 %.1f req/s  p50 %s  p99 %s\n",
+			provider.Address, provider.Requests, provider.Errors, provider.Throughput,
+			formatMs(provider.Latency.P50), formatMs(provider.Latency.P99))
+	}
+}
+
+// targetQPSText 目标QPS的展示文本
+func targetQPSText(qps float64) string {
+	if qps <= 0 {
+		return "不限"
+	}
+	return fmt.Sprintf("%.0f", qps)
+}
+
+// loadParamSets 加载参数组文件：JSON数组的数组，或每行一个JSON数组
+func loadParamSets(file string) ([][]interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取参数组文件失败: %v", err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	var sets [][]interface{}
+	for {
+		var value interface{}
+		if err := decoder.Decode(&value); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("解析参数组文件失败: %v", err)
+		}
+
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("参数组必须是JSON数组: %s", cellText(value))
+		}
+		// 整个文件是一个数组的数组时展开为多个参数组
+		if len(sets) == 0 && len(list) > 0 && !decoder.More() && allArrays(list) {
+			for _, item := range list {
+				sets = append(sets, convertJSONNumbers(item.([]interface{})))
+			}
+			continue
+		}
+		sets = append(sets, convertJSONNumbers(list))
+	}
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("参数组文件 %s 中没有参数", file)
+	}
+	return sets, nil
+}
+
+// allArrays 判断列表中的元素是否都是数组
+func allArrays(list []interface{}) bool {
+	for _, item := range list {
+		if _, ok := item.([]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveBenchProviders 解析压测的目标提供者，ZooKeeper模式下使用过滤后的全部提供者
+func resolveBenchProviders(cfg *DubboConfig, serviceName string) ([]string, error) {
+	c := &RealDubboClient{config: cfg}
+	registryURL, err := c.parseRegistryURL()
+	if err != nil {
+		return nil, fmt.Errorf("解析注册中心地址失败: %v", err)
+	}
+
+	switch registryURL.Protocol {
+	case "zookeeper":
+		candidates, err := c.getProviderCandidates(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		var providers []string
+		for _, candidate := range candidates {
+			if candidate.Excluded == "" {
+				providers = append(providers, candidate.Address)
+			}
+		}
+		if len(providers) == 0 {
+			return nil, fmt.Errorf("服务 %s 过滤后没有可用的服务提供者", serviceName)
+		}
+		return providers, nil
+	case "dubbo", "direct":
+		return []string{registryURL.Address}, nil
+	default:
+		return nil, fmt.Errorf("压测暂不支持 %s 注册中心", registryURL.Protocol)
+	}
+}
+
+// bench命令 - 压测
+func newBenchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench [service] [method] [params...] | [expression]",
+		Short: "压测Dubbo服务方法",
+		Long: `以指定的并发和QPS压测Dubbo服务方法，实时输出吞吐量和延迟，结束后输出延迟分位数、
+延迟分布、错误分类和每个服务提供者的吞吐量
+
+压测前解析好服务提供者并编码调用命令，压测期间通过连接池中的telnet会话直接发送，
+请求按轮询分配到过滤后的所有提供者和参数组
+
+示例:
+  dubbo-invoke bench 'com.example.UserService.getUserById(123)' -C 20 -d 30s
+  dubbo-invoke bench com.example.UserService getUserById 123 -C 50 --qps 2000 -n 100000 --warmup 5s
+  dubbo-invoke bench com.example.UserService getUserById --params-file ids.jsonl --json bench.json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runBenchCommand,
+	}
+
+	cmd.Flags().IntP("concurrency", "C", 10, "并发数")
+	cmd.Flags().Float64("qps", 0, "目标QPS，0表示不限速")
+	cmd.Flags().DurationP("duration", "d", 0, "压测时长(不含预热)，未指定时长和请求数时默认10s")
+	cmd.Flags().Int64P("requests", "n", 0, "请求数(不含预热)")
+	cmd.Flags().Duration("warmup", 0, "预热时长，预热期间的请求不计入统计")
+	cmd.Flags().String("params-file", "", "参数组文件：JSON数组的数组，或每行一个JSON数组，请求轮流使用")
+	cmd.Flags().String("json", "", "JSON报告输出文件")
+	cmd.Flags().StringP("version", "V", "", "服务版本")
+	cmd.Flags().StringP("group", "g", "", "服务分组")
+	cmd.Flags().String("tag", "", "服务标签，用于过滤服务提供者")
+	cmd.Flags().StringSliceP("types", "T", nil, "参数类型列表")
+	cmd.Flags().String("metadata", "", "服务定义元数据文件(JSON)，用于压测前校验参数")
+	cmd.Flags().Bool("no-validate", false, "跳过压测前的参数校验")
+
+	return cmd
+}
+
+// runBenchCommand 执行压测
+func runBenchCommand(cmd *cobra.Command, args []string) error {
+	var serviceName, methodName string
+	var params []string
+	if strings.Contains(args[0], "(") && strings.Contains(args[0], ")") {
+		serviceName, methodName, params = parseInvokeExpression(args[0])
+		if serviceName == "" || methodName == "" {
+			return fmt.Errorf("无效的调用表达式格式，期望格式: service.method(params)")
+		}
+	} else {
+		if len(args) < 2 {
+			return fmt.Errorf("需要至少指定服务名和方法名")
+		}
+		serviceName = args[0]
+		methodName = args[1]
+		params = args[2:]
+	}
+
+	registry, _ := cmd.Flags().GetString("registry")
+	appName, _ := cmd.Flags().GetString("app")
+	timeout, _ := cmd.Flags().GetInt("timeout")
+	version, _ := cmd.Flags().GetString("version")
+	group, _ := cmd.Flags().GetString("group")
+	tag, _ := cmd.Flags().GetString("tag")
+	types, _ := cmd.Flags().GetStringSlice("types")
+	metadataFile, _ := cmd.Flags().GetString("metadata")
+	noValidate, _ := cmd.Flags().GetBool("no-validate")
+	paramsFile, _ := cmd.Flags().GetString("params-file")
+	jsonFile, _ := cmd.Flags().GetString("json")
+
+	opts := BenchOptions{Timeout: time.Duration(timeout) * time.Millisecond}
+	opts.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+	opts.QPS, _ = cmd.Flags().GetFloat64("qps")
+	opts.Duration, _ = cmd.Flags().GetDuration("duration")
+	opts.Requests, _ = cmd.Flags().GetInt64("requests")
+	opts.Warmup, _ = cmd.Flags().GetDuration("warmup")
+	if opts.Concurrency <= 0 {
+		return fmt.Errorf("并发数必须大于0")
+	}
+	if opts.Duration <= 0 && opts.Requests <= 0 {
+		opts.Duration = 10 * time.Second
+	}
+
+	config := &DubboConfig{
+		Registry:       registry,
+		Application:    appName,
+		Timeout:        opts.Timeout,
+		Version:        version,
+		Group:          group,
+		Tag:            tag,
+		MetadataFile:   metadataFile,
+		MaxPayloadSize: maxPayloadBytes(cmd),
+	}
+	activeProfile(cmd).Apply(config)
+
+	// 准备参数组
+	var paramSets [][]interface{}
+	if paramsFile != "" {
+		var err error
+		if paramSets, err = loadParamSets(paramsFile); err != nil {
+			return err
+		}
+	} else {
+		parsedParams, err := parseParams(params, types)
+		if err != nil {
+			return fmt.Errorf("解析参数失败: %v", err)
+		}
+		paramSets = [][]interface{}{parsedParams}
+	}
+
+	// 与invoke一样转换参数类型，并预先编码所有参数组的调用命令
+	converter := &DubboClient{config: config}
+	encoder := &RealDubboClient{config: config}
+	payloads := make([][]byte, len(paramSets))
+	for i, paramSet := range paramSets {
+		if !noValidate {
+			if err := ValidateInvokeArguments(config, serviceName, methodName, types, paramSet); err != nil {
+				return fmt.Errorf("第%d组参数: %v", i+1, err)
+			}
+		}
+		processedParams, _, err := converter.prepareParams(types, paramSet)
+		if err != nil {
+			return fmt.Errorf("第%d组参数: %v", i+1, err)
+		}
+		_, payload, _, err := encoder.buildInvokePayload(serviceName, methodName, processedParams)
+		if err != nil {
+			return fmt.Errorf("第%d组参数: %v", i+1, err)
+		}
+		payloads[i] = payload
+	}
+
+	providers, err := resolveBenchProviders(config, serviceName)
+	if err != nil {
+		return err
+	}
+
+	color.Green("压测 %s.%s: 并发 %d, 目标QPS %s, 提供者 %s",
+		serviceName, methodName, opts.Concurrency, targetQPSText(opts.QPS), strings.Join(providers, ", "))
+
+	runner := NewBenchRunner(opts, providers, payloads, os.Stdout)
+	startedAt, measured := runner.Run(cmd.Context())
+	report := runner.Report(serviceName, methodName, registry, startedAt, measured)
+	printBenchReport(os.Stdout, report)
+
+	if jsonFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("生成JSON报告失败: %v", err)
+		}
+		if err := os.WriteFile(jsonFile, data, 0644); err != nil {
+			return fmt.Errorf("写入JSON报告失败: %v", err)
+		}
+		fmt.Printf("报告已写入: %s\n", jsonFile)
+	}
+
+	if cmd.Context().Err() != nil {
+		return fmt.Errorf("压测被中断")
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// loadBalanceFirst 当前使用的负载均衡策略：按注册顺序选择第一个可用的提供者
+const loadBalanceFirst = "first"
+
+// ProviderCandidate 注册中心中的服务提供者
+type ProviderCandidate struct {
+	URL      string   `json:"url"`
+	Address  string   `json:"address"`
+	Version  string   `json:"version,omitempty"`
+	Group    string   `json:"group,omitempty"`
+	Tag      string   `json:"tag,omitempty"`
+	Methods  []string `json:"methods,omitempty"` // 提供者注册的方法名
+	Selected bool     `json:"selected"`
+	Excluded string   `json:"excluded,omitempty"` // 被过滤掉的原因
+}
+
+// CallPlan 调用计划，描述一次调用会连接哪个提供者、发送什么内容
+type CallPlan struct {
+	Registry     string              `json:"registry"`
+	RegistryType string              `json:"registryType"`
+	ServiceName  string              `json:"serviceName"`
+	MethodName   string              `json:"methodName"`
+	Version      string              `json:"version,omitempty"`
+	Group        string              `json:"group,omitempty"`
+	Tag          string              `json:"tag,omitempty"`
+	Candidates   []ProviderCandidate `json:"candidates"`
+	LoadBalance  string              `json:"loadBalance"`
+	Provider     string              `json:"provider"` // 选中的提供者地址
+	Params       []interface{}       `json:"params"`
+	ParamTypes   []string            `json:"paramTypes"`
+	Protocol     string              `json:"protocol"`
+	Encoding     string              `json:"encoding"`
+	Command      string              `json:"command"`     // 发送的telnet命令
+	PayloadSize  int                 `json:"payloadSize"` // 编码后的字节数
+	PayloadHex   string              `json:"payloadHex"`  // 编码后的字节，十六进制
+	Warnings     []string            `json:"warnings,omitempty"`
+}
+
+// PlanInvoke 解析注册中心和服务提供者、应用过滤和负载均衡、生成发送内容，但不连接服务提供者也不发送任何数据
+// paramTypes中未指定的类型按参数值推断，仅用于展示
+func PlanInvoke(cfg *DubboConfig, serviceName, methodName string, paramTypes []string, params []interface{}) (*CallPlan, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("配置不能为空")
+	}
+	if serviceName == "" {
+		return nil, fmt.Errorf("服务名不能为空")
+	}
+	if methodName == "" {
+		return nil, fmt.Errorf("方法名不能为空")
+	}
+
+	// 与buildDubboInvokeCommand一样，使用未连接的客户端复用真实调用的格式化逻辑
+	c := &RealDubboClient{config: cfg}
+	registryURL, err := c.parseRegistryURL()
+	if err != nil {
+		return nil, fmt.Errorf("解析注册中心地址失败: %v", err)
+	}
+
+	plan := &CallPlan{
+		Registry:     cfg.Registry,
+		RegistryType: registryURL.Protocol,
+		ServiceName:  serviceName,
+		MethodName:   methodName,
+		Version:      cfg.Version,
+		Group:        cfg.Group,
+		Tag:          cfg.Tag,
+		Candidates:   []ProviderCandidate{},
+		LoadBalance:  loadBalanceFirst,
+		Params:       params,
+		ParamTypes:   make([]string, len(params)),
+		Protocol:     "dubbo-telnet",
+	}
+
+	// 推断参数类型
+	inferrer := &DubboClient{}
+	for i, param := range params {
+		if i < len(paramTypes) && paramTypes[i] != "" {
+			plan.ParamTypes[i] = paramTypes[i]
+		} else {
+			plan.ParamTypes[i] = inferrer.inferParamType(param)
+		}
+	}
+
+	// 解析服务提供者
+	switch registryURL.Protocol {
+	case "zookeeper":
+		candidates, err := c.getProviderCandidates(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		plan.Candidates = candidates
+		if selected := selectProvider(plan.Candidates); selected != nil {
+			plan.Provider = selected.Address
+		} else {
+			plan.Warnings = append(plan.Warnings, "过滤后没有可用的服务提供者，调用将失败")
+		}
+	case "dubbo", "direct":
+		plan.Provider = registryURL.Address
+		plan.LoadBalance = "none"
+		if cfg.Version != "" || cfg.Group != "" || cfg.Tag != "" {
+			plan.Warnings = append(plan.Warnings, "直连模式不经过注册中心，版本/分组/标签过滤不生效")
+		}
+	case "nacos":
+		plan.Warnings = append(plan.Warnings, "Nacos注册中心暂不支持泛化调用，调用将失败")
+	default:
+		return nil, fmt.Errorf("不支持的注册中心类型: %s", registryURL.Protocol)
+	}
+
+	// 生成与真实调用完全一致的发送内容
+	command, payload, encoding, err := c.buildInvokePayload(serviceName, methodName, params)
+	if err != nil {
+		return nil, err
+	}
+	plan.Encoding = encoding
+	plan.Command = command
+	plan.PayloadSize = len(payload)
+	plan.PayloadHex = hex.EncodeToString(payload)
+
+	return plan, nil
+}
+
+// getProviderCandidates 从ZooKeeper读取服务提供者列表，并按版本、分组和标签过滤
+func (c *RealDubboClient) getProviderCandidates(serviceName string) ([]ProviderCandidate, error) {
+	registryURL, err := c.parseRegistryURL()
+	if err != nil {
+		return nil, fmt.Errorf("解析注册中心地址失败: %v", err)
+	}
+
+	// 连接到ZooKeeper
+	zkConn, err := acquireZooKeeper(registryURL.Address)
+	if err != nil {
+		return nil, fmt.Errorf("连接ZooKeeper失败: %v", err)
+	}
+
+	// 构建服务路径
+	servicePath := fmt.Sprintf("/dubbo/%s/providers", serviceName)
+	fmt.Printf("查找服务提供者路径: %s\n", servicePath)
+
+	// 检查路径是否存在
+	exists, _, err := zkConn.Exists(servicePath)
+	if err != nil {
+		return nil, fmt.Errorf("检查服务路径失败: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("服务 %s 在ZooKeeper中不存在", serviceName)
+	}
+
+	// 获取提供者列表
+	providers, _, err := zkConn.Children(servicePath)
+	if err != nil {
+		return nil, fmt.Errorf("获取服务提供者列表失败: %v", err)
+	}
+
+	candidates := make([]ProviderCandidate, 0, len(providers))
+	for _, providerURL := range providers {
+		candidate, err := parseProviderCandidate(providerURL)
+		if err != nil {
+			fmt.Printf("跳过无法解析的提供者: %v\n", err)
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	filterProviders(candidates, c.config.Version, c.config.Group, c.config.Tag)
+	return candidates, nil
+}
+
+// parseProviderCandidate 解析ZooKeeper中的提供者URL
+func parseProviderCandidate(providerURL string) (ProviderCandidate, error) {
+	decodedURL, err := url.QueryUnescape(providerURL)
+	if err != nil {
+		return ProviderCandidate{}, fmt.Errorf("URL解码失败: %v", err)
+	}
+
+	// Dubbo提供者URL格式: dubbo://ip:port/serviceName?version=1.0.0&...
+	if !strings.HasPrefix(decodedURL, "dubbo://") {
+		return ProviderCandidate{}, fmt.Errorf("无效的提供者URL格式: %s", decodedURL)
+	}
+	parsed, err := url.Parse(decodedURL)
+	if err != nil || parsed.Host == "" {
+		return ProviderCandidate{}, fmt.Errorf("无效的提供者URL格式: %s", decodedURL)
+	}
+
+	query := parsed.Query()
+	tag := query.Get("dubbo.tag")
+	if tag == "" {
+		tag = query.Get("tag")
+	}
+
+	var methods []string
+	if value := query.Get("methods"); value != "" {
+		methods = strings.Split(value, ",")
+	}
+
+	return ProviderCandidate{
+		URL:     decodedURL,
+		Address: parsed.Host,
+		Version: query.Get("version"),
+		Group:   query.Get("group"),
+		Tag:     tag,
+		Methods: methods,
+	}, nil
+}
+
+// filterProviders 按版本、分组和标签过滤提供者，未指定的条件不参与过滤
+func filterProviders(candidates []ProviderCandidate, version, group, tag string) {
+	for i := range candidates {
+		candidate := &candidates[i]
+		switch {
+		case version != "" && candidate.Version != version:
+			candidate.Excluded = fmt.Sprintf("版本不匹配: %s", candidate.Version)
+		case group != "" && candidate.Group != group:
+			candidate.Excluded = fmt.Sprintf("分组不匹配: %s", candidate.Group)
+		case tag != "" && candidate.Tag != tag:
+			candidate.Excluded = fmt.Sprintf("标签不匹配: %s", candidate.Tag)
+		}
+	}
+}
+
+// selectProvider 按负载均衡策略选择提供者并标记为选中，没有可用提供者时返回nil
+func selectProvider(candidates []ProviderCandidate) *ProviderCandidate {
+	for i := range candidates {
+		if candidates[i].Excluded == "" {
+			candidates[i].Selected = true
+			return &candidates[i]
+		}
+	}
+	return nil
+}
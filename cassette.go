@@ -0,0 +1,464 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// cassetteVersion 录制文件格式版本
+const cassetteVersion = 1
+
+// 回放结果状态
+const (
+	ReplaySame      = "same"
+	ReplayDifferent = "different"
+	ReplayError     = "error"
+)
+
+// cassetteMu 串行化对录制文件的读改写，Web端并发调用时避免互相覆盖
+var cassetteMu sync.Mutex
+
+// Cassette 调用录制文件，记录请求、实际调用的服务提供者和响应
+type Cassette struct {
+	Version      int           `yaml:"version" json:"version"`
+	Ignore       []string      `yaml:"ignore,omitempty" json:"ignore,omitempty"` // 回放时默认忽略的路径，如时间戳、ID
+	Interactions []Interaction `yaml:"interactions" json:"interactions"`
+}
+
+// Interaction 一次录制的调用
+type Interaction struct {
+	RecordedAt time.Time        `yaml:"recordedAt" json:"recordedAt"`
+	Request    CassetteRequest  `yaml:"request" json:"request"`
+	Provider   string           `yaml:"provider,omitempty" json:"provider,omitempty"`
+	Response   CassetteResponse `yaml:"response" json:"response"`
+}
+
+// CassetteRequest 录制的调用请求
+type CassetteRequest struct {
+	Registry    string   `yaml:"registry" json:"registry"`
+	Namespace   string   `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	App         string   `yaml:"app,omitempty" json:"app,omitempty"`
+	ServiceName string   `yaml:"service" json:"service"`
+	MethodName  string   `yaml:"method" json:"method"`
+	Parameters  string   `yaml:"parameters" json:"parameters"` // JSON数组文本，保持Long类型精度
+	Types       []string `yaml:"types,omitempty" json:"types,omitempty"`
+	Version     string   `yaml:"version,omitempty" json:"version,omitempty"`
+	Group       string   `yaml:"group,omitempty" json:"group,omitempty"`
+	Tag         string   `yaml:"tag,omitempty" json:"tag,omitempty"`
+	Timeout     int      `yaml:"timeout,omitempty" json:"timeout,omitempty"` // 单位毫秒
+}
+
+// CassetteResponse 录制的调用响应
+type CassetteResponse struct {
+	Success  bool   `yaml:"success" json:"success"`
+	Result   string `yaml:"result,omitempty" json:"result,omitempty"` // 格式化后的JSON文本，非JSON结果保持原文
+	Error    string `yaml:"error,omitempty" json:"error,omitempty"`
+	Duration int64  `yaml:"duration" json:"duration"` // 单位毫秒
+}
+
+// ReplayResult 单个调用的回放结果
+type ReplayResult struct {
+	Index            int         `json:"index"`
+	Service          string      `json:"service"`
+	Method           string      `json:"method"`
+	Status           string      `json:"status"`
+	RecordedProvider string      `json:"recordedProvider,omitempty"`
+	Provider         string      `json:"provider,omitempty"`
+	RecordedDuration int64       `json:"recordedDuration"`
+	Duration         int64       `json:"duration"`
+	Diffs            []DiffEntry `json:"diffs,omitempty"`
+	Error            string      `json:"error,omitempty"`
+}
+
+// ReplayReport 回放汇总
+type ReplayReport struct {
+	File      string         `json:"file"`
+	Registry  string         `json:"registry,omitempty"` // 覆盖后的注册中心，未覆盖时为空
+	Ignore    []string       `json:"ignore,omitempty"`
+	Total     int            `json:"total"`
+	Same      int            `json:"same"`
+	Different int            `json:"different"`
+	Errors    int            `json:"errors"`
+	Results   []ReplayResult `json:"results"`
+}
+
+// replayOverrides 回放时覆盖的调用环境，空值表示沿用录制时的配置
+type replayOverrides struct {
+	registry  string
+	namespace string
+	app       string
+	version   string
+	group     string
+	tag       string
+	timeout   int
+}
+
+// LoadCassette 读取录制文件
+func LoadCassette(file string) (*Cassette, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取录制文件失败: %v", err)
+	}
+
+	var cassette Cassette
+	if err := yaml.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("解析录制文件失败: %v", err)
+	}
+	if cassette.Version == 0 {
+		cassette.Version = cassetteVersion
+	}
+	return &cassette, nil
+}
+
+// AppendInteraction 向录制文件追加一次调用，文件不存在时创建
+func AppendInteraction(file string, interaction Interaction) error {
+	cassetteMu.Lock()
+	defer cassetteMu.Unlock()
+
+	cassette := &Cassette{Version: cassetteVersion}
+	if _, err := os.Stat(file); err == nil {
+		loaded, err := LoadCassette(file)
+		if err != nil {
+			return err
+		}
+		cassette = loaded
+	}
+	cassette.Interactions = append(cassette.Interactions, interaction)
+
+	data, err := yaml.Marshal(cassette)
+	if err != nil {
+		return fmt.Errorf("生成录制文件失败: %v", err)
+	}
+
+	// 先写临时文件再重命名，中途退出时不会留下半个文件
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".cassette-*.yaml")
+	if err != nil {
+		return fmt.Errorf("写入录制文件失败: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入录制文件失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入录制文件失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入录制文件失败: %v", err)
+	}
+	return nil
+}
+
+// newInteraction 根据调用配置和结果构建录制记录
+func newInteraction(config *DubboConfig, serviceName, methodName string, types []string, params []interface{}, provider string, result interface{}, invokeErr error, duration int64) Interaction {
+	paramsJson, err := json.Marshal(params)
+	if err != nil || params == nil {
+		paramsJson = []byte("[]")
+	}
+
+	interaction := Interaction{
+		RecordedAt: time.Now(),
+		Request: CassetteRequest{
+			Registry:    config.Registry,
+			Namespace:   config.Namespace,
+			App:         config.Application,
+			ServiceName: serviceName,
+			MethodName:  methodName,
+			Parameters:  string(paramsJson),
+			Types:       types,
+			Version:     config.Version,
+			Group:       config.Group,
+			Tag:         config.Tag,
+			Timeout:     int(config.Timeout / time.Millisecond),
+		},
+		Provider: provider,
+		Response: CassetteResponse{
+			Success:  invokeErr == nil,
+			Duration: duration,
+		},
+	}
+	if invokeErr != nil {
+		interaction.Response.Error = invokeErr.Error()
+	} else {
+		interaction.Response.Result = cassetteResultText(result)
+	}
+	return interaction
+}
+
+// recordInteraction 录制一次调用，录制失败只输出警告，不影响调用结果
+func recordInteraction(file string, interaction Interaction) {
+	if err := AppendInteraction(file, interaction); err != nil {
+		color.Yellow("警告: 录制调用失败: %v", err)
+	}
+}
+
+// cassetteResultText 将结果格式化为便于阅读和比较的文本
+func cassetteResultText(result interface{}) string {
+	decoded := decodeResult(result)
+	if text, ok := decoded.(string); ok {
+		return text
+	}
+	data, err := json.MarshalIndent(decoded, "", "  ")
+	if err != nil {
+		return cellText(result)
+	}
+	return string(data)
+}
+
+// newReplayCommand 创建replay命令
+func newReplayCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay <cassette.yaml>",
+		Short: "回放录制的调用并比较结果",
+		Long: `按顺序重新执行录制文件中的调用，逐个与录制的响应做结构化比较
+
+默认使用录制时的注册中心和调用参数，通过 --registry 等参数可以回放到其他环境。
+--ignore 指定忽略的路径(可重复)，与录制文件中的 ignore 列表合并，匹配的字段及其子字段不参与比较:
+  $.data.updateTime      指定路径
+  $.data.items[*].id     数组中每个元素的字段
+  $..traceId             任意层级的字段
+  timestamp              等同于 $..timestamp
+
+存在差异或调用失败时以非0状态退出
+
+示例:
+  dubbo-invoke invoke 'com.example.UserService.getUserById(123)' --record user.yaml
+  dubbo-invoke replay user.yaml
+  dubbo-invoke replay user.yaml --registry zookeeper://10.0.0.2:2181 --ignore timestamp --ignore '$.data.id'
+  dubbo-invoke replay user.yaml --json replay.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runReplayCommand,
+	}
+
+	cmd.Flags().String("namespace", "", "覆盖命名空间")
+	cmd.Flags().StringP("version", "V", "", "覆盖服务版本")
+	cmd.Flags().StringP("group", "g", "", "覆盖服务分组")
+	cmd.Flags().String("tag", "", "覆盖服务标签")
+	cmd.Flags().StringArray("ignore", nil, "比较时忽略的路径，可重复指定，如 '$..timestamp'、'$.data.items[*].id'")
+	cmd.Flags().String("json", "", "JSON报告输出文件")
+
+	return cmd
+}
+
+// runReplayCommand 执行回放
+func runReplayCommand(cmd *cobra.Command, args []string) error {
+	file := args[0]
+	cassette, err := LoadCassette(file)
+	if err != nil {
+		return err
+	}
+	if len(cassette.Interactions) == 0 {
+		return fmt.Errorf("录制文件 %s 中没有调用记录", file)
+	}
+
+	// 只覆盖显式指定的参数，全局参数的默认值不覆盖录制时的配置
+	var overrides replayOverrides
+	if cmd.Flags().Changed("registry") {
+		overrides.registry, _ = cmd.Flags().GetString("registry")
+	}
+	if cmd.Flags().Changed("app") {
+		overrides.app, _ = cmd.Flags().GetString("app")
+	}
+	if cmd.Flags().Changed("timeout") {
+		overrides.timeout, _ = cmd.Flags().GetInt("timeout")
+	}
+	overrides.namespace, _ = cmd.Flags().GetString("namespace")
+	overrides.version, _ = cmd.Flags().GetString("version")
+	overrides.group, _ = cmd.Flags().GetString("group")
+	overrides.tag, _ = cmd.Flags().GetString("tag")
+
+	ignoreFlags, _ := cmd.Flags().GetStringArray("ignore")
+	ignore := append(append([]string{}, cassette.Ignore...), ignoreFlags...)
+	if _, err := parseIgnoreRules(ignore); err != nil {
+		return err
+	}
+
+	jsonFile, _ := cmd.Flags().GetString("json")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+
+	// 非详细模式下屏蔽调用过程日志，只输出回放结果
+	var out io.Writer = os.Stdout
+	if !verbose {
+		var restore func()
+		out, restore, err = discardLogs()
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	report := &ReplayReport{
+		File:     file,
+		Registry: overrides.registry,
+		Ignore:   ignore,
+		Total:    len(cassette.Interactions),
+	}
+	for i, interaction := range cassette.Interactions {
+		result := replayInteraction(cmd, i+1, interaction, overrides, ignore)
+		switch result.Status {
+		case ReplaySame:
+			report.Same++
+		case ReplayDifferent:
+			report.Different++
+		default:
+			report.Errors++
+		}
+		printReplayResult(out, result)
+		report.Results = append(report.Results, result)
+	}
+
+	printReplaySummary(out, report)
+
+	if jsonFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("生成JSON报告失败: %v", err)
+		}
+		if err := os.WriteFile(jsonFile, data, 0644); err != nil {
+			return fmt.Errorf("写入JSON报告失败: %v", err)
+		}
+	}
+
+	if report.Different > 0 || report.Errors > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("回放发现 %d 个差异调用、%d 个错误", report.Different, report.Errors)
+	}
+	return nil
+}
+
+// replayInteraction 重新执行一次录制的调用并与录制的响应比较
+func replayInteraction(cmd *cobra.Command, index int, interaction Interaction, overrides replayOverrides, ignore []string) ReplayResult {
+	request := interaction.Request
+	result := ReplayResult{
+		Index:            index,
+		Service:          request.ServiceName,
+		Method:           request.MethodName,
+		RecordedProvider: interaction.Provider,
+		RecordedDuration: interaction.Response.Duration,
+	}
+
+	config := &DubboConfig{
+		Registry:       firstNonEmpty(overrides.registry, request.Registry),
+		Namespace:      firstNonEmpty(overrides.namespace, request.Namespace),
+		Application:    firstNonEmpty(overrides.app, request.App, "dubbo-invoke-client"),
+		Version:        firstNonEmpty(overrides.version, request.Version),
+		Group:          firstNonEmpty(overrides.group, request.Group),
+		Tag:            firstNonEmpty(overrides.tag, request.Tag),
+		MaxPayloadSize: maxPayloadBytes(cmd),
+	}
+	activeProfile(cmd).Apply(config)
+	timeout := request.Timeout
+	if overrides.timeout > 0 {
+		timeout = overrides.timeout
+	}
+	if timeout <= 0 {
+		timeout = 3000
+	}
+	config.Timeout = time.Duration(timeout) * time.Millisecond
+
+	params, err := decodeCassetteParameters(request.Parameters)
+	if err != nil {
+		result.Status = ReplayError
+		result.Error = err.Error()
+		return result
+	}
+
+	client, err := NewDubboClient(config)
+	if err != nil {
+		result.Status = ReplayError
+		result.Error = fmt.Sprintf("创建Dubbo客户端失败: %v", err)
+		return result
+	}
+	defer client.Close()
+
+	start := time.Now()
+	value, invokeErr := client.GenericInvokeContext(cmd.Context(), request.ServiceName, request.MethodName, request.Types, params)
+	result.Duration = time.Since(start).Milliseconds()
+	result.Provider = client.LastProvider()
+
+	recorded := interaction.Response
+	switch {
+	case invokeErr != nil && !recorded.Success:
+		// 录制时同样失败，视为行为一致
+		result.Status = ReplaySame
+		result.Error = invokeErr.Error()
+	case invokeErr != nil:
+		result.Status = ReplayError
+		result.Error = invokeErr.Error()
+	case !recorded.Success:
+		result.Status = ReplayDifferent
+		result.Error = fmt.Sprintf("录制时调用失败(%s)，回放时调用成功", recorded.Error)
+	default:
+		diffs, err := DiffJSON(parseJSONText(recorded.Result), decodeResult(value), ignore)
+		if err != nil {
+			result.Status = ReplayError
+			result.Error = err.Error()
+			return result
+		}
+		result.Diffs = diffs
+		result.Status = ReplaySame
+		if len(diffs) > 0 {
+			result.Status = ReplayDifferent
+		}
+	}
+	return result
+}
+
+// decodeCassetteParameters 解析录制的参数数组，保持Long类型精度
+func decodeCassetteParameters(text string) ([]interface{}, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var params []interface{}
+	if err := decoder.Decode(&params); err != nil {
+		return nil, fmt.Errorf("解析录制的参数失败: %v", err)
+	}
+	return convertJSONNumbers(params), nil
+}
+
+// printReplayResult 输出单个调用的回放结果
+func printReplayResult(w io.Writer, result ReplayResult) {
+	target := fmt.Sprintf("#%d %s.%s", result.Index, result.Service, result.Method)
+	timing := fmt.Sprintf("(%dms, 录制时 %dms)", result.Duration, result.RecordedDuration)
+
+	switch result.Status {
+	case ReplaySame:
+		color.New(color.FgGreen).Fprintf(w, "✔ %s %s\n", target, timing)
+	case ReplayDifferent:
+		color.New(color.FgRed).Fprintf(w, "✘ %s %s: %d 处差异\n", target, timing, len(result.Diffs))
+		if result.Error != "" {
+			color.New(color.FgRed).Fprintf(w, "    %s\n", result.Error)
+		}
+		printDiff(w, result.Diffs, "    ")
+	default:
+		color.New(color.FgMagenta).Fprintf(w, "! %s %s: %s\n", target, timing, result.Error)
+	}
+}
+
+// printReplaySummary 输出回放汇总
+func printReplaySummary(w io.Writer, report *ReplayReport) {
+	fmt.Fprintln(w)
+	summary := fmt.Sprintf("共 %d 个调用: %d 个一致, %d 个有差异, %d 个错误", report.Total, report.Same, report.Different, report.Errors)
+	if report.Different > 0 || report.Errors > 0 {
+		color.New(color.FgRed).Fprintln(w, summary)
+	} else {
+		color.New(color.FgGreen).Fprintln(w, summary)
+	}
+	if len(report.Ignore) > 0 {
+		fmt.Fprintf(w, "忽略的路径: %s\n", strings.Join(report.Ignore, ", "))
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// defaultCatalogTTL 服务目录缓存的默认有效期，可以通过 DUBBO_INVOKE_CACHE_TTL 环境变量修改，如 10m、2h
+const defaultCatalogTTL = 30 * time.Minute
+
+// catalogFileNamePattern 注册中心地址中不能用于文件名的字符
+var catalogFileNamePattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// CatalogService 服务目录中的服务，Methods为空且MethodsAt为零表示尚未获取方法
+type CatalogService struct {
+	Name      string             `json:"name"`
+	Methods   []MethodDefinition `json:"methods,omitempty"`
+	Types     []TypeDefinition   `json:"types,omitempty"`    // 参数类型定义，用于生成参数骨架
+	Variants  []ServiceVariant   `json:"variants,omitempty"` // 提供者注册的版本和分组
+	MethodsAt time.Time          `json:"methodsAt,omitempty"`
+}
+
+// ServiceVariant 服务提供者注册的版本和分组
+type ServiceVariant struct {
+	Version string `json:"version,omitempty"`
+	Group   string `json:"group,omitempty"`
+}
+
+// String 以 版本/分组 的形式描述，没有分组时只有版本
+func (v ServiceVariant) String() string {
+	if v.Group == "" {
+		return v.Version
+	}
+	return v.Version + "/" + v.Group
+}
+
+// MethodsFresh 判断服务的方法是否在有效期内
+func (s *CatalogService) MethodsFresh(ttl time.Duration) bool {
+	return !s.MethodsAt.IsZero() && time.Since(s.MethodsAt) < ttl
+}
+
+// TypeMap 返回按类名索引的类型定义
+func (s *CatalogService) TypeMap() map[string]*TypeDefinition {
+	types := make(map[string]*TypeDefinition, len(s.Types))
+	for i := range s.Types {
+		types[s.Types[i].Type] = &s.Types[i]
+	}
+	return types
+}
+
+// ServiceCatalog 按注册中心(和命名空间)缓存在本地的服务和方法目录
+// 用于命令行补全，注册中心不可用时也可以离线浏览
+type ServiceCatalog struct {
+	Registry  string           `json:"registry"`
+	Namespace string           `json:"namespace,omitempty"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+	Services  []CatalogService `json:"services"`
+
+	path string
+}
+
+// catalogDir 返回服务目录缓存所在的目录
+func catalogDir() (string, error) {
+	home, err := dubboInvokeHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "cache"), nil
+}
+
+// catalogTTL 返回服务目录缓存的有效期
+func catalogTTL() time.Duration {
+	if value := os.Getenv("DUBBO_INVOKE_CACHE_TTL"); value != "" {
+		if ttl, err := time.ParseDuration(value); err == nil && ttl >= 0 {
+			return ttl
+		}
+	}
+	return defaultCatalogTTL
+}
+
+// catalogPath 返回注册中心(和命名空间)的服务目录缓存文件路径
+func catalogPath(cfg *DubboConfig) (string, error) {
+	dir, err := catalogDir()
+	if err != nil {
+		return "", err
+	}
+	key := cfg.Registry
+	if cfg.Namespace != "" {
+		key += "@" + cfg.Namespace
+	}
+	return filepath.Join(dir, "catalog-"+catalogFileNamePattern.ReplaceAllString(key, "_")+".json"), nil
+}
+
+// LoadServiceCatalog 加载注册中心的服务目录缓存，缓存不存在时返回空目录
+func LoadServiceCatalog(cfg *DubboConfig) (*ServiceCatalog, error) {
+	path, err := catalogPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	catalog := &ServiceCatalog{
+		Registry:  cfg.Registry,
+		Namespace: cfg.Namespace,
+		path:      path,
+	}
+
+	data, err := os.ReadFile(catalog.path)
+	if os.IsNotExist(err) {
+		return catalog, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取服务目录缓存失败: %v", err)
+	}
+	if err := json.Unmarshal(data, catalog); err != nil {
+		return nil, fmt.Errorf("解析服务目录缓存 %s 失败: %v", catalog.path, err)
+	}
+	return catalog, nil
+}
+
+// Path 返回缓存文件路径
+func (c *ServiceCatalog) Path() string {
+	return c.path
+}
+
+// Empty 判断缓存中是否没有服务列表
+func (c *ServiceCatalog) Empty() bool {
+	return c.UpdatedAt.IsZero()
+}
+
+// Fresh 判断服务列表是否在有效期内
+func (c *ServiceCatalog) Fresh(ttl time.Duration) bool {
+	return !c.Empty() && time.Since(c.UpdatedAt) < ttl
+}
+
+// ServiceNames 返回缓存的服务名，已排序
+func (c *ServiceCatalog) ServiceNames() []string {
+	names := make([]string, len(c.Services))
+	for i, service := range c.Services {
+		names[i] = service.Name
+	}
+	return names
+}
+
+// Service 返回缓存的服务，不存在时返回nil
+func (c *ServiceCatalog) Service(name string) *CatalogService {
+	index := sort.Search(len(c.Services), func(i int) bool { return c.Services[i].Name >= name })
+	if index < len(c.Services) && c.Services[index].Name == name {
+		return &c.Services[index]
+	}
+	return nil
+}
+
+// SetServices 更新服务列表，保留仍存在的服务已缓存的方法
+func (c *ServiceCatalog) SetServices(names []string) {
+	services := make([]CatalogService, 0, len(names))
+	for _, name := range names {
+		if existing := c.Service(name); existing != nil {
+			services = append(services, *existing)
+		} else {
+			services = append(services, CatalogService{Name: name})
+		}
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	c.Services = services
+	c.UpdatedAt = time.Now()
+}
+
+// SetMethods 更新服务的方法和类型定义，服务不在列表中时加入列表
+func (c *ServiceCatalog) SetMethods(name string, methods []MethodDefinition, types []TypeDefinition) {
+	service := c.Service(name)
+	if service == nil {
+		c.Services = append(c.Services, CatalogService{Name: name})
+		sort.Slice(c.Services, func(i, j int) bool { return c.Services[i].Name < c.Services[j].Name })
+		service = c.Service(name)
+	}
+	service.Methods = methods
+	service.Types = types
+	service.MethodsAt = time.Now()
+}
+
+// ExpireMethods 将所有服务的方法标记为过期，下次使用时重新获取，获取失败时仍可使用
+func (c *ServiceCatalog) ExpireMethods() {
+	for i := range c.Services {
+		c.Services[i].MethodsAt = time.Time{}
+	}
+}
+
+// RefreshServices 从注册中心获取服务列表并更新目录
+func (c *ServiceCatalog) RefreshServices(client *DubboClient) error {
+	services, err := client.ListServices()
+	if err != nil {
+		return err
+	}
+	c.SetServices(services)
+	return nil
+}
+
+// RefreshMethods 获取服务的方法并更新目录
+// 优先使用元数据文件或元数据中心中的服务定义，没有时使用提供者注册的方法名
+func (c *ServiceCatalog) RefreshMethods(client *DubboClient, name string) (*CatalogService, error) {
+	definition, _, err := FindServiceDefinition(client.GetConfig(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []MethodDefinition
+	var types []TypeDefinition
+	if definition != nil {
+		methods = append(methods, definition.Methods...)
+		types = definition.Types
+	} else {
+		names, err := client.ListMethods(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, method := range names {
+			methods = append(methods, MethodDefinition{Name: method})
+		}
+	}
+	sort.SliceStable(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+
+	// 版本和分组仅用于展示，获取失败时不影响方法
+	variants, _ := client.ListVariants(name)
+
+	c.SetMethods(name, methods, types)
+	service := c.Service(name)
+	service.Variants = variants
+	return service, nil
+}
+
+// Save 保存服务目录缓存
+func (c *ServiceCatalog) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化服务目录失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("创建缓存目录失败: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".catalog-*.json")
+	if err != nil {
+		return fmt.Errorf("写入服务目录缓存失败: %v", err)
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入服务目录缓存失败: %v", err)
+	}
+	return nil
+}
+
+// describeAge 以易读的方式描述缓存距今的时间
+func describeAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return "刚刚"
+	case age < time.Hour:
+		return fmt.Sprintf("%d分钟前", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%d小时前", int(age.Hours()))
+	}
+	return fmt.Sprintf("%d天前", int(age.Hours()/24))
+}
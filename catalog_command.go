@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// cache命令 - 管理本地服务目录缓存
+func newCacheCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "管理本地缓存的服务和方法目录",
+		Long: `管理按注册中心缓存在 ~/.dubbo-invoke/cache 下的服务和方法目录
+
+服务目录用于 invoke、list 命令参数的Tab补全，注册中心不可用时 list 和 shell 使用缓存离线浏览
+缓存默认30分钟后过期，可以通过 DUBBO_INVOKE_CACHE_TTL 环境变量修改，如 10m、2h
+
+启用补全:
+  source <(dubbo-invoke completion bash)
+  dubbo-invoke completion zsh > "${fpath[1]}/_dubbo-invoke"
+  dubbo-invoke completion powershell | Out-String | Invoke-Expression
+
+示例:
+  dubbo-invoke cache refresh
+  dubbo-invoke cache refresh -P prod
+  dubbo-invoke cache clear --all`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "refresh",
+		Short: "从注册中心重新获取服务和方法并保存到缓存",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheRefreshCommand,
+	})
+
+	clearCmd := &cobra.Command{
+		Use:   "clear",
+		Short: "删除当前注册中心的缓存，指定 --all 时删除所有注册中心的缓存",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheClearCommand,
+	}
+	clearCmd.Flags().Bool("all", false, "删除所有注册中心的缓存")
+	cmd.AddCommand(clearCmd)
+
+	return cmd
+}
+
+// catalogConfig 按命令行参数和当前环境生成访问注册中心的配置
+func catalogConfig(cmd *cobra.Command) *DubboConfig {
+	registry, _ := cmd.Flags().GetString("registry")
+	appName, _ := cmd.Flags().GetString("app")
+	config := &DubboConfig{
+		Registry:    registry,
+		Application: appName,
+		Timeout:     5 * time.Second,
+	}
+	if flag := cmd.Flags().Lookup("metadata"); flag != nil {
+		config.MetadataFile = flag.Value.String()
+	}
+	activeProfile(cmd).Apply(config)
+	return config
+}
+
+// runCacheRefreshCommand 重新获取服务列表和所有服务的方法
+func runCacheRefreshCommand(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	config := catalogConfig(cmd)
+	catalog, err := LoadServiceCatalog(config)
+	if err != nil {
+		return err
+	}
+
+	color.Cyan("从 %s 获取服务和方法...", config.Registry)
+	failed := 0
+	err = runQuietly(verbose, func() error {
+		client, err := NewDubboClient(config)
+		if err != nil {
+			return fmt.Errorf("创建Dubbo客户端失败: %v", err)
+		}
+		defer client.Close()
+		defer closeZooKeeperSessions()
+
+		if err := catalog.RefreshServices(client); err != nil {
+			return fmt.Errorf("获取服务列表失败: %v", err)
+		}
+		for _, name := range catalog.ServiceNames() {
+			if _, err := catalog.RefreshMethods(client, name); err != nil {
+				failed++
+				if verbose {
+					color.Yellow("获取服务 %s 的方法失败: %v", name, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := catalog.Save(); err != nil {
+		return err
+	}
+
+	methods := 0
+	for _, service := range catalog.Services {
+		methods += len(service.Methods)
+	}
+	color.Green("已缓存 %d 个服务、%d 个方法: %s", len(catalog.Services), methods, catalog.Path())
+	if failed > 0 {
+		color.Yellow("%d 个服务的方法获取失败，使用 -v 查看原因", failed)
+	}
+	return nil
+}
+
+// runCacheClearCommand 删除服务目录缓存，缓存文件损坏时同样可以删除
+func runCacheClearCommand(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	if !all {
+		config := catalogConfig(cmd)
+		path, err := catalogPath(config)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除服务目录缓存失败: %v", err)
+		}
+		color.Green("已删除 %s 的服务目录缓存", config.Registry)
+		return nil
+	}
+
+	dir, err := catalogDir()
+	if err != nil {
+		return err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "catalog-*.json"))
+	if err != nil {
+		return fmt.Errorf("查找缓存文件失败: %v", err)
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return fmt.Errorf("删除缓存文件失败: %v", err)
+		}
+	}
+	color.Green("已删除 %d 个服务目录缓存", len(files))
+	return nil
+}
+
+// completionCatalog 加载补全使用的服务目录，服务列表过期时从注册中心刷新，刷新失败时使用过期的缓存
+// 补全在 __complete 命令中执行，不会执行 PersistentPreRunE，需要自行加载当前环境
+func completionCatalog(cmd *cobra.Command) (*ServiceCatalog, *DubboClient, error) {
+	if err := applyActiveProfile(cmd, nil); err != nil {
+		return nil, nil, err
+	}
+	config := catalogConfig(cmd)
+	catalog, err := LoadServiceCatalog(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := NewDubboClient(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !catalog.Fresh(catalogTTL()) {
+		if err := catalog.RefreshServices(client); err != nil {
+			cobra.CompDebugln(fmt.Sprintf("刷新服务列表失败，使用缓存: %v", err), false)
+		} else if err := catalog.Save(); err != nil {
+			cobra.CompDebugln(err.Error(), false)
+		}
+	}
+	return catalog, client, nil
+}
+
+// catalogMethods 返回服务的方法，过期时重新获取，获取失败时使用过期的缓存
+func catalogMethods(catalog *ServiceCatalog, client *DubboClient, service string) []MethodDefinition {
+	if cached := catalog.Service(service); cached != nil && cached.MethodsFresh(catalogTTL()) {
+		return cached.Methods
+	}
+	refreshed, err := catalog.RefreshMethods(client, service)
+	if err != nil {
+		cobra.CompDebugln(fmt.Sprintf("获取服务 %s 的方法失败，使用缓存: %v", service, err), false)
+		if cached := catalog.Service(service); cached != nil {
+			return cached.Methods
+		}
+		return nil
+	}
+	if err := catalog.Save(); err != nil {
+		cobra.CompDebugln(err.Error(), false)
+	}
+	return refreshed.Methods
+}
+
+// methodCompletions 返回方法名补全，描述中列出各个重载的参数类型
+func methodCompletions(methods []MethodDefinition, prefix, toComplete string) []string {
+	var names []string
+	signatures := make(map[string][]string)
+	for _, method := range methods {
+		name := prefix + method.Name
+		if !strings.HasPrefix(name, toComplete) {
+			continue
+		}
+		if _, ok := signatures[name]; !ok {
+			names = append(names, name)
+		}
+		if method.ParameterTypes != nil {
+			signatures[name] = append(signatures[name], "("+strings.Join(method.ParameterTypes, ", ")+")")
+		} else {
+			signatures[name] = append(signatures[name], "")
+		}
+	}
+
+	completions := make([]string, len(names))
+	for i, name := range names {
+		completions[i] = name
+		if description := strings.Trim(strings.Join(signatures[name], " "), " "); description != "" {
+			completions[i] += "\t" + description
+		}
+	}
+	return completions
+}
+
+// completeServiceArgs list命令参数的补全: 服务名
+func completeServiceArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []string
+	runQuietly(false, func() error {
+		catalog, client, err := completionCatalog(cmd)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+			return err
+		}
+		defer client.Close()
+		for _, name := range catalog.ServiceNames() {
+			if strings.HasPrefix(name, toComplete) {
+				completions = append(completions, name)
+			}
+		}
+		return nil
+	})
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeInvokeArgs invoke命令参数的补全: 第一个参数补全服务名，或在 服务. 之后补全 服务.方法( 表达式，
+// 第二个参数补全方法名
+func completeInvokeArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 1 || (len(args) == 1 && strings.Contains(args[0], "(")) || strings.Contains(toComplete, "(") {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	directive := cobra.ShellCompDirectiveNoFileComp
+	runQuietly(false, func() error {
+		catalog, client, err := completionCatalog(cmd)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+			return err
+		}
+		defer client.Close()
+
+		if len(args) == 1 {
+			completions = methodCompletions(catalogMethods(catalog, client, args[0]), "", toComplete)
+			return nil
+		}
+
+		// 输入了完整的服务名和点时补全表达式中的方法
+		if dot := strings.LastIndex(toComplete, "."); dot > 0 && catalog.Service(toComplete[:dot]) != nil {
+			service := toComplete[:dot]
+			completions = methodCompletions(catalogMethods(catalog, client, service), service+".", toComplete)
+			for i := range completions {
+				name, description, _ := strings.Cut(completions[i], "\t")
+				completions[i] = strings.TrimRight(name+"(\t"+description, "\t")
+			}
+			directive |= cobra.ShellCompDirectiveNoSpace
+			return nil
+		}
+		for _, name := range catalog.ServiceNames() {
+			if strings.HasPrefix(name, toComplete) {
+				completions = append(completions, name)
+			}
+		}
+		return nil
+	})
+	return completions, directive
+}
@@ -0,0 +1,787 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// collectionVarPattern 匹配集合请求中的变量引用，如 {{companyId}}
+var collectionVarPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.]*)\s*\}\}`)
+
+// collectionNamePattern 集合、文件夹、请求和环境的名称，不能包含路径分隔符，也不能以 . 开头
+var collectionNamePattern = regexp.MustCompile(`^[^./\\:*?"<>|\s][^/\\:*?"<>|]*$`)
+
+// Collection 请求集合，每个集合保存为集合目录下的一个YAML文件，便于纳入git管理
+type Collection struct {
+	Name         string                           `yaml:"name" json:"name"`
+	Description  string                           `yaml:"description,omitempty" json:"description,omitempty"`
+	Registry     string                           `yaml:"registry,omitempty" json:"registry,omitempty"`       // 默认注册中心，环境和请求中未指定时使用
+	Timeout      int                              `yaml:"timeout,omitempty" json:"timeout,omitempty"`         // 默认超时时间，单位毫秒
+	Environment  string                           `yaml:"environment,omitempty" json:"environment,omitempty"` // 当前使用的环境
+	Variables    map[string]interface{}           `yaml:"variables,omitempty" json:"variables,omitempty"`
+	Environments map[string]CollectionEnvironment `yaml:"environments,omitempty" json:"environments,omitempty"`
+	Requests     []SavedRequest                   `yaml:"requests,omitempty" json:"requests,omitempty"`
+	Folders      []CollectionFolder               `yaml:"folders,omitempty" json:"folders,omitempty"`
+}
+
+// CollectionEnvironment 集合的一个环境，环境中的变量覆盖集合级别的同名变量
+type CollectionEnvironment struct {
+	Registry  string                 `yaml:"registry,omitempty" json:"registry,omitempty"`
+	Namespace string                 `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	App       string                 `yaml:"app,omitempty" json:"app,omitempty"`
+	Variables map[string]interface{} `yaml:"variables,omitempty" json:"variables,omitempty"`
+}
+
+// CollectionFolder 集合中的文件夹，可以嵌套
+type CollectionFolder struct {
+	Name     string             `yaml:"name" json:"name"`
+	Requests []SavedRequest     `yaml:"requests,omitempty" json:"requests,omitempty"`
+	Folders  []CollectionFolder `yaml:"folders,omitempty" json:"folders,omitempty"`
+}
+
+// SavedRequest 保存的请求，字段中可以使用 {{变量}} 引用环境变量
+type SavedRequest struct {
+	Name        string        `yaml:"name" json:"name"`
+	Description string        `yaml:"description,omitempty" json:"description,omitempty"`
+	Service     string        `yaml:"service" json:"service"`
+	Method      string        `yaml:"method" json:"method"`
+	Params      []interface{} `yaml:"params,omitempty" json:"params,omitempty"`
+	Types       []string      `yaml:"types,omitempty" json:"types,omitempty"`
+	Registry    string        `yaml:"registry,omitempty" json:"registry,omitempty"`
+	Namespace   string        `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Version     string        `yaml:"version,omitempty" json:"version,omitempty"`
+	Group       string        `yaml:"group,omitempty" json:"group,omitempty"`
+	Tag         string        `yaml:"tag,omitempty" json:"tag,omitempty"`
+	Timeout     int           `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Expect      []string      `yaml:"expect,omitempty" json:"expect,omitempty"`
+}
+
+// CollectionSummary 集合列表中的摘要信息
+type CollectionSummary struct {
+	Name         string    `json:"name"`
+	Description  string    `json:"description,omitempty"`
+	Environment  string    `json:"environment,omitempty"`
+	Environments []string  `json:"environments"`
+	Requests     []string  `json:"requests"` // 请求路径，如 users/getUserById
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// ResolvedRequest 选定环境并替换变量后的请求
+type ResolvedRequest struct {
+	SavedRequest
+	Path        string `json:"path"`
+	Environment string `json:"environment,omitempty"`
+	App         string `json:"app,omitempty"`
+}
+
+// CollectionStore 集合存储，每个集合对应目录下的 <name>.yaml
+type CollectionStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// collectionsDir 集合目录，未指定时为 ~/.dubbo-invoke/collections
+func collectionsDir(dir string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+	home, err := dubboInvokeHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "collections"), nil
+}
+
+// OpenCollectionStore 打开集合目录，不存在时创建
+func OpenCollectionStore(dir string) (*CollectionStore, error) {
+	dir, err := collectionsDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建集合目录失败: %v", err)
+	}
+	return &CollectionStore{dir: dir}, nil
+}
+
+// Dir 返回集合目录
+func (s *CollectionStore) Dir() string {
+	return s.dir
+}
+
+// path 返回集合文件路径
+func (s *CollectionStore) path(name string) string {
+	return filepath.Join(s.dir, name+".yaml")
+}
+
+// Exists 集合是否存在
+func (s *CollectionStore) Exists(name string) bool {
+	if !collectionNamePattern.MatchString(name) {
+		return false
+	}
+	_, err := os.Stat(s.path(name))
+	return err == nil
+}
+
+// List 列出所有集合，按名称排序，无法解析的文件输出警告后跳过
+func (s *CollectionStore) List() ([]CollectionSummary, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("读取集合目录失败: %v", err)
+	}
+	sort.Strings(files)
+
+	summaries := make([]CollectionSummary, 0, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		if strings.HasPrefix(name, ".") {
+			continue // 保存过程中的临时文件
+		}
+		collection, err := s.Load(name)
+		if err != nil {
+			color.Yellow("警告: 跳过集合文件 %s: %v", file, err)
+			continue
+		}
+		summary := CollectionSummary{
+			Name:         collection.Name,
+			Description:  collection.Description,
+			Environment:  collection.Environment,
+			Environments: sortedEnvironmentNames(collection.Environments),
+			Requests:     collection.RequestPaths(),
+		}
+		if info, err := os.Stat(file); err == nil {
+			summary.UpdatedAt = info.ModTime()
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// Load 读取集合，集合名以文件名为准
+func (s *CollectionStore) Load(name string) (*Collection, error) {
+	if !collectionNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("无效的集合名称: %q", name)
+	}
+	data, err := os.ReadFile(s.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("集合不存在: %s", name)
+		}
+		return nil, fmt.Errorf("读取集合失败: %v", err)
+	}
+
+	var collection Collection
+	if err := yaml.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("解析集合 %s 失败: %v", name, err)
+	}
+	collection.Name = name
+	return &collection, nil
+}
+
+// Save 校验并保存集合，overwrite为false时集合已存在则返回错误
+func (s *CollectionStore) Save(collection *Collection, overwrite bool) error {
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(collection)
+	if err != nil {
+		return fmt.Errorf("生成集合文件失败: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := s.path(collection.Name)
+	if !overwrite {
+		if _, err := os.Stat(file); err == nil {
+			return fmt.Errorf("集合已存在: %s", collection.Name)
+		}
+	}
+
+	// 先写临时文件再重命名，中途退出时不会留下半个文件
+	tmp, err := os.CreateTemp(s.dir, ".collection-*.yaml")
+	if err != nil {
+		return fmt.Errorf("写入集合失败: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入集合失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入集合失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入集合失败: %v", err)
+	}
+	return nil
+}
+
+// Delete 删除集合
+func (s *CollectionStore) Delete(name string) error {
+	if !collectionNamePattern.MatchString(name) {
+		return fmt.Errorf("无效的集合名称: %q", name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("集合不存在: %s", name)
+		}
+		return fmt.Errorf("删除集合失败: %v", err)
+	}
+	return nil
+}
+
+// Validate 校验名称和请求，同一文件夹中的请求和子文件夹不能重名
+func (c *Collection) Validate() error {
+	if !collectionNamePattern.MatchString(c.Name) {
+		return fmt.Errorf("无效的集合名称: %q，名称不能为空、以 . 开头或包含 / \\ : * ? \" < > |", c.Name)
+	}
+	for name := range c.Environments {
+		if !collectionNamePattern.MatchString(name) {
+			return fmt.Errorf("无效的环境名称: %q", name)
+		}
+	}
+	if c.Environment != "" {
+		if _, exists := c.Environments[c.Environment]; !exists {
+			return fmt.Errorf("当前环境 %s 未在 environments 中定义", c.Environment)
+		}
+	}
+	return validateCollectionFolder("", c.Requests, c.Folders)
+}
+
+// validateCollectionFolder 递归校验文件夹中的请求和子文件夹
+func validateCollectionFolder(prefix string, requests []SavedRequest, folders []CollectionFolder) error {
+	names := make(map[string]bool)
+	for _, request := range requests {
+		path := prefix + request.Name
+		if !collectionNamePattern.MatchString(request.Name) {
+			return fmt.Errorf("无效的请求名称: %q", path)
+		}
+		if names[request.Name] {
+			return fmt.Errorf("请求名称重复: %s", path)
+		}
+		names[request.Name] = true
+		if request.Service == "" || request.Method == "" {
+			return fmt.Errorf("请求 %s 缺少service或method", path)
+		}
+	}
+	for _, folder := range folders {
+		path := prefix + folder.Name
+		if !collectionNamePattern.MatchString(folder.Name) {
+			return fmt.Errorf("无效的文件夹名称: %q", path)
+		}
+		if names[folder.Name] {
+			return fmt.Errorf("文件夹与请求或其他文件夹重名: %s", path)
+		}
+		names[folder.Name] = true
+		if err := validateCollectionFolder(path+"/", folder.Requests, folder.Folders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RequestPaths 返回集合中所有请求的路径，文件夹与请求名之间用 / 分隔
+func (c *Collection) RequestPaths() []string {
+	var paths []string
+	var walk func(prefix string, requests []SavedRequest, folders []CollectionFolder)
+	walk = func(prefix string, requests []SavedRequest, folders []CollectionFolder) {
+		for _, request := range requests {
+			paths = append(paths, prefix+request.Name)
+		}
+		for _, folder := range folders {
+			walk(prefix+folder.Name+"/", folder.Requests, folder.Folders)
+		}
+	}
+	walk("", c.Requests, c.Folders)
+	return paths
+}
+
+// Find 按路径查找请求，如 getUserById 或 users/admin/getUserById
+func (c *Collection) Find(path string) (*SavedRequest, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	requests, folders := c.Requests, c.Folders
+	for _, segment := range segments[:len(segments)-1] {
+		found := false
+		for _, folder := range folders {
+			if folder.Name == segment {
+				requests, folders = folder.Requests, folder.Folders
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("集合 %s 中不存在文件夹: %s", c.Name, segment)
+		}
+	}
+
+	name := segments[len(segments)-1]
+	for i := range requests {
+		if requests[i].Name == name {
+			return &requests[i], nil
+		}
+	}
+	return nil, fmt.Errorf("集合 %s 中不存在请求: %s (可用: %s)", c.Name, path, strings.Join(c.RequestPaths(), ", "))
+}
+
+// Resolve 在指定环境下替换请求中的变量，env为空时使用集合的当前环境
+// 变量依次从overrides、环境变量、集合变量和系统环境变量中查找
+func (c *Collection) Resolve(path, env string, overrides map[string]interface{}) (*ResolvedRequest, error) {
+	request, err := c.Find(path)
+	if err != nil {
+		return nil, err
+	}
+
+	env = firstNonEmpty(env, c.Environment)
+	var environment CollectionEnvironment
+	if env != "" {
+		var exists bool
+		if environment, exists = c.Environments[env]; !exists {
+			return nil, fmt.Errorf("集合 %s 中不存在环境: %s (可用: %s)", c.Name, env, strings.Join(sortedEnvironmentNames(c.Environments), ", "))
+		}
+	}
+
+	missingSet := make(map[string]bool)
+	lookup := func(name string) (interface{}, bool) {
+		for _, vars := range []map[string]interface{}{overrides, environment.Variables, c.Variables} {
+			if value, exists := vars[name]; exists {
+				return value, true
+			}
+		}
+		if envValue, exists := os.LookupEnv(name); exists {
+			return envValue, true
+		}
+		missingSet[name] = true
+		return nil, false
+	}
+	expandString := func(text string) string {
+		return collectionVarPattern.ReplaceAllStringFunc(text, func(ref string) string {
+			value, ok := lookup(collectionVarPattern.FindStringSubmatch(ref)[1])
+			if !ok {
+				return ref
+			}
+			return cellText(value)
+		})
+	}
+
+	resolved := &ResolvedRequest{
+		SavedRequest: *request,
+		Path:         strings.Trim(path, "/"),
+		Environment:  env,
+		App:          expandString(environment.App),
+	}
+	expanded := &resolved.SavedRequest
+	expanded.Service = expandString(request.Service)
+	expanded.Method = expandString(request.Method)
+	expanded.Registry = expandString(firstNonEmpty(request.Registry, environment.Registry, c.Registry))
+	expanded.Namespace = expandString(firstNonEmpty(request.Namespace, environment.Namespace))
+	expanded.Version = expandString(request.Version)
+	expanded.Group = expandString(request.Group)
+	expanded.Tag = expandString(request.Tag)
+	if expanded.Timeout <= 0 {
+		expanded.Timeout = c.Timeout
+	}
+
+	expanded.Params = make([]interface{}, len(request.Params))
+	for i, param := range request.Params {
+		expanded.Params[i] = expandValue(param, collectionVarPattern, lookup, expandString)
+	}
+	expanded.Types = make([]string, len(request.Types))
+	for i, paramType := range request.Types {
+		expanded.Types[i] = expandString(paramType)
+	}
+	expanded.Expect = make([]string, len(request.Expect))
+	for i, expect := range request.Expect {
+		expanded.Expect[i] = expandString(expect)
+	}
+
+	if len(missingSet) > 0 {
+		missing := make([]string, 0, len(missingSet))
+		for name := range missingSet {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("变量未定义: %s", strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
+
+// InvokeRequest 转换为Web端的调用请求
+func (r *ResolvedRequest) InvokeRequest() (InvokeRequest, error) {
+	params := r.Params
+	if params == nil {
+		params = []interface{}{}
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return InvokeRequest{}, fmt.Errorf("序列化请求参数失败: %v", err)
+	}
+	return InvokeRequest{
+		ServiceName: r.Service,
+		MethodName:  r.Method,
+		Parameters:  data,
+		Types:       r.Types,
+		Registry:    r.Registry,
+		App:         r.App,
+		Timeout:     r.Timeout,
+		Group:       r.Group,
+		Version:     r.Version,
+		Tag:         r.Tag,
+		Namespace:   r.Namespace,
+		Expect:      r.Expect,
+	}, nil
+}
+
+// splitRequestRef 将 COLLECTION/REQUEST 拆分为集合名和请求路径
+func splitRequestRef(ref string) (string, string, error) {
+	ref = strings.Trim(ref, "/")
+	index := strings.Index(ref, "/")
+	if index <= 0 || index == len(ref)-1 {
+		return "", "", fmt.Errorf("请求格式应为 集合/请求 或 集合/文件夹/请求: %s", ref)
+	}
+	return ref[:index], ref[index+1:], nil
+}
+
+// normalizeCollectionValues 将JSON解码得到的json.Number转换为普通数值，超过15位的整数保持为字符串
+func normalizeCollectionValues(c *Collection) {
+	normalizeVars := func(vars map[string]interface{}) {
+		for name, value := range vars {
+			vars[name] = convertJSONNumber(value)
+		}
+	}
+	var normalizeRequests func(requests []SavedRequest, folders []CollectionFolder)
+	normalizeRequests = func(requests []SavedRequest, folders []CollectionFolder) {
+		for i := range requests {
+			requests[i].Params = convertJSONNumbers(requests[i].Params)
+		}
+		for _, folder := range folders {
+			normalizeRequests(folder.Requests, folder.Folders)
+		}
+	}
+
+	normalizeVars(c.Variables)
+	for _, environment := range c.Environments {
+		normalizeVars(environment.Variables)
+	}
+	normalizeRequests(c.Requests, c.Folders)
+}
+
+// sortedEnvironmentNames 返回按字母排序的环境名
+func sortedEnvironmentNames(environments map[string]CollectionEnvironment) []string {
+	names := make([]string, 0, len(environments))
+	for name := range environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CollectionRunRequest 执行集合中的请求: POST /api/collections/{name}/run
+type CollectionRunRequest struct {
+	Request     string                 `json:"request"`     // 请求路径，如 users/getUserById
+	Environment string                 `json:"environment"` // 为空时使用集合的当前环境
+	Variables   map[string]interface{} `json:"variables"`   // 覆盖环境中的变量
+}
+
+// decodeCollection 解析请求体中的集合，保持Long类型精度
+func decodeCollection(r *http.Request) (*Collection, error) {
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	var collection Collection
+	if err := decoder.Decode(&collection); err != nil {
+		return nil, fmt.Errorf("请求解析失败: %v", err)
+	}
+	normalizeCollectionValues(&collection)
+	return &collection, nil
+}
+
+// handleCollections 处理集合列表查询(GET)和新建(POST)
+// POST 带 ?format=postman 时请求体为Postman集合，?name= 指定集合名称，?overwrite=true 覆盖同名集合
+func (ws *WebServer) handleCollections(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	switch r.Method {
+	case "OPTIONS":
+		w.WriteHeader(http.StatusOK)
+	case "GET":
+		summaries, err := ws.collections.List()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			ws.writeError(w, err.Error())
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"collections": summaries,
+		})
+	case "POST":
+		var collection *Collection
+		var warnings []string
+		var err error
+		if format := r.URL.Query().Get("format"); format != "" {
+			collection, warnings, err = ws.importCollection(r, format)
+		} else {
+			collection, err = decodeCollection(r)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			ws.writeError(w, err.Error())
+			return
+		}
+		if overwrite, _ := strconv.ParseBool(r.URL.Query().Get("overwrite")); !overwrite && ws.collections.Exists(collection.Name) {
+			w.WriteHeader(http.StatusConflict)
+			ws.writeError(w, fmt.Sprintf("集合已存在: %s", collection.Name))
+			return
+		}
+		if err := ws.collections.Save(collection, true); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			ws.writeError(w, err.Error())
+			return
+		}
+		color.Green("[WEB] 已保存集合 %s", collection.Name)
+
+		w.WriteHeader(http.StatusCreated)
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(map[string]interface{}{
+			"success":    true,
+			"collection": collection,
+			"warnings":   warnings,
+		})
+	default:
+		ws.writeError(w, "只支持GET和POST方法")
+	}
+}
+
+// handleCollection 处理单个集合的查询(GET)、保存(PUT)、删除(DELETE)，以及 POST /api/collections/{name}/run
+func (ws *WebServer) handleCollection(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	name := strings.TrimPrefix(r.URL.Path, "/api/collections/")
+	if name == "" {
+		ws.handleCollections(w, r)
+		return
+	}
+	if strings.HasSuffix(name, "/run") {
+		ws.handleCollectionRun(w, r, strings.TrimSuffix(name, "/run"))
+		return
+	}
+	if strings.HasSuffix(name, "/export") {
+		ws.handleCollectionExport(w, r, strings.TrimSuffix(name, "/export"))
+		return
+	}
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if !ws.collections.Exists(name) {
+		w.WriteHeader(http.StatusNotFound)
+		ws.writeError(w, fmt.Sprintf("集合不存在: %s", name))
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		collection, err := ws.collections.Load(name)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			ws.writeError(w, err.Error())
+			return
+		}
+		writeCollectionJSON(w, collection)
+	case "PUT":
+		collection, err := decodeCollection(r)
+		if err != nil {
+			ws.writeError(w, err.Error())
+			return
+		}
+		if collection.Name == "" {
+			collection.Name = name
+		}
+		// 名称变化时按重命名处理，目标集合已存在则拒绝
+		renamed := collection.Name != name
+		if renamed && ws.collections.Exists(collection.Name) {
+			w.WriteHeader(http.StatusConflict)
+			ws.writeError(w, fmt.Sprintf("集合已存在: %s", collection.Name))
+			return
+		}
+		if err := ws.collections.Save(collection, !renamed); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			ws.writeError(w, err.Error())
+			return
+		}
+		if renamed {
+			if err := ws.collections.Delete(name); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				ws.writeError(w, err.Error())
+				return
+			}
+		}
+		color.Green("[WEB] 已保存集合 %s", collection.Name)
+		writeCollectionJSON(w, collection)
+	case "DELETE":
+		if err := ws.collections.Delete(name); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			ws.writeError(w, err.Error())
+			return
+		}
+		color.Yellow("[WEB] 已删除集合 %s", name)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("集合 %s 已删除", name),
+		})
+	default:
+		ws.writeError(w, "只支持GET、PUT和DELETE方法")
+	}
+}
+
+// handleCollectionRun 在指定环境下执行集合中的请求，结果与 /api/invoke 相同并写入历史记录
+// 带 ?dryRun=true 时只返回替换变量后的调用计划
+func (ws *WebServer) handleCollectionRun(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		ws.writeError(w, "只支持POST请求")
+		return
+	}
+
+	var body CollectionRunRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		ws.writeError(w, fmt.Sprintf("请求解析失败: %v", err))
+		return
+	}
+	for key, value := range body.Variables {
+		body.Variables[key] = convertJSONNumber(value)
+	}
+
+	if !ws.collections.Exists(name) {
+		w.WriteHeader(http.StatusNotFound)
+		ws.writeError(w, fmt.Sprintf("集合不存在: %s", name))
+		return
+	}
+	collection, err := ws.collections.Load(name)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		ws.writeError(w, err.Error())
+		return
+	}
+	resolved, err := collection.Resolve(body.Request, body.Environment, body.Variables)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		ws.writeError(w, err.Error())
+		return
+	}
+	req, err := resolved.InvokeRequest()
+	if err != nil {
+		ws.writeError(w, err.Error())
+		return
+	}
+	if req.Registry == "" {
+		req.Registry = ws.registry
+	}
+	if req.App == "" {
+		req.App = ws.app
+	}
+	if req.Timeout <= 0 {
+		req.Timeout = ws.timeout
+	}
+
+	color.Blue("[WEB] 执行集合请求 %s/%s (环境: %s): %s.%s", name, resolved.Path, firstNonEmpty(resolved.Environment, "无"), req.ServiceName, req.MethodName)
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun")); dryRun {
+		ws.handleDryRun(w, req)
+		return
+	}
+	ws.serveInvoke(w, r, req)
+}
+
+// importCollection 解析导入的集合: POST /api/collections?format=postman|yaml[&name=...]
+func (ws *WebServer) importCollection(r *http.Request, format string) (*Collection, []string, error) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取请求失败: %v", err)
+	}
+	name := r.URL.Query().Get("name")
+	switch format {
+	case "postman":
+		return ImportPostman(data, name)
+	case "yaml":
+		return ParseCollectionImport(data, name)
+	default:
+		return nil, nil, fmt.Errorf("不支持的导入格式: %s (可选: postman, yaml)", format)
+	}
+}
+
+// handleCollectionExport 导出集合: GET /api/collections/{name}/export?format=yaml|postman
+func (ws *WebServer) handleCollectionExport(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		ws.writeError(w, "只支持GET请求")
+		return
+	}
+	if !ws.collections.Exists(name) {
+		w.WriteHeader(http.StatusNotFound)
+		ws.writeError(w, fmt.Sprintf("集合不存在: %s", name))
+		return
+	}
+	collection, err := ws.collections.Load(name)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		ws.writeError(w, err.Error())
+		return
+	}
+	format := firstNonEmpty(r.URL.Query().Get("format"), "yaml")
+	data, err := MarshalCollectionExport(collection, format)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		ws.writeError(w, err.Error())
+		return
+	}
+
+	filename := name + ".yaml"
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	if format == "postman" {
+		filename = name + ".postman_collection.json"
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Write(data)
+}
+
+// writeCollectionJSON 输出集合
+func writeCollectionJSON(w http.ResponseWriter, collection *Collection) {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(map[string]interface{}{
+		"success":    true,
+		"collection": collection,
+	})
+}
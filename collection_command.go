@@ -0,0 +1,309 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// newRunCommand 创建run命令
+func newRunCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run <collection/request>",
+		Short: "执行集合中保存的请求",
+		Long: `执行集合中保存的请求，请求中的 {{变量}} 按所选环境替换
+集合保存在 ~/.dubbo-invoke/collections/<集合名>.yaml，可以通过Web端的 /api/collections 接口或直接编辑YAML文件维护
+变量依次从 --var、环境变量、集合变量和系统环境变量中查找
+
+示例:
+  dubbo-invoke run --list
+  dubbo-invoke run --list user
+  dubbo-invoke run user/getUserById
+  dubbo-invoke run user/admin/disableUser -e pre --var userId=10086
+  dubbo-invoke run user/getUserById --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runRunCommand,
+	}
+
+	cmd.Flags().StringP("env", "e", "", "使用的环境，默认为集合中的 environment")
+	cmd.Flags().StringArray("var", nil, "覆盖变量，格式为 name=value，值按JSON解析，解析失败时作为字符串，可重复指定")
+	cmd.Flags().Bool("list", false, "列出集合或集合中的请求")
+	cmd.Flags().Bool("dry-run", false, "只输出替换变量后的调用计划，不发送请求")
+	cmd.Flags().String("collections-dir", "", "集合目录，默认为 ~/.dubbo-invoke/collections")
+	addHistoryRerunFlags(cmd)
+
+	return cmd
+}
+
+// runRunCommand 执行集合中的请求
+func runRunCommand(cmd *cobra.Command, args []string) error {
+	dir, _ := cmd.Flags().GetString("collections-dir")
+	list, _ := cmd.Flags().GetBool("list")
+	store, err := OpenCollectionStore(dir)
+	if err != nil {
+		return err
+	}
+
+	if list {
+		if len(args) == 0 {
+			return printCollections(store)
+		}
+		return printCollectionRequests(store, strings.Trim(args[0], "/"))
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("需要指定请求，格式为 集合/请求，使用 --list 查看可用的请求")
+	}
+
+	collectionName, requestPath, err := splitRequestRef(args[0])
+	if err != nil {
+		return err
+	}
+	collection, err := store.Load(collectionName)
+	if err != nil {
+		return err
+	}
+	env, _ := cmd.Flags().GetString("env")
+	rawVars, _ := cmd.Flags().GetStringArray("var")
+	vars, err := parseVarFlags(rawVars)
+	if err != nil {
+		return err
+	}
+	resolved, err := collection.Resolve(requestPath, env, vars)
+	if err != nil {
+		return err
+	}
+
+	config := &DubboConfig{
+		Registry:       resolved.Registry,
+		Application:    resolved.App,
+		Namespace:      resolved.Namespace,
+		Version:        resolved.Version,
+		Group:          resolved.Group,
+		Tag:            resolved.Tag,
+		MaxPayloadSize: maxPayloadBytes(cmd),
+	}
+	profile := activeProfile(cmd)
+	profile.Apply(config)
+	timeout := resolved.Timeout
+	// 命令行显式指定的参数优先于集合中的配置
+	if cmd.Flags().Changed("registry") || config.Registry == "" {
+		config.Registry, _ = cmd.Flags().GetString("registry")
+	}
+	if cmd.Flags().Changed("app") || config.Application == "" {
+		config.Application, _ = cmd.Flags().GetString("app")
+	}
+	if cmd.Flags().Changed("timeout") || timeout <= 0 {
+		timeout, _ = cmd.Flags().GetInt("timeout")
+	}
+	if cmd.Flags().Changed("namespace") {
+		config.Namespace, _ = cmd.Flags().GetString("namespace")
+	}
+	config.Timeout = time.Duration(timeout) * time.Millisecond
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		plan, err := PlanInvoke(config, resolved.Service, resolved.Method, resolved.Types, resolved.Params)
+		if err != nil {
+			return fmt.Errorf("生成调用计划失败: %v", err)
+		}
+		printCallPlan(plan)
+		return nil
+	}
+
+	banner := fmt.Sprintf("执行 %s/%s (环境: %s): %s.%s", collection.Name, resolved.Path,
+		firstNonEmpty(resolved.Environment, "无"), resolved.Service, resolved.Method)
+	return invokeAndReport(cmd, config, profile, banner, resolved.Service, resolved.Method, resolved.Types, resolved.Params, resolved.Expect)
+}
+
+// parseVarFlags 解析 --var name=value，值按JSON解析以保留数字和布尔类型，超过15位的整数保持为字符串
+func parseVarFlags(values []string) (map[string]interface{}, error) {
+	vars := make(map[string]interface{}, len(values))
+	for _, item := range values {
+		name, value, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("变量格式应为 name=value: %s", item)
+		}
+		decoder := json.NewDecoder(strings.NewReader(value))
+		decoder.UseNumber()
+		var parsed interface{}
+		if err := decoder.Decode(&parsed); err == nil && !decoder.More() {
+			vars[name] = convertJSONNumber(parsed)
+		} else {
+			vars[name] = value
+		}
+	}
+	return vars, nil
+}
+
+// printCollections 列出所有集合
+func printCollections(store *CollectionStore) error {
+	summaries, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(summaries) == 0 {
+		color.Yellow("%s 中没有集合", store.Dir())
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "集合\t请求数\t环境\t说明")
+	for _, summary := range summaries {
+		environments := make([]string, len(summary.Environments))
+		for i, name := range summary.Environments {
+			environments[i] = name
+			if name == summary.Environment {
+				environments[i] = name + "*"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", summary.Name, len(summary.Requests), strings.Join(environments, ","), summary.Description)
+	}
+	return tw.Flush()
+}
+
+// printCollectionRequests 列出集合中的请求
+func printCollectionRequests(store *CollectionStore, name string) error {
+	collection, err := store.Load(name)
+	if err != nil {
+		return err
+	}
+
+	color.Cyan("集合: %s", collection.Name)
+	if collection.Description != "" {
+		color.Cyan("说明: %s", collection.Description)
+	}
+	if len(collection.Environments) > 0 {
+		color.Cyan("环境: %s (当前: %s)", strings.Join(sortedEnvironmentNames(collection.Environments), ", "), firstNonEmpty(collection.Environment, "无"))
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "请求\t调用\t说明")
+	for _, path := range collection.RequestPaths() {
+		request, _ := collection.Find(path)
+		fmt.Fprintf(tw, "%s/%s\t%s.%s\t%s\n", collection.Name, path, request.Service, request.Method, request.Description)
+	}
+	return tw.Flush()
+}
+
+// newCollectionCommand 创建collection命令
+func newCollectionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "collection",
+		Short: "管理请求集合，导入导出Postman集合",
+		Long: `管理请求集合，支持与Postman v2.1集合互相转换
+
+示例:
+  dubbo-invoke collection list
+  dubbo-invoke collection import user-api.postman_collection.json --name user
+  dubbo-invoke collection export user --format postman -f user.postman_collection.json
+  dubbo-invoke collection export user -f user.yaml`,
+	}
+	cmd.PersistentFlags().String("collections-dir", "", "集合目录，默认为 ~/.dubbo-invoke/collections")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "列出集合",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("collections-dir")
+			store, err := OpenCollectionStore(dir)
+			if err != nil {
+				return err
+			}
+			return printCollections(store)
+		},
+	})
+
+	importCmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "导入Postman集合或集合YAML文件",
+		Long: `导入Postman v2.1集合(JSON)或本工具的集合文件(YAML)
+Postman请求的请求体含有服务名、方法名和参数时(如 {"service": ..., "method": ..., "params": [...]})导入为Dubbo调用，
+请求体为参数数组且URL以 /<服务名>/<方法名> 结尾的请求同样可以导入，其他请求跳过`,
+		Args: cobra.ExactArgs(1),
+		RunE: runCollectionImportCommand,
+	}
+	importCmd.Flags().String("name", "", "集合名称，默认使用文件中的名称")
+	importCmd.Flags().Bool("force", false, "集合已存在时覆盖")
+	cmd.AddCommand(importCmd)
+
+	exportCmd := &cobra.Command{
+		Use:   "export <collection>",
+		Short: "导出集合",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runCollectionExportCommand,
+	}
+	exportCmd.Flags().String("format", "yaml", "导出格式: yaml|postman")
+	exportCmd.Flags().StringP("file", "f", "", "输出文件，默认输出到标准输出")
+	cmd.AddCommand(exportCmd)
+
+	return cmd
+}
+
+// runCollectionImportCommand 导入集合
+func runCollectionImportCommand(cmd *cobra.Command, args []string) error {
+	dir, _ := cmd.Flags().GetString("collections-dir")
+	name, _ := cmd.Flags().GetString("name")
+	force, _ := cmd.Flags().GetBool("force")
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("读取导入文件失败: %v", err)
+	}
+	collection, warnings, err := ParseCollectionImport(data, name)
+	for _, warning := range warnings {
+		color.Yellow("⚠ %s", warning)
+	}
+	if err != nil {
+		return err
+	}
+
+	store, err := OpenCollectionStore(dir)
+	if err != nil {
+		return err
+	}
+	if err := store.Save(collection, force); err != nil {
+		if store.Exists(collection.Name) && !force {
+			return fmt.Errorf("%v，使用 --force 覆盖或 --name 指定其他名称", err)
+		}
+		return err
+	}
+	color.Green("已导入集合 %s (%d 个请求) 到 %s", collection.Name, len(collection.RequestPaths()), store.Dir())
+	return nil
+}
+
+// runCollectionExportCommand 导出集合
+func runCollectionExportCommand(cmd *cobra.Command, args []string) error {
+	dir, _ := cmd.Flags().GetString("collections-dir")
+	format, _ := cmd.Flags().GetString("format")
+	file, _ := cmd.Flags().GetString("file")
+
+	store, err := OpenCollectionStore(dir)
+	if err != nil {
+		return err
+	}
+	collection, err := store.Load(args[0])
+	if err != nil {
+		return err
+	}
+	data, err := MarshalCollectionExport(collection, format)
+	if err != nil {
+		return err
+	}
+
+	if file == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("写入导出文件失败: %v", err)
+	}
+	color.Green("已导出集合 %s 到 %s", collection.Name, file)
+	return nil
+}
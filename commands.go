@@ -1,15 +1,17 @@
 package main
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
@@ -39,6 +41,27 @@ func runInvokeCommand(cmd *cobra.Command, args []string) error {
 		params = args[2:]
 	}
 
+	// 结果输出选项
+	output, _ := cmd.Flags().GetString("output")
+	query, _ := cmd.Flags().GetString("query")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	expects, _ := cmd.Flags().GetStringArray("expect")
+	maxTime, _ := cmd.Flags().GetInt64("max-time")
+	if maxTime > 0 {
+		expects = append(expects, fmt.Sprintf("duration <= %d", maxTime))
+	}
+	if !validOutputFormat(output) {
+		return fmt.Errorf("不支持的输出格式: %s (可选: json, yaml, table, raw, csv)", output)
+	}
+
+	// quiet模式下标准输出只保留结果，便于通过管道传给其他工具
+	var resultOut io.Writer = os.Stdout
+	if quiet {
+		var restore func()
+		resultOut, restore = redirectLogsToStderr()
+		defer restore()
+	}
+
 	// 获取命令行参数
 	registry, _ := cmd.Flags().GetString("registry")
 	appName, _ := cmd.Flags().GetString("app")
@@ -49,6 +72,12 @@ func runInvokeCommand(cmd *cobra.Command, args []string) error {
 	types, _ := cmd.Flags().GetStringSlice("types")
 	example, _ := cmd.Flags().GetBool("example")
 	verbose, _ := cmd.Flags().GetBool("verbose")
+	tag, _ := cmd.Flags().GetString("tag")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	metadataFile, _ := cmd.Flags().GetString("metadata")
+	noValidate, _ := cmd.Flags().GetBool("no-validate")
+	recordFile, _ := cmd.Flags().GetString("record")
+	noHistory, _ := cmd.Flags().GetBool("no-history")
 
 	if verbose {
 		color.Cyan("调用参数:")
@@ -67,6 +96,11 @@ func runInvokeCommand(cmd *cobra.Command, args []string) error {
 		color.Cyan("  参数: %v", params)
 	}
 
+	// 录制的调用由replay以泛化调用回放
+	if recordFile != "" && !generic {
+		return fmt.Errorf("--record 只能录制泛化调用，不能与 --generic=false 同时使用")
+	}
+
 	// 如果需要生成示例参数
 	if example {
 		exampleParams := generateExampleParams(types)
@@ -79,12 +113,18 @@ func runInvokeCommand(cmd *cobra.Command, args []string) error {
 
 	// 创建Dubbo客户端配置
 	config := &DubboConfig{
-		Registry:    registry,
-		Application: appName,
-		Timeout:     time.Duration(timeout) * time.Millisecond,
-		Version:     version,
-		Group:       group,
+		Registry:       registry,
+		Application:    appName,
+		Timeout:        time.Duration(timeout) * time.Millisecond,
+		Version:        version,
+		Group:          group,
+		Tag:            tag,
+		MetadataFile:   metadataFile,
+		MaxPayloadSize: maxPayloadBytes(cmd),
 	}
+	// 命名空间、认证信息、默认版本和分组等使用当前环境的配置
+	profile := activeProfile(cmd)
+	profile.Apply(config)
 
 	// 创建Dubbo客户端
 	client, err := NewDubboClient(config)
@@ -99,29 +139,147 @@ func runInvokeCommand(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("解析参数失败: %v", err)
 	}
 
+	// 发送前按方法元数据校验参数，避免类型不匹配时只能看到服务端的反序列化错误
+	// dry-run只使用元数据文件和本地缓存的服务目录，不访问元数据中心
+	if !noValidate {
+		validate := ValidateInvokeArguments
+		if dryRun {
+			validate = ValidateInvokeArgumentsOffline
+		}
+		if err := validate(config, serviceName, methodName, types, parsedParams); err != nil {
+			return err
+		}
+	}
+
+	// dry-run只展示调用计划，不发送任何请求
+	if dryRun {
+		plan, err := client.PlanInvoke(serviceName, methodName, types, parsedParams)
+		if err != nil {
+			return fmt.Errorf("生成调用计划失败: %v", err)
+		}
+		printCallPlan(plan)
+		return nil
+	}
+
 	// 执行调用
 	var result interface{}
+	invokeStart := time.Now()
 	if generic {
-		result, err = client.GenericInvoke(serviceName, methodName, types, parsedParams)
+		result, err = client.GenericInvokeContext(cmd.Context(), serviceName, methodName, types, parsedParams)
 	} else {
 		result, err = client.DirectInvoke(serviceName, methodName, parsedParams)
 	}
+	duration := time.Since(invokeStart).Milliseconds()
+
+	// 成功和失败的调用都录制，回放时可以确认错误行为是否一致
+	if recordFile != "" {
+		recordInteraction(recordFile, newInteraction(config, serviceName, methodName, types, parsedParams, client.LastProvider(), result, err, duration))
+	}
+
+	// 详细模式下输出各阶段耗时，失败的调用同样输出，便于定位慢在哪个环节
+	if verbose && client.LastTimings() != nil {
+		timingsJson, _ := json.MarshalIndent(map[string]interface{}{"timings": client.LastTimings()}, "", "  ")
+		color.Cyan("调用耗时分解:")
+		color.Cyan("%s", string(timingsJson))
+	}
+
+	// 断言针对完整结果求值，不受--query影响
+	var assertions []AssertionResult
+	if err == nil && len(expects) > 0 {
+		assertions = EvaluateAssertions(expects, AssertionContext{Result: decodeResult(result), Duration: duration})
+	}
+
+	// 与Web端写入同一份历史记录，便于在页面或history命令中重新调用
+	if !noHistory {
+		saveCLIHistory(config, profileName(profile), serviceName, methodName, types, parsedParams, result, err, duration, expects, assertions)
+	}
 
 	if err != nil {
 		return fmt.Errorf("调用失败: %v", err)
 	}
 
-	// 直接使用原始结果，不进行额外的数据包装处理
-		processedResult := result
+	// 与Web端一样将返回的JSON字符串解析为对象，再按查询路径过滤
+	processedResult := decodeResult(result)
+	if query != "" {
+		processedResult, err = queryResult(processedResult, query)
+		if err != nil {
+			return err
+		}
+	} else if output == OutputRaw {
+		// raw格式且未过滤时输出服务端返回的原始文本
+		processedResult = result
+	}
 
 	// 输出结果
-	color.Green("调用成功:")
-	resultJson, _ := json.MarshalIndent(processedResult, "", "  ")
-	fmt.Println(string(resultJson))
+	if !quiet {
+		color.Green("调用成功:")
+	}
+	if err := writeResult(resultOut, processedResult, output); err != nil {
+		return err
+	}
 
+	if len(assertions) > 0 {
+		printAssertionReport(assertions)
+		if failed := AssertionsFailed(assertions); failed > 0 {
+			// 断言失败不是用法错误，不输出帮助信息
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d/%d 个断言失败", failed, len(assertions))
+		}
+	}
 	return nil
 }
 
+// printCallPlan 输出调用计划
+func printCallPlan(plan *CallPlan) {
+	color.Green("调用计划 (dry-run，未发送任何请求):")
+	color.Cyan("  注册中心: %s (%s)", plan.Registry, plan.RegistryType)
+	color.Cyan("  服务: %s", plan.ServiceName)
+	color.Cyan("  方法: %s", plan.MethodName)
+	if plan.Version != "" || plan.Group != "" || plan.Tag != "" {
+		color.Cyan("  过滤条件: 版本=%q 分组=%q 标签=%q", plan.Version, plan.Group, plan.Tag)
+	}
+
+	if len(plan.Candidates) > 0 {
+		color.Cyan("  候选提供者 (%d):", len(plan.Candidates))
+		for _, candidate := range plan.Candidates {
+			switch {
+			case candidate.Selected:
+				color.Green("    * %s version=%s group=%s tag=%s", candidate.Address, candidate.Version, candidate.Group, candidate.Tag)
+			case candidate.Excluded != "":
+				color.Yellow("    - %s (已过滤: %s)", candidate.Address, candidate.Excluded)
+			default:
+				fmt.Printf("      %s version=%s group=%s tag=%s\n", candidate.Address, candidate.Version, candidate.Group, candidate.Tag)
+			}
+		}
+	}
+	color.Cyan("  负载均衡: %s", plan.LoadBalance)
+	color.Cyan("  目标提供者: %s", plan.Provider)
+
+	for i, paramType := range plan.ParamTypes {
+		paramJson, _ := json.Marshal(plan.Params[i])
+		color.Cyan("  参数%d: %s %s", i+1, paramType, string(paramJson))
+	}
+
+	color.Cyan("  协议: %s, 编码: %s, 大小: %d 字节", plan.Protocol, plan.Encoding, plan.PayloadSize)
+	color.Cyan("  发送内容:")
+	fmt.Print("    " + plan.Command)
+	payload, _ := hex.DecodeString(plan.PayloadHex)
+	fmt.Print(hex.Dump(payload))
+
+	for _, warning := range plan.Warnings {
+		color.Yellow("  警告: %s", warning)
+	}
+}
+
+// maxPayloadBytes 读取--max-payload标志(MB)并转换为字节数，未设置时返回0使用默认值
+func maxPayloadBytes(cmd *cobra.Command) int {
+	maxPayload, err := cmd.Flags().GetInt("max-payload")
+	if err != nil || maxPayload <= 0 {
+		return 0
+	}
+	return maxPayload * 1024 * 1024
+}
+
 // runListCommand 列出可用服务
 func runListCommand(cmd *cobra.Command, args []string) error {
 	registry, _ := cmd.Flags().GetString("registry")
@@ -140,6 +298,7 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 		Application: appName,
 		Timeout:     5 * time.Second,
 	}
+	activeProfile(cmd).Apply(config)
 
 	// 创建Dubbo客户端
 	client, err := NewDubboClient(config)
@@ -148,11 +307,26 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 	}
 	defer client.Close()
 
-	// 获取服务列表
-	services, err := client.ListServices()
+	// 获取服务列表，成功时更新本地缓存，注册中心不可用时使用缓存离线浏览
+	catalog, err := LoadServiceCatalog(config)
 	if err != nil {
-		return fmt.Errorf("获取服务列表失败: %v", err)
+		return err
 	}
+	offline := false
+	if err := catalog.RefreshServices(client); err != nil {
+		if catalog.Empty() {
+			return fmt.Errorf("获取服务列表失败: %v", err)
+		}
+		offline = true
+		color.Yellow("获取服务列表失败: %v", err)
+		color.Yellow("使用%s缓存的服务目录: %s", describeAge(catalog.UpdatedAt), catalog.Path())
+	}
+	defer func() {
+		if err := catalog.Save(); err != nil {
+			color.Yellow("警告: %v", err)
+		}
+	}()
+	services := catalog.ServiceNames()
 
 	// 过滤服务
 	if filter != "" {
@@ -168,14 +342,14 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 	// 如果指定了特定服务，显示其方法
 	if len(args) > 0 {
 		serviceName := args[0]
-		methods, err := client.ListMethods(serviceName)
+		methods, err := listServiceMethods(catalog, client, serviceName, offline)
 		if err != nil {
 			return fmt.Errorf("获取服务方法失败: %v", err)
 		}
 
 		color.Green("服务 %s 的方法:", serviceName)
 		for _, method := range methods {
-			color.White("  %s", method)
+			color.White("  %s", methodSignature(method))
 		}
 		return nil
 	}
@@ -185,10 +359,10 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 	for _, service := range services {
 		color.White("  %s", service)
 		if showMethods {
-			methods, err := client.ListMethods(service)
+			methods, err := listServiceMethods(catalog, client, service, offline)
 			if err == nil {
 				for _, method := range methods {
-					color.Cyan("    └─ %s", method)
+					color.Cyan("    └─ %s", methodSignature(method))
 				}
 			}
 		}
@@ -197,72 +371,155 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// listServiceMethods 获取服务的方法并更新缓存，获取失败或离线时使用缓存的方法
+func listServiceMethods(catalog *ServiceCatalog, client *DubboClient, serviceName string, offline bool) ([]MethodDefinition, error) {
+	cached := catalog.Service(serviceName)
+	if offline {
+		if cached == nil || cached.MethodsAt.IsZero() {
+			return nil, fmt.Errorf("缓存中没有服务 %s 的方法", serviceName)
+		}
+		return cached.Methods, nil
+	}
+
+	service, err := catalog.RefreshMethods(client, serviceName)
+	if err != nil {
+		if cached == nil || cached.MethodsAt.IsZero() {
+			return nil, err
+		}
+		color.Yellow("获取服务 %s 的方法失败，使用%s缓存的方法: %v", serviceName, describeAge(cached.MethodsAt), err)
+		return cached.Methods, nil
+	}
+	return service.Methods, nil
+}
+
 // runConfigInitCommand 初始化配置文件
 func runConfigInitCommand(cmd *cobra.Command, args []string) error {
 	configFile, _ := cmd.Flags().GetString("config")
-
-	// 默认配置
-	defaultConfig := map[string]interface{}{
-		"registry": map[string]interface{}{
-			"address":  "zookeeper://127.0.0.1:2181",
-			"timeout":  "5s",
-			"username": "",
-			"password": "",
-		},
-		"application": map[string]interface{}{
-			"name":    "dubbo-invoke-client",
-			"version": "1.0.0",
-			"owner":   "",
-		},
-		"consumer": map[string]interface{}{
-			"timeout":     "3s",
-			"retries":     0,
-			"loadbalance": "random",
-			"generic":     true,
-		},
-		"protocol": map[string]interface{}{
-			"name": "dubbo",
-			"port": 20880,
-		},
-	}
+	cm := NewConfigManagerAt(configFile)
 
 	// 检查文件是否已存在
-	if _, err := os.Stat(configFile); err == nil {
-		return fmt.Errorf("配置文件 %s 已存在", configFile)
+	if cm.Exists() {
+		return fmt.Errorf("配置文件 %s 已存在", cm.GetConfigPath())
 	}
 
-	// 创建配置文件
-	data, err := yaml.Marshal(defaultConfig)
-	if err != nil {
-		return fmt.Errorf("序列化配置失败: %v", err)
+	// 写入与 Config 结构一致的默认配置，并附带常用环境的示例
+	cm.GetConfig().Current = "dev"
+	cm.GetConfig().Profiles = map[string]ProfileConfig{
+		"dev":  {Description: "开发环境", Registry: "zookeeper://127.0.0.1:2181"},
+		"test": {Description: "测试环境", Registry: "nacos://127.0.0.1:8848", Namespace: "test"},
+		"pre":  {Description: "预发环境", Registry: "nacos://127.0.0.1:8848", Namespace: "pre", Timeout: "5s"},
+		"prod": {Description: "生产环境", Registry: "nacos://127.0.0.1:8848", Namespace: "prod", Timeout: "5s"},
 	}
-
-	err = os.WriteFile(configFile, data, 0644)
-	if err != nil {
-		return fmt.Errorf("写入配置文件失败: %v", err)
+	if err := cm.SaveConfig(); err != nil {
+		return err
 	}
 
-	color.Green("配置文件已创建: %s", configFile)
+	color.Green("配置文件已创建: %s", cm.GetConfigPath())
+	color.Cyan("已添加示例环境 dev、test、pre、prod，请按实际情况修改注册中心地址")
 	return nil
 }
 
 // runConfigShowCommand 显示当前配置
 func runConfigShowCommand(cmd *cobra.Command, args []string) error {
 	configFile, _ := cmd.Flags().GetString("config")
+	cm := NewConfigManagerAt(configFile)
+	if !cm.Exists() {
+		return fmt.Errorf("配置文件 %s 不存在，请先执行 dubbo-invoke config init", cm.GetConfigPath())
+	}
+	if err := cm.LoadConfig(); err != nil {
+		return err
+	}
+
+	// 指定 --profile 时显示合并基础配置和环境变量后实际使用的配置
+	if cmd.Flags().Changed("profile") {
+		name, _ := cmd.Flags().GetString("profile")
+		profile, err := LoadActiveProfile(configFile, name)
+		if err != nil {
+			return err
+		}
+		profile.Password = maskPassword(profile.Password)
+		data, _ := yaml.Marshal(profile.ProfileConfig)
+		color.Green("环境 %s (%s):", profile.Name, cm.GetConfigPath())
+		fmt.Print(string(data))
+		return nil
+	}
+
+	// 显示配置，隐藏密码，凭据引用原样显示
+	config := *cm.GetConfig()
+	config.Registry.Password = maskPassword(config.Registry.Password)
+	profiles := make(map[string]ProfileConfig, len(config.Profiles))
+	for name, profile := range config.Profiles {
+		profile.Password = maskPassword(profile.Password)
+		profiles[name] = profile
+	}
+	config.Profiles = profiles
+
+	color.Green("当前配置 (%s):", cm.GetConfigPath())
+	data, _ := yaml.Marshal(config)
+	fmt.Print(string(data))
+
+	return nil
+}
+
+// runConfigProfilesCommand 列出配置文件中的环境
+func runConfigProfilesCommand(cmd *cobra.Command, args []string) error {
+	configFile, _ := cmd.Flags().GetString("config")
+	cm := NewConfigManagerAt(configFile)
+	if err := cm.LoadConfig(); err != nil {
+		return err
+	}
+	names := cm.ProfileNames()
+	if len(names) == 0 {
+		color.Yellow("%s 中没有定义环境", cm.GetConfigPath())
+		return nil
+	}
+
+	current := firstNonEmpty(os.Getenv("DUBBO_INVOKE_PROFILE"), cm.CurrentProfile())
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  环境\t注册中心\t命名空间\t超时\t说明")
+	for _, name := range names {
+		profile, err := cm.ResolveProfile(name)
+		if err != nil {
+			return err
+		}
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\t%s\n", marker, name, profile.Registry, profile.Namespace, profile.Timeout, profile.Description)
+	}
+	return tw.Flush()
+}
 
-	// 读取配置文件
-	viper.SetConfigFile(configFile)
-	err := viper.ReadInConfig()
+// runConfigUseCommand 切换配置文件中的当前环境
+func runConfigUseCommand(cmd *cobra.Command, args []string) error {
+	cm, err := loadConfigForUpdate(cmd)
 	if err != nil {
-		return fmt.Errorf("读取配置文件失败: %v", err)
+		return err
+	}
+	if cm.doc == nil {
+		return fmt.Errorf("配置文件 %s 不存在，请先执行 dubbo-invoke config init", cm.GetConfigPath())
 	}
 
-	// 显示配置
-	color.Green("当前配置 (%s):", configFile)
-	allSettings := viper.AllSettings()
-	data, _ := yaml.Marshal(allSettings)
-	fmt.Println(string(data))
+	name := args[0]
+	if name == "-" {
+		name = ""
+	}
+	if err := cm.UseProfile(name); err != nil {
+		return err
+	}
+	if err := cm.Save(); err != nil {
+		return err
+	}
 
+	if name == "" {
+		color.Green("已切换为不使用任何环境")
+		return nil
+	}
+	color.Green("已切换到环境: %s", name)
+	if os.Getenv("DUBBO_INVOKE_PROFILE") != "" {
+		color.Yellow("注意: DUBBO_INVOKE_PROFILE 环境变量优先于配置文件中的当前环境")
+	}
 	return nil
 }
 
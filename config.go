@@ -1,56 +1,97 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/spf13/viper"
+	"github.com/fatih/color"
 	yaml "gopkg.in/yaml.v3"
 )
 
 // Config 应用配置
 type Config struct {
-	Registry    RegistryConfig `yaml:"registry" mapstructure:"registry"`
-	Application AppConfig      `yaml:"application" mapstructure:"application"`
-	Defaults    DefaultConfig  `yaml:"defaults" mapstructure:"defaults"`
+	Registry    RegistryConfig `yaml:"registry"`
+	Application AppConfig      `yaml:"application"`
+	Defaults    DefaultConfig  `yaml:"defaults"`
+	// Current 当前使用的环境，为空时只使用上面的基础配置
+	Current  string                   `yaml:"current,omitempty"`
+	Profiles map[string]ProfileConfig `yaml:"profiles,omitempty"`
 }
 
 // RegistryConfig 注册中心配置
 type RegistryConfig struct {
-	Address  string `yaml:"address" mapstructure:"address"`
-	Protocol string `yaml:"protocol" mapstructure:"protocol"`
-	Username string `yaml:"username" mapstructure:"username"`
-	Password string `yaml:"password" mapstructure:"password"`
-	Timeout  string `yaml:"timeout" mapstructure:"timeout"`
+	Address   string `yaml:"address"`
+	Protocol  string `yaml:"protocol"`
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password"`
+	Namespace string `yaml:"namespace,omitempty"`
+	Timeout   string `yaml:"timeout"`
 }
 
 // AppConfig 应用配置
 type AppConfig struct {
-	Name    string `yaml:"name" mapstructure:"name"`
-	Version string `yaml:"version" mapstructure:"version"`
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
 }
 
 // DefaultConfig 默认配置
 type DefaultConfig struct {
-	Timeout  string `yaml:"timeout" mapstructure:"timeout"`
-	Protocol string `yaml:"protocol" mapstructure:"protocol"`
-	Version  string `yaml:"version" mapstructure:"version"`
-	Group    string `yaml:"group" mapstructure:"group"`
+	Timeout  string `yaml:"timeout"`
+	Protocol string `yaml:"protocol"`
+	Version  string `yaml:"version"`
+	Group    string `yaml:"group"`
+	// Charset telnet调用使用的字符集，默认为GBK
+	Charset     string            `yaml:"charset,omitempty"`
+	Attachments map[string]string `yaml:"attachments,omitempty"`
+}
+
+// ProfileConfig 环境配置，未填写的项沿用基础配置
+type ProfileConfig struct {
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Registry    string            `yaml:"registry,omitempty" json:"registry,omitempty"`
+	Namespace   string            `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Username    string            `yaml:"username,omitempty" json:"username,omitempty"`
+	Password    string            `yaml:"password,omitempty" json:"-"`
+	App         string            `yaml:"app,omitempty" json:"app,omitempty"`
+	Version     string            `yaml:"version,omitempty" json:"version,omitempty"`
+	Group       string            `yaml:"group,omitempty" json:"group,omitempty"`
+	Timeout     string            `yaml:"timeout,omitempty" json:"timeout,omitempty"` // 调用超时，如 3s、500ms
+	Attachments map[string]string `yaml:"attachments,omitempty" json:"attachments,omitempty"`
+	Charset     string            `yaml:"charset,omitempty" json:"charset,omitempty"`
 }
 
 // ConfigManager 配置管理器
 type ConfigManager struct {
 	configPath string
 	config     *Config
+	doc        *yaml.Node // 配置文件的YAML文档，修改配置项时在文档上修改以保留注释和顺序
 }
 
 // NewConfigManager 创建配置管理器
 func NewConfigManager() *ConfigManager {
-	homeDir, _ := os.UserHomeDir()
-	configPath := filepath.Join(homeDir, ".dubbo-invoke", "config.yaml")
-	
+	return NewConfigManagerAt("")
+}
+
+// NewConfigManagerAt 创建使用指定配置文件的配置管理器，路径为空时依次使用
+// DUBBO_INVOKE_CONFIG 环境变量和 ~/.dubbo-invoke/config.yaml
+func NewConfigManagerAt(configPath string) *ConfigManager {
+	if configPath == "" {
+		configPath = os.Getenv("DUBBO_INVOKE_CONFIG")
+	}
+	if configPath == "" {
+		home, err := dubboInvokeHome()
+		if err != nil {
+			home = ".dubbo-invoke"
+		}
+		configPath = filepath.Join(home, "config.yaml")
+	}
+
 	return &ConfigManager{
 		configPath: configPath,
 		config:     getDefaultConfig(),
@@ -74,103 +115,343 @@ func getDefaultConfig() *Config {
 			Protocol: "dubbo",
 			Version:  "",
 			Group:    "",
+			Charset:  "GBK",
 		},
 	}
 }
 
-// LoadConfig 加载配置
+// LoadConfig 加载配置，配置文件不存在时使用默认配置，由 config init 创建文件
+// 旧版格式的配置文件会自动迁移为当前格式，原文件备份为 .bak
 func (cm *ConfigManager) LoadConfig() error {
-	// 检查配置文件是否存在
-	if _, err := os.Stat(cm.configPath); os.IsNotExist(err) {
-		// 配置文件不存在，创建默认配置
-		return cm.SaveConfig()
+	data, notes, err := cm.readDocument()
+	if err != nil || cm.doc == nil {
+		return err
+	}
+
+	cm.saveMigration(data, notes)
+
+	var problems []string
+	for _, issue := range validateConfigNode(cm.doc.Content[0]) {
+		if !issue.Warning {
+			problems = append(problems, issue.String())
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("配置文件 %s 有误:\n  %s\n可以执行 dubbo-invoke config edit 修改，或用 config set/unset 修改单个配置项",
+			cm.configPath, strings.Join(problems, "\n  "))
 	}
+	return cm.decodeDocument()
+}
 
-	// 使用viper加载配置
-	viper.SetConfigFile(cm.configPath)
-	viper.SetConfigType("yaml")
+// readDocument 读取并解析配置文件，在内存中完成旧版格式的迁移，返回原始内容和迁移说明
+// 只检查YAML语法，配置项的问题由 validateConfigNode 检查，便于 config set/unset/edit 修复有误的配置文件
+func (cm *ConfigManager) readDocument() ([]byte, []string, error) {
+	data, err := os.ReadFile(cm.configPath)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		return fmt.Errorf("读取配置文件失败: %v", err)
+	// 不使用viper解析，viper会把环境名和attachments的键转为小写
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("解析配置文件 %s 失败: %v", cm.configPath, err)
+	}
+	if len(doc.Content) == 0 {
+		// 空文件
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
 	}
+	cm.doc = &doc
+	return data, migrateConfigNode(doc.Content[0]), nil
+}
 
-	// 解析配置到结构体
-	if err := viper.Unmarshal(cm.config); err != nil {
-		return fmt.Errorf("解析配置失败: %v", err)
+// saveMigration 备份旧版配置文件并保存迁移后的配置，迁移说明输出到标准错误，避免混入命令的输出
+func (cm *ConfigManager) saveMigration(original []byte, notes []string) {
+	if len(notes) == 0 {
+		return
 	}
+	backup := cm.configPath + ".bak"
+	err := os.WriteFile(backup, original, 0600)
+	if err == nil {
+		err = cm.Save()
+	}
+	warn := color.New(color.FgYellow)
+	if err != nil {
+		warn.Fprintf(os.Stderr, "配置文件 %s 为旧版格式，自动迁移失败: %v\n", cm.configPath, err)
+	} else {
+		warn.Fprintf(os.Stderr, "配置文件 %s 已迁移为新格式，原文件备份为 %s\n", cm.configPath, backup)
+	}
+	for _, note := range notes {
+		warn.Fprintf(os.Stderr, "  - %s\n", note)
+	}
+}
 
+// decodeDocument 将配置文档解析为配置结构，文档中没有的配置项使用默认值
+func (cm *ConfigManager) decodeDocument() error {
+	config := getDefaultConfig()
+	if err := cm.doc.Decode(config); err != nil {
+		return fmt.Errorf("解析配置文件 %s 失败: %v", cm.configPath, err)
+	}
+	cm.config = config
 	return nil
 }
 
-// SaveConfig 保存配置
+// document 返回配置文档的根节点，配置文件不存在时由当前配置生成
+func (cm *ConfigManager) document() (*yaml.Node, error) {
+	if cm.doc == nil {
+		var root yaml.Node
+		if err := root.Encode(cm.config); err != nil {
+			return nil, fmt.Errorf("序列化配置失败: %v", err)
+		}
+		cm.doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&root}}
+	}
+	root := cm.doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("配置文件 %s 的顶层应为对象", cm.configPath)
+	}
+	return root, nil
+}
+
+// Exists 配置文件是否存在
+func (cm *ConfigManager) Exists() bool {
+	_, err := os.Stat(cm.configPath)
+	return err == nil
+}
+
+// SaveConfig 将当前配置完整写入配置文件
 func (cm *ConfigManager) SaveConfig() error {
+	cm.doc = nil
+	return cm.Save()
+}
+
+// Save 保存配置文件，通过 Set/Unset 修改的配置文件保留原有的注释和顺序
+func (cm *ConfigManager) Save() error {
 	// 确保配置目录存在
 	configDir := filepath.Dir(cm.configPath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("创建配置目录失败: %v", err)
 	}
-
-	// 将配置序列化为YAML
-	data, err := yaml.Marshal(cm.config)
+	data, err := cm.encodeDocument()
 	if err != nil {
-		return fmt.Errorf("序列化配置失败: %v", err)
+		return err
 	}
 
-	// 写入文件
-	if err := os.WriteFile(cm.configPath, data, 0644); err != nil {
+	// 写入文件，配置中可能含有注册中心密码，只允许当前用户读取
+	if err := os.WriteFile(cm.configPath, data, 0600); err != nil {
 		return fmt.Errorf("写入配置文件失败: %v", err)
 	}
 
 	return nil
 }
 
+// encodeDocument 将配置文档序列化为YAML，缩进与 config init 生成的文件一致
+func (cm *ConfigManager) encodeDocument() ([]byte, error) {
+	if _, err := cm.document(); err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cm.doc); err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %v", err)
+	}
+	encoder.Close()
+	return buffer.Bytes(), nil
+}
+
 // GetConfig 获取配置
 func (cm *ConfigManager) GetConfig() *Config {
 	return cm.config
 }
 
-// SetRegistryAddress 设置注册中心地址
-func (cm *ConfigManager) SetRegistryAddress(address string) {
-	cm.config.Registry.Address = address
+// Get 获取配置项的值，未设置的配置项返回默认值，key为空时返回全部配置
+// 返回nil表示配置项未设置且没有默认值
+func (cm *ConfigManager) Get(key string) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := root.Encode(cm.config); err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %v", err)
+	}
+	if key == "" {
+		return &root, nil
+	}
+	if _, _, err := lookupConfigField(key); err != nil && !isConfigSection(strings.Split(key, ".")) {
+		return nil, err
+	}
+	return yamlLookupPath(&root, strings.Split(key, ".")), nil
 }
 
-// SetRegistryAuth 设置注册中心认证
-func (cm *ConfigManager) SetRegistryAuth(username, password string) {
-	cm.config.Registry.Username = username
-	cm.config.Registry.Password = password
-}
+// Set 校验并设置配置项，键值对类型的配置项可以整体设置为JSON对象，也可以按 <配置项>.<键> 单独设置
+func (cm *ConfigManager) Set(key, value string) error {
+	field, mapKey, err := lookupConfigField(key)
+	if err != nil {
+		return err
+	}
+	segments := strings.Split(key, ".")
+	if segments[0] == "profiles" && !profileNamePattern.MatchString(segments[1]) {
+		return fmt.Errorf("环境名称 %s 无效，只能包含字母、数字、下划线和连字符", segments[1])
+	}
 
-// SetDefaultTimeout 设置默认超时时间
-func (cm *ConfigManager) SetDefaultTimeout(timeout string) {
-	cm.config.Defaults.Timeout = timeout
+	root, err := cm.document()
+	if err != nil {
+		return err
+	}
+
+	var node *yaml.Node
+	if field.Kind == configKindMap && mapKey == "" {
+		if node, err = parseConfigMapValue(value); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	} else {
+		// 环境列表取自文档，配置文件其他部分有误时也能切换环境
+		profiles := make(map[string]bool)
+		if section := yamlMappingValue(root, "profiles"); section != nil && section.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(section.Content); i += 2 {
+				profiles[section.Content[i].Value] = true
+			}
+		}
+		if mapKey == "" {
+			if err := validateConfigValue(field, value, profiles); err != nil {
+				return fmt.Errorf("%s: %v", key, err)
+			}
+		}
+		node = yamlStringNode(value)
+	}
+
+	parent, err := yamlEnsurePath(root, segments[:len(segments)-1])
+	if err != nil {
+		return err
+	}
+	yamlSetMappingValue(parent, segments[len(segments)-1], node)
+	cm.refresh()
+	return nil
 }
 
-// SetDefaultVersion 设置默认版本
-func (cm *ConfigManager) SetDefaultVersion(version string) {
-	cm.config.Defaults.Version = version
+// refresh 修改文档后重新解析配置，文档中其他配置项有误时保留原配置，由 validateConfigNode 报告问题
+func (cm *ConfigManager) refresh() {
+	if err := cm.decodeDocument(); err != nil {
+		cm.config = getDefaultConfig()
+	}
 }
 
-// SetDefaultGroup 设置默认分组
-func (cm *ConfigManager) SetDefaultGroup(group string) {
-	cm.config.Defaults.Group = group
+// Unset 删除配置项，删除后使用默认值；可以删除整个分组(如某个环境)，也可以删除未知的配置项
+func (cm *ConfigManager) Unset(key string) error {
+	root, err := cm.document()
+	if err != nil {
+		return err
+	}
+	segments := strings.Split(key, ".")
+	parent := yamlLookupPath(root, segments[:len(segments)-1])
+	if !yamlDeleteMappingKey(parent, segments[len(segments)-1]) {
+		return fmt.Errorf("配置项 %s 未设置", key)
+	}
+	cm.refresh()
+	return nil
 }
 
-// GetDubboConfig 获取Dubbo客户端配置
+// GetDubboConfig 获取当前环境的Dubbo客户端配置
 func (cm *ConfigManager) GetDubboConfig() *DubboConfig {
-	timeout, _ := time.ParseDuration(cm.config.Defaults.Timeout)
-	
+	profile, err := cm.ResolveProfile(cm.config.Current)
+	if err != nil {
+		profile = cm.baseProfile()
+	}
+	timeout, _ := parseConfigTimeout(profile.Timeout)
+
 	return &DubboConfig{
-		Registry:    cm.config.Registry.Address,
-		Application: cm.config.Application.Name,
+		Registry:    profile.Registry,
+		Application: profile.App,
 		Timeout:     timeout,
-		Version:     cm.config.Defaults.Version,
-		Group:       cm.config.Defaults.Group,
+		Version:     profile.Version,
+		Group:       profile.Group,
 		Protocol:    cm.config.Defaults.Protocol,
-		Username:    cm.config.Registry.Username,
-		Password:    cm.config.Registry.Password,
+		Username:    profile.Username,
+		Password:    profile.Password,
+		Namespace:   profile.Namespace,
+		Charset:     profile.Charset,
+		Attachments: profile.Attachments,
 	}
 }
 
+// ProfileNames 返回排序后的环境名称
+func (cm *ConfigManager) ProfileNames() []string {
+	names := make([]string, 0, len(cm.config.Profiles))
+	for name := range cm.config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CurrentProfile 返回当前使用的环境名称
+func (cm *ConfigManager) CurrentProfile() string {
+	return cm.config.Current
+}
+
+// UseProfile 切换当前环境，名称为空时不使用任何环境
+func (cm *ConfigManager) UseProfile(name string) error {
+	// 由 Set 检查环境是否存在
+	return cm.Set("current", name)
+}
+
+// baseProfile 将基础配置转换为环境配置，作为各环境的默认值
+func (cm *ConfigManager) baseProfile() *ProfileConfig {
+	base := &ProfileConfig{
+		Registry:  cm.config.Registry.Address,
+		Namespace: cm.config.Registry.Namespace,
+		Username:  cm.config.Registry.Username,
+		Password:  cm.config.Registry.Password,
+		App:       cm.config.Application.Name,
+		Version:   cm.config.Defaults.Version,
+		Group:     cm.config.Defaults.Group,
+		Timeout:   cm.config.Defaults.Timeout,
+		Charset:   cm.config.Defaults.Charset,
+	}
+	if len(cm.config.Defaults.Attachments) > 0 {
+		base.Attachments = make(map[string]string, len(cm.config.Defaults.Attachments))
+		for key, value := range cm.config.Defaults.Attachments {
+			base.Attachments[key] = value
+		}
+	}
+	return base
+}
+
+// ResolveProfile 合并基础配置和指定环境的配置，名称为空时只返回基础配置
+func (cm *ConfigManager) ResolveProfile(name string) (*ProfileConfig, error) {
+	resolved := cm.baseProfile()
+	if name == "" {
+		return resolved, nil
+	}
+	profile, ok := cm.config.Profiles[name]
+	if !ok {
+		if len(cm.config.Profiles) == 0 {
+			return nil, fmt.Errorf("环境 %s 不存在，配置文件 %s 中没有定义任何环境", name, cm.configPath)
+		}
+		return nil, fmt.Errorf("环境 %s 不存在，可用的环境: %s", name, strings.Join(cm.ProfileNames(), ", "))
+	}
+
+	resolved.Description = profile.Description
+	resolved.Registry = firstNonEmpty(profile.Registry, resolved.Registry)
+	resolved.Namespace = firstNonEmpty(profile.Namespace, resolved.Namespace)
+	resolved.App = firstNonEmpty(profile.App, resolved.App)
+	resolved.Version = firstNonEmpty(profile.Version, resolved.Version)
+	resolved.Group = firstNonEmpty(profile.Group, resolved.Group)
+	resolved.Timeout = firstNonEmpty(profile.Timeout, resolved.Timeout)
+	resolved.Charset = firstNonEmpty(profile.Charset, resolved.Charset)
+	// 用户名和密码成对使用，环境指定了用户名时不沿用基础配置的密码
+	if profile.Username != "" || profile.Password != "" {
+		resolved.Username = profile.Username
+		resolved.Password = profile.Password
+	}
+	for key, value := range profile.Attachments {
+		if resolved.Attachments == nil {
+			resolved.Attachments = make(map[string]string, len(profile.Attachments))
+		}
+		resolved.Attachments[key] = value
+	}
+	return resolved, nil
+}
+
 // ShowConfig 显示当前配置
 func (cm *ConfigManager) ShowConfig() (string, error) {
 	data, err := yaml.Marshal(cm.config)
@@ -180,24 +461,36 @@ func (cm *ConfigManager) ShowConfig() (string, error) {
 	return string(data), nil
 }
 
-// ValidateConfig 验证配置
+// ValidateConfig 按配置项定义验证合并默认值后的配置，返回所有问题
 func (cm *ConfigManager) ValidateConfig() error {
-	if cm.config.Registry.Address == "" {
-		return fmt.Errorf("注册中心地址不能为空")
+	root, err := cm.Get("")
+	if err != nil {
+		return err
 	}
-
-	if cm.config.Application.Name == "" {
-		return fmt.Errorf("应用名称不能为空")
+	var problems []string
+	for _, issue := range validateConfigNode(root) {
+		if !issue.Warning {
+			problems = append(problems, issue.String())
+		}
 	}
-
-	// 验证超时时间格式
-	if _, err := time.ParseDuration(cm.config.Defaults.Timeout); err != nil {
-		return fmt.Errorf("无效的超时时间格式: %s", cm.config.Defaults.Timeout)
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
 	}
-
 	return nil
 }
 
+// parseConfigTimeout 解析配置中的超时时间，支持 3s、500ms 等格式，纯数字按毫秒处理，与 --timeout 参数一致
+func parseConfigTimeout(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if ms, err := strconv.Atoi(value); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return time.ParseDuration(value)
+}
+
 // GetConfigPath 获取配置文件路径
 func (cm *ConfigManager) GetConfigPath() string {
 	return cm.configPath
@@ -206,4 +499,4 @@ func (cm *ConfigManager) GetConfigPath() string {
 // ResetConfig 重置为默认配置
 func (cm *ConfigManager) ResetConfig() {
 	cm.config = getDefaultConfig()
-}
\ No newline at end of file
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// loadConfigForUpdate 加载配置文件用于修改，配置项有误时也能加载，便于通过 set/unset 修复
+func loadConfigForUpdate(cmd *cobra.Command) (*ConfigManager, error) {
+	configFile, _ := cmd.Flags().GetString("config")
+	cm := NewConfigManagerAt(configFile)
+	data, notes, err := cm.readDocument()
+	if err != nil {
+		return nil, err
+	}
+	if cm.doc != nil {
+		cm.saveMigration(data, notes)
+		cm.refresh()
+	}
+	return cm, nil
+}
+
+// configErrors 返回配置文档中的错误，不包含提示
+func configErrors(cm *ConfigManager) []ConfigIssue {
+	root, err := cm.document()
+	if err != nil {
+		return []ConfigIssue{{Key: "(根)", Message: err.Error()}}
+	}
+	var problems []ConfigIssue
+	for _, issue := range validateConfigNode(root) {
+		if !issue.Warning {
+			problems = append(problems, issue)
+		}
+	}
+	return problems
+}
+
+// warnConfigErrors 修改配置后提示文件中仍存在的错误
+func warnConfigErrors(cm *ConfigManager) {
+	problems := configErrors(cm)
+	if len(problems) == 0 {
+		return
+	}
+	color.Yellow("配置文件中仍有 %d 个错误:", len(problems))
+	for _, issue := range problems {
+		color.Yellow("  %s", issue)
+	}
+}
+
+// runConfigGetCommand 显示配置项的值，未指定配置项时列出所有配置项
+func runConfigGetCommand(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	configFile, _ := cmd.Flags().GetString("config")
+	cm := NewConfigManagerAt(configFile)
+	if err := cm.LoadConfig(); err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		root, err := cm.Get("")
+		if err != nil {
+			return err
+		}
+		printConfigEntries("", root)
+		return nil
+	}
+
+	key := args[0]
+	node, err := cm.Get(key)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("配置项 %s 未设置", key)
+	}
+	if node.Kind == yaml.ScalarNode {
+		if field, _, _ := lookupConfigField(key); field != nil && field.Secret {
+			fmt.Println(maskPassword(node.Value))
+			return nil
+		}
+		fmt.Println(node.Value)
+		return nil
+	}
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return fmt.Errorf("序列化配置失败: %v", err)
+	}
+	return encoder.Close()
+}
+
+// printConfigEntries 按 key = value 的形式逐行输出配置项，隐藏密码
+func printConfigEntries(prefix string, node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		value := node.Value
+		if field, _, err := lookupConfigField(prefix); err == nil && field.Secret {
+			value = maskPassword(value)
+		}
+		fmt.Printf("%s = %s\n", prefix, value)
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		printConfigEntries(key, node.Content[i+1])
+	}
+}
+
+// runConfigSetCommand 设置配置项，保留配置文件中的注释
+func runConfigSetCommand(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	cm, err := loadConfigForUpdate(cmd)
+	if err != nil {
+		return err
+	}
+	key, value := args[0], args[1]
+	if err := cm.Set(key, value); err != nil {
+		return err
+	}
+	if err := cm.Save(); err != nil {
+		return err
+	}
+
+	if field, _, _ := lookupConfigField(key); field != nil && field.Secret {
+		color.Green("已设置 %s = %s", key, maskPassword(value))
+		warnMissingSecret(value)
+	} else {
+		color.Green("已设置 %s = %s", key, value)
+	}
+	warnConfigErrors(cm)
+	return nil
+}
+
+// warnMissingSecret 密码以明文保存或引用的凭据不存在时提示
+func warnMissingSecret(value string) {
+	if value == "" {
+		return
+	}
+	if !isSecretRef(value) {
+		color.Yellow("提示: 密码以明文保存在配置文件中，建议执行 dubbo-invoke secret set <名称> 加密保存，并设置为 %s<名称>", secretRefPrefix)
+		return
+	}
+	name := strings.TrimPrefix(value, secretRefPrefix)
+	if store, err := openSecretStore(); err == nil && store.Entry(name) == nil {
+		color.Yellow("提示: 凭据 %s 尚不存在，请执行 dubbo-invoke secret set %s", name, name)
+	}
+}
+
+// runConfigUnsetCommand 删除配置项，删除后使用默认值
+func runConfigUnsetCommand(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	cm, err := loadConfigForUpdate(cmd)
+	if err != nil {
+		return err
+	}
+	if cm.doc == nil {
+		return fmt.Errorf("配置文件 %s 不存在", cm.GetConfigPath())
+	}
+	key := args[0]
+	root, err := cm.document()
+	if err != nil {
+		return err
+	}
+	current := yamlMappingValue(root, "current")
+
+	if err := cm.Unset(key); err != nil {
+		return err
+	}
+	// 删除当前环境时同时清除 current，避免之后的命令因环境不存在而失败
+	removedCurrent := false
+	if segments := strings.Split(key, "."); current != nil && current.Value != "" &&
+		(key == "profiles" || (len(segments) == 2 && segments[0] == "profiles" && segments[1] == current.Value)) {
+		removedCurrent = cm.Unset("current") == nil
+	}
+	if err := cm.Save(); err != nil {
+		return err
+	}
+
+	color.Green("已删除 %s", key)
+	if removedCurrent {
+		color.Yellow("已删除的环境 %s 是当前环境，当前环境已清除", current.Value)
+	}
+	warnConfigErrors(cm)
+	return nil
+}
+
+// runConfigValidateCommand 检查配置文件，列出所有问题及所在行，有错误时以非0状态退出
+func runConfigValidateCommand(cmd *cobra.Command, args []string) error {
+	configFile, _ := cmd.Flags().GetString("config")
+	cm := NewConfigManagerAt(configFile)
+	_, notes, err := cm.readDocument()
+	cmd.SilenceUsage = true
+	if err != nil {
+		return err
+	}
+	if cm.doc == nil {
+		return fmt.Errorf("配置文件 %s 不存在，请先执行 dubbo-invoke config init", cm.GetConfigPath())
+	}
+
+	if len(notes) > 0 {
+		color.Yellow("配置文件为旧版格式，加载时将自动迁移:")
+		for _, note := range notes {
+			color.Yellow("  - %s", note)
+		}
+	}
+
+	issues := validateConfigNode(cm.doc.Content[0])
+	if store, err := openSecretStore(); err == nil {
+		for _, ref := range configSecretReferences(cm.doc.Content[0]) {
+			if validateSecretName(ref.Name) == nil && store.Entry(ref.Name) == nil {
+				issues = append(issues, ConfigIssue{Key: ref.Key, Line: ref.Line,
+					Message: fmt.Sprintf("凭据 %s 不存在，请执行 dubbo-invoke secret set %s", ref.Name, ref.Name)})
+			}
+		}
+	}
+
+	errors := 0
+	for _, issue := range issues {
+		if issue.Warning {
+			color.Yellow("提示: %s", issue)
+			continue
+		}
+		errors++
+		color.Red("错误: %s", issue)
+	}
+	if errors > 0 {
+		return fmt.Errorf("配置文件 %s 有 %d 个错误", cm.GetConfigPath(), errors)
+	}
+	color.Green("配置文件 %s 有效", cm.GetConfigPath())
+	return nil
+}
+
+// checkConfigData 检查配置文件内容，与加载时一样先迁移旧版格式
+func checkConfigData(data []byte) ([]ConfigIssue, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("YAML格式错误: %v", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	migrateConfigNode(doc.Content[0])
+	return validateConfigNode(doc.Content[0]), nil
+}
+
+// runConfigEditCommand 在编辑器中修改配置文件，保存前检查，有错误时可以重新编辑
+func runConfigEditCommand(cmd *cobra.Command, args []string) error {
+	configFile, _ := cmd.Flags().GetString("config")
+	cm := NewConfigManagerAt(configFile)
+	original, err := os.ReadFile(cm.GetConfigPath())
+	if os.IsNotExist(err) {
+		// 配置文件不存在时从默认配置开始编辑
+		if original, err = cm.encodeDocument(); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	// 在临时文件中编辑，放弃时不影响原文件
+	tmp, err := os.CreateTemp("", "dubbo-invoke-config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(original)
+	tmp.Close()
+	if err != nil {
+		return fmt.Errorf("写入临时文件失败: %v", err)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var data []byte
+	for {
+		if err := openInEditor(tmp.Name()); err != nil {
+			return err
+		}
+		if data, err = os.ReadFile(tmp.Name()); err != nil {
+			return fmt.Errorf("读取临时文件失败: %v", err)
+		}
+		if cm.Exists() && bytes.Equal(data, original) {
+			color.Yellow("配置文件未修改")
+			return nil
+		}
+
+		issues, err := checkConfigData(data)
+		if err != nil {
+			issues = []ConfigIssue{{Key: "(文件)", Message: err.Error()}}
+		}
+		errors := 0
+		for _, issue := range issues {
+			if issue.Warning {
+				color.Yellow("提示: %s", issue)
+				continue
+			}
+			errors++
+			color.Red("错误: %s", issue)
+		}
+		if errors == 0 {
+			break
+		}
+
+		fmt.Print("配置文件有误，重新编辑(e)/仍然保存(s)/放弃(q)? [e] ")
+		answer, _ := reader.ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer == "s" {
+			break
+		}
+		if answer != "" && answer != "e" {
+			color.Yellow("已放弃修改")
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cm.GetConfigPath()), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	if err := os.WriteFile(cm.GetConfigPath(), data, 0600); err != nil {
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+	color.Green("配置文件已保存: %s", cm.GetConfigPath())
+	return nil
+}
+
+// runConfigPathCommand 输出配置文件路径
+func runConfigPathCommand(cmd *cobra.Command, args []string) error {
+	configFile, _ := cmd.Flags().GetString("config")
+	fmt.Println(NewConfigManagerAt(configFile).GetConfigPath())
+	return nil
+}
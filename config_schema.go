@@ -0,0 +1,518 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// configValueKind 配置项的值类型
+type configValueKind int
+
+const (
+	configKindString   configValueKind = iota
+	configKindRegistry                 // 注册中心地址，如 nacos://127.0.0.1:8848
+	configKindDuration                 // 超时时间，如 3s、500ms
+	configKindCharset                  // telnet调用的字符集
+	configKindProfile                  // 已定义的环境名称
+	configKindMap                      // 字符串键值对，如 attachments
+)
+
+// configField 配置项定义
+type configField struct {
+	Key         string // 点分路径，profiles.* 中的 * 表示环境名
+	Kind        configValueKind
+	Description string
+	Required    bool // 不能为空
+	Secret      bool // 列出配置时隐藏
+}
+
+// configSchema 配置文件中所有可用的配置项
+var configSchema = []configField{
+	{Key: "registry.address", Kind: configKindRegistry, Description: "注册中心地址，如 nacos://127.0.0.1:8848", Required: true},
+	{Key: "registry.protocol", Description: "注册中心类型"},
+	{Key: "registry.namespace", Description: "命名空间(Nacos)"},
+	{Key: "registry.username", Description: "注册中心用户名"},
+	{Key: "registry.password", Description: "注册中心密码，建议使用 secret://<凭据名称> 引用加密保存的凭据", Secret: true},
+	{Key: "registry.timeout", Kind: configKindDuration, Description: "连接注册中心的超时时间"},
+	{Key: "application.name", Description: "应用名称", Required: true},
+	{Key: "application.version", Description: "应用版本"},
+	{Key: "defaults.timeout", Kind: configKindDuration, Description: "调用超时时间，如 3s、500ms"},
+	{Key: "defaults.protocol", Description: "调用协议"},
+	{Key: "defaults.version", Description: "默认服务版本"},
+	{Key: "defaults.group", Description: "默认服务分组"},
+	{Key: "defaults.charset", Kind: configKindCharset, Description: "telnet调用使用的字符集: GBK、GB18030、UTF-8"},
+	{Key: "defaults.attachments", Kind: configKindMap, Description: "隐式参数"},
+	{Key: "current", Kind: configKindProfile, Description: "当前环境"},
+	{Key: "profiles.*.description", Description: "环境说明"},
+	{Key: "profiles.*.registry", Kind: configKindRegistry, Description: "注册中心地址"},
+	{Key: "profiles.*.namespace", Description: "命名空间(Nacos)"},
+	{Key: "profiles.*.username", Description: "注册中心用户名"},
+	{Key: "profiles.*.password", Description: "注册中心密码，建议使用 secret://<凭据名称> 引用加密保存的凭据", Secret: true},
+	{Key: "profiles.*.app", Description: "应用名称"},
+	{Key: "profiles.*.version", Description: "默认服务版本"},
+	{Key: "profiles.*.group", Description: "默认服务分组"},
+	{Key: "profiles.*.timeout", Kind: configKindDuration, Description: "调用超时时间，如 3s、500ms"},
+	{Key: "profiles.*.charset", Kind: configKindCharset, Description: "telnet调用使用的字符集"},
+	{Key: "profiles.*.attachments", Kind: configKindMap, Description: "隐式参数"},
+}
+
+// profileNamePattern 环境名称，不能包含点号，否则无法通过点分路径引用
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// supportedRegistrySchemes 支持的注册中心类型
+var supportedRegistrySchemes = []string{"zookeeper", "nacos", "dubbo", "direct"}
+
+// ConfigIssue 配置文件中的问题
+type ConfigIssue struct {
+	Key     string
+	Line    int
+	Message string
+	Warning bool // 只是提示，不影响使用
+}
+
+func (i ConfigIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("第%d行 %s: %s", i.Line, i.Key, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.Key, i.Message)
+}
+
+// matchConfigKey 按段比较配置路径，* 匹配任意环境名
+func matchConfigKey(pattern, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+	for i := range pattern {
+		if pattern[i] != "*" && pattern[i] != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isConfigSection 判断路径是否为包含子配置项的分组，如 registry、profiles.prod
+func isConfigSection(segments []string) bool {
+	if len(segments) == 0 {
+		return true
+	}
+	for _, field := range configSchema {
+		pattern := strings.Split(field.Key, ".")
+		if len(pattern) > len(segments) && matchConfigKey(pattern[:len(segments)], segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupConfigField 查找配置项定义，键值对类型的配置项可以再带一级键名，返回该键名
+func lookupConfigField(key string) (*configField, string, error) {
+	segments := strings.Split(key, ".")
+	for i := range configSchema {
+		field := &configSchema[i]
+		pattern := strings.Split(field.Key, ".")
+		if matchConfigKey(pattern, segments) {
+			return field, "", nil
+		}
+		if field.Kind == configKindMap && len(segments) == len(pattern)+1 && matchConfigKey(pattern, segments[:len(pattern)]) {
+			return field, segments[len(pattern)], nil
+		}
+	}
+	if isConfigSection(segments) {
+		return nil, "", fmt.Errorf("%s 是配置分组，请指定其中的配置项: %s", key, strings.Join(configSectionKeys(segments), ", "))
+	}
+	if hint := legacyConfigKeys[key]; hint != "" {
+		return nil, "", fmt.Errorf("%s 是旧版配置项，%s", key, hint)
+	}
+	if renamed := renamedConfigKeys[key]; renamed != "" {
+		return nil, "", fmt.Errorf("%s 是旧版配置项，已改为 %s", key, renamed)
+	}
+	return nil, "", fmt.Errorf("未知的配置项 %s%s", key, suggestConfigKey(segments))
+}
+
+// configSectionKeys 返回分组下的配置项名称
+func configSectionKeys(segments []string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, field := range configSchema {
+		pattern := strings.Split(field.Key, ".")
+		if len(pattern) > len(segments) && matchConfigKey(pattern[:len(segments)], segments) && !seen[pattern[len(segments)]] {
+			seen[pattern[len(segments)]] = true
+			keys = append(keys, pattern[len(segments)])
+		}
+	}
+	return keys
+}
+
+// suggestConfigKey 为未知的配置项给出提示：同一分组下的可用配置项，或其他分组中的同名配置项
+func suggestConfigKey(segments []string) string {
+	parent := segments[:len(segments)-1]
+	if len(parent) > 0 && isConfigSection(parent) {
+		return fmt.Sprintf("，%s 下可用的配置项: %s", strings.Join(parent, "."), strings.Join(configSectionKeys(parent), ", "))
+	}
+	last := segments[len(segments)-1]
+	var candidates []string
+	for _, field := range configSchema {
+		if strings.HasSuffix(field.Key, "."+last) {
+			candidates = append(candidates, field.Key)
+		}
+	}
+	if len(candidates) > 0 {
+		return fmt.Sprintf("，是否想使用 %s", strings.Join(candidates, " 或 "))
+	}
+	return fmt.Sprintf("，可用的配置分组: %s", strings.Join(configSectionKeys(nil), ", "))
+}
+
+// validateConfigValue 校验单个配置项的值
+func validateConfigValue(field *configField, value string, profiles map[string]bool) error {
+	if value == "" {
+		if field.Required {
+			return fmt.Errorf("不能为空")
+		}
+		return nil
+	}
+	if field.Secret && isSecretRef(value) {
+		return validateSecretName(strings.TrimPrefix(value, secretRefPrefix))
+	}
+	switch field.Kind {
+	case configKindRegistry:
+		scheme, address, ok := strings.Cut(value, "://")
+		if !ok || address == "" {
+			return fmt.Errorf("无效的注册中心地址 %q，格式如 nacos://127.0.0.1:8848", value)
+		}
+		for _, supported := range supportedRegistrySchemes {
+			if scheme == supported {
+				return nil
+			}
+		}
+		return fmt.Errorf("不支持的注册中心类型 %q，可选: %s", scheme, strings.Join(supportedRegistrySchemes, ", "))
+	case configKindDuration:
+		timeout, err := parseConfigTimeout(value)
+		if err != nil || timeout < 0 {
+			return fmt.Errorf("无效的超时时间 %q，格式如 3s、500ms", value)
+		}
+	case configKindCharset:
+		if !validCharset(value) {
+			return fmt.Errorf("不支持的字符集 %q，可选: GBK, GB18030, UTF-8", value)
+		}
+	case configKindProfile:
+		if !profiles[value] {
+			names := make([]string, 0, len(profiles))
+			for name := range profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if len(names) == 0 {
+				return fmt.Errorf("环境 %s 不存在，配置文件中没有定义任何环境", value)
+			}
+			return fmt.Errorf("环境 %s 不存在，可用的环境: %s", value, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// parseConfigMapValue 解析键值对类型配置项的值，格式为JSON对象
+func parseConfigMapValue(value string) (*yaml.Node, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, fmt.Errorf("值应为JSON对象，如 '{\"traceId\":\"abc\"}': %v", err)
+	}
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range keys {
+		value := raw[key]
+		text, ok := value.(string)
+		if !ok {
+			data, _ := json.Marshal(value)
+			text = string(data)
+		}
+		yamlSetMappingValue(node, key, yamlStringNode(text))
+	}
+	return node, nil
+}
+
+// yamlStringNode 创建字符串节点
+func yamlStringNode(value string) *yaml.Node {
+	node := &yaml.Node{}
+	node.SetString(value)
+	return node
+}
+
+// yamlMappingValue 返回映射节点中键对应的值节点，不存在时返回nil
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlSetMappingValue 设置映射节点中键对应的值，键已存在时保留键上的注释
+func yamlSetMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			value.LineComment = firstNonEmpty(value.LineComment, node.Content[i+1].LineComment)
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, yamlStringNode(key), value)
+}
+
+// yamlDeleteMappingKey 删除映射节点中的键，返回键是否存在
+func yamlDeleteMappingKey(node *yaml.Node, key string) bool {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// yamlLookupPath 按路径查找节点，不存在时返回nil
+func yamlLookupPath(node *yaml.Node, segments []string) *yaml.Node {
+	for _, segment := range segments {
+		node = yamlMappingValue(node, segment)
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// yamlEnsurePath 按路径查找映射节点，不存在的分组自动创建
+func yamlEnsurePath(node *yaml.Node, segments []string) (*yaml.Node, error) {
+	for i, segment := range segments {
+		next := yamlMappingValue(node, segment)
+		if next == nil || (next.Kind == yaml.ScalarNode && next.Tag == "!!null") {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			yamlSetMappingValue(node, segment, next)
+		}
+		if next.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s 不是配置分组，无法在其中设置配置项", strings.Join(segments[:i+1], "."))
+		}
+		node = next
+	}
+	return node, nil
+}
+
+// yamlKindName 节点类型的中文名称，用于错误信息
+func yamlKindName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "对象"
+	case yaml.SequenceNode:
+		return "列表"
+	default:
+		return "值"
+	}
+}
+
+// validateConfigNode 按配置项定义检查配置文件，返回所有问题及所在行
+func validateConfigNode(root *yaml.Node) []ConfigIssue {
+	if root == nil || (root.Kind == yaml.ScalarNode && root.Tag == "!!null") {
+		return nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return []ConfigIssue{{Key: "(根)", Line: root.Line, Message: "配置文件的顶层应为对象"}}
+	}
+
+	profiles := make(map[string]bool)
+	if node := yamlMappingValue(root, "profiles"); node != nil && node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			profiles[node.Content[i].Value] = true
+		}
+	}
+
+	var issues []ConfigIssue
+	var walk func(node *yaml.Node, path []string)
+	walk = func(node *yaml.Node, path []string) {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			segments := append(append([]string{}, path...), keyNode.Value)
+			key := strings.Join(segments, ".")
+			empty := valueNode.Kind == yaml.ScalarNode && valueNode.Tag == "!!null"
+
+			if len(path) == 1 && path[0] == "profiles" && !profileNamePattern.MatchString(keyNode.Value) {
+				issues = append(issues, ConfigIssue{Key: key, Line: keyNode.Line, Message: "环境名称只能包含字母、数字、下划线和连字符"})
+				continue
+			}
+
+			field, _, err := lookupConfigField(key)
+			if field == nil {
+				if !isConfigSection(segments) {
+					issue := ConfigIssue{Key: key, Line: keyNode.Line, Message: err.Error()}
+					if hint, ok := legacyConfigKeys[key]; ok {
+						issue.Message = hint
+						issue.Warning = true
+					}
+					issues = append(issues, issue)
+					continue
+				}
+				if empty {
+					continue
+				}
+				if valueNode.Kind != yaml.MappingNode {
+					issues = append(issues, ConfigIssue{Key: key, Line: valueNode.Line, Message: fmt.Sprintf("应为对象，实际为%s", yamlKindName(valueNode))})
+					continue
+				}
+				walk(valueNode, segments)
+				continue
+			}
+
+			if field.Kind == configKindMap {
+				if empty {
+					continue
+				}
+				if valueNode.Kind != yaml.MappingNode {
+					issues = append(issues, ConfigIssue{Key: key, Line: valueNode.Line, Message: fmt.Sprintf("应为键值对，实际为%s", yamlKindName(valueNode))})
+					continue
+				}
+				for j := 0; j+1 < len(valueNode.Content); j += 2 {
+					if entry := valueNode.Content[j+1]; entry.Kind != yaml.ScalarNode {
+						issues = append(issues, ConfigIssue{Key: key + "." + valueNode.Content[j].Value, Line: entry.Line, Message: fmt.Sprintf("应为字符串，实际为%s", yamlKindName(entry))})
+					}
+				}
+				continue
+			}
+
+			if valueNode.Kind != yaml.ScalarNode {
+				issues = append(issues, ConfigIssue{Key: key, Line: valueNode.Line, Message: fmt.Sprintf("应为字符串，实际为%s", yamlKindName(valueNode))})
+				continue
+			}
+			value := valueNode.Value
+			if empty {
+				value = ""
+			}
+			if err := validateConfigValue(field, value, profiles); err != nil {
+				issues = append(issues, ConfigIssue{Key: key, Line: valueNode.Line, Message: err.Error()})
+			} else if field.Secret && value != "" && !isSecretRef(value) {
+				issues = append(issues, ConfigIssue{Key: key, Line: valueNode.Line, Warning: true,
+					Message: fmt.Sprintf("密码以明文保存，建议执行 dubbo-invoke secret set <名称> 加密保存，并改为 %s<名称>", secretRefPrefix)})
+			}
+		}
+	}
+	walk(root, nil)
+	return issues
+}
+
+// legacyConfigKeys 旧版配置中不再使用且无法迁移的配置项
+var legacyConfigKeys = map[string]string{
+	"services": "常用服务配置已不再使用，请将常用请求保存为集合，见 dubbo-invoke run --help",
+}
+
+// renamedConfigKeys 旧版配置项与对应的当前配置项，加载时自动迁移
+var renamedConfigKeys = map[string]string{
+	"defaults.service_version": "defaults.version",
+	"defaults.service_group":   "defaults.group",
+	"consumer.timeout":         "defaults.timeout",
+	"protocol.name":            "defaults.protocol",
+}
+
+// migrateConfigNode 将旧版配置文件迁移为当前格式，返回迁移说明，不需要迁移时返回空
+// 旧版格式包括 defaults.service_version/service_group、单位为秒的数字超时时间，
+// 以及早期 config init 生成的 consumer、protocol 分组
+func migrateConfigNode(root *yaml.Node) []string {
+	if root == nil || root.Kind != yaml.MappingNode {
+		return nil
+	}
+	var notes []string
+
+	// 将旧键的值移动到新键，新键已存在时保留新键
+	move := func(from *yaml.Node, fromKey string, to *yaml.Node, toKey, fromPath, toPath string) {
+		value := yamlMappingValue(from, fromKey)
+		if value == nil {
+			return
+		}
+		yamlDeleteMappingKey(from, fromKey)
+		if yamlMappingValue(to, toKey) != nil {
+			notes = append(notes, fmt.Sprintf("%s 已被 %s 取代，已删除", fromPath, toPath))
+			return
+		}
+		yamlSetMappingValue(to, toKey, value)
+		notes = append(notes, fmt.Sprintf("%s 已迁移为 %s", fromPath, toPath))
+	}
+	drop := func(node *yaml.Node, key, path, reason string) {
+		if yamlDeleteMappingKey(node, key) {
+			notes = append(notes, fmt.Sprintf("%s %s，已删除", path, reason))
+		}
+	}
+	section := func(key string) *yaml.Node {
+		node := yamlMappingValue(root, key)
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		return node
+	}
+
+	consumer := section("consumer")
+	protocol := section("protocol")
+	defaults := section("defaults")
+	if defaults == nil && (consumer != nil || protocol != nil) {
+		defaults = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		yamlSetMappingValue(root, "defaults", defaults)
+	}
+
+	if defaults != nil {
+		move(defaults, "service_version", defaults, "version", "defaults.service_version", "defaults.version")
+		move(defaults, "service_group", defaults, "group", "defaults.service_group", "defaults.group")
+		drop(defaults, "retries", "defaults.retries", "不再支持")
+		drop(defaults, "loadbalance", "defaults.loadbalance", "不再支持")
+	}
+	if consumer != nil {
+		move(consumer, "timeout", defaults, "timeout", "consumer.timeout", "defaults.timeout")
+		yamlDeleteMappingKey(root, "consumer")
+		notes = append(notes, "consumer 中的 retries、loadbalance、generic 不再支持，已删除")
+	}
+	if protocol != nil {
+		move(protocol, "name", defaults, "protocol", "protocol.name", "defaults.protocol")
+		yamlDeleteMappingKey(root, "protocol")
+		notes = append(notes, "protocol.port 不再使用，已删除")
+	}
+	if application := section("application"); application != nil {
+		drop(application, "owner", "application.owner", "不再使用")
+	}
+
+	// 旧版超时时间为不带单位的秒数；环境中的数字超时与 --timeout 一致按毫秒处理
+	migrateTimeout := func(node *yaml.Node, path, unit string) {
+		value := yamlMappingValue(node, "timeout")
+		if value == nil || value.Kind != yaml.ScalarNode || value.Tag != "!!int" {
+			return
+		}
+		converted := value.Value + unit
+		notes = append(notes, fmt.Sprintf("%s.timeout: %s 已迁移为 %s", path, value.Value, converted))
+		value.SetString(converted)
+	}
+	if registry := section("registry"); registry != nil {
+		migrateTimeout(registry, "registry", "s")
+	}
+	if defaults != nil {
+		migrateTimeout(defaults, "defaults", "s")
+	}
+	if profiles := section("profiles"); profiles != nil {
+		for i := 0; i+1 < len(profiles.Content); i += 2 {
+			if profiles.Content[i+1].Kind == yaml.MappingNode {
+				migrateTimeout(profiles.Content[i+1], "profiles."+profiles.Content[i].Value, "ms")
+			}
+		}
+	}
+
+	return notes
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlPathValue 按 a.b.c 形式的路径取标量值，不存在时返回false
+func yamlPathValue(root *yaml.Node, path string) (string, bool) {
+	node := root
+	for _, key := range strings.Split(path, ".") {
+		node = yamlMappingValue(node, key)
+		if node == nil {
+			return "", false
+		}
+	}
+	return node.Value, true
+}
+
+func TestMigrateConfigNode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string // 迁移后的路径和值
+		absent  []string          // 迁移后应删除的路径
+		notes   []string          // 迁移说明中应包含的内容
+		noNotes bool
+	}{
+		{
+			name: "旧版服务版本和分组",
+			input: `defaults:
+  service_version: 1.0.0
+  service_group: order
+`,
+			want:   map[string]string{"defaults.version": "1.0.0", "defaults.group": "order"},
+			absent: []string{"defaults.service_version", "defaults.service_group"},
+			notes:  []string{"defaults.service_version 已迁移为 defaults.version"},
+		},
+		{
+			name: "新旧版本同时存在时保留新键",
+			input: `defaults:
+  version: 2.0.0
+  service_version: 1.0.0
+`,
+			want:   map[string]string{"defaults.version": "2.0.0"},
+			absent: []string{"defaults.service_version"},
+			notes:  []string{"defaults.service_version 已被 defaults.version 取代"},
+		},
+		{
+			name: "consumer分组中的超时时间",
+			input: `consumer:
+  timeout: 5
+  retries: 2
+`,
+			want:   map[string]string{"defaults.timeout": "5s"},
+			absent: []string{"consumer"},
+			notes:  []string{"consumer.timeout 已迁移为 defaults.timeout", "defaults.timeout: 5 已迁移为 5s"},
+		},
+		{
+			name: "protocol分组",
+			input: `protocol:
+  name: dubbo
+  port: 20880
+`,
+			want:   map[string]string{"defaults.protocol": "dubbo"},
+			absent: []string{"protocol"},
+			notes:  []string{"protocol.name 已迁移为 defaults.protocol"},
+		},
+		{
+			name: "数字超时时间按所在位置补充单位",
+			input: `registry:
+  timeout: 3
+defaults:
+  timeout: 10
+profiles:
+  test:
+    timeout: 500
+  prod:
+    timeout: 5s
+`,
+			want: map[string]string{
+				"registry.timeout":      "3s",
+				"defaults.timeout":      "10s",
+				"profiles.test.timeout": "500ms",
+				"profiles.prod.timeout": "5s",
+			},
+			notes: []string{"profiles.test.timeout: 500 已迁移为 500ms"},
+		},
+		{
+			name: "当前格式不需要迁移",
+			input: `defaults:
+  version: 1.0.0
+  timeout: 3s
+profiles:
+  test:
+    registry: nacos://127.0.0.1:8848
+    timeout: 5s
+`,
+			want:    map[string]string{"defaults.version": "1.0.0", "profiles.test.timeout": "5s"},
+			noNotes: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var document yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.input), &document); err != nil {
+				t.Fatalf("解析YAML失败: %v", err)
+			}
+			root := document.Content[0]
+
+			notes := migrateConfigNode(root)
+			if tt.noNotes && len(notes) > 0 {
+				t.Errorf("不应迁移，实际迁移说明: %v", notes)
+			}
+			for path, want := range tt.want {
+				if got, ok := yamlPathValue(root, path); !ok || got != want {
+					t.Errorf("%s = %q (存在: %t)，期望 %q", path, got, ok, want)
+				}
+			}
+			for _, path := range tt.absent {
+				if _, ok := yamlPathValue(root, path); ok {
+					t.Errorf("%s 应已删除", path)
+				}
+			}
+			joined := strings.Join(notes, "\n")
+			for _, note := range tt.notes {
+				if !strings.Contains(joined, note) {
+					t.Errorf("迁移说明中缺少 %q，实际: %v", note, notes)
+				}
+			}
+		})
+	}
+}
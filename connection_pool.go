@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// telnetPrompt Dubbo telnet命令行提示符，每条命令的响应都以它结尾
+const telnetPrompt = "dubbo>"
+
+// PooledConn 连接池中的服务提供者连接
+// 同一连接同一时刻只会被一个调用方持有，telnet会话上的调用因此串行执行
+type PooledConn struct {
+	net.Conn
+	key       string
+	createdAt time.Time
+	lastUsed  time.Time
+	broken    bool
+	reused    bool
+}
+
+// Reused 连接是否复用自空闲连接，而不是本次新建
+func (pc *PooledConn) Reused() bool {
+	return pc.reused
+}
+
+// MarkBroken 标记连接不可复用（读写失败或响应未对齐到提示符），归还时将被关闭
+func (pc *PooledConn) MarkBroken() {
+	pc.broken = true
+}
+
+// ConnectionPool 按协议和地址区分的服务提供者连接池
+type ConnectionPool struct {
+	mu                  sync.Mutex
+	idle                map[string][]*PooledConn
+	slots               map[string]chan struct{} // 每个提供者的连接数上限
+	maxPerProvider      int
+	idleTimeout         time.Duration
+	healthCheckInterval time.Duration
+	dialTimeout         time.Duration
+	stopCh              chan struct{}
+	closed              bool
+}
+
+var (
+	sharedPool     *ConnectionPool
+	sharedPoolOnce sync.Once
+)
+
+// getConnectionPool 获取进程内共享的连接池，容量取自OptimizedDubboConfig.ConnectionPool
+func getConnectionPool() *ConnectionPool {
+	sharedPoolOnce.Do(func() {
+		cfg := NewOptimizedDubboConfig(&DubboConfig{})
+		sharedPool = NewConnectionPool(cfg.ConnectionPool, 60*time.Second)
+	})
+	return sharedPool
+}
+
+// NewConnectionPool 创建连接池
+func NewConnectionPool(maxPerProvider int, idleTimeout time.Duration) *ConnectionPool {
+	if maxPerProvider <= 0 {
+		maxPerProvider = 1
+	}
+	pool := &ConnectionPool{
+		idle:                make(map[string][]*PooledConn),
+		slots:               make(map[string]chan struct{}),
+		maxPerProvider:      maxPerProvider,
+		idleTimeout:         idleTimeout,
+		healthCheckInterval: 10 * time.Second,
+		dialTimeout:         5 * time.Second,
+		stopCh:              make(chan struct{}),
+	}
+	go pool.evictLoop()
+	return pool
+}
+
+// poolKey 生成连接池键
+func poolKey(protocol, address string) string {
+	return protocol + "://" + address
+}
+
+// Acquire 获取到指定提供者的连接，达到上限时等待其他调用方归还
+func (p *ConnectionPool) Acquire(ctx context.Context, protocol, address string) (*PooledConn, error) {
+	key := poolKey(protocol, address)
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("连接池已关闭")
+	}
+	slot, ok := p.slots[key]
+	if !ok {
+		slot = make(chan struct{}, p.maxPerProvider)
+		p.slots[key] = slot
+	}
+	p.mu.Unlock()
+
+	// 占用一个连接名额
+	select {
+	case slot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("等待 %s 的空闲连接失败: %v", key, ctx.Err())
+	}
+
+	// 优先复用最近使用的空闲连接
+	for {
+		pc := p.popIdle(key)
+		if pc == nil {
+			break
+		}
+		if p.idleTimeout > 0 && time.Since(pc.lastUsed) > p.idleTimeout {
+			pc.Conn.Close()
+			continue
+		}
+		if time.Since(pc.lastUsed) > p.healthCheckInterval {
+			if err := p.healthCheck(pc); err != nil {
+				fmt.Printf("[POOL] 连接 %s 健康检查失败，重新建立: %v\n", key, err)
+				pc.Conn.Close()
+				continue
+			}
+		}
+		pc.lastUsed = time.Now()
+		pc.reused = true
+		return pc, nil
+	}
+
+	// 没有可用的空闲连接，建立新连接
+	dialer := &net.Dialer{Timeout: p.dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		<-slot
+		return nil, fmt.Errorf("连接Dubbo服务提供者失败 %s: %v", address, err)
+	}
+	fmt.Printf("[POOL] 新建到 %s 的连接\n", key)
+
+	now := time.Now()
+	return &PooledConn{
+		Conn:      conn,
+		key:       key,
+		createdAt: now,
+		lastUsed:  now,
+	}, nil
+}
+
+// Release 归还连接，不可复用的连接会被直接关闭
+func (p *ConnectionPool) Release(pc *PooledConn) {
+	if pc == nil {
+		return
+	}
+
+	p.mu.Lock()
+	slot := p.slots[pc.key]
+	if pc.broken || p.closed {
+		pc.Conn.Close()
+	} else {
+		pc.Conn.SetDeadline(time.Time{})
+		pc.lastUsed = time.Now()
+		p.idle[pc.key] = append(p.idle[pc.key], pc)
+	}
+	p.mu.Unlock()
+
+	if slot != nil {
+		<-slot
+	}
+}
+
+// Stats 返回每个提供者的空闲和使用中连接数
+func (p *ConnectionPool) Stats() map[string]map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[string]map[string]int)
+	for key, slot := range p.slots {
+		stats[key] = map[string]int{
+			"idle":  len(p.idle[key]),
+			"inUse": len(slot),
+			"max":   p.maxPerProvider,
+		}
+	}
+	return stats
+}
+
+// Close 关闭连接池及所有空闲连接
+func (p *ConnectionPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.stopCh)
+	for key, conns := range p.idle {
+		for _, pc := range conns {
+			pc.Conn.Close()
+		}
+		delete(p.idle, key)
+	}
+}
+
+// popIdle 取出最近归还的空闲连接
+func (p *ConnectionPool) popIdle(key string) *PooledConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	conns := p.idle[key]
+	if len(conns) == 0 {
+		return nil
+	}
+	pc := conns[len(conns)-1]
+	p.idle[key] = conns[:len(conns)-1]
+	return pc
+}
+
+// healthCheck 发送空命令，确认连接仍能返回telnet提示符
+func (p *ConnectionPool) healthCheck(pc *PooledConn) error {
+	if _, err := pc.Write([]byte("\n")); err != nil {
+		return err
+	}
+	_, err := readUntilPrompt(pc.Conn, time.Now().Add(time.Second))
+	pc.Conn.SetDeadline(time.Time{})
+	return err
+}
+
+// evictLoop 定期关闭超过空闲时间的连接
+func (p *ConnectionPool) evictLoop() {
+	if p.idleTimeout <= 0 {
+		return
+	}
+	ticker := time.NewTicker(p.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			for key, conns := range p.idle {
+				kept := conns[:0]
+				for _, pc := range conns {
+					if time.Since(pc.lastUsed) > p.idleTimeout {
+						pc.Conn.Close()
+						continue
+					}
+					kept = append(kept, pc)
+				}
+				p.idle[key] = kept
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// hasTelnetPrompt 判断响应是否已以telnet提示符结束
+func hasTelnetPrompt(text string) bool {
+	return strings.HasSuffix(strings.TrimRight(text, " \r\n"), telnetPrompt)
+}
+
+// promptTailSize 判断响应是否结束时检查的尾部长度，提示符之后可能还有空白和换行
+const promptTailSize = len(telnetPrompt) + 16
+
+// hasPromptTail 只检查已读取数据的尾部是否为telnet提示符，避免每次读取后扫描整个响应
+func hasPromptTail(data []byte) bool {
+	if len(data) > promptTailSize {
+		data = data[len(data)-promptTailSize:]
+	}
+	return hasTelnetPrompt(string(data))
+}
+
+// readUntilPrompt 读取直到telnet提示符出现，用于同一会话上命令的帧同步
+func readUntilPrompt(conn net.Conn, deadline time.Time) ([]byte, error) {
+	conn.SetReadDeadline(deadline)
+
+	var buffer bytes.Buffer
+	tempBuffer := make([]byte, 4096)
+	for {
+		n, err := conn.Read(tempBuffer)
+		if n > 0 {
+			buffer.Write(tempBuffer[:n])
+			if hasPromptTail(buffer.Bytes()) {
+				return buffer.Bytes(), nil
+			}
+		}
+		if err != nil {
+			return buffer.Bytes(), fmt.Errorf("等待telnet提示符失败: %v", err)
+		}
+	}
+}
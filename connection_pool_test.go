@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+// startTelnetServer 启动模拟的Dubbo telnet服务，每收到一行返回一次提示符
+func startTelnetServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听端口失败: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					if _, err := reader.ReadString('\n'); err != nil {
+						return
+					}
+					conn.Write([]byte("{}\r\nelapsed: 0 ms.\r\n" + telnetPrompt))
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestConnectionPoolReuse(t *testing.T) {
+	address := startTelnetServer(t)
+	pool := NewConnectionPool(2, time.Minute)
+	defer pool.Close()
+	ctx := context.Background()
+
+	first, err := pool.Acquire(ctx, "dubbo", address)
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	if first.Reused() {
+		t.Error("新建的连接不应标记为复用")
+	}
+	pool.Release(first)
+
+	second, err := pool.Acquire(ctx, "dubbo", address)
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	if !second.Reused() || second != first {
+		t.Error("归还的连接应被复用")
+	}
+
+	// 不可复用的连接归还时关闭，下次获取时新建
+	second.MarkBroken()
+	pool.Release(second)
+	third, err := pool.Acquire(ctx, "dubbo", address)
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	if third.Reused() || third == second {
+		t.Error("标记为不可复用的连接不应再使用")
+	}
+	pool.Release(third)
+
+	stats := pool.Stats()[poolKey("dubbo", address)]
+	if stats["idle"] != 1 || stats["inUse"] != 0 || stats["max"] != 2 {
+		t.Errorf("连接池统计为 %v", stats)
+	}
+}
+
+func TestConnectionPoolLimit(t *testing.T) {
+	address := startTelnetServer(t)
+	pool := NewConnectionPool(1, time.Minute)
+	defer pool.Close()
+
+	held, err := pool.Acquire(context.Background(), "dubbo", address)
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+
+	// 达到上限时等待其他调用方归还，超时后返回错误
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(ctx, "dubbo", address); err == nil {
+		t.Fatal("达到连接数上限时应等待并超时")
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		pool.Release(held)
+	}()
+	waited, err := pool.Acquire(context.Background(), "dubbo", address)
+	if err != nil {
+		t.Fatalf("其他调用方归还后应获取到连接: %v", err)
+	}
+	pool.Release(waited)
+
+	pool.Close()
+	if _, err := pool.Acquire(context.Background(), "dubbo", address); err == nil {
+		t.Error("连接池关闭后获取连接应返回错误")
+	}
+}
+
+func TestConnectionPoolHealthCheck(t *testing.T) {
+	address := startTelnetServer(t)
+	pool := NewConnectionPool(1, time.Minute)
+	defer pool.Close()
+	pool.healthCheckInterval = 0
+
+	pc, err := pool.Acquire(context.Background(), "dubbo", address)
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	pool.Release(pc)
+
+	// 空闲连接通过健康检查后复用
+	pc, err = pool.Acquire(context.Background(), "dubbo", address)
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	if !pc.Reused() {
+		t.Error("健康的空闲连接应被复用")
+	}
+
+	// 连接已失效时健康检查失败，重新建立连接
+	pc.Conn.Close()
+	pool.Release(pc)
+	renewed, err := pool.Acquire(context.Background(), "dubbo", address)
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	if renewed.Reused() || renewed == pc {
+		t.Error("失效的连接应重新建立")
+	}
+	pool.Release(renewed)
+}
+
+func TestHasPromptTail(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{"{}\r\nelapsed: 1 ms.\r\ndubbo>", true},
+		{"{}\r\nelapsed: 1 ms.\r\ndubbo> \r\n", true},
+		{`{"text": "dubbo>"}`, false},
+		{"dubbo", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := hasPromptTail([]byte(tt.data)); got != tt.want {
+			t.Errorf("hasPromptTail(%q) = %t，期望 %t", tt.data, got, tt.want)
+		}
+	}
+}
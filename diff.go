@@ -0,0 +1,483 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// DiffEnvironment 对比调用的一侧环境
+type DiffEnvironment struct {
+	Registry  string `json:"registry"`
+	Namespace string `json:"namespace"`
+	App       string `json:"app"`
+	Version   string `json:"version"` // 为空时使用请求中的版本
+	Group     string `json:"group"`   // 为空时使用请求中的分组
+	Tag       string `json:"tag"`     // 为空时使用请求中的标签
+}
+
+// DiffRequest 对比调用请求：同一个服务方法和参数分别调用两个环境
+type DiffRequest struct {
+	ServiceName    string          `json:"serviceName"`
+	MethodName     string          `json:"methodName"`
+	Parameters     json.RawMessage `json:"parameters"`
+	Types          []string        `json:"types"`
+	Timeout        int             `json:"timeout"`
+	Version        string          `json:"version"`
+	Group          string          `json:"group"`
+	Tag            string          `json:"tag"`
+	SkipValidation bool            `json:"skipValidation"`
+	Left           DiffEnvironment `json:"left"`
+	Right          DiffEnvironment `json:"right"`
+	Ignore         []string        `json:"ignore"` // 忽略的路径，如 $..timestamp、$.data.items[*].id
+}
+
+// DiffSide 对比调用一侧的结果
+type DiffSide struct {
+	Registry  string         `json:"registry"`
+	Namespace string         `json:"namespace,omitempty"`
+	Success   bool           `json:"success"`
+	Data      interface{}    `json:"data,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Duration  int64          `json:"duration"` // 单位毫秒
+	Timings   *InvokeTimings `json:"timings,omitempty"`
+}
+
+// DiffResponse 对比调用响应
+type DiffResponse struct {
+	Success   bool           `json:"success"`
+	Identical bool           `json:"identical"` // 两侧都调用成功且忽略指定路径后结果一致
+	Left      DiffSide       `json:"left"`
+	Right     DiffSide       `json:"right"`
+	Diffs     []DiffEntry    `json:"diffs"`
+	Summary   map[string]int `json:"summary"`
+	Ignore    []string       `json:"ignore,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Duration  int64          `json:"duration"`
+}
+
+// runDiff 并发调用两个环境并比较结果
+func (ws *WebServer) runDiff(ctx context.Context, req DiffRequest) (*DiffResponse, error) {
+	if req.ServiceName == "" || req.MethodName == "" {
+		return nil, fmt.Errorf("需要指定服务名和方法名")
+	}
+	if req.Left.Registry == "" || req.Right.Registry == "" {
+		return nil, fmt.Errorf("需要指定两侧的注册中心")
+	}
+	// 先校验忽略规则，避免调用完成后才发现规则无效
+	if _, err := parseIgnoreRules(req.Ignore); err != nil {
+		return nil, err
+	}
+	if req.Timeout <= 0 {
+		req.Timeout = ws.timeout
+	}
+
+	start := time.Now()
+	response := &DiffResponse{Success: true, Ignore: req.Ignore}
+	sides := []*DiffSide{&response.Left, &response.Right}
+	environments := []DiffEnvironment{req.Left, req.Right}
+
+	var wg sync.WaitGroup
+	for i := range sides {
+		wg.Add(1)
+		go func(side *DiffSide, env DiffEnvironment) {
+			defer wg.Done()
+			invokeReq := InvokeRequest{
+				ServiceName:    req.ServiceName,
+				MethodName:     req.MethodName,
+				Parameters:     req.Parameters,
+				Types:          req.Types,
+				Registry:       env.Registry,
+				Namespace:      env.Namespace,
+				App:            firstNonEmpty(env.App, ws.app, "dubbo-invoke-client"),
+				Timeout:        req.Timeout,
+				Version:        firstNonEmpty(env.Version, req.Version),
+				Group:          firstNonEmpty(env.Group, req.Group),
+				Tag:            firstNonEmpty(env.Tag, req.Tag),
+				SkipValidation: req.SkipValidation,
+			}
+
+			side.Registry = env.Registry
+			side.Namespace = env.Namespace
+			invokeStart := time.Now()
+			result, timings, err := ws.executeInvoke(ctx, invokeReq, false)
+			side.Duration = time.Since(invokeStart).Milliseconds()
+			side.Timings = timings
+			if err != nil {
+				side.Error = err.Error()
+				return
+			}
+			side.Success = true
+			side.Data = result
+		}(sides[i], environments[i])
+	}
+	wg.Wait()
+
+	response.Duration = time.Since(start).Milliseconds()
+	response.Diffs = []DiffEntry{}
+	if response.Left.Success && response.Right.Success {
+		diffs, err := DiffJSON(response.Left.Data, response.Right.Data, req.Ignore)
+		if err != nil {
+			return nil, err
+		}
+		response.Diffs = diffs
+		response.Identical = len(diffs) == 0
+	} else {
+		response.Error = "至少一侧调用失败，无法比较结果"
+	}
+	response.Summary = diffSummary(response.Diffs)
+	return response, nil
+}
+
+// handleDiff 处理对比调用请求
+func (ws *WebServer) handleDiff(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		ws.writeError(w, "只支持POST请求")
+		return
+	}
+
+	var req DiffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		color.Red("[WEB] 对比请求解析失败: %v", err)
+		ws.writeError(w, fmt.Sprintf("请求解析失败: %v", err))
+		return
+	}
+
+	color.Blue("[WEB] 开始对比调用: %s.%s, %s ↔ %s", req.ServiceName, req.MethodName, req.Left.Registry, req.Right.Registry)
+	response, err := ws.runDiff(r.Context(), req)
+	if err != nil {
+		color.Red("[WEB] 对比调用失败: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		ws.writeError(w, err.Error())
+		return
+	}
+	color.Green("[WEB] 对比完成，差异数: %d", len(response.Diffs))
+
+	// 结果中的大整数已由executeInvoke转换为字符串，直接编码即可
+	json.NewEncoder(w).Encode(response)
+}
+
+// newDiffCommand 创建diff命令
+func newDiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [service] [method] [params...] | [expression]",
+		Short: "对比同一调用在两个环境中的结果",
+		Long: `用相同的服务、方法和参数同时调用两个注册中心/命名空间，输出结果的结构化差异:
+  ~ 值变化   + 右侧新增   - 右侧缺少   ! 类型变化
+
+--ignore 指定忽略的路径(可重复)，匹配的字段及其子字段不参与比较:
+  $.data.updateTime      指定路径
+  $.data.items[*].id     数组中每个元素的字段
+  $..traceId             任意层级的字段
+  timestamp              等同于 $..timestamp
+
+结果存在差异或任一侧调用失败时以非0状态退出
+
+示例:
+  dubbo-invoke diff 'com.example.UserService.getUserById(123)' --left zookeeper://test:2181 --right zookeeper://pre:2181
+  dubbo-invoke diff com.example.UserService getUserById 123 --left nacos://nacos:8848 --left-namespace test --right nacos://nacos:8848 --right-namespace pre
+  dubbo-invoke diff 'com.example.UserService.getUserById(123)' --left zookeeper://test:2181 --right zookeeper://pre:2181 --ignore timestamp --json diff.json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runDiffCommand,
+	}
+
+	cmd.Flags().String("left", "", "左侧注册中心地址")
+	cmd.Flags().String("right", "", "右侧注册中心地址")
+	cmd.Flags().String("left-namespace", "", "左侧命名空间")
+	cmd.Flags().String("right-namespace", "", "右侧命名空间")
+	cmd.Flags().StringArray("ignore", nil, "比较时忽略的路径，可重复指定，如 '$..timestamp'、'$.data.items[*].id'")
+	cmd.Flags().String("json", "", "JSON报告输出文件")
+	cmd.Flags().StringP("version", "V", "", "服务版本")
+	cmd.Flags().StringP("group", "g", "", "服务分组")
+	cmd.Flags().String("tag", "", "服务标签，用于过滤服务提供者")
+	cmd.Flags().StringSliceP("types", "T", nil, "参数类型列表")
+	cmd.Flags().String("metadata", "", "服务定义元数据文件(JSON)，用于调用前校验参数")
+	cmd.Flags().Bool("no-validate", false, "跳过调用前的参数校验")
+	cmd.MarkFlagRequired("left")
+	cmd.MarkFlagRequired("right")
+
+	return cmd
+}
+
+// runDiffCommand 执行对比调用
+func runDiffCommand(cmd *cobra.Command, args []string) error {
+	var serviceName, methodName string
+	var params []string
+	if strings.Contains(args[0], "(") && strings.Contains(args[0], ")") {
+		serviceName, methodName, params = parseInvokeExpression(args[0])
+		if serviceName == "" || methodName == "" {
+			return fmt.Errorf("无效的调用表达式格式，期望格式: service.method(params)")
+		}
+	} else {
+		if len(args) < 2 {
+			return fmt.Errorf("需要至少指定服务名和方法名")
+		}
+		serviceName = args[0]
+		methodName = args[1]
+		params = args[2:]
+	}
+
+	appName, _ := cmd.Flags().GetString("app")
+	timeout, _ := cmd.Flags().GetInt("timeout")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	left, _ := cmd.Flags().GetString("left")
+	right, _ := cmd.Flags().GetString("right")
+	leftNamespace, _ := cmd.Flags().GetString("left-namespace")
+	rightNamespace, _ := cmd.Flags().GetString("right-namespace")
+	ignore, _ := cmd.Flags().GetStringArray("ignore")
+	jsonFile, _ := cmd.Flags().GetString("json")
+	version, _ := cmd.Flags().GetString("version")
+	group, _ := cmd.Flags().GetString("group")
+	tag, _ := cmd.Flags().GetString("tag")
+	types, _ := cmd.Flags().GetStringSlice("types")
+	metadataFile, _ := cmd.Flags().GetString("metadata")
+	noValidate, _ := cmd.Flags().GetBool("no-validate")
+
+	parsedParams, err := parseParams(params, types)
+	if err != nil {
+		return fmt.Errorf("解析参数失败: %v", err)
+	}
+	paramsJson, err := json.Marshal(parsedParams)
+	if err != nil {
+		return fmt.Errorf("序列化参数失败: %v", err)
+	}
+
+	// 与Web端共用同一调用流程，两侧结果经过相同的大整数处理后再比较
+	server := &WebServer{
+		app:          appName,
+		timeout:      timeout,
+		maxPayload:   maxPayloadBytes(cmd),
+		metadataFile: metadataFile,
+	}
+	req := DiffRequest{
+		ServiceName:    serviceName,
+		MethodName:     methodName,
+		Parameters:     paramsJson,
+		Types:          types,
+		Timeout:        timeout,
+		Version:        version,
+		Group:          group,
+		Tag:            tag,
+		SkipValidation: noValidate,
+		Left:           DiffEnvironment{Registry: left, Namespace: leftNamespace},
+		Right:          DiffEnvironment{Registry: right, Namespace: rightNamespace},
+		Ignore:         ignore,
+	}
+
+	// 非详细模式下屏蔽两侧调用的过程日志，只输出对比结果
+	var out io.Writer = os.Stdout
+	if !verbose {
+		var restore func()
+		out, restore, err = discardLogs()
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	response, err := server.runDiff(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	printDiffResponse(out, response)
+
+	if jsonFile != "" {
+		data, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			return fmt.Errorf("生成JSON报告失败: %v", err)
+		}
+		if err := os.WriteFile(jsonFile, data, 0644); err != nil {
+			return fmt.Errorf("写入JSON报告失败: %v", err)
+		}
+	}
+
+	if !response.Identical {
+		cmd.SilenceUsage = true
+		if response.Error != "" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return fmt.Errorf("两侧结果存在 %d 处差异", len(response.Diffs))
+	}
+	return nil
+}
+
+// printDiffResponse 输出对比结果
+func printDiffResponse(w io.Writer, response *DiffResponse) {
+	for _, side := range []struct {
+		label string
+		side  DiffSide
+	}{{"左侧", response.Left}, {"右侧", response.Right}} {
+		target := side.side.Registry
+		if side.side.Namespace != "" {
+			target += " (命名空间: " + side.side.Namespace + ")"
+		}
+		if side.side.Success {
+			color.New(color.FgCyan).Fprintf(w, "%s: %s %dms\n", side.label, target, side.side.Duration)
+		} else {
+			color.New(color.FgRed).Fprintf(w, "%s: %s 调用失败: %s\n", side.label, target, side.side.Error)
+		}
+	}
+	fmt.Fprintln(w)
+
+	if !response.Left.Success || !response.Right.Success {
+		return
+	}
+	if response.Identical {
+		color.New(color.FgGreen).Fprintln(w, "两侧结果一致")
+		return
+	}
+
+	printDiff(w, response.Diffs, "")
+	summary := response.Summary
+	fmt.Fprintf(w, "\n共 %d 处差异: %d 处变化, %d 处新增, %d 处缺少, %d 处类型变化\n",
+		len(response.Diffs), summary[DiffChanged], summary[DiffAdded], summary[DiffRemoved], summary[DiffTypeChanged])
+}
+
+// HistoryDiffSide 参与对比的历史记录摘要
+type HistoryDiffSide struct {
+	ID          string    `json:"id"`
+	ServiceName string    `json:"serviceName"`
+	MethodName  string    `json:"methodName"`
+	Registry    string    `json:"registry"`
+	Namespace   string    `json:"namespace"`
+	Success     bool      `json:"success"`
+	Timestamp   time.Time `json:"timestamp"`
+	Duration    int64     `json:"duration"`
+}
+
+// HistoryDiffResponse 历史记录对比响应
+type HistoryDiffResponse struct {
+	Success   bool            `json:"success"`
+	Identical bool            `json:"identical"`
+	A         HistoryDiffSide `json:"a"`
+	B         HistoryDiffSide `json:"b"`
+	Diffs     []DiffEntry     `json:"diffs"`
+	Summary   map[string]int  `json:"summary"`
+	Ignore    []string        `json:"ignore,omitempty"`
+}
+
+// handleHistoryDiff 比较两条历史记录的结果: GET /api/history/diff?a=<id>&b=<id>[&ignore=<path>...]
+func (ws *WebServer) handleHistoryDiff(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != "GET" {
+		ws.writeError(w, "只支持GET方法")
+		return
+	}
+
+	query := r.URL.Query()
+	idA, idB := query.Get("a"), query.Get("b")
+	if idA == "" || idB == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		ws.writeError(w, "需要通过参数a和b指定两条历史记录的ID")
+		return
+	}
+	response, status, err := ws.diffHistory(idA, idB, query["ignore"])
+	if err != nil {
+		w.WriteHeader(status)
+		ws.writeError(w, err.Error())
+		return
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// diffHistory 比较两条历史记录的结果，出错时同时返回HTTP状态码
+func (ws *WebServer) diffHistory(idA, idB string, ignore []string) (*HistoryDiffResponse, int, error) {
+	entryA, ok := ws.history.Get(idA)
+	if !ok {
+		return nil, http.StatusNotFound, fmt.Errorf("历史记录不存在: %s", idA)
+	}
+	entryB, ok := ws.history.Get(idB)
+	if !ok {
+		return nil, http.StatusNotFound, fmt.Errorf("历史记录不存在: %s", idB)
+	}
+
+	valueA, err := ws.historyResultValue(entryA)
+	if err != nil {
+		return nil, http.StatusGone, err
+	}
+	valueB, err := ws.historyResultValue(entryB)
+	if err != nil {
+		return nil, http.StatusGone, err
+	}
+	diffs, err := DiffJSON(valueA, valueB, ignore)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	return &HistoryDiffResponse{
+		Success:   true,
+		Identical: len(diffs) == 0,
+		A:         historyDiffSide(entryA),
+		B:         historyDiffSide(entryB),
+		Diffs:     diffs,
+		Summary:   diffSummary(diffs),
+		Ignore:    ignore,
+	}, http.StatusOK, nil
+}
+
+// historyResultValue 解析历史记录中保存的结果文本
+// 大结果从结果文件读取完整内容；数字按safeCopyValue的规则处理，超过15位的整数保持为字符串
+func (ws *WebServer) historyResultValue(entry CallHistory) (interface{}, error) {
+	text := entry.Result
+	if entry.ResultID != "" {
+		if ws.results == nil {
+			return nil, fmt.Errorf("历史记录 %s 的结果已不可用", entry.ID)
+		}
+		file, _, err := ws.results.Open(entry.ResultID)
+		if err != nil {
+			return nil, fmt.Errorf("读取历史记录 %s 的完整结果失败: %v", entry.ID, err)
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取历史记录 %s 的完整结果失败: %v", entry.ID, err)
+		}
+		text = string(data)
+	}
+
+	// 失败记录保存的是错误信息，按字符串参与比较
+	if !entry.Success {
+		return text, nil
+	}
+	return safeCopyValue(parseJSONText(text)), nil
+}
+
+// historyDiffSide 提取历史记录的摘要信息
+func historyDiffSide(entry CallHistory) HistoryDiffSide {
+	return HistoryDiffSide{
+		ID:          entry.ID,
+		ServiceName: entry.ServiceName,
+		MethodName:  entry.MethodName,
+		Registry:    entry.Registry,
+		Namespace:   entry.Namespace,
+		Success:     entry.Success,
+		Timestamp:   entry.Timestamp,
+		Duration:    entry.Duration,
+	}
+}
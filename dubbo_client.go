@@ -1,28 +1,39 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
 
 // DubboConfig Dubbo客户端配置
 type DubboConfig struct {
-	Registry    string        // 注册中心地址
-	Application string        // 应用名称
-	Timeout     time.Duration // 调用超时时间
-	Version     string        // 服务版本
-	Group       string        // 服务分组
-	Protocol    string        // 协议类型
-	Username    string        // 注册中心用户名
-	Password    string        // 注册中心密码
-	Namespace   string        // 命名空间（用于Nacos等注册中心）
+	Registry       string            // 注册中心地址
+	Application    string            // 应用名称
+	Timeout        time.Duration     // 调用超时时间
+	Version        string            // 服务版本
+	Group          string            // 服务分组
+	Protocol       string            // 协议类型
+	Username       string            // 注册中心用户名
+	Password       string            // 注册中心密码
+	Namespace      string            // 命名空间（用于Nacos等注册中心）
+	Tag            string            // 服务标签，用于过滤服务提供者
+	MetadataFile   string            // 服务定义元数据文件，用于调用前校验参数
+	MaxPayloadSize int               // 最大响应大小(字节)，0表示使用默认值
+	Attachments    map[string]string // 隐式参数，telnet协议无法传递
+	Charset        string            // telnet调用使用的字符集：GBK(默认)、GB18030、UTF-8
+	ResultSpool    *ResultStore      // 响应超过SpoolThreshold后边读取边写入的结果存储，为nil时响应只保存在内存中
+	SpoolThreshold int               // 写入ResultSpool的响应大小阈值(字节)
 }
 
 // DubboClient Dubbo客户端
 type DubboClient struct {
-	config    *DubboConfig
-	connected bool
+	config       *DubboConfig
+	connected    bool
+	lastTimings  *InvokeTimings // 最近一次调用的各阶段耗时
+	lastProvider string         // 最近一次调用的服务提供者地址
 }
 
 // NewDubboClient 创建新的Dubbo客户端
@@ -69,7 +80,7 @@ func (c *DubboClient) initConfig() error {
 	// TODO: 实际的Dubbo配置初始化
 	// 这里暂时只做基本验证
 	fmt.Printf("初始化Dubbo配置: 注册中心=%s, 应用=%s\n", c.config.Registry, c.config.Application)
-	
+
 	return nil
 }
 
@@ -111,11 +122,13 @@ type GenericInvokeRequest struct {
 
 // GenericInvokeResponse 泛化调用响应
 type GenericInvokeResponse struct {
-	Success   bool        `json:"success"`
-	Result    interface{} `json:"result,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	Timestamp int64       `json:"timestamp"`
-	Duration  int64       `json:"duration"` // 调用耗时(毫秒)
+	Success   bool           `json:"success"`
+	Result    interface{}    `json:"result,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Timestamp int64          `json:"timestamp"`
+	Duration  int64          `json:"duration"`           // 调用耗时(毫秒)
+	Timings   *InvokeTimings `json:"timings,omitempty"`  // 各阶段耗时
+	Provider  string         `json:"provider,omitempty"` // 实际调用的服务提供者地址
 }
 
 // start 启动Dubbo客户端
@@ -124,12 +137,17 @@ func (c *DubboClient) start() error {
 	// 这里暂时只设置连接状态
 	fmt.Printf("启动Dubbo客户端: %s\n", c.config.Registry)
 	c.connected = true
-	
+
 	return nil
 }
 
 // GenericInvoke 泛化调用
 func (c *DubboClient) GenericInvoke(serviceName, methodName string, paramTypes []string, params []interface{}) (interface{}, error) {
+	return c.GenericInvokeContext(context.Background(), serviceName, methodName, paramTypes, params)
+}
+
+// GenericInvokeContext 支持取消的泛化调用
+func (c *DubboClient) GenericInvokeContext(ctx context.Context, serviceName, methodName string, paramTypes []string, params []interface{}) (interface{}, error) {
 	if !c.connected {
 		return nil, fmt.Errorf("客户端未连接")
 	}
@@ -143,6 +161,51 @@ func (c *DubboClient) GenericInvoke(serviceName, methodName string, paramTypes [
 	}
 
 	// 参数类型推断和验证
+	processedParams, processedTypes, err := c.prepareParams(paramTypes, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 构建调用请求
+	request := &GenericInvokeRequest{
+		ServiceName: serviceName,
+		MethodName:  methodName,
+		ParamTypes:  processedTypes,
+		Params:      processedParams,
+		Timeout:     c.config.Timeout,
+		Version:     c.config.Version,
+		Group:       c.config.Group,
+	}
+
+	// 执行泛化调用
+	response, err := c.executeGenericInvoke(ctx, request)
+	if response != nil {
+		c.lastTimings = response.Timings
+		c.lastProvider = response.Provider
+	}
+	if err != nil {
+		return nil, fmt.Errorf("泛化调用执行失败: %v", err)
+	}
+
+	// 检查调用是否成功
+	if !response.Success {
+		return nil, fmt.Errorf("泛化调用失败: %s", response.Error)
+	}
+
+	return response.Result, nil
+}
+
+// PlanInvoke 生成调用计划，参数按与GenericInvokeContext相同的规则处理，不发送任何请求
+func (c *DubboClient) PlanInvoke(serviceName, methodName string, paramTypes []string, params []interface{}) (*CallPlan, error) {
+	processedParams, processedTypes, err := c.prepareParams(paramTypes, params)
+	if err != nil {
+		return nil, err
+	}
+	return PlanInvoke(c.config, serviceName, methodName, processedTypes, processedParams)
+}
+
+// prepareParams 按指定类型转换参数，未指定类型的参数自动推断类型
+func (c *DubboClient) prepareParams(paramTypes []string, params []interface{}) ([]interface{}, []string, error) {
 	inferrer := NewTypeInferrer()
 	processedParams := make([]interface{}, len(params))
 	processedTypes := make([]string, len(params))
@@ -155,7 +218,7 @@ func (c *DubboClient) GenericInvoke(serviceName, methodName string, paramTypes [
 			paramType := inferrer.InferType(paramTypes[i])
 			convertedParam, err := c.convertParamByType(param, paramType)
 			if err != nil {
-				return nil, fmt.Errorf("参数%d类型转换失败: %v", i+1, err)
+				return nil, nil, fmt.Errorf("参数%d类型转换失败: %v", i+1, err)
 			}
 			processedParams[i] = convertedParam
 		} else {
@@ -166,29 +229,34 @@ func (c *DubboClient) GenericInvoke(serviceName, methodName string, paramTypes [
 		}
 	}
 
-	// 构建调用请求
-	request := &GenericInvokeRequest{
-		ServiceName: serviceName,
-		MethodName:  methodName,
-		ParamTypes:  processedTypes,
-		Params:      processedParams,
-		Timeout:     c.config.Timeout,
-		Version:     c.config.Version,
-		Group:       c.config.Group,
-	}
+	return processedParams, processedTypes, nil
+}
 
-	// 执行泛化调用
-	response, err := c.executeGenericInvoke(request)
-	if err != nil {
-		return nil, fmt.Errorf("泛化调用执行失败: %v", err)
+// InvokeAsync 异步泛化调用，立即返回可等待或取消的InvokeFuture
+func (c *DubboClient) InvokeAsync(ctx context.Context, serviceName, methodName string, paramTypes []string, params []interface{}) *InvokeFuture {
+	ctx, cancel := context.WithCancel(ctx)
+	future := &InvokeFuture{
+		done:   make(chan struct{}),
+		cancel: cancel,
 	}
 
-	// 检查调用是否成功
-	if !response.Success {
-		return nil, fmt.Errorf("泛化调用失败: %s", response.Error)
-	}
+	go func() {
+		defer close(future.done)
+		defer cancel()
+		future.result, future.err = c.GenericInvokeContext(ctx, serviceName, methodName, paramTypes, params)
+	}()
 
-	return response.Result, nil
+	return future
+}
+
+// LastTimings 返回最近一次调用的各阶段耗时，尚未调用时返回nil
+func (c *DubboClient) LastTimings() *InvokeTimings {
+	return c.lastTimings
+}
+
+// LastProvider 返回最近一次调用的服务提供者地址
+func (c *DubboClient) LastProvider() string {
+	return c.lastProvider
 }
 
 // DirectInvoke 直接调用（暂不实现，需要具体的接口定义）
@@ -218,25 +286,74 @@ func (c *DubboClient) ListServices() ([]string, error) {
 	return realClient.ListServices()
 }
 
-// ListMethods 列出服务方法
+// ListMethods 列出服务方法，优先使用服务定义中的方法，没有元数据时使用ZooKeeper中提供者注册的方法名
 func (c *DubboClient) ListMethods(serviceName string) ([]string, error) {
-	// 获取服务元数据
-	// 这里需要根据具体的服务发现机制来实现
+	definition, _, err := FindServiceDefinition(c.config, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
 	methods := make([]string, 0)
-	
-	// 模拟方法列表获取
-	methods = append(methods, "示例方法列表获取功能")
-	methods = append(methods, "请根据实际服务接口实现")
-	
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			methods = append(methods, name)
+		}
+	}
+	if definition != nil {
+		for _, method := range definition.Methods {
+			add(method.Name)
+		}
+	} else if strings.HasPrefix(c.config.Registry, "zookeeper://") {
+		candidates, err := (&RealDubboClient{config: c.config}).getProviderCandidates(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range candidates {
+			for _, name := range candidate.Methods {
+				add(name)
+			}
+		}
+	}
+
+	sort.Strings(methods)
 	return methods, nil
 }
 
+// ListVariants 列出服务提供者注册的版本和分组
+// ZooKeeper从提供者URL中获取，Nacos从 providers:接口:版本:分组 形式的服务名中解析
+func (c *DubboClient) ListVariants(serviceName string) ([]ServiceVariant, error) {
+	if _, version, group, ok := parseNacosServiceName(serviceName); ok {
+		return []ServiceVariant{{Version: version, Group: group}}, nil
+	}
+	if !strings.HasPrefix(c.config.Registry, "zookeeper://") {
+		return nil, nil
+	}
+
+	candidates, err := (&RealDubboClient{config: c.config}).getProviderCandidates(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[ServiceVariant]bool)
+	variants := make([]ServiceVariant, 0)
+	for _, candidate := range candidates {
+		variant := ServiceVariant{Version: candidate.Version, Group: candidate.Group}
+		if !seen[variant] {
+			seen[variant] = true
+			variants = append(variants, variant)
+		}
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].String() < variants[j].String() })
+	return variants, nil
+}
+
 // Close 关闭客户端
 func (c *DubboClient) Close() error {
 	// TODO: 实际的资源清理逻辑
 	fmt.Println("关闭Dubbo客户端")
 	c.connected = false
-	
+
 	return nil
 }
 
@@ -276,12 +393,12 @@ func (c *DubboClient) Ping() error {
 // convertParamByType 根据类型转换参数
 func (c *DubboClient) convertParamByType(param interface{}, paramType ParameterType) (interface{}, error) {
 	inferrer := NewTypeInferrer()
-	
+
 	// 如果参数是字符串，尝试按类型解析
 	if paramStr, ok := param.(string); ok {
 		return inferrer.ParseParameterValue(paramStr, paramType)
 	}
-	
+
 	// 如果参数已经是正确类型，直接返回
 	return param, nil
 }
@@ -311,30 +428,47 @@ func (c *DubboClient) inferParamType(param interface{}) string {
 }
 
 // executeGenericInvoke 执行泛化调用
-func (c *DubboClient) executeGenericInvoke(request *GenericInvokeRequest) (*GenericInvokeResponse, error) {
+func (c *DubboClient) executeGenericInvoke(ctx context.Context, request *GenericInvokeRequest) (*GenericInvokeResponse, error) {
 	startTime := time.Now()
-	
-	// TODO: 实际的Dubbo泛化调用逻辑
-	// 这里暂时返回模拟结果
-	fmt.Printf("执行泛化调用: 服务=%s, 方法=%s, 参数类型=%v, 参数=%v\n", 
+
+	fmt.Printf("执行泛化调用: 服务=%s, 方法=%s, 参数类型=%v, 参数=%v\n",
 		request.ServiceName, request.MethodName, request.ParamTypes, request.Params)
-	
-	// 模拟调用延迟
-	time.Sleep(100 * time.Millisecond)
-	
+
+	// 与ListServices一样，委托给真实的dubbo客户端执行
+	realClient, err := NewRealDubboClient(&DubboConfig{
+		Registry:       c.config.Registry,
+		Application:    c.config.Application,
+		Timeout:        request.Timeout,
+		Version:        request.Version,
+		Group:          request.Group,
+		Tag:            c.config.Tag,
+		Protocol:       c.config.Protocol,
+		Username:       c.config.Username,
+		Password:       c.config.Password,
+		Namespace:      c.config.Namespace,
+		MaxPayloadSize: c.config.MaxPayloadSize,
+		Attachments:    c.config.Attachments,
+		Charset:        c.config.Charset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建真实dubbo客户端失败: %v", err)
+	}
+	defer realClient.Close()
+
+	result, err := realClient.GenericInvokeContext(ctx, request.ServiceName, request.MethodName, request.ParamTypes, request.Params)
+
 	// 构建响应
 	response := &GenericInvokeResponse{
-		Success:   true,
-		Result: map[string]interface{}{
-			"message": "调用成功",
-			"data":    "模拟返回数据",
-			"service": request.ServiceName,
-			"method":  request.MethodName,
-			"params":  request.Params,
-		},
+		Success:   err == nil,
+		Result:    result,
 		Timestamp: time.Now().Unix(),
 		Duration:  time.Since(startTime).Milliseconds(),
+		Timings:   realClient.LastTimings(),
+		Provider:  realClient.LastProvider(),
+	}
+	if err != nil {
+		response.Error = err.Error()
 	}
-	
+
 	return response, nil
-}
\ No newline at end of file
+}
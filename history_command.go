@@ -0,0 +1,573 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// newHistoryCommand 创建history命令
+func newHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "查看和重新执行调用历史",
+		Long: `查看和重新执行调用历史，CLI和Web UI的调用记录在同一个文件中，
+在浏览器中发起的调用也可以在终端重新执行
+
+记录ID可以是完整ID、ID的唯一后缀(至少4位)，或 last 表示最近一条
+
+示例:
+  dubbo-invoke history list
+  dubbo-invoke history list -q getUserById --failed --since 24h
+  dubbo-invoke history show last
+  dubbo-invoke history rerun 1718000000000000000
+  dubbo-invoke history rerun last -r zookeeper://10.0.0.2:2181
+  dubbo-invoke history edit last
+  dubbo-invoke history export --service UserService -f history.json
+  dubbo-invoke history export --since 7d --format markdown -f report.md`,
+	}
+
+	cmd.AddCommand(newHistoryListCommand())
+	cmd.AddCommand(newHistoryShowCommand())
+	cmd.AddCommand(newHistoryRerunCommand())
+	cmd.AddCommand(newHistoryEditCommand())
+	cmd.AddCommand(newHistoryExportCommand())
+
+	return cmd
+}
+
+// addHistoryFilterFlags 添加查询条件参数
+func addHistoryFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("search", "q", "", "全文搜索服务、方法、参数和结果")
+	cmd.Flags().String("service", "", "按服务名过滤")
+	cmd.Flags().String("method", "", "按方法名过滤")
+	cmd.Flags().String("registry-filter", "", "按注册中心过滤")
+	cmd.Flags().Bool("success", false, "只显示成功的调用")
+	cmd.Flags().Bool("failed", false, "只显示失败的调用")
+	cmd.Flags().String("since", "", "起始时间，如 2024-05-01、2024-05-01 08:00:00 或 24h、7d")
+	cmd.Flags().String("until", "", "结束时间，格式同 --since")
+}
+
+// historyQueryFromFlags 根据命令行参数构建查询条件
+func historyQueryFromFlags(cmd *cobra.Command) (HistoryQuery, error) {
+	var query HistoryQuery
+	query.Text, _ = cmd.Flags().GetString("search")
+	query.Service, _ = cmd.Flags().GetString("service")
+	query.Method, _ = cmd.Flags().GetString("method")
+	query.Registry, _ = cmd.Flags().GetString("registry-filter")
+
+	onlySuccess, _ := cmd.Flags().GetBool("success")
+	onlyFailed, _ := cmd.Flags().GetBool("failed")
+	switch {
+	case onlySuccess && onlyFailed:
+		return query, fmt.Errorf("--success 和 --failed 不能同时使用")
+	case onlySuccess || onlyFailed:
+		query.Success = &onlySuccess
+	}
+
+	for name, target := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		if value, _ := cmd.Flags().GetString(name); value != "" {
+			t, err := parseHistoryTime(value)
+			if err != nil {
+				return query, err
+			}
+			*target = t
+		}
+	}
+	return query, nil
+}
+
+// openHistoryStore 打开默认位置的历史记录
+func openHistoryStore() (*HistoryStore, error) {
+	return OpenDefaultHistoryStore(HistoryRetention{MaxEntries: defaultHistoryMaxEntries, MaxAge: defaultHistoryMaxAge})
+}
+
+// findHistoryEntry 按完整ID、唯一后缀或 last 查找历史记录
+func findHistoryEntry(store *HistoryStore, ref string) (CallHistory, error) {
+	if ref == "last" {
+		page := store.Search(HistoryQuery{Limit: 1})
+		if len(page.Items) == 0 {
+			return CallHistory{}, fmt.Errorf("没有调用历史")
+		}
+		return page.Items[0], nil
+	}
+	if entry, ok := store.Get(ref); ok {
+		return entry, nil
+	}
+	if len(ref) < 4 {
+		return CallHistory{}, fmt.Errorf("历史记录不存在: %s", ref)
+	}
+
+	var matched []CallHistory
+	for _, entry := range store.Search(HistoryQuery{}).Items {
+		if strings.HasSuffix(entry.ID, ref) {
+			matched = append(matched, entry)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return CallHistory{}, fmt.Errorf("历史记录不存在: %s", ref)
+	case 1:
+		return matched[0], nil
+	default:
+		return CallHistory{}, fmt.Errorf("ID后缀 %s 匹配到 %d 条记录，请提供更长的ID", ref, len(matched))
+	}
+}
+
+// newHistoryListCommand 创建history list命令
+func newHistoryListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "列出调用历史(最新的在前)",
+		Args:  cobra.NoArgs,
+		RunE:  runHistoryListCommand,
+	}
+	addHistoryFilterFlags(cmd)
+	cmd.Flags().IntP("limit", "n", 20, "最多显示的条数，0表示全部")
+	cmd.Flags().Int("offset", 0, "跳过的条数")
+	cmd.Flags().StringP("output", "o", "table", "输出格式: table|json")
+	return cmd
+}
+
+// runHistoryListCommand 列出调用历史
+func runHistoryListCommand(cmd *cobra.Command, args []string) error {
+	query, err := historyQueryFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	query.Limit, _ = cmd.Flags().GetInt("limit")
+	query.Offset, _ = cmd.Flags().GetInt("offset")
+	output, _ := cmd.Flags().GetString("output")
+
+	store, err := openHistoryStore()
+	if err != nil {
+		return err
+	}
+	page := store.Search(query)
+
+	switch output {
+	case "json":
+		return writeHistoryJSON(os.Stdout, page)
+	case "table":
+	default:
+		return fmt.Errorf("不支持的输出格式: %s (可选: table, json)", output)
+	}
+
+	if page.Total == 0 {
+		color.Yellow("没有匹配的调用历史")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\t时间\t状态\t来源\t耗时\t调用\t参数")
+	for _, entry := range page.Items {
+		status := "成功"
+		if !entry.Success {
+			status = "失败"
+		}
+		params, _ := json.Marshal(entry.Parameters)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%dms\t%s.%s\t%s\n",
+			entry.ID, entry.Timestamp.Local().Format("2006-01-02 15:04:05"), status, firstNonEmpty(entry.Source, "web"),
+			entry.Duration, entry.ServiceName, entry.MethodName, truncateRunes(string(params), 60))
+	}
+	tw.Flush()
+
+	shown := page.Offset + len(page.Items)
+	if shown < page.Total {
+		color.Cyan("显示 %d-%d，共 %d 条，使用 --offset %d 查看更多", page.Offset+1, shown, page.Total, shown)
+	}
+	return nil
+}
+
+// newHistoryShowCommand 创建history show命令
+func newHistoryShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <id|last>",
+		Short: "显示调用历史详情",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runHistoryShowCommand,
+	}
+	cmd.Flags().StringP("output", "o", "text", "输出格式: text|json")
+	return cmd
+}
+
+// runHistoryShowCommand 显示调用历史详情
+func runHistoryShowCommand(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	store, err := openHistoryStore()
+	if err != nil {
+		return err
+	}
+	entry, err := findHistoryEntry(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case "json":
+		return writeHistoryJSON(os.Stdout, entry)
+	case "text":
+	default:
+		return fmt.Errorf("不支持的输出格式: %s (可选: text, json)", output)
+	}
+
+	params, _ := json.Marshal(entry.Parameters)
+	color.Cyan("ID:       %s", entry.ID)
+	color.Cyan("时间:     %s", entry.Timestamp.Local().Format("2006-01-02 15:04:05"))
+	color.Cyan("来源:     %s", firstNonEmpty(entry.Source, "web"))
+	color.Cyan("服务:     %s", entry.ServiceName)
+	color.Cyan("方法:     %s", entry.MethodName)
+	color.Cyan("参数:     %s", string(params))
+	if len(entry.Types) > 0 {
+		color.Cyan("参数类型: %s", strings.Join(entry.Types, ", "))
+	}
+	color.Cyan("注册中心: %s", entry.Registry)
+	if entry.Namespace != "" {
+		color.Cyan("命名空间: %s", entry.Namespace)
+	}
+	if entry.Version != "" || entry.Group != "" || entry.Tag != "" {
+		color.Cyan("过滤条件: 版本=%q 分组=%q 标签=%q", entry.Version, entry.Group, entry.Tag)
+	}
+	color.Cyan("耗时:     %dms", entry.Duration)
+	if entry.Timings != nil {
+		color.Cyan("耗时分解: %s", entry.Timings)
+	}
+	if len(entry.Assertions) > 0 {
+		printAssertionReport(entry.Assertions)
+	}
+
+	if !entry.Success {
+		color.Red("调用失败: %s", entry.Result)
+		return nil
+	}
+	color.Green("结果:")
+	if entry.ResultID != "" || entry.ResultTruncated {
+		color.Yellow("(结果较大，只保存了开头部分)")
+		fmt.Println(entry.Result)
+		return nil
+	}
+	return writeResult(os.Stdout, parseJSONText(entry.Result), OutputJSON)
+}
+
+// newHistoryRerunCommand 创建history rerun命令
+func newHistoryRerunCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rerun <id|last>",
+		Short: "按历史记录重新调用",
+		Long: `按历史记录中的服务、方法、参数和注册中心重新调用，结果写入新的历史记录
+通过 --registry、--app、--timeout 等参数可以改为调用其他环境`,
+		Args: cobra.ExactArgs(1),
+		RunE: runHistoryRerunCommand,
+	}
+	addHistoryRerunFlags(cmd)
+	return cmd
+}
+
+// newHistoryEditCommand 创建history edit命令
+func newHistoryEditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit <id|last>",
+		Short: "在编辑器中修改历史记录的参数后重新调用",
+		Long: `将历史记录的参数写入临时文件并用 $EDITOR 打开，保存退出后按修改后的参数重新调用
+未设置 $EDITOR 时使用 $VISUAL，仍未设置时在Windows上使用notepad，其他系统使用vi`,
+		Args: cobra.ExactArgs(1),
+		RunE: runHistoryEditCommand,
+	}
+	addHistoryRerunFlags(cmd)
+	return cmd
+}
+
+// addHistoryRerunFlags 添加重新调用的参数
+func addHistoryRerunFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "json", "结果输出格式: json|yaml|table|raw|csv")
+	cmd.Flags().StringP("query", "q", "", "按JSONPath/jq风格的路径过滤结果")
+	cmd.Flags().String("namespace", "", "覆盖命名空间")
+	cmd.Flags().Bool("no-history", false, "不将本次调用写入历史记录")
+}
+
+// runHistoryRerunCommand 按历史记录重新调用
+func runHistoryRerunCommand(cmd *cobra.Command, args []string) error {
+	store, err := openHistoryStore()
+	if err != nil {
+		return err
+	}
+	entry, err := findHistoryEntry(store, args[0])
+	if err != nil {
+		return err
+	}
+	return rerunHistoryEntry(cmd, entry, entry.Parameters)
+}
+
+// runHistoryEditCommand 编辑参数后重新调用
+func runHistoryEditCommand(cmd *cobra.Command, args []string) error {
+	store, err := openHistoryStore()
+	if err != nil {
+		return err
+	}
+	entry, err := findHistoryEntry(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	params, err := editParameters(entry)
+	if err != nil {
+		return err
+	}
+	return rerunHistoryEntry(cmd, entry, params)
+}
+
+// openInEditor 使用 $EDITOR 或 $VISUAL 编辑文件，未设置时Windows下使用记事本，其他系统使用vi
+func openInEditor(file string) error {
+	editor := firstNonEmpty(os.Getenv("EDITOR"), os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// EDITOR中可能带有参数，如 "code --wait"
+	editorArgs := strings.Fields(editor)
+	editorCmd := exec.Command(editorArgs[0], append(editorArgs[1:], file)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("运行编辑器 %s 失败: %v", editor, err)
+	}
+	return nil
+}
+
+// editParameters 在编辑器中修改参数，返回修改后的参数数组
+func editParameters(entry CallHistory) ([]interface{}, error) {
+	params := entry.Parameters
+	if params == nil {
+		params = []interface{}{}
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(params); err != nil {
+		return nil, fmt.Errorf("序列化参数失败: %v", err)
+	}
+
+	tmp, err := os.CreateTemp("", "dubbo-invoke-params-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buffer.Bytes()); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("写入临时文件失败: %v", err)
+	}
+	tmp.Close()
+
+	if err := openInEditor(tmp.Name()); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return nil, fmt.Errorf("读取修改后的参数失败: %v", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("参数为空，已取消调用")
+	}
+	edited, err := decodeCassetteParameters(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("参数必须是JSON数组: %v", err)
+	}
+	return edited, nil
+}
+
+// rerunHistoryEntry 按历史记录的调用配置重新调用，只覆盖显式指定的全局参数
+func rerunHistoryEntry(cmd *cobra.Command, entry CallHistory, params []interface{}) error {
+	config := &DubboConfig{
+		Registry:       entry.Registry,
+		Application:    firstNonEmpty(entry.App, "dubbo-invoke-client"),
+		Namespace:      entry.Namespace,
+		Version:        entry.Version,
+		Group:          entry.Group,
+		Tag:            entry.Tag,
+		MaxPayloadSize: maxPayloadBytes(cmd),
+	}
+	// 使用记录调用时的环境补充认证信息和隐式参数，显式指定 --profile 时使用指定的环境
+	profile := activeProfile(cmd)
+	if entry.Profile != "" && (profile == nil || !profile.Explicit) {
+		configPath, _ := cmd.Flags().GetString("config")
+		entryProfile, err := LoadActiveProfile(configPath, entry.Profile)
+		if err != nil {
+			return fmt.Errorf("加载历史记录使用的环境 %s 失败: %v", entry.Profile, err)
+		}
+		profile = entryProfile
+	}
+	profile.Apply(config)
+	timeout := entry.Timeout
+	if cmd.Flags().Changed("registry") {
+		config.Registry, _ = cmd.Flags().GetString("registry")
+	}
+	if cmd.Flags().Changed("app") {
+		config.Application, _ = cmd.Flags().GetString("app")
+	}
+	if cmd.Flags().Changed("timeout") || timeout <= 0 {
+		timeout, _ = cmd.Flags().GetInt("timeout")
+	}
+	if cmd.Flags().Changed("namespace") {
+		config.Namespace, _ = cmd.Flags().GetString("namespace")
+	}
+	config.Timeout = time.Duration(timeout) * time.Millisecond
+	if config.Registry == "" {
+		config.Registry, _ = cmd.Flags().GetString("registry")
+	}
+
+	banner := fmt.Sprintf("重新调用 %s.%s (历史记录 %s)", entry.ServiceName, entry.MethodName, entry.ID)
+	return invokeAndReport(cmd, config, profile, banner, entry.ServiceName, entry.MethodName, entry.Types, params, entry.Expect)
+}
+
+// invokeAndReport 执行一次调用、写入历史记录并按 --output、--query 输出结果，带有断言时输出断言报告
+// 供 history rerun/edit 和 run 共用，cmd 需要有 addHistoryRerunFlags 添加的参数
+func invokeAndReport(cmd *cobra.Command, config *DubboConfig, profile *ActiveProfile, banner, serviceName, methodName string, types []string, params []interface{}, expects []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	query, _ := cmd.Flags().GetString("query")
+	noHistory, _ := cmd.Flags().GetBool("no-history")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	if !validOutputFormat(output) {
+		return fmt.Errorf("不支持的输出格式: %s (可选: json, yaml, table, raw, csv)", output)
+	}
+
+	// 调用过程日志写到标准错误，标准输出只保留结果
+	resultOut, restore := redirectLogsToStderr()
+	defer restore()
+	color.Cyan(banner)
+	if !verbose {
+		var restoreLogs func()
+		var err error
+		_, restoreLogs, err = discardLogs()
+		if err != nil {
+			return err
+		}
+		defer restoreLogs()
+	}
+
+	client, err := NewDubboClient(config)
+	if err != nil {
+		return fmt.Errorf("创建Dubbo客户端失败: %v", err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	result, err := client.GenericInvokeContext(cmd.Context(), serviceName, methodName, types, params)
+	duration := time.Since(start).Milliseconds()
+
+	var assertions []AssertionResult
+	if err == nil && len(expects) > 0 {
+		assertions = EvaluateAssertions(expects, AssertionContext{Result: decodeResult(result), Duration: duration})
+	}
+	if !noHistory {
+		saveCLIHistory(config, profileName(profile), serviceName, methodName, types, params, result, err, duration, expects, assertions)
+	}
+	if err != nil {
+		return fmt.Errorf("调用失败: %v", err)
+	}
+
+	processedResult := decodeResult(result)
+	if query != "" {
+		processedResult, err = queryResult(processedResult, query)
+		if err != nil {
+			return err
+		}
+	} else if output == OutputRaw {
+		processedResult = result
+	}
+	if err := writeResult(resultOut, processedResult, output); err != nil {
+		return err
+	}
+
+	if len(assertions) > 0 {
+		printAssertionReport(assertions)
+		if failed := AssertionsFailed(assertions); failed > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d/%d 个断言失败", failed, len(assertions))
+		}
+	}
+	return nil
+}
+
+// newHistoryExportCommand 创建history export命令
+func newHistoryExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "导出调用历史",
+		Args:  cobra.NoArgs,
+		RunE:  runHistoryExportCommand,
+	}
+	addHistoryFilterFlags(cmd)
+	cmd.Flags().String("format", "json", "导出格式: json|jsonl|har|markdown|csv")
+	cmd.Flags().StringP("file", "f", "", "输出文件，默认输出到标准输出")
+	return cmd
+}
+
+// runHistoryExportCommand 导出调用历史
+func runHistoryExportCommand(cmd *cobra.Command, args []string) error {
+	query, err := historyQueryFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	file, _ := cmd.Flags().GetString("file")
+	if format, err = normalizeHistoryExportFormat(format); err != nil {
+		return err
+	}
+
+	store, err := openHistoryStore()
+	if err != nil {
+		return err
+	}
+	entries := store.Search(query).Items
+
+	var out io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("创建导出文件失败: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := WriteHistoryExport(out, entries, format); err != nil {
+		return fmt.Errorf("导出调用历史失败: %v", err)
+	}
+
+	if file != "" {
+		color.Green("已导出 %d 条调用历史到 %s", len(entries), file)
+	}
+	return nil
+}
+
+// writeHistoryJSON 以缩进的JSON格式输出
+func writeHistoryJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// truncateRunes 截断过长的文本
+func truncateRunes(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "..."
+}
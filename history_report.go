@@ -0,0 +1,454 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// 调用历史导出格式
+const (
+	HistoryExportJSON     = "json"
+	HistoryExportJSONL    = "jsonl"
+	HistoryExportHAR      = "har"
+	HistoryExportMarkdown = "markdown"
+	HistoryExportCSV      = "csv"
+)
+
+// historyReportResultLimit Markdown报告中每条结果最多保留的字符数
+const historyReportResultLimit = 4000
+
+// historyExportFormats 支持的导出格式及对应的Content-Type和文件扩展名
+var historyExportFormats = map[string]struct {
+	contentType string
+	extension   string
+}{
+	HistoryExportJSON:     {"application/json; charset=utf-8", "json"},
+	HistoryExportJSONL:    {"application/x-ndjson; charset=utf-8", "jsonl"},
+	HistoryExportHAR:      {"application/json; charset=utf-8", "har"},
+	HistoryExportMarkdown: {"text/markdown; charset=utf-8", "md"},
+	HistoryExportCSV:      {"text/csv; charset=utf-8", "csv"},
+}
+
+// normalizeHistoryExportFormat 校验导出格式，md 视为 markdown
+func normalizeHistoryExportFormat(format string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "md" {
+		format = HistoryExportMarkdown
+	}
+	if _, ok := historyExportFormats[format]; !ok {
+		return "", fmt.Errorf("不支持的导出格式: %s (可选: json, jsonl, har, markdown, csv)", format)
+	}
+	return format, nil
+}
+
+// WriteHistoryExport 按指定格式导出调用历史，entries按时间倒序
+func WriteHistoryExport(w io.Writer, entries []CallHistory, format string) error {
+	format, err := normalizeHistoryExportFormat(format)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case HistoryExportJSONL:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	case HistoryExportHAR:
+		return writeHistoryJSON(w, historyHAR(entries))
+	case HistoryExportMarkdown:
+		return writeHistoryMarkdown(w, entries)
+	case HistoryExportCSV:
+		return writeHistoryCSV(w, entries)
+	default:
+		if entries == nil {
+			entries = []CallHistory{}
+		}
+		return writeHistoryJSON(w, entries)
+	}
+}
+
+// HAR 1.2 格式，每次调用对应一个entry，请求URL形如 dubbo://<service>/<method>?registry=...
+type harLog struct {
+	Log harContent `json:"log"`
+}
+
+type harContent struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+
+	// 以下划线开头的自定义字段，保留无法映射到HTTP的调用信息
+	DubboTypes      []string          `json:"_types,omitempty"`
+	DubboAssertions []AssertionResult `json:"_assertions,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    harPostData    `json:"postData"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harBody        `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harBody struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// harTimings 阶段耗时：dns对应连接注册中心和查找提供者，connect对应获取连接，receive对应读取和解析响应
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// historyHAR 将调用历史转换为HAR
+func historyHAR(entries []CallHistory) harLog {
+	har := harLog{Log: harContent{
+		Version: "1.2",
+		Creator: harCreator{Name: "dubbo-invoke", Version: version},
+		Entries: make([]harEntry, 0, len(entries)),
+	}}
+
+	for _, entry := range entries {
+		query := url.Values{}
+		queryString := []harNameValue{}
+		for _, param := range []harNameValue{
+			{"registry", entry.Registry},
+			{"namespace", entry.Namespace},
+			{"version", entry.Version},
+			{"group", entry.Group},
+			{"tag", entry.Tag},
+		} {
+			if param.Value != "" {
+				query.Set(param.Name, param.Value)
+				queryString = append(queryString, param)
+			}
+		}
+		requestURL := url.URL{Scheme: "dubbo", Host: entry.ServiceName, Path: "/" + entry.MethodName, RawQuery: query.Encode()}
+
+		params := entry.Parameters
+		if params == nil {
+			params = []interface{}{}
+		}
+		paramsText := cellText(params)
+
+		status, statusText := 200, "OK"
+		if !entry.Success {
+			status, statusText = 500, "Invoke Failed"
+		}
+		mimeType := "text/plain"
+		if json.Valid([]byte(entry.Result)) {
+			mimeType = "application/json"
+		}
+
+		timings := harTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: float64(entry.Duration)}
+		if t := entry.Timings; t != nil {
+			timings = harTimings{
+				Blocked: -1,
+				DNS:     round3(t.Connect + t.Lookup),
+				Connect: t.Dial,
+				SSL:     -1,
+				Send:    t.Send,
+				Wait:    t.Wait,
+				Receive: round3(t.Read + t.Parse),
+			}
+		}
+		total := float64(entry.Duration)
+		if entry.Timings != nil {
+			total = entry.Timings.Total
+		}
+
+		comment := fmt.Sprintf("id=%s source=%s", entry.ID, firstNonEmpty(entry.Source, "web"))
+		if entry.ResultTruncated || entry.ResultID != "" {
+			comment += " 结果较大，只保存了开头部分"
+		}
+
+		har.Log.Entries = append(har.Log.Entries, harEntry{
+			StartedDateTime: entry.Timestamp.Format(time.RFC3339Nano),
+			Time:            total,
+			Request: harRequest{
+				Method:      "INVOKE",
+				URL:         requestURL.String(),
+				HTTPVersion: "dubbo",
+				Cookies:     []harNameValue{},
+				Headers:     []harNameValue{},
+				QueryString: queryString,
+				PostData:    harPostData{MimeType: "application/json", Text: paramsText},
+				HeadersSize: -1,
+				BodySize:    len(paramsText),
+			},
+			Response: harResponse{
+				Status:      status,
+				StatusText:  statusText,
+				HTTPVersion: "dubbo",
+				Cookies:     []harNameValue{},
+				Headers:     []harNameValue{},
+				Content:     harBody{Size: len(entry.Result), MimeType: mimeType, Text: entry.Result},
+				HeadersSize: -1,
+				BodySize:    len(entry.Result),
+			},
+			Timings:         timings,
+			Comment:         comment,
+			DubboTypes:      entry.Types,
+			DubboAssertions: entry.Assertions,
+		})
+	}
+	return har
+}
+
+// writeHistoryMarkdown 输出Markdown报告：汇总表格和每次调用的参数、结果
+func writeHistoryMarkdown(w io.Writer, entries []CallHistory) error {
+	var buffer bytes.Buffer
+	succeeded := 0
+	for _, entry := range entries {
+		if entry.Success {
+			succeeded++
+		}
+	}
+
+	buffer.WriteString("# Dubbo调用历史\n\n")
+	fmt.Fprintf(&buffer, "导出时间: %s，共 %d 条，成功 %d，失败 %d\n\n",
+		time.Now().Format("2006-01-02 15:04:05"), len(entries), succeeded, len(entries)-succeeded)
+	if len(entries) == 0 {
+		_, err := w.Write(buffer.Bytes())
+		return err
+	}
+
+	buffer.WriteString("| # | 时间 | 调用 | 注册中心 | 状态 | 耗时 |\n")
+	buffer.WriteString("|---|------|------|----------|------|------|\n")
+	for i, entry := range entries {
+		fmt.Fprintf(&buffer, "| %d | %s | `%s.%s` | %s | %s | %dms |\n",
+			i+1, entry.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			markdownCell(entry.ServiceName), markdownCell(entry.MethodName), markdownCell(entry.Registry),
+			historyStatusText(entry), entry.Duration)
+	}
+
+	for i, entry := range entries {
+		fmt.Fprintf(&buffer, "\n## %d. %s.%s\n\n", i+1, entry.ServiceName, entry.MethodName)
+		fmt.Fprintf(&buffer, "- ID: `%s`\n", entry.ID)
+		fmt.Fprintf(&buffer, "- 时间: %s\n", entry.Timestamp.Local().Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(&buffer, "- 来源: %s\n", firstNonEmpty(entry.Source, "web"))
+		fmt.Fprintf(&buffer, "- 注册中心: %s\n", entry.Registry)
+		if entry.Namespace != "" {
+			fmt.Fprintf(&buffer, "- 命名空间: %s\n", entry.Namespace)
+		}
+		if entry.Version != "" || entry.Group != "" || entry.Tag != "" {
+			fmt.Fprintf(&buffer, "- 过滤条件: 版本=%q 分组=%q 标签=%q\n", entry.Version, entry.Group, entry.Tag)
+		}
+		if len(entry.Types) > 0 {
+			fmt.Fprintf(&buffer, "- 参数类型: %s\n", strings.Join(entry.Types, ", "))
+		}
+		fmt.Fprintf(&buffer, "- 状态: %s\n", historyStatusText(entry))
+		fmt.Fprintf(&buffer, "- 耗时: %dms\n", entry.Duration)
+		if len(entry.Assertions) > 0 {
+			buffer.WriteString("- 断言:\n")
+			for _, assertion := range entry.Assertions {
+				if assertion.Passed {
+					fmt.Fprintf(&buffer, "  - ✔ `%s`\n", assertion.Expression)
+				} else {
+					fmt.Fprintf(&buffer, "  - ✘ `%s`: %s\n", assertion.Expression, assertion.Message)
+				}
+			}
+		}
+
+		params := entry.Parameters
+		if params == nil {
+			params = []interface{}{}
+		}
+		paramsText, _ := json.MarshalIndent(params, "", "  ")
+		buffer.WriteString("\n参数:\n\n")
+		writeMarkdownCode(&buffer, string(paramsText), "json")
+
+		// json.Indent不改变数字的写法，大整数不会丢失精度
+		result, lang := entry.Result, ""
+		var indented bytes.Buffer
+		if json.Indent(&indented, []byte(result), "", "  ") == nil {
+			result, lang = indented.String(), "json"
+		}
+		if len([]rune(result)) > historyReportResultLimit {
+			result = truncateRunes(result, historyReportResultLimit)
+		}
+		if entry.Success {
+			buffer.WriteString("\n结果:\n\n")
+		} else {
+			buffer.WriteString("\n错误:\n\n")
+		}
+		writeMarkdownCode(&buffer, result, lang)
+	}
+
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// writeHistoryCSV 每次调用一行，参数和结果为紧凑的JSON文本
+func writeHistoryCSV(w io.Writer, entries []CallHistory) error {
+	writer := csv.NewWriter(w)
+	header := []string{"id", "timestamp", "source", "service", "method", "registry", "namespace", "version", "group", "tag",
+		"success", "duration", "types", "parameters", "result", "assertions"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		params := entry.Parameters
+		if params == nil {
+			params = []interface{}{}
+		}
+		assertions := ""
+		if len(entry.Assertions) > 0 {
+			assertions = fmt.Sprintf("%d/%d", len(entry.Assertions)-AssertionsFailed(entry.Assertions), len(entry.Assertions))
+		}
+		row := []string{
+			entry.ID,
+			entry.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			firstNonEmpty(entry.Source, "web"),
+			entry.ServiceName,
+			entry.MethodName,
+			entry.Registry,
+			entry.Namespace,
+			entry.Version,
+			entry.Group,
+			entry.Tag,
+			strconv.FormatBool(entry.Success),
+			strconv.FormatInt(entry.Duration, 10),
+			strings.Join(entry.Types, ","),
+			cellText(params),
+			entry.Result,
+			assertions,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("CSV输出失败: %v", err)
+	}
+	return nil
+}
+
+// historyStatusText 调用状态的文字描述
+func historyStatusText(entry CallHistory) string {
+	if !entry.Success {
+		return "✘ 失败"
+	}
+	if failed := AssertionsFailed(entry.Assertions); failed > 0 {
+		return fmt.Sprintf("✘ 断言失败 %d/%d", failed, len(entry.Assertions))
+	}
+	return "✔ 成功"
+}
+
+// markdownCell 转义Markdown表格单元格中的竖线和换行
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+// writeMarkdownCode 输出代码块，内容中含有 ``` 时使用更长的围栏
+func writeMarkdownCode(buffer *bytes.Buffer, text, lang string) {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(buffer, "%s%s\n%s\n%s\n", fence, lang, strings.TrimRight(text, "\n"), fence)
+}
+
+// handleHistoryExport 导出调用历史: GET /api/history/export?format=har|markdown|csv|json|jsonl
+// 过滤条件与 /api/history 相同，未指定limit时导出全部匹配的记录
+func (ws *WebServer) handleHistoryExport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != "GET" {
+		w.Header().Set("Content-Type", "application/json")
+		ws.writeError(w, "只支持GET方法")
+		return
+	}
+
+	values := r.URL.Query()
+	if values.Get("limit") == "" {
+		values.Set("limit", "0")
+	}
+	query, err := parseHistoryQuery(values)
+	var format string
+	if err == nil {
+		format, err = normalizeHistoryExportFormat(firstNonEmpty(values.Get("format"), HistoryExportJSON))
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		ws.writeError(w, err.Error())
+		return
+	}
+
+	entries := ws.history.Search(query).Items
+	spec := historyExportFormats[format]
+	filename := fmt.Sprintf("dubbo-invoke-history-%s.%s", time.Now().Format("2006-01-02T15-04-05"), spec.extension)
+	w.Header().Set("Content-Type", spec.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := WriteHistoryExport(w, entries, format); err != nil {
+		color.Red("[WEB] 导出调用历史失败: %v", err)
+		return
+	}
+	color.Green("[WEB] 已导出 %d 条调用历史 (%s)", len(entries), format)
+}
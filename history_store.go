@@ -0,0 +1,712 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// 历史记录默认保留策略
+const (
+	defaultHistoryMaxEntries = 1000
+	defaultHistoryMaxAge     = 30 * 24 * time.Hour
+	historyResultLimit       = 1024 * 1024      // 单条记录保存的结果上限，超过时只保存开头部分
+	historyLockTimeout       = 5 * time.Second  // 等待其他进程释放历史记录锁的最长时间
+	historyLockStale         = 10 * time.Second // 锁文件超过该时间未更新时，视为持有锁的进程已退出
+	historyLockRefresh       = 2 * time.Second  // 持有锁期间更新锁文件修改时间的间隔，须远小于historyLockStale
+)
+
+// HistoryRetention 历史记录保留策略，为0的项不限制
+type HistoryRetention struct {
+	MaxEntries int
+	MaxAge     time.Duration
+}
+
+// HistoryQuery 历史记录查询条件，为空的条件不参与过滤
+type HistoryQuery struct {
+	Text     string    // 全文搜索：服务、方法、参数、结果、注册中心
+	Service  string    // 服务名，包含匹配，不区分大小写
+	Method   string    // 方法名，包含匹配，不区分大小写
+	Registry string    // 注册中心，包含匹配
+	Success  *bool     // 调用是否成功
+	Since    time.Time // 起始时间(含)
+	Until    time.Time // 结束时间(不含)
+	Offset   int
+	Limit    int
+}
+
+// HistoryPage 分页查询结果，按时间倒序
+type HistoryPage struct {
+	Total  int           `json:"total"`
+	Offset int           `json:"offset"`
+	Limit  int           `json:"limit"`
+	Items  []CallHistory `json:"items"`
+}
+
+// HistoryStore 基于JSON Lines文件的调用历史存储，CLI和Web端共用
+// 写入时追加一行，其他进程写入的记录在下次读取前自动加载；追加和整理文件时持有锁文件，避免整理时丢失其他进程追加的记录
+type HistoryStore struct {
+	path      string
+	retention HistoryRetention
+	mu        sync.Mutex
+	entries   []CallHistory // 按写入顺序
+	size      int64         // 已加载的文件大小
+	file      os.FileInfo   // 已加载的文件，用于判断文件是否被其他进程整理替换；为nil时尚未加载，首次读取时加载
+}
+
+// dubboInvokeHome 本地数据目录，默认为 ~/.dubbo-invoke，可通过环境变量 DUBBO_INVOKE_HOME 指定
+func dubboInvokeHome() (string, error) {
+	if dir := os.Getenv("DUBBO_INVOKE_HOME"); dir != "" {
+		return dir, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户目录失败: %v", err)
+	}
+	return filepath.Join(homeDir, ".dubbo-invoke"), nil
+}
+
+// defaultHistoryPath 默认的历史记录文件路径
+func defaultHistoryPath() (string, error) {
+	dir, err := dubboInvokeHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// OpenDefaultHistoryStore 打开默认位置的历史记录
+func OpenDefaultHistoryStore(retention HistoryRetention) (*HistoryStore, error) {
+	path, err := defaultHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+	return OpenHistoryStore(path, retention)
+}
+
+// AppendHistory 只追加一条记录，不加载已有的记录，供每次调用只写入一条记录的CLI使用
+// 记录数超过保留上限时才读取整个文件并整理
+func AppendHistory(path string, retention HistoryRetention, entry CallHistory) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建历史记录目录失败: %v", err)
+	}
+	store := &HistoryStore{path: path, retention: retention}
+	return store.Add(entry)
+}
+
+// OpenHistoryStore 打开历史记录文件，不存在时创建，并按保留策略清理过期记录
+func OpenHistoryStore(path string, retention HistoryRetention) (*HistoryStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建历史记录目录失败: %v", err)
+	}
+
+	store := &HistoryStore{path: path, retention: retention}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.reload(); err != nil {
+		return nil, err
+	}
+	if store.expire() {
+		unlock, err := store.lockFile()
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+
+		// 加锁前其他进程可能已追加记录
+		if err := store.refresh(); err != nil {
+			return nil, err
+		}
+		store.expire()
+		if err := store.compact(); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// Path 返回历史记录文件路径
+func (s *HistoryStore) Path() string {
+	return s.path
+}
+
+// Add 追加一条历史记录
+func (s *HistoryStore) Add(entry CallHistory) error {
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if len(entry.Result) > historyResultLimit {
+		entry.Result = previewBytes([]byte(entry.Result), historyResultLimit)
+		entry.ResultTruncated = true
+	}
+
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(entry); err != nil {
+		return fmt.Errorf("序列化历史记录失败: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lockFile()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// 单次追加写入一整行，记录中可能有参数和结果中的敏感数据，只允许当前用户读写
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("打开历史记录文件失败: %v", err)
+	}
+	if _, err := file.Write(line.Bytes()); err != nil {
+		file.Close()
+		return fmt.Errorf("写入历史记录失败: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入历史记录失败: %v", err)
+	}
+
+	// 已加载时只读取新增的部分，同时读入刚写入的记录和其他进程并发追加的记录；未加载时只统计行数，不解析记录
+	count := 0
+	if s.file != nil {
+		if err := s.refresh(); err != nil {
+			return err
+		}
+		count = len(s.entries)
+	} else if s.retention.MaxEntries > 0 {
+		if count, err = countHistoryLines(s.path); err != nil {
+			return err
+		}
+	}
+
+	// 超出上限10%后再整理文件，避免每次写入都重写
+	if s.retention.MaxEntries > 0 && count > s.retention.MaxEntries+s.retention.MaxEntries/10 {
+		if err := s.refresh(); err != nil {
+			return err
+		}
+		s.expire()
+		return s.compact()
+	}
+	return nil
+}
+
+// countHistoryLines 统计历史记录文件的行数
+func countHistoryLines(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("读取历史记录文件失败: %v", err)
+	}
+	defer file.Close()
+
+	count := 0
+	buffer := make([]byte, 64*1024)
+	for {
+		n, err := file.Read(buffer)
+		count += bytes.Count(buffer[:n], []byte{'\n'})
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("读取历史记录文件失败: %v", err)
+		}
+	}
+}
+
+// Get 按ID获取历史记录
+func (s *HistoryStore) Get(id string) (CallHistory, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshQuietly()
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if s.entries[i].ID == id {
+			return s.entries[i], true
+		}
+	}
+	return CallHistory{}, false
+}
+
+// Search 按条件分页查询历史记录，最新的记录在前
+func (s *HistoryStore) Search(query HistoryQuery) HistoryPage {
+	s.mu.Lock()
+	s.refreshQuietly()
+	entries := append([]CallHistory(nil), s.entries...)
+	s.mu.Unlock()
+
+	matched := make([]CallHistory, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		if query.matches(entries[i]) {
+			matched = append(matched, entries[i])
+		}
+	}
+	// 写入顺序与时间顺序通常一致，排序保证多个进程交错写入时也按时间倒序
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Timestamp.After(matched[j].Timestamp)
+	})
+
+	page := HistoryPage{Total: len(matched), Offset: query.Offset, Limit: query.Limit}
+	if page.Offset < 0 {
+		page.Offset = 0
+	}
+	if page.Offset > len(matched) {
+		page.Offset = len(matched)
+	}
+	end := len(matched)
+	if page.Limit > 0 && page.Offset+page.Limit < end {
+		end = page.Offset + page.Limit
+	}
+	page.Items = matched[page.Offset:end]
+	return page
+}
+
+// Count 返回历史记录总数
+func (s *HistoryStore) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshQuietly()
+	return len(s.entries)
+}
+
+// Clear 清空历史记录
+func (s *HistoryStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lockFile()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s.entries = nil
+	return s.compact()
+}
+
+// matches 判断记录是否满足查询条件
+func (q HistoryQuery) matches(entry CallHistory) bool {
+	if q.Service != "" && !containsFold(entry.ServiceName, q.Service) {
+		return false
+	}
+	if q.Method != "" && !containsFold(entry.MethodName, q.Method) {
+		return false
+	}
+	if q.Registry != "" && !containsFold(entry.Registry, q.Registry) {
+		return false
+	}
+	if q.Success != nil && entry.Success != *q.Success {
+		return false
+	}
+	if !q.Since.IsZero() && entry.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !entry.Timestamp.Before(q.Until) {
+		return false
+	}
+	if q.Text != "" {
+		params, _ := json.Marshal(entry.Parameters)
+		fields := []string{entry.ServiceName, entry.MethodName, entry.Registry, entry.Namespace, string(params), entry.Result}
+		found := false
+		for _, field := range fields {
+			if containsFold(field, q.Text) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold 不区分大小写的包含判断
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// refreshQuietly 加载其他进程写入的记录，失败时沿用内存中的数据
+func (s *HistoryStore) refreshQuietly() {
+	if err := s.refresh(); err != nil {
+		color.Yellow("警告: 加载历史记录失败: %v", err)
+	}
+}
+
+// refresh 文件增长时只读取新增部分，文件被其他进程整理替换或清空过时重新加载
+func (s *HistoryStore) refresh() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		s.entries = nil
+		s.size = 0
+		s.file = nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取历史记录文件失败: %v", err)
+	}
+	defer file.Close()
+
+	// 从已打开的文件获取信息，避免判断后文件又被替换
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("读取历史记录文件失败: %v", err)
+	}
+	if s.file == nil || !os.SameFile(s.file, info) || info.Size() < s.size {
+		return s.load(file, info)
+	}
+	if info.Size() == s.size {
+		return nil
+	}
+
+	if _, err := file.Seek(s.size, io.SeekStart); err != nil {
+		return s.load(file, info)
+	}
+	entries, read, err := readHistoryLines(file)
+	if err != nil {
+		return err
+	}
+	s.entries = append(s.entries, entries...)
+	s.size += read
+	s.file = info
+	return nil
+}
+
+// reload 重新读取整个文件
+func (s *HistoryStore) reload() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		s.entries = nil
+		s.size = 0
+		s.file = nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取历史记录文件失败: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("读取历史记录文件失败: %v", err)
+	}
+	return s.load(file, info)
+}
+
+// load 从头读取已打开的文件
+func (s *HistoryStore) load(file *os.File, info os.FileInfo) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("读取历史记录文件失败: %v", err)
+	}
+	entries, read, err := readHistoryLines(file)
+	if err != nil {
+		return err
+	}
+	s.entries = entries
+	s.size = read
+	s.file = info
+	return nil
+}
+
+// lockFile 创建锁文件，阻止其他进程同时追加或整理历史记录，返回释放锁的函数
+// 持有锁期间定期更新锁文件的修改时间；持有锁的进程异常退出时锁文件会残留，超过historyLockStale未更新后视为已释放
+func (s *HistoryStore) lockFile() (func(), error) {
+	lockPath := s.path + ".lock"
+	deadline := time.Now().Add(historyLockTimeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(file, "%d\n", os.Getpid())
+			file.Close()
+			return holdLockFile(lockPath), nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("锁定历史记录文件失败: %v", err)
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > historyLockStale {
+			removeStaleLock(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("等待其他进程释放历史记录锁超时，如没有正在运行的dubbo-invoke，请删除 %s", lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// holdLockFile 持有锁期间定期更新锁文件的修改时间，避免耗时较长的整理被其他进程当作残留的锁删除，返回释放锁的函数
+func holdLockFile(lockPath string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(historyLockRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				now := time.Now()
+				os.Chtimes(lockPath, now, now)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		os.Remove(lockPath)
+	}
+}
+
+// removeStaleLock 删除残留的锁文件
+// 先重命名为当前进程独有的文件名再确认是否仍未更新，多个进程同时清理时不会删除其他进程刚创建的锁
+func removeStaleLock(lockPath string) {
+	claimed := fmt.Sprintf("%s.%d.stale", lockPath, os.Getpid())
+	if err := os.Rename(lockPath, claimed); err != nil {
+		return
+	}
+	if info, err := os.Stat(claimed); err == nil && time.Since(info.ModTime()) <= historyLockStale {
+		// 其他进程已清理并重新加锁，放回它的锁；Link在目标已存在时失败，不会覆盖第三个进程的锁
+		os.Link(claimed, lockPath)
+	}
+	os.Remove(claimed)
+}
+
+// readHistoryLines 读取完整的行，返回已读取的字节数；末尾未写完的行留到下次读取，无法解析的行跳过
+func readHistoryLines(r io.Reader) ([]CallHistory, int64, error) {
+	var entries []CallHistory
+	var read int64
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("读取历史记录文件失败: %v", err)
+		}
+		read += int64(len(line))
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var entry CallHistory
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, read, nil
+}
+
+// expire 按保留策略删除内存中的过期记录，返回是否有记录被删除
+func (s *HistoryStore) expire() bool {
+	count := len(s.entries)
+	if s.retention.MaxAge > 0 {
+		cutoff := time.Now().Add(-s.retention.MaxAge)
+		kept := s.entries[:0]
+		for _, entry := range s.entries {
+			if !entry.Timestamp.Before(cutoff) {
+				kept = append(kept, entry)
+			}
+		}
+		s.entries = kept
+	}
+	if s.retention.MaxEntries > 0 && len(s.entries) > s.retention.MaxEntries {
+		s.entries = append([]CallHistory(nil), s.entries[len(s.entries)-s.retention.MaxEntries:]...)
+	}
+	return len(s.entries) != count
+}
+
+// compact 将内存中的记录重写到文件，先写临时文件再重命名；调用方需持有锁文件
+func (s *HistoryStore) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".history-*.jsonl")
+	if err != nil {
+		return fmt.Errorf("整理历史记录失败: %v", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	for _, entry := range s.entries {
+		if err := encoder.Encode(entry); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return fmt.Errorf("整理历史记录失败: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("整理历史记录失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("整理历史记录失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("整理历史记录失败: %v", err)
+	}
+
+	s.updateStat()
+	return nil
+}
+
+// updateStat 记录整理后的文件，用于判断其他进程是否写入或替换
+func (s *HistoryStore) updateStat() {
+	if info, err := os.Stat(s.path); err == nil {
+		s.size = info.Size()
+		s.file = info
+	}
+}
+
+// parseHistoryQuery 解析查询参数: q、service、method、registry、success、since、until、offset、limit
+func parseHistoryQuery(values url.Values) (HistoryQuery, error) {
+	query := HistoryQuery{
+		Text:     values.Get("q"),
+		Service:  values.Get("service"),
+		Method:   values.Get("method"),
+		Registry: values.Get("registry"),
+		Limit:    50,
+	}
+
+	if value := values.Get("success"); value != "" {
+		success, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, fmt.Errorf("无效的success参数: %s", value)
+		}
+		query.Success = &success
+	}
+	for name, target := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		if value := values.Get(name); value != "" {
+			t, err := parseHistoryTime(value)
+			if err != nil {
+				return query, err
+			}
+			*target = t
+		}
+	}
+	for name, target := range map[string]*int{"offset": &query.Offset, "limit": &query.Limit} {
+		if value := values.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return query, fmt.Errorf("无效的%s参数: %s", name, value)
+			}
+			*target = n
+		}
+	}
+	return query, nil
+}
+
+// parseHistoryTime 解析时间条件，支持RFC3339、日期、日期时间，以及 30m、24h、7d 这样的相对时间(表示多久以前)
+func parseHistoryTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil {
+			return time.Now().Add(-time.Duration(days) * 24 * time.Hour), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的时间: %s (支持 2006-01-02、2006-01-02 15:04:05、RFC3339 或 24h、7d 等相对时间)", value)
+}
+
+// invokeRequestFromHistory 根据历史记录构建调用请求
+func invokeRequestFromHistory(entry CallHistory) (InvokeRequest, error) {
+	params := entry.Parameters
+	if params == nil {
+		params = []interface{}{}
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return InvokeRequest{}, fmt.Errorf("序列化历史参数失败: %v", err)
+	}
+	return InvokeRequest{
+		ServiceName: entry.ServiceName,
+		MethodName:  entry.MethodName,
+		Parameters:  data,
+		Types:       entry.Types,
+		Registry:    entry.Registry,
+		App:         entry.App,
+		Timeout:     entry.Timeout,
+		Group:       entry.Group,
+		Version:     entry.Version,
+		Tag:         entry.Tag,
+		Namespace:   entry.Namespace,
+		Expect:      entry.Expect,
+		Profile:     entry.Profile,
+	}, nil
+}
+
+// saveCLIHistory 将CLI的调用写入历史记录，结果与Web端一样按safeCopyValue处理后保存为JSON文本
+// profile 为调用时使用的环境名称，重新调用时据此补充认证信息和隐式参数
+func saveCLIHistory(config *DubboConfig, profile string, serviceName, methodName string, types []string, params []interface{}, result interface{}, invokeErr error, duration int64, expects []string, assertions []AssertionResult) {
+	path, err := defaultHistoryPath()
+	if err != nil {
+		color.Yellow("警告: 打开历史记录失败: %v", err)
+		return
+	}
+
+	entry := CallHistory{
+		ServiceName: serviceName,
+		MethodName:  methodName,
+		Parameters:  safeCopyParameters(params),
+		Types:       types,
+		Registry:    config.Registry,
+		App:         config.Application,
+		Success:     invokeErr == nil,
+		Duration:    duration,
+		Namespace:   config.Namespace,
+		Version:     config.Version,
+		Group:       config.Group,
+		Tag:         config.Tag,
+		Timeout:     int(config.Timeout / time.Millisecond),
+		Expect:      expects,
+		Assertions:  assertions,
+		Profile:     profile,
+		Source:      "cli",
+	}
+	if invokeErr != nil {
+		entry.Result = invokeErr.Error()
+	} else {
+		var buffer bytes.Buffer
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(safeCopyValue(decodeResult(result))); err == nil {
+			entry.Result = strings.TrimSuffix(buffer.String(), "\n")
+		} else {
+			entry.Result = cellText(result)
+		}
+	}
+
+	// 只追加一条记录，不必加载整个历史记录文件
+	retention := HistoryRetention{MaxEntries: defaultHistoryMaxEntries, MaxAge: defaultHistoryMaxAge}
+	if err := AppendHistory(path, retention, entry); err != nil {
+		color.Yellow("警告: 保存历史记录失败: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHistoryStoreConcurrentAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store, err := OpenHistoryStore(path, HistoryRetention{})
+	if err != nil {
+		t.Fatalf("打开历史记录失败: %v", err)
+	}
+
+	// 多个未加载的存储模拟同时写入的CLI进程，已打开的存储模拟Web端
+	var wg sync.WaitGroup
+	for writer := 0; writer < 4; writer++ {
+		wg.Add(1)
+		go func(writer int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				entry := CallHistory{ID: fmt.Sprintf("%d-%d", writer, i), ServiceName: "com.example.UserService", MethodName: "getUserById"}
+				if err := AppendHistory(path, HistoryRetention{}, entry); err != nil {
+					t.Errorf("追加历史记录失败: %v", err)
+				}
+			}
+		}(writer)
+	}
+	for i := 0; i < 25; i++ {
+		if err := store.Add(CallHistory{ID: fmt.Sprintf("web-%d", i)}); err != nil {
+			t.Errorf("追加历史记录失败: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if got := store.Count(); got != 125 {
+		t.Errorf("共 %d 条记录，期望 125", got)
+	}
+	if _, ok := store.Get("3-24"); !ok {
+		t.Error("未读取到其他进程追加的记录")
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("写入完成后锁文件应已删除: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("历史记录文件权限应为0600: %v %v", info.Mode(), err)
+		}
+	}
+}
+
+func TestHistoryStoreCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	retention := HistoryRetention{MaxEntries: 10, MaxAge: time.Hour}
+
+	// 过期的记录在整理时删除
+	if err := AppendHistory(path, retention, CallHistory{ID: "expired", Timestamp: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatalf("追加历史记录失败: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := AppendHistory(path, retention, CallHistory{ID: fmt.Sprintf("%d", i)}); err != nil {
+			t.Fatalf("追加历史记录失败: %v", err)
+		}
+	}
+	// 未超过上限的10%时只追加
+	if count, err := countHistoryLines(path); err != nil || count != 11 {
+		t.Fatalf("整理前有 %d 行 (%v)，期望 11", count, err)
+	}
+
+	if err := AppendHistory(path, retention, CallHistory{ID: "10"}); err != nil {
+		t.Fatalf("追加历史记录失败: %v", err)
+	}
+	store, err := OpenHistoryStore(path, retention)
+	if err != nil {
+		t.Fatalf("打开历史记录失败: %v", err)
+	}
+	page := store.Search(HistoryQuery{})
+	if page.Total != 10 {
+		t.Fatalf("整理后有 %d 条记录，期望 10", page.Total)
+	}
+	if _, ok := store.Get("expired"); ok {
+		t.Error("过期的记录应已删除")
+	}
+	if _, ok := store.Get("0"); ok {
+		t.Error("超出上限时应删除最早的记录")
+	}
+	if _, ok := store.Get("10"); !ok {
+		t.Error("最新的记录应保留")
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("清空历史记录失败: %v", err)
+	}
+	if count, err := countHistoryLines(path); err != nil || count != 0 {
+		t.Errorf("清空后有 %d 行 (%v)", count, err)
+	}
+}
+
+func TestHistoryStoreStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	lockPath := path + ".lock"
+
+	// 异常退出的进程残留的锁文件
+	if err := os.WriteFile(lockPath, []byte("1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * historyLockStale)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendHistory(path, HistoryRetention{}, CallHistory{ID: "1"}); err != nil {
+		t.Fatalf("残留的锁文件应被删除: %v", err)
+	}
+
+	// 判断过期后其他进程已清理并重新加锁时，不能删除新的锁
+	if err := os.WriteFile(lockPath, []byte("2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	removeStaleLock(lockPath)
+	if data, err := os.ReadFile(lockPath); err != nil || string(data) != "2\n" {
+		t.Errorf("其他进程的锁被删除: %q %v", data, err)
+	}
+	if matches, _ := filepath.Glob(lockPath + ".*"); len(matches) > 0 {
+		t.Errorf("清理锁文件时残留了临时文件: %v", matches)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// elapsedPattern 匹配telnet响应末尾提供者返回的耗时，如 "elapsed: 12 ms."
+var elapsedPattern = regexp.MustCompile(`elapsed:\s*(\d+)\s*ms`)
+
+// InvokeTimings 单次调用各阶段耗时，单位毫秒
+// 除Server外各阶段按顺序发生，Total为它们之和
+type InvokeTimings struct {
+	Connect float64 `json:"connect"` // 连接注册中心
+	Lookup  float64 `json:"lookup"`  // 从注册中心查找服务提供者
+	Dial    float64 `json:"dial"`    // 获取到服务提供者的连接（复用连接时接近0）
+	Send    float64 `json:"send"`    // 编码并发送invoke命令
+	Wait    float64 `json:"wait"`    // 发送完成到收到首个字节，包含网络往返和提供者处理时间
+	Read    float64 `json:"read"`    // 首个字节到响应读取完成
+	Parse   float64 `json:"parse"`   // 解码和解析响应
+	Server  float64 `json:"server"`  // 提供者返回的elapsed，-1表示响应中没有该值
+	Total   float64 `json:"total"`
+	Reused  bool    `json:"reused"` // 是否复用了连接池中的连接
+}
+
+// sum 汇总各顺序阶段的耗时
+func (t *InvokeTimings) sum() {
+	t.Total = round3(t.Connect + t.Lookup + t.Dial + t.Send + t.Wait + t.Read + t.Parse)
+}
+
+// String 单行格式化输出，用于日志
+func (t *InvokeTimings) String() string {
+	server := "n/a"
+	if t.Server >= 0 {
+		server = fmt.Sprintf("%.0fms", t.Server)
+	}
+	return fmt.Sprintf("connect=%.1fms lookup=%.1fms dial=%.1fms send=%.1fms wait=%.1fms read=%.1fms parse=%.1fms server=%s total=%.1fms reused=%t",
+		t.Connect, t.Lookup, t.Dial, t.Send, t.Wait, t.Read, t.Parse, server, t.Total, t.Reused)
+}
+
+// msSince 返回自start以来经过的毫秒数，保留3位小数
+func msSince(start time.Time) float64 {
+	return round3(float64(time.Since(start).Microseconds()) / 1000)
+}
+
+// round3 保留3位小数
+func round3(v float64) float64 {
+	return float64(int64(v*1000+0.5)) / 1000
+}
+
+// parseServerElapsed 从telnet响应中提取提供者返回的耗时，未找到时返回-1
+func parseServerElapsed(responseText string) float64 {
+	matches := elapsedPattern.FindAllStringSubmatch(responseText, -1)
+	if len(matches) == 0 {
+		return -1
+	}
+	// 以最后一个为准，避免业务数据中恰好包含相同文本
+	elapsed, err := strconv.ParseFloat(strings.TrimSpace(matches[len(matches)-1][1]), 64)
+	if err != nil {
+		return -1
+	}
+	return elapsed
+}
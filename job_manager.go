@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// JobStatus 后台任务状态
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCanceled  JobStatus = "canceled"
+)
+
+// maxRetainedJobs 已结束任务的最大保留数量
+const maxRetainedJobs = 200
+
+// jobSequence 任务序号，保证同一时刻提交的任务ID不重复
+var jobSequence atomic.Uint64
+
+// JobInvokeResult 任务中单次调用的结果
+type JobInvokeResult struct {
+	Index       int         `json:"index"`
+	ServiceName string      `json:"serviceName"`
+	MethodName  string      `json:"methodName"`
+	Success     bool        `json:"success"`
+	Data        interface{} `json:"data,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	Duration    int64       `json:"duration"` // 调用耗时，单位毫秒
+}
+
+// Job 后台调用任务，包含一组按顺序执行的调用
+type Job struct {
+	ID         string            `json:"id"`
+	Status     JobStatus         `json:"status"`
+	Total      int               `json:"total"`
+	Completed  int               `json:"completed"`
+	Failed     int               `json:"failed"`
+	Results    []JobInvokeResult `json:"results,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	StartedAt  *time.Time        `json:"startedAt,omitempty"`
+	FinishedAt *time.Time        `json:"finishedAt,omitempty"`
+
+	requests []InvokeRequest
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// JobInvoker 执行单次调用的函数
+type JobInvoker func(ctx context.Context, req InvokeRequest) (interface{}, error)
+
+// JobManager 后台任务管理器，通过AsyncProcessor限制并发数和排队数量
+type JobManager struct {
+	mu        sync.RWMutex
+	jobs      map[string]*Job
+	order     []string
+	processor *AsyncProcessor
+	invoker   JobInvoker
+	timeout   time.Duration
+}
+
+// NewJobManager 创建任务管理器，并发数和队列长度取自OptimizedDubboConfig.WorkerCount
+func NewJobManager(cfg *OptimizedDubboConfig, invoker JobInvoker, timeout time.Duration) *JobManager {
+	processor := NewAsyncProcessor(cfg.WorkerCount)
+	processor.Start()
+
+	return &JobManager{
+		jobs:      make(map[string]*Job),
+		processor: processor,
+		invoker:   invoker,
+		timeout:   timeout,
+	}
+}
+
+// Submit 提交一组调用，返回任务快照
+func (jm *JobManager) Submit(requests []InvokeRequest) (*Job, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("至少需要一个调用请求")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		ID:        fmt.Sprintf("job-%d-%d", time.Now().UnixNano(), jobSequence.Add(1)),
+		Status:    JobQueued,
+		Total:     len(requests),
+		CreatedAt: time.Now(),
+		requests:  requests,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	jm.mu.Lock()
+	jm.jobs[job.ID] = job
+	jm.order = append(jm.order, job.ID)
+	jm.evictLocked()
+	jm.mu.Unlock()
+
+	// 任务整体超时时间按调用数量累计
+	var timeout time.Duration
+	if jm.timeout > 0 {
+		timeout = jm.timeout * time.Duration(len(requests))
+	}
+
+	err := jm.processor.SubmitTask(AsyncTask{
+		ID:      job.ID,
+		Context: ctx,
+		Timeout: timeout,
+		Run: func(ctx context.Context) (interface{}, error) {
+			return nil, jm.run(ctx, job)
+		},
+		Callback: func(_ interface{}, err error) {
+			jm.finish(job, err)
+		},
+	})
+	if err != nil {
+		cancel()
+		jm.mu.Lock()
+		delete(jm.jobs, job.ID)
+		for i, id := range jm.order {
+			if id == job.ID {
+				jm.order = append(jm.order[:i], jm.order[i+1:]...)
+				break
+			}
+		}
+		jm.mu.Unlock()
+		return nil, err
+	}
+
+	return jm.Get(job.ID)
+}
+
+// run 按顺序执行任务中的调用
+func (jm *JobManager) run(ctx context.Context, job *Job) error {
+	now := time.Now()
+	jm.mu.Lock()
+	// 排队时已被取消
+	if job.Status == JobCanceled {
+		jm.mu.Unlock()
+		return context.Canceled
+	}
+	job.Status = JobRunning
+	job.StartedAt = &now
+	jm.mu.Unlock()
+
+	for i, req := range job.requests {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		startTime := time.Now()
+		result, err := jm.invoker(ctx, req)
+		item := JobInvokeResult{
+			Index:       i,
+			ServiceName: req.ServiceName,
+			MethodName:  req.MethodName,
+			Success:     err == nil,
+			Duration:    time.Since(startTime).Milliseconds(),
+		}
+		if err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Data = safeCopyValue(result)
+		}
+
+		jm.mu.Lock()
+		job.Results = append(job.Results, item)
+		job.Completed++
+		if err != nil {
+			job.Failed++
+		}
+		jm.mu.Unlock()
+	}
+
+	return nil
+}
+
+// finish 记录任务结束状态
+func (jm *JobManager) finish(job *Job, err error) {
+	now := time.Now()
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	// 排队时取消的任务在Cancel中已记录结束时间
+	if job.FinishedAt == nil {
+		job.FinishedAt = &now
+	}
+	switch {
+	case job.ctx.Err() == context.Canceled:
+		job.Status = JobCanceled
+	case err == context.DeadlineExceeded:
+		job.Status = JobFailed
+		job.Error = fmt.Sprintf("任务超时: %s", job.ID)
+	case err != nil:
+		job.Status = JobFailed
+		job.Error = err.Error()
+	case job.Failed > 0:
+		job.Status = JobFailed
+		job.Error = fmt.Sprintf("%d/%d 个调用失败", job.Failed, job.Total)
+	default:
+		job.Status = JobSucceeded
+	}
+	job.cancel()
+}
+
+// Get 获取任务快照
+func (jm *JobManager) Get(id string) (*Job, error) {
+	jm.mu.RLock()
+	defer jm.mu.RUnlock()
+
+	job, ok := jm.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("任务不存在: %s", id)
+	}
+	return job.snapshot(true), nil
+}
+
+// List 按提交时间倒序列出任务摘要
+func (jm *JobManager) List() []*Job {
+	jm.mu.RLock()
+	defer jm.mu.RUnlock()
+
+	jobs := make([]*Job, 0, len(jm.order))
+	for i := len(jm.order) - 1; i >= 0; i-- {
+		jobs = append(jobs, jm.jobs[jm.order[i]].snapshot(false))
+	}
+	return jobs
+}
+
+// Cancel 取消排队中或执行中的任务
+func (jm *JobManager) Cancel(id string) (*Job, error) {
+	jm.mu.Lock()
+	job, ok := jm.jobs[id]
+	if !ok {
+		jm.mu.Unlock()
+		return nil, fmt.Errorf("任务不存在: %s", id)
+	}
+	if job.FinishedAt != nil {
+		jm.mu.Unlock()
+		return nil, fmt.Errorf("任务已结束: %s", id)
+	}
+	job.cancel()
+	// 尚未开始执行的任务直接结束，不必等待工作协程从队列中取出
+	if job.Status == JobQueued {
+		now := time.Now()
+		job.Status = JobCanceled
+		job.FinishedAt = &now
+	}
+	jm.mu.Unlock()
+
+	return jm.Get(id)
+}
+
+// Stats 返回任务队列统计
+func (jm *JobManager) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"workers":       jm.processor.WorkerCount(),
+		"queued":        jm.processor.QueueLength(),
+		"queueCapacity": jm.processor.QueueCapacity(),
+	}
+}
+
+// Stop 停止任务管理器
+func (jm *JobManager) Stop() {
+	jm.mu.Lock()
+	for _, job := range jm.jobs {
+		job.cancel()
+	}
+	jm.mu.Unlock()
+	jm.processor.Stop()
+}
+
+// evictLocked 清理超出保留数量的已结束任务，调用方需持有写锁
+func (jm *JobManager) evictLocked() {
+	if len(jm.order) <= maxRetainedJobs {
+		return
+	}
+	kept := make([]string, 0, len(jm.order))
+	excess := len(jm.order) - maxRetainedJobs
+	for _, id := range jm.order {
+		if excess > 0 && jm.jobs[id].FinishedAt != nil {
+			delete(jm.jobs, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	jm.order = kept
+}
+
+// snapshot 复制任务状态，调用方需持有读锁
+func (j *Job) snapshot(withResults bool) *Job {
+	copied := *j
+	copied.requests = nil
+	copied.ctx = nil
+	copied.cancel = nil
+	if withResults {
+		copied.Results = append([]JobInvokeResult(nil), j.Results...)
+	} else {
+		copied.Results = nil
+	}
+	return &copied
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitJob 等待任务结束，超时返回最后一次的快照
+func waitJob(t *testing.T, jm *JobManager, id string) *Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := jm.Get(id)
+		if err != nil {
+			t.Fatalf("获取任务失败: %v", err)
+		}
+		if job.FinishedAt != nil || time.Now().After(deadline) {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestJobManagerRun(t *testing.T) {
+	jm := NewJobManager(&OptimizedDubboConfig{WorkerCount: 2}, func(ctx context.Context, req InvokeRequest) (interface{}, error) {
+		if req.MethodName == "fail" {
+			return nil, fmt.Errorf("调用失败")
+		}
+		return map[string]interface{}{"method": req.MethodName}, nil
+	}, time.Second)
+
+	tests := []struct {
+		name      string
+		methods   []string
+		status    JobStatus
+		completed int
+		failed    int
+	}{
+		{"全部成功", []string{"a", "b"}, JobSucceeded, 2, 0},
+		{"部分失败", []string{"a", "fail", "c"}, JobFailed, 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := make([]InvokeRequest, len(tt.methods))
+			for i, method := range tt.methods {
+				requests[i] = InvokeRequest{ServiceName: "com.example.UserService", MethodName: method}
+			}
+			submitted, err := jm.Submit(requests)
+			if err != nil {
+				t.Fatalf("提交任务失败: %v", err)
+			}
+			job := waitJob(t, jm, submitted.ID)
+			if job.Status != tt.status || job.Completed != tt.completed || job.Failed != tt.failed {
+				t.Errorf("状态 %s 完成 %d 失败 %d，期望 %s %d %d", job.Status, job.Completed, job.Failed, tt.status, tt.completed, tt.failed)
+			}
+			if len(job.Results) != len(tt.methods) {
+				t.Fatalf("结果数 %d，期望 %d", len(job.Results), len(tt.methods))
+			}
+			for i, result := range job.Results {
+				if result.Index != i || result.MethodName != tt.methods[i] {
+					t.Errorf("第%d个结果为 %+v", i, result)
+				}
+			}
+		})
+	}
+
+	if _, err := jm.Submit(nil); err == nil {
+		t.Error("提交空任务应返回错误")
+	}
+}
+
+func TestJobManagerUniqueIDs(t *testing.T) {
+	jm := NewJobManager(&OptimizedDubboConfig{WorkerCount: 4}, func(ctx context.Context, req InvokeRequest) (interface{}, error) {
+		return nil, nil
+	}, 0)
+
+	var mu sync.Mutex
+	ids := make(map[string]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job, err := jm.Submit([]InvokeRequest{{}})
+			if err != nil {
+				t.Errorf("提交任务失败: %v", err)
+				return
+			}
+			mu.Lock()
+			ids[job.ID] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if len(ids) != 20 || len(jm.List()) != 20 {
+		t.Errorf("20个任务得到 %d 个不同的ID，列表中有 %d 个任务", len(ids), len(jm.List()))
+	}
+}
+
+func TestJobManagerCancel(t *testing.T) {
+	started := make(chan struct{}, 1)
+	jm := NewJobManager(&OptimizedDubboConfig{WorkerCount: 1}, func(ctx context.Context, req InvokeRequest) (interface{}, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, 0)
+
+	running, err := jm.Submit([]InvokeRequest{{}, {}})
+	if err != nil {
+		t.Fatalf("提交任务失败: %v", err)
+	}
+	<-started
+	queued, err := jm.Submit([]InvokeRequest{{}})
+	if err != nil {
+		t.Fatalf("提交任务失败: %v", err)
+	}
+
+	// 排队中的任务取消后立即结束，之后也不会开始执行
+	job, err := jm.Cancel(queued.ID)
+	if err != nil {
+		t.Fatalf("取消任务失败: %v", err)
+	}
+	if job.Status != JobCanceled || job.FinishedAt == nil {
+		t.Errorf("排队中的任务取消后状态为 %s", job.Status)
+	}
+
+	// 执行中的任务取消后不再执行剩余的调用
+	if _, err := jm.Cancel(running.ID); err != nil {
+		t.Fatalf("取消任务失败: %v", err)
+	}
+	job = waitJob(t, jm, running.ID)
+	if job.Status != JobCanceled || job.Completed != 1 {
+		t.Errorf("执行中的任务取消后状态为 %s，完成 %d 个调用", job.Status, job.Completed)
+	}
+
+	job = waitJob(t, jm, queued.ID)
+	if job.Status != JobCanceled || job.StartedAt != nil {
+		t.Errorf("排队时取消的任务状态为 %s，开始时间 %v", job.Status, job.StartedAt)
+	}
+	if _, err := jm.Cancel(queued.ID); err == nil {
+		t.Error("取消已结束的任务应返回错误")
+	}
+	if _, err := jm.Cancel("job-unknown"); err == nil {
+		t.Error("取消不存在的任务应返回错误")
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// 结构化差异的类型
+const (
+	DiffAdded       = "added"
+	DiffRemoved     = "removed"
+	DiffChanged     = "changed"
+	DiffTypeChanged = "type_changed"
+)
+
+// DiffEntry 一处结构化差异
+type DiffEntry struct {
+	Path    string      `json:"path"`
+	Kind    string      `json:"kind"`
+	Old     interface{} `json:"old,omitempty"`
+	New     interface{} `json:"new,omitempty"`
+	OldType string      `json:"oldType,omitempty"` // 仅type_changed
+	NewType string      `json:"newType,omitempty"` // 仅type_changed
+}
+
+// diffPathSegment 路径中的一段：对象字段或数组下标
+type diffPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// ignoreSegment 忽略规则中的一段
+type ignoreSegment struct {
+	kind  string // key、index、anyKey、anyIndex、descent
+	key   string
+	index int
+}
+
+// ignoreRule 解析后的忽略规则
+type ignoreRule struct {
+	pattern  string
+	segments []ignoreSegment
+}
+
+// identifierPattern 可以用 .key 形式表示的字段名
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// DiffJSON 比较两个JSON值的结构差异，返回按路径排序的差异列表
+// 数字按数值比较，超过15位的整数按文本比较以免丢失精度；整数、小数和超过15位的整数字符串视为同一类型。
+// ignore为忽略规则，支持 $.data.updateTime、$.items[*].id、$.data.*、$..traceId，
+// 不含路径符号的规则如 timestamp 等同于 $..timestamp；匹配的路径及其子节点都不参与比较
+func DiffJSON(oldValue, newValue interface{}, ignore []string) ([]DiffEntry, error) {
+	rules, err := parseIgnoreRules(ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []DiffEntry{}
+	diffValues(oldValue, newValue, nil, rules, &entries)
+	return entries, nil
+}
+
+// diffValues 递归比较两个值
+func diffValues(oldValue, newValue interface{}, path []diffPathSegment, rules []ignoreRule, entries *[]DiffEntry) {
+	if ignoredPath(rules, path) {
+		return
+	}
+
+	oldKind, newKind := diffKind(oldValue), diffKind(newValue)
+	if oldKind != newKind {
+		*entries = append(*entries, DiffEntry{
+			Path:    formatDiffPath(path),
+			Kind:    DiffTypeChanged,
+			Old:     oldValue,
+			New:     newValue,
+			OldType: oldKind,
+			NewType: newKind,
+		})
+		return
+	}
+
+	switch oldKind {
+	case "object":
+		oldObject := oldValue.(map[string]interface{})
+		newObject := newValue.(map[string]interface{})
+		keys := make(map[string]bool, len(oldObject)+len(newObject))
+		for key := range oldObject {
+			keys[key] = true
+		}
+		for key := range newObject {
+			keys[key] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, key := range sortedKeys {
+			childPath := appendPath(path, diffPathSegment{key: key})
+			oldChild, inOld := oldObject[key]
+			newChild, inNew := newObject[key]
+			switch {
+			case inOld && inNew:
+				diffValues(oldChild, newChild, childPath, rules, entries)
+			case ignoredPath(rules, childPath):
+			case inOld:
+				*entries = append(*entries, DiffEntry{Path: formatDiffPath(childPath), Kind: DiffRemoved, Old: oldChild})
+			default:
+				*entries = append(*entries, DiffEntry{Path: formatDiffPath(childPath), Kind: DiffAdded, New: newChild})
+			}
+		}
+	case "array":
+		oldArray := oldValue.([]interface{})
+		newArray := newValue.([]interface{})
+		length := len(oldArray)
+		if len(newArray) > length {
+			length = len(newArray)
+		}
+		for i := 0; i < length; i++ {
+			childPath := appendPath(path, diffPathSegment{index: i, isIndex: true})
+			switch {
+			case i < len(oldArray) && i < len(newArray):
+				diffValues(oldArray[i], newArray[i], childPath, rules, entries)
+			case ignoredPath(rules, childPath):
+			case i < len(oldArray):
+				*entries = append(*entries, DiffEntry{Path: formatDiffPath(childPath), Kind: DiffRemoved, Old: oldArray[i]})
+			default:
+				*entries = append(*entries, DiffEntry{Path: formatDiffPath(childPath), Kind: DiffAdded, New: newArray[i]})
+			}
+		}
+	case "number":
+		if numberText(oldValue) != numberText(newValue) {
+			*entries = append(*entries, DiffEntry{Path: formatDiffPath(path), Kind: DiffChanged, Old: oldValue, New: newValue})
+		}
+	case "null":
+	default:
+		if oldValue != newValue {
+			*entries = append(*entries, DiffEntry{Path: formatDiffPath(path), Kind: DiffChanged, Old: oldValue, New: newValue})
+		}
+	}
+}
+
+// appendPath 复制路径并追加一段，避免子路径共享底层数组
+func appendPath(path []diffPathSegment, segment diffPathSegment) []diffPathSegment {
+	childPath := make([]diffPathSegment, len(path)+1)
+	copy(childPath, path)
+	childPath[len(path)] = segment
+	return childPath
+}
+
+// diffKind 比较时使用的类型，整数和小数都视为number
+// 与safeCopyValue的规则一致，超过15位的整数字符串视为number，避免同一个Long在一侧是数字、另一侧是字符串时误报
+func diffKind(value interface{}) string {
+	kind := jsonKind(value)
+	switch kind {
+	case "integer":
+		return "number"
+	case "string":
+		if isLargeIntegerString(value.(string)) {
+			return "number"
+		}
+	}
+	return kind
+}
+
+// isLargeIntegerString 判断是否为safeCopyValue转换出的大整数字符串
+func isLargeIntegerString(text string) bool {
+	return isIntegerString(text) && len(strings.TrimPrefix(text, "-")) >= 16
+}
+
+// numberText 数字的规范文本，用于精确比较
+func numberText(value interface{}) string {
+	text := cellText(value)
+	if isIntegerString(text) {
+		negative := strings.HasPrefix(text, "-")
+		digits := strings.TrimLeft(strings.TrimPrefix(text, "-"), "0")
+		if digits == "" {
+			return "0"
+		}
+		if negative {
+			return "-" + digits
+		}
+		return digits
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		if f == float64(int64(f)) && f < 1e15 && f > -1e15 {
+			return strconv.FormatInt(int64(f), 10)
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return text
+}
+
+// formatDiffPath 格式化路径，如 $.data.items[0]["user-name"]
+func formatDiffPath(path []diffPathSegment) string {
+	var builder strings.Builder
+	builder.WriteString("$")
+	for _, segment := range path {
+		switch {
+		case segment.isIndex:
+			fmt.Fprintf(&builder, "[%d]", segment.index)
+		case identifierPattern.MatchString(segment.key):
+			builder.WriteString(".")
+			builder.WriteString(segment.key)
+		default:
+			fmt.Fprintf(&builder, "[%s]", strconv.Quote(segment.key))
+		}
+	}
+	return builder.String()
+}
+
+// parseIgnoreRules 解析忽略规则
+func parseIgnoreRules(patterns []string) ([]ignoreRule, error) {
+	rules := make([]ignoreRule, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		segments, err := parseIgnorePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, ignoreRule{pattern: pattern, segments: segments})
+	}
+	return rules, nil
+}
+
+// parseIgnorePattern 解析单条忽略规则
+func parseIgnorePattern(pattern string) ([]ignoreSegment, error) {
+	expr := pattern
+	if !strings.ContainsAny(expr, "$.[") {
+		expr = "$.." + expr
+	}
+	expr = strings.TrimPrefix(expr, "$")
+
+	var segments []ignoreSegment
+	for i := 0; i < len(expr); {
+		switch {
+		case strings.HasPrefix(expr[i:], ".."):
+			segments = append(segments, ignoreSegment{kind: "descent"})
+			i += 2
+			i = parseIgnoreKey(expr, i, &segments)
+		case expr[i] == '.':
+			i++
+			i = parseIgnoreKey(expr, i, &segments)
+		case expr[i] == '[':
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("无效的忽略规则 %s: 缺少 ]", pattern)
+			}
+			inner := strings.TrimSpace(expr[i+1 : i+end])
+			i += end + 1
+			switch {
+			case inner == "*" || inner == "":
+				segments = append(segments, ignoreSegment{kind: "anyIndex"})
+			case strings.HasPrefix(inner, "'") || strings.HasPrefix(inner, "\""):
+				segments = append(segments, ignoreSegment{kind: "key", key: strings.Trim(inner, "'\"")})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("无效的忽略规则 %s: 无效的数组下标 %s", pattern, inner)
+				}
+				segments = append(segments, ignoreSegment{kind: "index", index: index})
+			}
+		default:
+			i = parseIgnoreKey(expr, i, &segments)
+		}
+	}
+	return segments, nil
+}
+
+// parseIgnoreKey 解析点号后的字段名，* 表示任意字段
+func parseIgnoreKey(expr string, i int, segments *[]ignoreSegment) int {
+	start := i
+	for i < len(expr) && expr[i] != '.' && expr[i] != '[' {
+		i++
+	}
+	key := expr[start:i]
+	switch key {
+	case "":
+	case "*":
+		*segments = append(*segments, ignoreSegment{kind: "anyKey"})
+	default:
+		*segments = append(*segments, ignoreSegment{kind: "key", key: key})
+	}
+	return i
+}
+
+// ignoredPath 判断路径是否匹配任一忽略规则
+func ignoredPath(rules []ignoreRule, path []diffPathSegment) bool {
+	for _, rule := range rules {
+		if matchIgnoreSegments(rule.segments, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchIgnoreSegments 判断规则是否完整匹配路径，descent可匹配任意层级
+func matchIgnoreSegments(rule []ignoreSegment, path []diffPathSegment) bool {
+	if len(rule) == 0 {
+		return len(path) == 0
+	}
+
+	segment := rule[0]
+	if segment.kind == "descent" {
+		for skip := 0; skip <= len(path); skip++ {
+			if matchIgnoreSegments(rule[1:], path[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+
+	current := path[0]
+	switch segment.kind {
+	case "key":
+		if current.isIndex || current.key != segment.key {
+			return false
+		}
+	case "anyKey":
+		// .* 同时匹配对象字段和数组元素
+	case "index":
+		if !current.isIndex || current.index != segment.index {
+			return false
+		}
+	case "anyIndex":
+		if !current.isIndex {
+			return false
+		}
+	}
+	return matchIgnoreSegments(rule[1:], path[1:])
+}
+
+// printDiff 输出结构化差异
+func printDiff(w io.Writer, entries []DiffEntry, indent string) {
+	for _, entry := range entries {
+		switch entry.Kind {
+		case DiffAdded:
+			color.New(color.FgGreen).Fprintf(w, "%s+ %s: %s\n", indent, entry.Path, describeOperand(entry.New))
+		case DiffRemoved:
+			color.New(color.FgRed).Fprintf(w, "%s- %s: %s\n", indent, entry.Path, describeOperand(entry.Old))
+		case DiffTypeChanged:
+			color.New(color.FgMagenta).Fprintf(w, "%s! %s: %s → %s (%s → %s)\n", indent, entry.Path,
+				entry.OldType, entry.NewType, describeOperand(entry.Old), describeOperand(entry.New))
+		default:
+			color.New(color.FgYellow).Fprintf(w, "%s~ %s: %s → %s\n", indent, entry.Path, describeOperand(entry.Old), describeOperand(entry.New))
+		}
+	}
+}
+
+// diffSummary 按类型统计差异数量
+func diffSummary(entries []DiffEntry) map[string]int {
+	summary := map[string]int{DiffAdded: 0, DiffRemoved: 0, DiffChanged: 0, DiffTypeChanged: 0}
+	for _, entry := range entries {
+		summary[entry.Kind]++
+	}
+	return summary
+}
+
+// parseJSONText 解析JSON文本，数字保持为json.Number；不是有效JSON时返回原字符串
+func parseJSONText(text string) interface{} {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return text
+	}
+	return value
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decodeDiffInput 按结果解析的方式解码JSON，数字保留为json.Number
+func decodeDiffInput(t *testing.T, text string) interface{} {
+	t.Helper()
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		t.Fatalf("解析JSON失败: %v", err)
+	}
+	return value
+}
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		old    string
+		new    string
+		ignore []string
+		want   []string // 差异的 路径 类型
+	}{
+		{
+			name: "相同的大整数",
+			old:  `{"id": 1234567890123456789012}`,
+			new:  `{"id": 1234567890123456789012}`,
+		},
+		{
+			name: "大整数只有最后一位不同",
+			old:  `{"id": 12345678901234567890}`,
+			new:  `{"id": 12345678901234567891}`,
+			want: []string{"$.id changed"},
+		},
+		{
+			name: "大整数一侧为数字一侧为字符串",
+			old:  `{"id": 12345678901234567890}`,
+			new:  `{"id": "12345678901234567890"}`,
+		},
+		{
+			name: "负数大整数",
+			old:  `{"id": -9223372036854775808}`,
+			new:  `{"id": "-9223372036854775808"}`,
+		},
+		{
+			name: "整数和小数按数值比较",
+			old:  `{"price": 1.50, "count": 2}`,
+			new:  `{"price": 1.5, "count": 2.0}`,
+		},
+		{
+			name: "短数字字符串不视为数字",
+			old:  `{"code": 200}`,
+			new:  `{"code": "200"}`,
+			want: []string{"$.code type_changed"},
+		},
+		{
+			name: "新增和删除字段",
+			old:  `{"a": 1, "b": [1, 2]}`,
+			new:  `{"a": 1, "b": [1], "c": null}`,
+			want: []string{"$.b[1] removed", "$.c added"},
+		},
+		{
+			name:   "忽略指定路径",
+			old:    `{"data": {"name": "a", "updateTime": 1}}`,
+			new:    `{"data": {"name": "a", "updateTime": 2}}`,
+			ignore: []string{"$.data.updateTime"},
+		},
+		{
+			name:   "忽略数组中每个元素的字段",
+			old:    `{"items": [{"id": 1, "name": "a"}, {"id": 2, "name": "b"}]}`,
+			new:    `{"items": [{"id": 3, "name": "a"}, {"id": 4, "name": "c"}]}`,
+			ignore: []string{"$.items[*].id"},
+			want:   []string{"$.items[1].name changed"},
+		},
+		{
+			name:   "不含路径符号的规则匹配任意层级",
+			old:    `{"timestamp": 1, "data": {"timestamp": 1, "list": [{"timestamp": 1}]}}`,
+			new:    `{"timestamp": 2, "data": {"timestamp": 2, "list": [{"timestamp": 2}]}}`,
+			ignore: []string{"timestamp"},
+		},
+		{
+			name:   "递归匹配的规则",
+			old:    `{"traceId": "a", "data": {"traceId": "b", "value": 1}}`,
+			new:    `{"data": {"traceId": "c", "value": 2}}`,
+			ignore: []string{"$..traceId"},
+			want:   []string{"$.data.value changed"},
+		},
+		{
+			name:   "忽略对象的所有字段",
+			old:    `{"data": {"a": 1, "b": 2}, "code": 0}`,
+			new:    `{"data": {"a": 3, "c": 4}, "code": 1}`,
+			ignore: []string{"$.data.*"},
+			want:   []string{"$.code changed"},
+		},
+		{
+			name:   "忽略新增的字段",
+			old:    `{"a": 1}`,
+			new:    `{"a": 1, "extra": {"x": 1}}`,
+			ignore: []string{"$.extra"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := DiffJSON(decodeDiffInput(t, tt.old), decodeDiffInput(t, tt.new), tt.ignore)
+			if err != nil {
+				t.Fatalf("DiffJSON返回错误: %v", err)
+			}
+			got := make([]string, len(entries))
+			for i, entry := range entries {
+				got[i] = entry.Path + " " + entry.Kind
+			}
+			want := tt.want
+			if want == nil {
+				want = []string{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("差异 %v，期望 %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDiffJSONInvalidIgnoreRule(t *testing.T) {
+	for _, rule := range []string{"$.items[0", "$.items[x]"} {
+		if _, err := DiffJSON(map[string]interface{}{}, map[string]interface{}{}, []string{rule}); err == nil {
+			t.Errorf("忽略规则 %s 应返回错误", rule)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -14,6 +15,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// interruptGracePeriod 收到Ctrl+C后等待命令自行结束的时间
+const interruptGracePeriod = 2 * time.Second
+
 var (
 	version = "1.0.0"
 	buildTime = "unknown"
@@ -82,8 +86,22 @@ func main() {
 
 	rootCmd := createRootCommand()
 
-	if err := rootCmd.Execute(); err != nil {
-		color.Red("错误: %v", err)
+	// Ctrl+C 取消正在进行的调用，并关闭到服务提供者的连接
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		// 恢复默认的信号处理，再次按Ctrl+C立即退出
+		stop()
+		// list、providers等命令阻塞在注册中心请求上时不检查ctx，超过宽限时间后直接退出
+		time.Sleep(interruptGracePeriod)
+		color.New(color.FgYellow).Fprintln(os.Stderr, "已中断")
+		os.Exit(130)
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		// 错误写到标准错误，避免混入通过管道传递的结果
+		color.New(color.FgRed).Fprintf(os.Stderr, "错误: %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -102,22 +120,39 @@ func createRootCommand() *cobra.Command {
 
 双击exe文件将自动启动Web UI模式`,
 		Version: fmt.Sprintf("%s (built at %s)", version, buildTime),
+		// 加载配置文件中的当前环境，作为未指定的全局参数的默认值
+		PersistentPreRunE: applyActiveProfile,
 	}
 
 	// 添加子命令
 	rootCmd.AddCommand(newInvokeCommand())
 	rootCmd.AddCommand(newListCommand())
 	rootCmd.AddCommand(newConfigCommand())
+	rootCmd.AddCommand(newSecretCommand())
 	rootCmd.AddCommand(newVersionCommand())
 	rootCmd.AddCommand(newWebCommand())
+	rootCmd.AddCommand(newTestCommand())
+	rootCmd.AddCommand(newBenchCommand())
+	rootCmd.AddCommand(newReplayCommand())
+	rootCmd.AddCommand(newDiffCommand())
+	rootCmd.AddCommand(newHistoryCommand())
+	rootCmd.AddCommand(newRunCommand())
+	rootCmd.AddCommand(newCollectionCommand())
+	rootCmd.AddCommand(newShellCommand())
+	rootCmd.AddCommand(newCacheCommand())
+	rootCmd.AddCommand(newSearchCommand())
+	rootCmd.AddCommand(newProvidersCommand())
+	rootCmd.AddCommand(newInventoryCommand())
 	rootCmd.AddCommand(newTestNacosCommand())
 
 	// 全局标志
-	rootCmd.PersistentFlags().StringP("config", "c", "config.yaml", "配置文件路径")
+	rootCmd.PersistentFlags().StringP("config", "c", "", "配置文件路径，默认为 ~/.dubbo-invoke/config.yaml，也可以通过 DUBBO_INVOKE_CONFIG 环境变量指定")
+	rootCmd.PersistentFlags().StringP("profile", "P", "", "使用的环境(如 dev、test、pre、prod)，默认为配置文件中的 current，也可以通过 DUBBO_INVOKE_PROFILE 环境变量指定")
 	rootCmd.PersistentFlags().StringP("registry", "r", "zookeeper://127.0.0.1:2181", "注册中心地址")
 	rootCmd.PersistentFlags().StringP("app", "a", "dubbo-invoke-client", "应用名称")
 	rootCmd.PersistentFlags().IntP("timeout", "t", 3000, "调用超时时间(毫秒)")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "详细输出")
+	rootCmd.PersistentFlags().Int("max-payload", 50, "最大响应大小(MB)，超过后中止读取")
 
 	return rootCmd
 }
@@ -136,9 +171,16 @@ func newInvokeCommand() *cobra.Command {
   
   # 新格式（表达式）
   dubbo-invoke invoke 'com.example.UserService.getUserById(123)'
-  dubbo-invoke invoke 'com.jzt.zhcai.user.companyinfo.CompanyInfoDubboApi.getCompanyInfoFromDb({"class":"com.jzt.zhcai.user.companyinfo.dto.request.UserCompanyInfoDetailReq","companyId":1})'`,
-		Args: cobra.MinimumNArgs(1),
-		RunE: runInvokeCommand,
+  dubbo-invoke invoke 'com.jzt.zhcai.user.companyinfo.CompanyInfoDubboApi.getCompanyInfoFromDb({"class":"com.jzt.zhcai.user.companyinfo.dto.request.UserCompanyInfoDetailReq","companyId":1})'
+
+  # 作为冒烟测试：断言失败时以非0状态退出
+  dubbo-invoke invoke com.example.UserService getUserById 123 --expect '$.success == true' --expect 'len($.data) > 0' --max-time 500
+
+  # 录制调用，之后可通过 replay 命令回放到其他环境并比较结果
+  dubbo-invoke invoke 'com.example.UserService.getUserById(123)' --record user.yaml`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeInvokeArgs,
+		RunE:              runInvokeCommand,
 	}
 
 	cmd.Flags().StringP("version", "V", "", "服务版本")
@@ -146,6 +188,17 @@ func newInvokeCommand() *cobra.Command {
 	cmd.Flags().BoolP("generic", "G", true, "使用泛化调用")
 	cmd.Flags().StringSliceP("types", "T", nil, "参数类型列表")
 	cmd.Flags().BoolP("example", "e", false, "生成示例参数")
+	cmd.Flags().String("tag", "", "服务标签，用于过滤服务提供者")
+	cmd.Flags().Bool("dry-run", false, "只解析注册中心和服务提供者并展示将要发送的内容，不实际调用")
+	cmd.Flags().String("metadata", "", "服务定义元数据文件(JSON)，用于调用前校验参数")
+	cmd.Flags().Bool("no-validate", false, "跳过调用前的参数校验")
+	cmd.Flags().StringP("output", "o", "json", "结果输出格式: json|yaml|table|raw|csv")
+	cmd.Flags().StringP("query", "q", "", "按JSONPath/jq风格的路径过滤结果，如 .data.items[*].id")
+	cmd.Flags().Bool("quiet", false, "标准输出只输出结果，日志写到标准错误")
+	cmd.Flags().StringArray("expect", nil, "结果断言，可重复指定，如 '$.success == true'、'len($.data) > 0'，失败时以非0状态退出")
+	cmd.Flags().Int64("max-time", 0, "调用耗时上限(毫秒)，超过时断言失败")
+	cmd.Flags().String("record", "", "将请求、服务提供者和响应追加到录制文件(YAML)，供replay命令回放")
+	cmd.Flags().Bool("no-history", false, "不将本次调用写入历史记录")
 
 	return cmd
 }
@@ -174,20 +227,43 @@ func openBrowser(url string) {
 
 // test-nacos命令 - 测试Nacos注册中心连接
 func newTestNacosCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "test-nacos",
 		Short: "测试Nacos注册中心连接",
 		Long: `测试与Nacos注册中心的连接，包括：
 - 连接测试
 - 获取服务列表
 - 查询服务详情
-- 验证服务实例状态`,
+- 验证服务实例状态
+
+注册中心地址、命名空间和认证信息来自 --registry 和当前环境，密码可以通过 secret:// 引用加密保存的凭据
+
+示例:
+  dubbo-invoke test-nacos --profile test
+  dubbo-invoke test-nacos -r nacos://127.0.0.1:8848 --namespace dev`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, _ := cmd.Flags().GetString("registry")
+			scheme, address, ok := strings.Cut(registry, "://")
+			if !ok || scheme != "nacos" {
+				return fmt.Errorf("注册中心 %s 不是Nacos地址，请通过 --registry nacos://host:port 或 --profile 指定", registry)
+			}
+			config := &DubboConfig{Registry: registry}
+			if cmd.Flags().Changed("namespace") {
+				config.Namespace, _ = cmd.Flags().GetString("namespace")
+			}
+			activeProfile(cmd).Apply(config)
+			password, err := resolveSecret(config.Password)
+			if err != nil {
+				return fmt.Errorf("读取注册中心密码失败: %v", err)
+			}
+
 			color.Green("开始测试Nacos注册中心连接...")
-			TestNacosRegistry()
+			TestNacosRegistry(address, config.Namespace, config.Username, password)
 			return nil
 		},
 	}
+	cmd.Flags().String("namespace", "", "命名空间，默认使用当前环境的命名空间")
+	return cmd
 }
 
 // version命令 - 显示版本信息
@@ -214,8 +290,11 @@ func newListCommand() *cobra.Command {
 
 示例:
   dubbo-invoke list                           # 列出所有服务
-  dubbo-invoke list com.example.UserService  # 列出指定服务的方法`,
-		RunE: runListCommand,
+  dubbo-invoke list com.example.UserService  # 列出指定服务的方法
+
+服务和方法会缓存到本地，注册中心不可用时使用缓存，参见 dubbo-invoke cache`,
+		ValidArgsFunction: completeServiceArgs,
+		RunE:              runListCommand,
 	}
 
 	cmd.Flags().BoolP("methods", "m", false, "显示服务方法")
@@ -229,20 +308,94 @@ func newConfigCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "config",
 		Short: "配置管理",
-		Long:  `管理dubbo-invoke的配置文件`,
+		Long: `管理dubbo-invoke的配置文件，配置文件默认位于 ~/.dubbo-invoke/config.yaml
+
+配置文件中可以定义多个环境(profiles)，每个环境包含注册中心、命名空间、认证信息、默认版本和分组、
+超时时间、隐式参数和字符集，未填写的项沿用文件顶层的基础配置。
+旧版格式的配置文件(service_version、数字形式的timeout等)在加载时自动迁移，原文件备份为 .bak。
+参数优先级: 命令行参数 > 环境变量(DUBBO_INVOKE_REGISTRY 等) > 所选环境 > 基础配置
+
+示例:
+  dubbo-invoke config init
+  dubbo-invoke config profiles
+  dubbo-invoke config use test
+  dubbo-invoke config show --profile prod
+  dubbo-invoke config set profiles.prod.timeout 5s
+  dubbo-invoke config get defaults.charset
+  dubbo-invoke config unset profiles.dev
+  dubbo-invoke config validate
+  dubbo-invoke invoke --profile pre 'com.example.UserService.getUserById(123)'`,
 	}
 
 	cmd.AddCommand(&cobra.Command{
 		Use:   "init",
 		Short: "初始化配置文件",
+		Args:  cobra.NoArgs,
 		RunE:  runConfigInitCommand,
 	})
 
 	cmd.AddCommand(&cobra.Command{
 		Use:   "show",
-		Short: "显示当前配置",
+		Short: "显示当前配置，指定 --profile 时显示合并后的环境配置",
+		Args:  cobra.NoArgs,
 		RunE:  runConfigShowCommand,
 	})
 
+	cmd.AddCommand(&cobra.Command{
+		Use:   "profiles",
+		Short: "列出配置文件中的环境",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigProfilesCommand,
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "use <profile>",
+		Short: "切换当前环境，使用 - 表示不使用任何环境",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runConfigUseCommand,
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get [key]",
+		Short: "显示配置项的值，不指定配置项时列出所有配置项",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runConfigGetCommand,
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "设置配置项，如 defaults.timeout 5s、profiles.prod.registry nacos://10.0.0.1:8848",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runConfigSetCommand,
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "unset <key>",
+		Short: "删除配置项或环境，删除后使用默认值",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runConfigUnsetCommand,
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "检查配置文件，列出所有问题及所在行",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigValidateCommand,
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "edit",
+		Short: "在编辑器($EDITOR)中修改配置文件，保存前检查",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigEditCommand,
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "path",
+		Short: "显示配置文件路径",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigPathCommand,
+	})
+
 	return cmd
 }
\ No newline at end of file
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-zookeeper/zk"
+)
+
+// ServiceDefinition Dubbo元数据中心上报的服务定义
+type ServiceDefinition struct {
+	CanonicalName string             `json:"canonicalName"`
+	Methods       []MethodDefinition `json:"methods"`
+	Types         []TypeDefinition   `json:"types"`
+}
+
+// MethodDefinition 服务定义中的方法
+type MethodDefinition struct {
+	Name           string   `json:"name"`
+	ParameterTypes []string `json:"parameterTypes"`
+	ReturnType     string   `json:"returnType"`
+}
+
+// TypeDefinition 服务定义中的类型，Properties为字段名到字段类型的映射
+// 兼容Dubbo 2.7（字段为嵌套的类型定义）和Dubbo 3（字段为类型名）两种格式
+type TypeDefinition struct {
+	Type       string            `json:"type"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Enums      []string          `json:"enums,omitempty"`
+	Required   []string          `json:"required,omitempty"` // 必填字段，元数据中心不提供，可在元数据文件中补充
+}
+
+// UnmarshalJSON 解析两种格式的字段定义
+func (td *TypeDefinition) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type       string                     `json:"type"`
+		Properties map[string]json.RawMessage `json:"properties"`
+		Enums      []string                   `json:"enums"`
+		Required   []string                   `json:"required"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	td.Type = raw.Type
+	td.Enums = raw.Enums
+	td.Required = raw.Required
+	td.Properties = make(map[string]string, len(raw.Properties))
+	for name, property := range raw.Properties {
+		var typeName string
+		if json.Unmarshal(property, &typeName) == nil {
+			td.Properties[name] = typeName
+			continue
+		}
+		var nested struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(property, &nested); err != nil {
+			return fmt.Errorf("无效的字段定义 %s.%s: %v", raw.Type, name, err)
+		}
+		td.Properties[name] = nested.Type
+	}
+	return nil
+}
+
+// MethodMetadata 调用前解析出的方法参数类型及相关类型定义
+type MethodMetadata struct {
+	ServiceName string                     `json:"serviceName"`
+	MethodName  string                     `json:"methodName"`
+	ParamTypes  []string                   `json:"paramTypes"`
+	Types       map[string]*TypeDefinition `json:"types,omitempty"` // 按类名索引
+	Source      string                     `json:"source"`          // 参数类型来源
+}
+
+// serviceDefinitionLookup 查找服务定义的方式，返回服务定义及其来源
+type serviceDefinitionLookup func(cfg *DubboConfig, serviceName string) (*ServiceDefinition, string, error)
+
+// ResolveMethodMetadata 解析方法的参数类型
+// 依次使用元数据文件、ZooKeeper元数据中心中的服务定义；显式指定的参数类型优先于服务定义中的方法签名。
+// 没有任何可用的元数据时返回nil
+func ResolveMethodMetadata(cfg *DubboConfig, serviceName, methodName string, paramTypes []string, argCount int) (*MethodMetadata, error) {
+	return resolveMethodMetadata(cfg, FindServiceDefinition, serviceName, methodName, paramTypes, argCount)
+}
+
+// resolveMethodMetadata 按lookup查找服务定义并解析方法的参数类型
+func resolveMethodMetadata(cfg *DubboConfig, lookup serviceDefinitionLookup, serviceName, methodName string, paramTypes []string, argCount int) (*MethodMetadata, error) {
+	definition, source, err := lookup(cfg, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &MethodMetadata{
+		ServiceName: serviceName,
+		MethodName:  methodName,
+		Types:       make(map[string]*TypeDefinition),
+	}
+	if definition != nil {
+		for i := range definition.Types {
+			metadata.Types[definition.Types[i].Type] = &definition.Types[i]
+		}
+	}
+
+	// 显式指定了全部参数类型时直接使用
+	if explicitTypesComplete(paramTypes, argCount) {
+		metadata.ParamTypes = paramTypes
+		metadata.Source = "types"
+		return metadata, nil
+	}
+
+	if definition == nil {
+		return nil, nil
+	}
+
+	// 按方法名和参数个数匹配方法签名，存在重载时优先选择参数个数一致的
+	var candidates []MethodDefinition
+	for _, method := range definition.Methods {
+		if method.Name == methodName {
+			candidates = append(candidates, method)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("服务 %s 中不存在方法 %s", serviceName, methodName)
+	}
+
+	selected := candidates[0]
+	matched := 0
+	for _, method := range candidates {
+		if len(method.ParameterTypes) == argCount {
+			if matched == 0 {
+				selected = method
+			}
+			matched++
+		}
+	}
+	if matched > 1 {
+		// 参数个数相同的重载无法区分，只能靠显式指定类型
+		fmt.Printf("方法 %s 存在 %d 个参数个数相同的重载，请通过 --types 指定参数类型\n", methodName, matched)
+		return nil, nil
+	}
+
+	metadata.ParamTypes = selected.ParameterTypes
+	metadata.Source = source
+	return metadata, nil
+}
+
+// FindServiceDefinition 依次从元数据文件、ZooKeeper元数据中心查找服务定义，返回服务定义及其来源
+// 没有找到时返回nil
+func FindServiceDefinition(cfg *DubboConfig, serviceName string) (*ServiceDefinition, string, error) {
+	if definition, source, err := findFileServiceDefinition(cfg, serviceName); err != nil || definition != nil {
+		return definition, source, err
+	}
+
+	if strings.HasPrefix(cfg.Registry, "zookeeper://") {
+		definition, err := getServiceDefinitionFromZooKeeper(strings.TrimPrefix(cfg.Registry, "zookeeper://"), serviceName)
+		if err != nil {
+			fmt.Printf("读取元数据中心失败，跳过: %v\n", err)
+		} else if definition != nil {
+			return definition, "zookeeper-metadata", nil
+		}
+	}
+	return nil, "", nil
+}
+
+// FindLocalServiceDefinition 只从元数据文件和本地缓存的服务目录查找服务定义，不访问注册中心和元数据中心
+// 服务目录中只有提供者注册的方法名、没有参数类型的服务视为没有服务定义
+func FindLocalServiceDefinition(cfg *DubboConfig, serviceName string) (*ServiceDefinition, string, error) {
+	if definition, source, err := findFileServiceDefinition(cfg, serviceName); err != nil || definition != nil {
+		return definition, source, err
+	}
+
+	catalog, err := LoadServiceCatalog(cfg)
+	if err != nil {
+		fmt.Printf("读取服务目录缓存失败，跳过: %v\n", err)
+		return nil, "", nil
+	}
+	service := catalog.Service(serviceName)
+	if service == nil || !hasParameterTypes(service) {
+		return nil, "", nil
+	}
+	definition := &ServiceDefinition{CanonicalName: serviceName, Methods: service.Methods, Types: service.Types}
+	return definition, "catalog:" + catalog.Path(), nil
+}
+
+// findFileServiceDefinition 从元数据文件查找服务定义，没有指定文件或文件中没有该服务时返回nil
+func findFileServiceDefinition(cfg *DubboConfig, serviceName string) (*ServiceDefinition, string, error) {
+	if cfg.MetadataFile == "" {
+		return nil, "", nil
+	}
+	definitions, err := LoadServiceDefinitions(cfg.MetadataFile)
+	if err != nil {
+		return nil, "", err
+	}
+	for i := range definitions {
+		if definitions[i].CanonicalName == serviceName {
+			return &definitions[i], "file:" + cfg.MetadataFile, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// hasParameterTypes 判断缓存的服务是否来自服务定义，只有方法名时无法区分无参方法和未知的参数类型
+func hasParameterTypes(service *CatalogService) bool {
+	if len(service.Types) > 0 {
+		return true
+	}
+	for _, method := range service.Methods {
+		if len(method.ParameterTypes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateInvokeArguments 调用前按解析出的方法元数据校验参数，返回的错误中列出所有问题
+// 没有可用的元数据时跳过校验
+func ValidateInvokeArguments(cfg *DubboConfig, serviceName, methodName string, paramTypes []string, args []interface{}) error {
+	return validateInvokeArguments(cfg, FindServiceDefinition, serviceName, methodName, paramTypes, args)
+}
+
+// ValidateInvokeArgumentsOffline dry-run使用的参数校验，只使用元数据文件和本地缓存的服务目录，不发起任何远程查询
+func ValidateInvokeArgumentsOffline(cfg *DubboConfig, serviceName, methodName string, paramTypes []string, args []interface{}) error {
+	return validateInvokeArguments(cfg, FindLocalServiceDefinition, serviceName, methodName, paramTypes, args)
+}
+
+// validateInvokeArguments 按lookup查找到的服务定义校验参数
+func validateInvokeArguments(cfg *DubboConfig, lookup serviceDefinitionLookup, serviceName, methodName string, paramTypes []string, args []interface{}) error {
+	metadata, err := resolveMethodMetadata(cfg, lookup, serviceName, methodName, paramTypes, len(args))
+	if err != nil {
+		return fmt.Errorf("参数校验失败: %v", err)
+	}
+	if metadata == nil {
+		fmt.Printf("未找到 %s.%s 的元数据，跳过参数校验\n", serviceName, methodName)
+		return nil
+	}
+
+	issues := NewTypeInferrer().ValidateArguments(args, metadata)
+	if len(issues) == 0 {
+		return nil
+	}
+
+	lines := make([]string, len(issues))
+	for i, issue := range issues {
+		lines[i] = "  " + issue.String()
+	}
+	return fmt.Errorf("参数校验失败 (参数类型来源: %s):\n%s", metadata.Source, strings.Join(lines, "\n"))
+}
+
+// explicitTypesComplete 判断是否显式指定了全部参数的类型
+func explicitTypesComplete(paramTypes []string, argCount int) bool {
+	if len(paramTypes) != argCount {
+		return false
+	}
+	for _, paramType := range paramTypes {
+		if paramType == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadServiceDefinitions 从文件加载服务定义，文件内容可以是单个服务定义或服务定义数组
+func LoadServiceDefinitions(file string) ([]ServiceDefinition, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取元数据文件失败: %v", err)
+	}
+
+	var definitions []ServiceDefinition
+	if err := json.Unmarshal(data, &definitions); err == nil {
+		return definitions, nil
+	}
+
+	var definition ServiceDefinition
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return nil, fmt.Errorf("解析元数据文件失败: %v", err)
+	}
+	return []ServiceDefinition{definition}, nil
+}
+
+// getServiceDefinitionFromZooKeeper 从ZooKeeper元数据中心读取服务定义
+// Dubbo 2.7的路径为 /dubbo/metadata/{service}[/{version}][/{group}]/provider/{application}，
+// 在该服务的元数据目录下查找第一个包含方法定义的节点
+func getServiceDefinitionFromZooKeeper(address, serviceName string) (*ServiceDefinition, error) {
+	zkConn, err := acquireZooKeeper(address)
+	if err != nil {
+		return nil, fmt.Errorf("连接ZooKeeper失败: %v", err)
+	}
+
+	root := fmt.Sprintf("/dubbo/metadata/%s", serviceName)
+	exists, _, err := zkConn.Exists(root)
+	if err != nil {
+		return nil, fmt.Errorf("检查元数据路径失败: %v", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	return findServiceDefinition(zkConn, root, 0)
+}
+
+// findServiceDefinition 在元数据目录下深度优先查找服务定义
+func findServiceDefinition(zkConn *zk.Conn, nodePath string, depth int) (*ServiceDefinition, error) {
+	if depth > 5 {
+		return nil, nil
+	}
+
+	data, _, err := zkConn.Get(nodePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取元数据节点失败 %s: %v", nodePath, err)
+	}
+	if len(data) > 0 {
+		var definition ServiceDefinition
+		if json.Unmarshal(data, &definition) == nil && len(definition.Methods) > 0 {
+			return &definition, nil
+		}
+	}
+
+	children, _, err := zkConn.Children(nodePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取元数据目录失败 %s: %v", nodePath, err)
+	}
+	for _, child := range children {
+		definition, err := findServiceDefinition(zkConn, path.Join(nodePath, child), depth+1)
+		if err != nil || definition != nil {
+			return definition, err
+		}
+	}
+	return nil, nil
+}
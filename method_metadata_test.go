@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateInvokeArgumentsOffline(t *testing.T) {
+	t.Setenv("DUBBO_INVOKE_HOME", t.TempDir())
+	// 注册中心地址不可达，dry-run的校验不应访问注册中心或元数据中心
+	cfg := &DubboConfig{Registry: "zookeeper://127.0.0.1:1"}
+
+	catalog, err := LoadServiceCatalog(cfg)
+	if err != nil {
+		t.Fatalf("加载服务目录失败: %v", err)
+	}
+	catalog.SetServices([]string{"com.example.UserService", "com.example.OrderService"})
+	catalog.SetMethods("com.example.UserService", []MethodDefinition{
+		{Name: "getUserById", ParameterTypes: []string{"java.lang.Long"}},
+	}, nil)
+	// 只有提供者注册的方法名，没有参数类型
+	catalog.SetMethods("com.example.OrderService", []MethodDefinition{{Name: "getOrder"}}, nil)
+	if err := catalog.Save(); err != nil {
+		t.Fatalf("保存服务目录失败: %v", err)
+	}
+
+	definition, source, err := FindLocalServiceDefinition(cfg, "com.example.UserService")
+	if err != nil || definition == nil || !strings.HasPrefix(source, "catalog:") {
+		t.Fatalf("应从服务目录缓存找到服务定义，实际 %v %q %v", definition, source, err)
+	}
+	if err := ValidateInvokeArgumentsOffline(cfg, "com.example.UserService", "getUserById", nil, []interface{}{"abc"}); err == nil {
+		t.Error("参数类型不匹配时应返回错误")
+	}
+	if err := ValidateInvokeArgumentsOffline(cfg, "com.example.UserService", "getUserById", nil, []interface{}{int64(1)}); err != nil {
+		t.Errorf("参数正确时不应返回错误: %v", err)
+	}
+
+	for _, service := range []string{"com.example.OrderService", "com.example.UnknownService"} {
+		if definition, _, err := FindLocalServiceDefinition(cfg, service); definition != nil || err != nil {
+			t.Errorf("%s 没有参数类型，应跳过校验，实际 %v %v", service, definition, err)
+		}
+	}
+	if err := ValidateInvokeArgumentsOffline(cfg, "com.example.OrderService", "getOrder", nil, []interface{}{1}); err != nil {
+		t.Errorf("没有服务定义时应跳过校验: %v", err)
+	}
+}
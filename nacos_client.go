@@ -107,6 +107,15 @@ func NewNacosClientWithAuth(serverAddr, namespace, groupName, username, password
 	}
 }
 
+// get 发送GET请求，请求地址中可能带有密码参数，错误信息中隐藏密码
+func (nc *NacosClient) get(rawURL string) (*http.Response, error) {
+	resp, err := nc.Client.Get(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s", redactSecrets(err.Error()))
+	}
+	return resp, nil
+}
+
 // TestConnection 测试与Nacos服务器的连接
 func (nc *NacosClient) TestConnection() error {
 	// 构建健康检查URL
@@ -191,9 +200,9 @@ func (nc *NacosClient) GetServiceList() (*NacosServiceList, error) {
 		}
 		
 		fullURL := fmt.Sprintf("%s?%s", serviceListURL, params.Encode())
-		fmt.Printf("请求URL: %s\n", fullURL)
+		fmt.Printf("请求URL: %s\n", redactSecrets(fullURL))
 		
-		resp, err := nc.Client.Get(fullURL)
+		resp, err := nc.get(fullURL)
 		if err != nil {
 			fmt.Printf("❌ 请求失败: %v\n", err)
 			continue
@@ -301,9 +310,9 @@ func (nc *NacosClient) GetNamespaces() ([]NamespaceInfo, error) {
 		if len(params) > 0 {
 			namespaceURL = fmt.Sprintf("%s?%s", namespaceURL, params.Encode())
 		}
-		fmt.Printf("\n尝试命名空间API端点 %d: %s\n", i+1, namespaceURL)
+		fmt.Printf("\n尝试命名空间API端点 %d: %s\n", i+1, redactSecrets(namespaceURL))
 		
-		resp, err := nc.Client.Get(namespaceURL)
+		resp, err := nc.get(namespaceURL)
 		if err != nil {
 			fmt.Printf("❌ 请求失败: %v\n", err)
 			continue
@@ -368,9 +377,9 @@ func (nc *NacosClient) GetServiceDetail(serviceName string) (*NacosService, erro
 	}
 	
 	fullURL := fmt.Sprintf("%s?%s", serviceDetailURL, params.Encode())
-	fmt.Printf("正在获取服务详情: %s\n", fullURL)
+	fmt.Printf("正在获取服务详情: %s\n", redactSecrets(fullURL))
 	
-	resp, err := nc.Client.Get(fullURL)
+	resp, err := nc.get(fullURL)
 	if err != nil {
 		return nil, fmt.Errorf("获取服务详情失败: %v", err)
 	}
@@ -476,22 +485,14 @@ func (nc *NacosClient) LoadAvailableServices() ([]ServiceInfo, error) {
 	return allServices, nil
 }
 
-// TestNacosRegistry 测试Nacos注册中心功能
-func TestNacosRegistry() {
+// TestNacosRegistry 测试Nacos注册中心功能，地址、命名空间和认证信息来自当前环境
+func TestNacosRegistry(nacosAddr, namespace, username, password string) {
 	fmt.Println("=== Nacos注册中心测试开始 ===")
 	
-	// 解析Nacos地址
-	nacosAddr := "yjj-nacos.it.yyjzt.com:28848"
 	fmt.Printf("测试Nacos地址: %s\n", nacosAddr)
 	
-	// 创建Nacos客户端，使用项目配置
-	client := NewNacosClientWithAuth(
-		nacosAddr,
-		"c946d14d-cd12-4baf-94a5-55c3adf17a68", // dev环境命名空间
-		"DEFAULT_GROUP",
-		"nacos",
-		"nacos",
-	)
+	// 创建Nacos客户端
+	client := NewNacosClientWithAuth(nacosAddr, namespace, "DEFAULT_GROUP", username, password)
 	
 	// 1. 测试连接
 	fmt.Println("\n1. 测试Nacos连接...")
@@ -580,6 +581,21 @@ func TestNacosRegistry() {
 }
 
 // RunNacosTest 运行Nacos测试的入口函数
-func RunNacosTest() {
-	TestNacosRegistry()
-}
\ No newline at end of file
+func RunNacosTest(nacosAddr, namespace, username, password string) {
+	TestNacosRegistry(nacosAddr, namespace, username, password)
+}
+// parseNacosServiceName 解析Dubbo在Nacos中注册的服务名 providers:接口:版本:分组，不是该形式时ok为false
+func parseNacosServiceName(name string) (iface, version, group string, ok bool) {
+	parts := strings.Split(name, ":")
+	if len(parts) < 2 || parts[0] != "providers" || parts[1] == "" {
+		return "", "", "", false
+	}
+	iface = parts[1]
+	if len(parts) > 2 {
+		version = parts[2]
+	}
+	if len(parts) > 3 {
+		group = parts[3]
+	}
+	return iface, version, group, true
+}
@@ -0,0 +1,492 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// postmanSchema Postman v2.1 集合格式
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// postmanDefaultBaseURL 导出时 {{baseUrl}} 的默认值，即本工具Web端的地址
+const postmanDefaultBaseURL = "http://localhost:8080"
+
+// postmanBareVarPattern 匹配JSON中未加引号的变量，如 {"companyId": {{companyId}}}
+var postmanBareVarPattern = regexp.MustCompile(`([:\[,]\s*)(\{\{\s*[A-Za-z_][A-Za-z0-9_.]*\s*\}\})`)
+
+// gateway payload 中各字段可能使用的键名，按优先级排列，匹配时不区分大小写
+var (
+	gatewayServiceKeys = []string{"serviceName", "service", "interfaceName", "interface", "className"}
+	gatewayMethodKeys  = []string{"methodName", "method"}
+	gatewayParamKeys   = []string{"parameters", "params", "args", "arguments", "paramValues"}
+	gatewayTypeKeys    = []string{"types", "parameterTypes", "paramTypes", "argTypes"}
+)
+
+// PostmanCollection Postman v2.1 集合，只包含与调用有关的字段
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+// PostmanInfo 集合信息
+type PostmanInfo struct {
+	Name        string          `json:"name"`
+	Description json.RawMessage `json:"description,omitempty"` // 字符串或 {"content": "..."}
+	Schema      string          `json:"schema"`
+}
+
+// PostmanItem 请求或文件夹，带有item的是文件夹
+type PostmanItem struct {
+	Name        string          `json:"name"`
+	Description json.RawMessage `json:"description,omitempty"`
+	Item        []PostmanItem   `json:"item,omitempty"`
+	Request     *PostmanRequest `json:"request,omitempty"`
+}
+
+// PostmanRequest HTTP请求
+type PostmanRequest struct {
+	Method      string          `json:"method"`
+	Header      []PostmanHeader `json:"header"`
+	Body        *PostmanBody    `json:"body,omitempty"`
+	URL         json.RawMessage `json:"url"` // 字符串或 {"raw": "..."}
+	Description json.RawMessage `json:"description,omitempty"`
+}
+
+// PostmanHeader 请求头
+type PostmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// PostmanBody 请求体，只支持raw模式
+type PostmanBody struct {
+	Mode    string                 `json:"mode"`
+	Raw     string                 `json:"raw"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// postmanInvokeBody 导出的请求体，字段与Web端的 InvokeRequest 相同
+type postmanInvokeBody struct {
+	ServiceName string        `json:"serviceName"`
+	MethodName  string        `json:"methodName"`
+	Parameters  []interface{} `json:"parameters"`
+	Types       []string      `json:"types,omitempty"`
+	Registry    string        `json:"registry"`
+	Namespace   string        `json:"namespace,omitempty"`
+	Version     string        `json:"version,omitempty"`
+	Group       string        `json:"group,omitempty"`
+	Tag         string        `json:"tag,omitempty"`
+	Timeout     int           `json:"timeout,omitempty"`
+	Expect      []string      `json:"expect,omitempty"`
+}
+
+// PostmanVariable 集合变量
+type PostmanVariable struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// IsPostmanCollection 判断JSON是否为Postman集合
+func IsPostmanCollection(data []byte) bool {
+	var probe struct {
+		Info struct {
+			Schema string `json:"schema"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return strings.Contains(probe.Info.Schema, "schema.getpostman.com")
+}
+
+// ImportPostman 将Postman v2.1集合转换为请求集合
+// 请求体像Dubbo网关报文(含服务名、方法名和参数)的请求会被导入，其他请求跳过并在返回的警告中列出
+func ImportPostman(data []byte, name string) (*Collection, []string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var postman PostmanCollection
+	if err := decoder.Decode(&postman); err != nil {
+		return nil, nil, fmt.Errorf("解析Postman集合失败: %v", err)
+	}
+	if !strings.Contains(postman.Info.Schema, "v2.1") && !strings.Contains(postman.Info.Schema, "v2.0") {
+		return nil, nil, fmt.Errorf("只支持Postman v2.0/v2.1格式的集合，当前为: %s", postman.Info.Schema)
+	}
+
+	collection := &Collection{
+		Name:        sanitizeCollectionName(firstNonEmpty(name, postman.Info.Name), "postman"),
+		Description: postmanText(postman.Info.Description),
+	}
+	if len(postman.Variable) > 0 {
+		collection.Variables = make(map[string]interface{}, len(postman.Variable))
+		for _, variable := range postman.Variable {
+			if variable.Key != "" {
+				collection.Variables[variable.Key] = convertJSONNumber(variable.Value)
+			}
+		}
+	}
+	// 导出时加入的 {{registry}} 还原为集合的默认注册中心，默认的 {{baseUrl}} 只用于Postman发送请求
+	if registry, ok := collection.Variables["registry"].(string); ok {
+		collection.Registry = registry
+		delete(collection.Variables, "registry")
+	}
+	if collection.Variables["baseUrl"] == postmanDefaultBaseURL {
+		delete(collection.Variables, "baseUrl")
+	}
+
+	var warnings []string
+	collection.Requests, collection.Folders = importPostmanItems(postman.Item, "", &warnings)
+	if len(collection.RequestPaths()) == 0 {
+		return nil, warnings, fmt.Errorf("Postman集合中没有可以识别为Dubbo调用的请求")
+	}
+	if err := collection.Validate(); err != nil {
+		return nil, warnings, err
+	}
+	return collection, warnings, nil
+}
+
+// importPostmanItems 递归转换请求和文件夹，同一文件夹中重名的按出现顺序加上序号
+func importPostmanItems(items []PostmanItem, prefix string, warnings *[]string) ([]SavedRequest, []CollectionFolder) {
+	var requests []SavedRequest
+	var folders []CollectionFolder
+	used := make(map[string]bool)
+	uniqueName := func(name string) string {
+		unique := name
+		for i := 2; used[unique]; i++ {
+			unique = fmt.Sprintf("%s (%d)", name, i)
+		}
+		used[unique] = true
+		return unique
+	}
+
+	for i, item := range items {
+		name := sanitizeCollectionName(item.Name, fmt.Sprintf("request-%d", i+1))
+		if item.Request == nil {
+			folderName := uniqueName(name)
+			subRequests, subFolders := importPostmanItems(item.Item, prefix+folderName+"/", warnings)
+			if len(subRequests) == 0 && len(subFolders) == 0 {
+				continue
+			}
+			folders = append(folders, CollectionFolder{Name: folderName, Requests: subRequests, Folders: subFolders})
+			continue
+		}
+
+		request, err := postmanToSavedRequest(item.Request)
+		if err != nil {
+			*warnings = append(*warnings, fmt.Sprintf("跳过 %s%s: %v", prefix, item.Name, err))
+			continue
+		}
+		request.Name = uniqueName(name)
+		request.Description = firstNonEmpty(postmanText(item.Description), postmanText(item.Request.Description))
+		requests = append(requests, *request)
+	}
+	return requests, folders
+}
+
+// postmanToSavedRequest 从请求体中识别Dubbo调用
+// 支持 {"service": ..., "method": ..., "params": [...]} 形式的网关报文，以及URL以 /<服务名>/<方法名> 结尾、请求体为参数数组的请求
+func postmanToSavedRequest(request *PostmanRequest) (*SavedRequest, error) {
+	if request.Body == nil || strings.TrimSpace(request.Body.Raw) == "" {
+		return nil, fmt.Errorf("没有请求体")
+	}
+	if request.Body.Mode != "" && request.Body.Mode != "raw" {
+		return nil, fmt.Errorf("不支持 %s 格式的请求体", request.Body.Mode)
+	}
+	body, err := parsePostmanJSON(request.Body.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	switch payload := body.(type) {
+	case map[string]interface{}:
+		saved := &SavedRequest{
+			Service: gatewayString(payload, gatewayServiceKeys),
+			Method:  gatewayString(payload, gatewayMethodKeys),
+		}
+		if saved.Service == "" || saved.Method == "" {
+			return nil, fmt.Errorf("请求体中没有服务名和方法名")
+		}
+		switch params := gatewayValue(payload, gatewayParamKeys).(type) {
+		case nil:
+		case []interface{}:
+			saved.Params = params
+		default:
+			saved.Params = []interface{}{params}
+		}
+		if types, ok := gatewayValue(payload, gatewayTypeKeys).([]interface{}); ok {
+			for _, paramType := range types {
+				saved.Types = append(saved.Types, cellText(paramType))
+			}
+		}
+		saved.Version = gatewayString(payload, []string{"version"})
+		saved.Group = gatewayString(payload, []string{"group"})
+		saved.Tag = gatewayString(payload, []string{"tag"})
+		saved.Namespace = gatewayString(payload, []string{"namespace"})
+		if registry := gatewayString(payload, []string{"registry"}); registry != "{{registry}}" {
+			saved.Registry = registry
+		}
+		if timeout, ok := gatewayValue(payload, []string{"timeout"}).(int64); ok {
+			saved.Timeout = int(timeout)
+		}
+		if expects, ok := gatewayValue(payload, []string{"expect"}).([]interface{}); ok {
+			for _, expect := range expects {
+				saved.Expect = append(saved.Expect, cellText(expect))
+			}
+		}
+		return saved, nil
+	case []interface{}:
+		segments := strings.Split(strings.Trim(postmanURLPath(request.URL), "/"), "/")
+		if len(segments) >= 2 {
+			service, method := segments[len(segments)-2], segments[len(segments)-1]
+			if strings.Contains(service, ".") && method != "" && !strings.Contains(method, ".") {
+				return &SavedRequest{Service: service, Method: method, Params: payload}, nil
+			}
+		}
+		return nil, fmt.Errorf("请求体为数组，但URL不是 /<服务名>/<方法名> 的形式")
+	default:
+		return nil, fmt.Errorf("请求体不是JSON对象或数组")
+	}
+}
+
+// parsePostmanJSON 解析请求体，未加引号的 {{变量}} 先加上引号，整个值为变量时替换后保留变量的类型
+func parsePostmanJSON(raw string) (interface{}, error) {
+	parse := func(text string) (interface{}, error) {
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		if decoder.More() {
+			return nil, fmt.Errorf("请求体包含多个JSON值")
+		}
+		return convertJSONNumber(value), nil
+	}
+
+	value, err := parse(raw)
+	if err != nil {
+		value, err = parse(postmanBareVarPattern.ReplaceAllString(raw, `$1"$2"`))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("请求体不是JSON: %v", err)
+	}
+	return value, nil
+}
+
+// gatewayValue 按候选键名(不区分大小写)查找字段
+func gatewayValue(payload map[string]interface{}, keys []string) interface{} {
+	for _, key := range keys {
+		if value, exists := payload[key]; exists {
+			return value
+		}
+	}
+	for _, key := range keys {
+		for name, value := range payload {
+			if strings.EqualFold(name, key) {
+				return value
+			}
+		}
+	}
+	return nil
+}
+
+// gatewayString 按候选键名查找字符串字段
+func gatewayString(payload map[string]interface{}, keys []string) string {
+	value, _ := gatewayValue(payload, keys).(string)
+	return strings.TrimSpace(value)
+}
+
+// postmanText 读取字符串或 {"content": "..."} 形式的描述
+func postmanText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	var description struct {
+		Content string `json:"content"`
+	}
+	json.Unmarshal(raw, &description)
+	return description.Content
+}
+
+// postmanURLPath 读取字符串或对象形式的URL中的路径部分
+func postmanURLPath(raw json.RawMessage) string {
+	var rawURL string
+	if json.Unmarshal(raw, &rawURL) != nil {
+		var object struct {
+			Raw  string   `json:"raw"`
+			Path []string `json:"path"`
+		}
+		json.Unmarshal(raw, &object)
+		if len(object.Path) > 0 {
+			return strings.Join(object.Path, "/")
+		}
+		rawURL = object.Raw
+	}
+	rawURL = strings.SplitN(rawURL, "?", 2)[0]
+	if index := strings.Index(rawURL, "://"); index >= 0 {
+		rawURL = rawURL[index+3:]
+	}
+	if index := strings.Index(rawURL, "/"); index >= 0 {
+		return rawURL[index:]
+	}
+	return ""
+}
+
+// sanitizeCollectionName 替换名称中不允许的字符，为空时使用fallback
+func sanitizeCollectionName(name, fallback string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// ExportPostman 将请求集合转换为Postman v2.1集合
+// 每个请求导出为 POST {{baseUrl}}/api/invoke，请求体与本工具Web端的调用接口相同，可以直接在Postman中执行
+// 当前环境的变量合并到集合变量中，未指定注册中心的请求使用 {{registry}} 变量
+func ExportPostman(collection *Collection) *PostmanCollection {
+	environment := collection.Environments[collection.Environment]
+	variables := make(map[string]interface{})
+	variables["baseUrl"] = postmanDefaultBaseURL
+	for name, value := range collection.Variables {
+		variables[name] = value
+	}
+	for name, value := range environment.Variables {
+		variables[name] = value
+	}
+	if registry := firstNonEmpty(environment.Registry, collection.Registry); registry != "" {
+		variables["registry"] = registry
+	}
+
+	description, _ := json.Marshal(collection.Description)
+	postman := &PostmanCollection{
+		Info: PostmanInfo{Name: collection.Name, Schema: postmanSchema},
+		Item: exportPostmanItems(collection.Requests, collection.Folders, environment),
+	}
+	if collection.Description != "" {
+		postman.Info.Description = description
+	}
+
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		postman.Variable = append(postman.Variable, PostmanVariable{Key: name, Value: variables[name]})
+	}
+	return postman
+}
+
+// exportPostmanItems 递归转换请求和文件夹
+func exportPostmanItems(requests []SavedRequest, folders []CollectionFolder, environment CollectionEnvironment) []PostmanItem {
+	items := make([]PostmanItem, 0, len(requests)+len(folders))
+	for _, request := range requests {
+		params := request.Params
+		if params == nil {
+			params = []interface{}{}
+		}
+		payload := postmanInvokeBody{
+			ServiceName: request.Service,
+			MethodName:  request.Method,
+			Parameters:  params,
+			Types:       request.Types,
+			Registry:    firstNonEmpty(request.Registry, "{{registry}}"),
+			Namespace:   firstNonEmpty(request.Namespace, environment.Namespace),
+			Version:     request.Version,
+			Group:       request.Group,
+			Tag:         request.Tag,
+			Timeout:     request.Timeout,
+			Expect:      request.Expect,
+		}
+
+		var body bytes.Buffer
+		encoder := json.NewEncoder(&body)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(payload)
+
+		item := PostmanItem{
+			Name: request.Name,
+			Request: &PostmanRequest{
+				Method: "POST",
+				Header: []PostmanHeader{{Key: "Content-Type", Value: "application/json"}},
+				Body: &PostmanBody{
+					Mode:    "raw",
+					Raw:     strings.TrimSuffix(body.String(), "\n"),
+					Options: map[string]interface{}{"raw": map[string]string{"language": "json"}},
+				},
+				URL: json.RawMessage(`{"raw":"{{baseUrl}}/api/invoke","host":["{{baseUrl}}"],"path":["api","invoke"]}`),
+			},
+		}
+		if request.Description != "" {
+			item.Description, _ = json.Marshal(request.Description)
+		}
+		items = append(items, item)
+	}
+	for _, folder := range folders {
+		items = append(items, PostmanItem{
+			Name: folder.Name,
+			Item: exportPostmanItems(folder.Requests, folder.Folders, environment),
+		})
+	}
+	return items
+}
+
+// ParseCollectionImport 解析导入的文件：Postman集合按ImportPostman转换，否则按集合YAML(或JSON)解析
+func ParseCollectionImport(data []byte, name string) (*Collection, []string, error) {
+	if IsPostmanCollection(data) {
+		return ImportPostman(data, name)
+	}
+
+	var collection Collection
+	if err := yaml.Unmarshal(data, &collection); err != nil {
+		return nil, nil, fmt.Errorf("无法识别导入文件，应为Postman v2.1集合或集合YAML文件: %v", err)
+	}
+	if name != "" {
+		collection.Name = name
+	}
+	if err := collection.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return &collection, nil, nil
+}
+
+// MarshalCollectionExport 按指定格式导出集合: yaml 或 postman
+func MarshalCollectionExport(collection *Collection, format string) ([]byte, error) {
+	switch format {
+	case "yaml", "":
+		data, err := yaml.Marshal(collection)
+		if err != nil {
+			return nil, fmt.Errorf("生成集合文件失败: %v", err)
+		}
+		return data, nil
+	case "postman":
+		var buffer bytes.Buffer
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(ExportPostman(collection)); err != nil {
+			return nil, fmt.Errorf("生成Postman集合失败: %v", err)
+		}
+		return buffer.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("不支持的导出格式: %s (可选: yaml, postman)", format)
+	}
+}
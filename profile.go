@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// profileEnvOverrides 覆盖环境配置的环境变量，优先级: 命令行参数 > 环境变量 > 环境配置 > 基础配置
+var profileEnvOverrides = []struct {
+	name string
+	set  func(p *ProfileConfig, value string)
+}{
+	{"DUBBO_INVOKE_REGISTRY", func(p *ProfileConfig, v string) { p.Registry = v }},
+	{"DUBBO_INVOKE_NAMESPACE", func(p *ProfileConfig, v string) { p.Namespace = v }},
+	{"DUBBO_INVOKE_USERNAME", func(p *ProfileConfig, v string) { p.Username = v }},
+	{"DUBBO_INVOKE_PASSWORD", func(p *ProfileConfig, v string) { p.Password = v }},
+	{"DUBBO_INVOKE_APP", func(p *ProfileConfig, v string) { p.App = v }},
+	{"DUBBO_INVOKE_VERSION", func(p *ProfileConfig, v string) { p.Version = v }},
+	{"DUBBO_INVOKE_GROUP", func(p *ProfileConfig, v string) { p.Group = v }},
+	{"DUBBO_INVOKE_TIMEOUT", func(p *ProfileConfig, v string) { p.Timeout = v }},
+	{"DUBBO_INVOKE_CHARSET", func(p *ProfileConfig, v string) { p.Charset = v }},
+}
+
+// ActiveProfile 本次执行使用的环境配置，已合并基础配置和环境变量
+type ActiveProfile struct {
+	Name     string // 环境名称，为空表示只使用基础配置
+	Explicit bool   // 通过 --profile 显式指定
+	ProfileConfig
+}
+
+// activeProfileKey 在命令上下文中保存 ActiveProfile 的键
+type activeProfileKey struct{}
+
+// LoadActiveProfile 加载配置文件并确定使用的环境，name为空时依次使用 DUBBO_INVOKE_PROFILE 环境变量和配置文件中的 current
+// 配置文件不存在时只应用环境变量
+func LoadActiveProfile(configPath, name string) (*ActiveProfile, error) {
+	cm := NewConfigManagerAt(configPath)
+	if name == "" {
+		name = os.Getenv("DUBBO_INVOKE_PROFILE")
+	}
+
+	profile := &ActiveProfile{Name: name}
+	if cm.Exists() {
+		if err := cm.LoadConfig(); err != nil {
+			return nil, err
+		}
+		if profile.Name == "" {
+			profile.Name = cm.CurrentProfile()
+		}
+		resolved, err := cm.ResolveProfile(profile.Name)
+		if err != nil {
+			return nil, err
+		}
+		profile.ProfileConfig = *resolved
+	} else if name != "" {
+		return nil, fmt.Errorf("环境 %s 不存在，配置文件 %s 不存在，请先执行 dubbo-invoke config init", name, cm.GetConfigPath())
+	}
+
+	for _, override := range profileEnvOverrides {
+		if value := os.Getenv(override.name); value != "" {
+			override.set(&profile.ProfileConfig, value)
+		}
+	}
+	if profile.Timeout != "" {
+		if _, err := parseConfigTimeout(profile.Timeout); err != nil {
+			return nil, fmt.Errorf("环境 %s 的超时时间格式无效: %s", firstNonEmpty(profile.Name, "默认"), profile.Timeout)
+		}
+	}
+	if !validCharset(profile.Charset) {
+		return nil, fmt.Errorf("不支持的字符集: %s (可选: GBK, GB18030, UTF-8)", profile.Charset)
+	}
+	return profile, nil
+}
+
+// applyActiveProfile 执行命令前加载当前环境，作为未在命令行指定的全局参数的默认值
+// 通过 --profile 显式指定环境时等同于在命令行指定了这些参数，会覆盖集合、历史记录和录制文件中的注册中心
+func applyActiveProfile(cmd *cobra.Command, args []string) error {
+	// config命令用于查看和修复配置文件，配置有误时也需要能够执行；secret命令不使用环境
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "config" || c.Name() == "secret" {
+			return nil
+		}
+	}
+
+	configPath, _ := cmd.Flags().GetString("config")
+	name, _ := cmd.Flags().GetString("profile")
+	profile, err := LoadActiveProfile(configPath, name)
+	if err != nil {
+		return err
+	}
+	profile.Explicit = cmd.Flags().Changed("profile")
+
+	profile.setFlag(cmd, "registry", profile.Registry)
+	profile.setFlag(cmd, "app", profile.App)
+	if timeout, _ := parseConfigTimeout(profile.Timeout); timeout > 0 {
+		profile.setFlag(cmd, "timeout", strconv.FormatInt(timeout.Milliseconds(), 10))
+	}
+
+	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose && profile.Name != "" {
+		color.Cyan("使用环境: %s", profile.Name)
+	}
+	cmd.SetContext(context.WithValue(cmd.Context(), activeProfileKey{}, profile))
+	return nil
+}
+
+// setFlag 将环境中的值设置为参数的值，命令行已指定的参数不覆盖
+func (p *ActiveProfile) setFlag(cmd *cobra.Command, name, value string) {
+	flag := cmd.Flags().Lookup(name)
+	if flag == nil || flag.Changed || value == "" {
+		return
+	}
+	if p.Explicit {
+		cmd.Flags().Set(name, value)
+		return
+	}
+	// 直接修改值，不标记为已指定，集合等自带配置的命令仍优先使用自己的配置
+	flag.Value.Set(value)
+}
+
+// activeProfile 返回命令上下文中的当前环境，未加载时返回nil
+func activeProfile(cmd *cobra.Command) *ActiveProfile {
+	if cmd.Context() == nil {
+		return nil
+	}
+	profile, _ := cmd.Context().Value(activeProfileKey{}).(*ActiveProfile)
+	return profile
+}
+
+// profileName 返回环境名称，未使用环境时为空
+func profileName(profile *ActiveProfile) string {
+	if profile == nil {
+		return ""
+	}
+	return profile.Name
+}
+
+// Apply 将环境中的命名空间、注册中心认证、默认版本和分组、隐式参数和字符集填入调用配置
+// 调用配置中已有的值优先；显式指定环境时命名空间以环境为准，避免沿用其他环境的命名空间
+func (p *ActiveProfile) Apply(config *DubboConfig) {
+	if p == nil || config == nil {
+		return
+	}
+	if p.Explicit {
+		config.Namespace = firstNonEmpty(p.Namespace, config.Namespace)
+	} else {
+		config.Namespace = firstNonEmpty(config.Namespace, p.Namespace)
+	}
+	if config.Username == "" && config.Password == "" {
+		config.Username = p.Username
+		config.Password = p.Password
+	}
+	config.Version = firstNonEmpty(config.Version, p.Version)
+	config.Group = firstNonEmpty(config.Group, p.Group)
+	config.Charset = firstNonEmpty(config.Charset, p.Charset)
+	if len(p.Attachments) > 0 {
+		attachments := make(map[string]string, len(p.Attachments)+len(config.Attachments))
+		for key, value := range p.Attachments {
+			attachments[key] = value
+		}
+		for key, value := range config.Attachments {
+			attachments[key] = value
+		}
+		config.Attachments = attachments
+	}
+}
+
+// ProfileSummary Web端展示的环境信息，不包含密码
+type ProfileSummary struct {
+	Name string `json:"name"`
+	ProfileConfig
+	TimeoutMillis int64 `json:"timeoutMs,omitempty"`
+	HasPassword   bool  `json:"hasPassword,omitempty"`
+}
+
+// handleProfiles 处理 /api/profiles，列出与CLI共用的配置文件中的环境
+func (ws *WebServer) handleProfiles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		ws.writeError(w, "只支持GET方法")
+		return
+	}
+	cm := NewConfigManagerAt(ws.configPath)
+	if err := cm.LoadConfig(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		ws.writeError(w, err.Error())
+		return
+	}
+
+	profiles := make([]ProfileSummary, 0, len(cm.ProfileNames()))
+	for _, name := range cm.ProfileNames() {
+		resolved, err := cm.ResolveProfile(name)
+		if err != nil {
+			continue
+		}
+		timeout, _ := parseConfigTimeout(resolved.Timeout)
+		profiles = append(profiles, ProfileSummary{
+			Name:          name,
+			ProfileConfig: *resolved,
+			TimeoutMillis: timeout.Milliseconds(),
+			HasPassword:   resolved.Password != "",
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(map[string]interface{}{
+		"success":    true,
+		"current":    firstNonEmpty(ws.profile, os.Getenv("DUBBO_INVOKE_PROFILE"), cm.CurrentProfile()),
+		"configPath": cm.GetConfigPath(),
+		"profiles":   profiles,
+	})
+}
+
+// applyProfile 将请求指定的环境应用到调用配置，未指定时使用启动时的 --profile 或配置文件中的当前环境
+func (ws *WebServer) applyProfile(name string, config *DubboConfig) error {
+	profile, err := LoadActiveProfile(ws.configPath, firstNonEmpty(strings.TrimSpace(name), ws.profile))
+	if err != nil {
+		return err
+	}
+	profile.Apply(config)
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const profileTestConfig = `registry:
+  address: "zookeeper://127.0.0.1:2181"
+  username: "base"
+  password: "base-secret"
+application:
+  name: "dubbo-invoke-cli"
+defaults:
+  timeout: "3s"
+  version: "1.0.0"
+  charset: "GBK"
+  attachments:
+    tenant: "t0"
+    trace: "on"
+current: "dev"
+profiles:
+  dev:
+    description: "开发环境"
+  pre:
+    registry: "nacos://127.0.0.1:8848"
+    namespace: "pre"
+    username: "pre"
+    timeout: "5s"
+    attachments:
+      tenant: "t1"
+`
+
+func TestLoadActiveProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(profileTestConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DUBBO_INVOKE_PROFILE", "")
+
+	tests := []struct {
+		name    string
+		profile string
+		env     map[string]string
+		want    ProfileConfig
+	}{
+		{
+			name: "使用配置文件中的当前环境",
+			want: ProfileConfig{
+				Description: "开发环境", Registry: "zookeeper://127.0.0.1:2181", Username: "base", Password: "base-secret",
+				App: "dubbo-invoke-cli", Version: "1.0.0", Timeout: "3s", Charset: "GBK",
+				Attachments: map[string]string{"tenant": "t0", "trace": "on"},
+			},
+		},
+		{
+			// 环境只填写了用户名时不沿用基础配置的密码
+			name:    "环境覆盖基础配置",
+			profile: "pre",
+			want: ProfileConfig{
+				Registry: "nacos://127.0.0.1:8848", Namespace: "pre", Username: "pre",
+				App: "dubbo-invoke-cli", Version: "1.0.0", Timeout: "5s", Charset: "GBK",
+				Attachments: map[string]string{"tenant": "t1", "trace": "on"},
+			},
+		},
+		{
+			name:    "环境变量覆盖环境配置",
+			profile: "pre",
+			env:     map[string]string{"DUBBO_INVOKE_NAMESPACE": "gray", "DUBBO_INVOKE_TIMEOUT": "10s"},
+			want: ProfileConfig{
+				Registry: "nacos://127.0.0.1:8848", Namespace: "gray", Username: "pre",
+				App: "dubbo-invoke-cli", Version: "1.0.0", Timeout: "10s", Charset: "GBK",
+				Attachments: map[string]string{"tenant": "t1", "trace": "on"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, override := range profileEnvOverrides {
+				t.Setenv(override.name, tt.env[override.name])
+			}
+			profile, err := LoadActiveProfile(path, tt.profile)
+			if err != nil {
+				t.Fatalf("加载环境失败: %v", err)
+			}
+			if got := profile.ProfileConfig; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("合并后的环境为 %+v，期望 %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadActiveProfile(path, "prod"); err == nil {
+		t.Error("不存在的环境应返回错误")
+	}
+}
+
+func TestActiveProfileApply(t *testing.T) {
+	profile := &ActiveProfile{
+		Name: "pre",
+		ProfileConfig: ProfileConfig{
+			Namespace: "pre", Username: "pre", Password: "secret", Version: "1.0.0",
+			Attachments: map[string]string{"tenant": "t1", "trace": "on"},
+		},
+	}
+
+	// 调用配置中已有的值优先
+	config := &DubboConfig{Namespace: "dev", Version: "2.0.0", Attachments: map[string]string{"tenant": "t2"}}
+	profile.Apply(config)
+	if config.Namespace != "dev" || config.Version != "2.0.0" || config.Username != "pre" || config.Password != "secret" {
+		t.Errorf("应用环境后的配置为 %+v", config)
+	}
+	if config.Attachments["tenant"] != "t2" || config.Attachments["trace"] != "on" {
+		t.Errorf("隐式参数为 %v", config.Attachments)
+	}
+	if profile.Attachments["tenant"] != "t1" {
+		t.Error("应用环境不应修改环境中的隐式参数")
+	}
+
+	// 显式指定环境时命名空间以环境为准
+	profile.Explicit = true
+	config = &DubboConfig{Namespace: "dev", Username: "other"}
+	profile.Apply(config)
+	if config.Namespace != "pre" || config.Username != "other" || config.Password != "" {
+		t.Errorf("显式指定环境后的配置为 %+v", config)
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// providerProbeTimeout --check 探测提供者端口的超时时间
+const providerProbeTimeout = 2 * time.Second
+
+// providerColumns CSV输出的列，与JSON字段名一致
+var providerColumns = []string{"service", "address", "application", "version", "group", "dubboVersion", "release",
+	"weight", "healthy", "enabled", "timeout", "serialization", "strayWeight", "probeError"}
+
+// providers命令 - 列出服务的提供者实例
+func newProvidersCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "providers <服务>",
+		Short: "列出服务的所有提供者实例",
+		Long: `列出服务在注册中心中的所有提供者实例，不按版本和分组过滤
+
+显示地址、应用、版本/分组、Dubbo版本、权重、健康、启用、超时和序列化方式
+ZooKeeper取自提供者URL并应用 configurators 中的禁用、权重和超时动态配置，Nacos取自实例和实例元数据
+ZooKeeper中注册即视为健康，使用 --check 探测提供者端口
+
+示例:
+  dubbo-invoke providers com.example.user.UserService
+  dubbo-invoke providers com.example.user.UserService --check -o csv`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeServiceArgs,
+		RunE:              runProvidersCommand,
+	}
+	cmd.Flags().StringP("output", "o", "table", "输出格式: table|json|csv")
+	cmd.Flags().Bool("check", false, "探测提供者端口，无法连接时标记为不健康")
+	return cmd
+}
+
+// inventory命令 - 列出注册中心中所有服务的提供者实例
+func newInventoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inventory",
+		Short: "列出注册中心中所有服务的提供者实例并统计Dubbo版本和异常权重",
+		Long: `列出注册中心中所有服务的提供者实例，字段与 providers 命令相同
+
+表格输出在最后按Dubbo版本统计实例数，并列出已禁用、不健康和权重与同一服务、版本和分组的多数实例不同的实例
+出现次数最多的权重不唯一时没有多数，该组实例全部列出
+Dubbo版本取 release 参数，Dubbo 2.6及之前没有该参数时取 dubbo 参数
+
+示例:
+  dubbo-invoke inventory
+  dubbo-invoke inventory --filter order --older-than 2.7.15
+  dubbo-invoke inventory -P prod -o csv > providers.csv`,
+		Args: cobra.NoArgs,
+		RunE: runInventoryCommand,
+	}
+	cmd.Flags().StringP("filter", "f", "", "只包含服务名中含有该关键字的服务")
+	cmd.Flags().String("older-than", "", "只列出Dubbo版本低于该版本的实例，如 2.7.15")
+	cmd.Flags().StringP("output", "o", "table", "输出格式: table|json|csv")
+	cmd.Flags().Bool("check", false, "探测提供者端口，无法连接时标记为不健康")
+	return cmd
+}
+
+// runProvidersCommand 列出服务的提供者
+func runProvidersCommand(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	output, err := providerOutput(cmd)
+	if err != nil {
+		return err
+	}
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	check, _ := cmd.Flags().GetBool("check")
+
+	var instances []ProviderInstance
+	err = runQuietly(verbose, func() error {
+		client, err := NewRealDubboClient(catalogConfig(cmd))
+		if err != nil {
+			return fmt.Errorf("创建Dubbo客户端失败: %v", err)
+		}
+		defer client.Close()
+		defer closeZooKeeperSessions()
+
+		instances, err = client.ListProviderInstances(args[0])
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(instances) == 0 {
+		return fmt.Errorf("服务 %s 没有注册的提供者", args[0])
+	}
+
+	if check {
+		probeProviders(instances, providerProbeTimeout)
+	}
+	markStrayWeights(instances)
+	if output != "table" {
+		return writeProviders(os.Stdout, instances, output)
+	}
+
+	color.Green("服务 %s 的提供者 (共%d个):", args[0], len(instances))
+	printProviderTable(instances, false)
+	printProviderSummary(instances, false)
+	return nil
+}
+
+// runInventoryCommand 列出所有服务的提供者
+func runInventoryCommand(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	output, err := providerOutput(cmd)
+	if err != nil {
+		return err
+	}
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	check, _ := cmd.Flags().GetBool("check")
+	filter, _ := cmd.Flags().GetString("filter")
+	olderThan, _ := cmd.Flags().GetString("older-than")
+
+	config := catalogConfig(cmd)
+	fmt.Fprintf(os.Stderr, "正在从 %s 获取所有服务的提供者...\n", config.Registry)
+
+	var instances []ProviderInstance
+	var failures []string
+	err = runQuietly(verbose, func() error {
+		client, err := NewRealDubboClient(config)
+		if err != nil {
+			return fmt.Errorf("创建Dubbo客户端失败: %v", err)
+		}
+		defer client.Close()
+		defer closeZooKeeperSessions()
+
+		services, err := client.ListServices()
+		if err != nil {
+			return fmt.Errorf("获取服务列表失败: %v", err)
+		}
+		sort.Strings(services)
+		for _, service := range services {
+			// Nacos中同时注册了消费者
+			if strings.HasPrefix(service, "consumers:") || !strings.Contains(service, filter) {
+				continue
+			}
+			serviceInstances, err := client.ListProviderInstances(service)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", service, err))
+				continue
+			}
+			instances = append(instances, serviceInstances...)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if check {
+		probeProviders(instances, providerProbeTimeout)
+	}
+	markStrayWeights(instances)
+	if olderThan != "" {
+		older := instances[:0]
+		for _, instance := range instances {
+			if release := instance.FrameworkRelease(); release != "" && compareReleases(release, olderThan) < 0 {
+				older = append(older, instance)
+			}
+		}
+		instances = older
+	}
+
+	for _, failure := range failures {
+		color.New(color.FgYellow).Fprintf(os.Stderr, "获取提供者失败: %s\n", failure)
+	}
+	if output != "table" {
+		return writeProviders(os.Stdout, instances, output)
+	}
+
+	if len(instances) == 0 {
+		color.Yellow("没有找到提供者")
+		return nil
+	}
+	printProviderTable(instances, true)
+	printProviderSummary(instances, true)
+	return nil
+}
+
+// providerOutput 返回 --output 参数，不支持的格式返回错误
+func providerOutput(cmd *cobra.Command) (string, error) {
+	output, _ := cmd.Flags().GetString("output")
+	switch output {
+	case "table", "json", "csv":
+		return output, nil
+	}
+	return "", fmt.Errorf("不支持的输出格式: %s (可选: table, json, csv)", output)
+}
+
+// writeProviders 以JSON或CSV输出提供者
+func writeProviders(w io.Writer, instances []ProviderInstance, output string) error {
+	if instances == nil {
+		instances = []ProviderInstance{}
+	}
+	if output == "json" {
+		return writeResult(w, instances, OutputJSON)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write(providerColumns)
+	for _, p := range instances {
+		writer.Write([]string{p.Service, p.Address, p.Application, p.Version, p.Group, p.DubboVersion, p.Release,
+			formatWeight(p.Weight), strconv.FormatBool(p.Healthy), strconv.FormatBool(p.Enabled), p.Timeout,
+			p.Serialization, strconv.FormatBool(p.StrayWeight), p.ProbeError})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("CSV输出失败: %v", err)
+	}
+	return nil
+}
+
+// printProviderTable 以表格输出提供者，withService时第一列为服务名
+func printProviderTable(instances []ProviderInstance, withService bool) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "地址\t应用\t版本/分组\tDubbo\tRelease\t权重\t健康\t启用\t超时\t序列化"
+	if withService {
+		header = "服务\t" + header
+	}
+	fmt.Fprintln(tw, header)
+	for _, p := range instances {
+		weight := formatWeight(p.Weight)
+		if p.StrayWeight {
+			weight += " !"
+		}
+		line := strings.Join([]string{
+			p.Address,
+			firstNonEmpty(p.Application, "-"),
+			firstNonEmpty(ServiceVariant{Version: p.Version, Group: p.Group}.String(), "-"),
+			firstNonEmpty(p.DubboVersion, "-"),
+			firstNonEmpty(p.Release, "-"),
+			weight,
+			yesNo(p.Healthy),
+			yesNo(p.Enabled),
+			firstNonEmpty(p.Timeout, "-"),
+			firstNonEmpty(p.Serialization, "-"),
+		}, "\t")
+		if withService {
+			line = p.Service + "\t" + line
+		}
+		fmt.Fprintln(tw, line)
+	}
+	tw.Flush()
+}
+
+// printProviderSummary 按Dubbo版本统计实例数，列出已禁用、不健康和权重异常的实例
+func printProviderSummary(instances []ProviderInstance, withServices bool) {
+	fmt.Println()
+	if withServices {
+		services := make(map[string]bool)
+		for _, instance := range instances {
+			services[instance.Service] = true
+		}
+		color.Cyan("共 %d 个服务、%d 个提供者实例", len(services), len(instances))
+	}
+
+	releases := make(map[string]int)
+	for _, instance := range instances {
+		releases[instance.FrameworkRelease()]++
+	}
+	names := make([]string, 0, len(releases))
+	for release := range releases {
+		names = append(names, release)
+	}
+	sort.Slice(names, func(i, j int) bool { return compareReleases(names[i], names[j]) < 0 })
+	counts := make([]string, len(names))
+	for i, release := range names {
+		counts[i] = fmt.Sprintf("%s ×%d", firstNonEmpty(release, "未知"), releases[release])
+	}
+	color.Cyan("Dubbo版本: %s", strings.Join(counts, ", "))
+
+	for _, p := range instances {
+		if p.StrayWeight {
+			color.Yellow("权重异常: %s %s 权重为 %s，与同一服务、版本和分组的其他实例不一致", p.Service, p.Address, formatWeight(p.Weight))
+		}
+		if !p.Enabled {
+			color.Yellow("已禁用: %s %s", p.Service, p.Address)
+		}
+		if !p.Healthy {
+			color.Red("%s", strings.TrimSpace(fmt.Sprintf("不健康: %s %s %s", p.Service, p.Address, p.ProbeError)))
+		}
+	}
+}
+
+// formatWeight 格式化权重，整数不显示小数
+func formatWeight(weight float64) string {
+	return strconv.FormatFloat(weight, 'f', -1, 64)
+}
+
+// yesNo 将布尔值显示为 是/否
+func yesNo(value bool) string {
+	if value {
+		return "是"
+	}
+	return "否"
+}
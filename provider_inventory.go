@@ -0,0 +1,327 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+)
+
+// defaultProviderWeight Dubbo提供者未配置权重时的默认值
+const defaultProviderWeight = 100
+
+// ProviderInstance 注册中心中的一个服务提供者实例
+type ProviderInstance struct {
+	Service       string  `json:"service"`
+	Address       string  `json:"address"`
+	Application   string  `json:"application,omitempty"`
+	Version       string  `json:"version,omitempty"`
+	Group         string  `json:"group,omitempty"`
+	DubboVersion  string  `json:"dubboVersion,omitempty"` // dubbo参数，2.7之后为协议版本，之前为框架版本
+	Release       string  `json:"release,omitempty"`      // 框架版本，Dubbo 2.7之后才有
+	Weight        float64 `json:"weight"`
+	Healthy       bool    `json:"healthy"`
+	Enabled       bool    `json:"enabled"`
+	Timeout       string  `json:"timeout,omitempty"`
+	Serialization string  `json:"serialization,omitempty"`
+	StrayWeight   bool    `json:"strayWeight,omitempty"` // 权重与同一服务、版本和分组的多数实例不同，没有多数时该组全部标记
+	ProbeError    string  `json:"probeError,omitempty"`  // 探测端口失败的原因
+}
+
+// FrameworkRelease 返回提供者的Dubbo框架版本，Dubbo 2.6及之前没有release参数，框架版本在dubbo参数中
+func (p ProviderInstance) FrameworkRelease() string {
+	return firstNonEmpty(p.Release, p.DubboVersion)
+}
+
+// ListProviderInstances 列出服务的所有提供者实例，不按版本、分组过滤
+// ZooKeeper从提供者URL中获取并应用动态配置中的禁用、权重和超时，Nacos从实例和实例元数据中获取
+func (c *RealDubboClient) ListProviderInstances(serviceName string) ([]ProviderInstance, error) {
+	registryURL, err := c.parseRegistryURL()
+	if err != nil {
+		return nil, fmt.Errorf("解析注册中心地址失败: %v", err)
+	}
+
+	var instances []ProviderInstance
+	switch registryURL.Protocol {
+	case "zookeeper":
+		instances, err = c.zooKeeperProviderInstances(registryURL.Address, serviceName)
+	case "nacos":
+		instances, err = c.nacosProviderInstances(serviceName)
+	default:
+		return nil, fmt.Errorf("%s 注册中心不支持查询提供者，请使用 zookeeper:// 或 nacos:// 注册中心", registryURL.Protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].Service != instances[j].Service {
+			return instances[i].Service < instances[j].Service
+		}
+		return instances[i].Address < instances[j].Address
+	})
+	return instances, nil
+}
+
+// zooKeeperProviderInstances 读取ZooKeeper中服务的提供者和动态配置
+func (c *RealDubboClient) zooKeeperProviderInstances(address, serviceName string) ([]ProviderInstance, error) {
+	conn, err := acquireZooKeeper(address)
+	if err != nil {
+		return nil, fmt.Errorf("连接ZooKeeper失败: %v", err)
+	}
+
+	// 只有消费者的服务没有providers节点
+	providers, _, err := conn.Children(fmt.Sprintf("/dubbo/%s/providers", serviceName))
+	if err == zk.ErrNoNode {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取服务提供者列表失败: %v", err)
+	}
+
+	instances := make([]ProviderInstance, 0, len(providers))
+	for _, providerURL := range providers {
+		instance, err := parseProviderInstance(serviceName, providerURL)
+		if err != nil {
+			fmt.Printf("跳过无法解析的提供者: %v\n", err)
+			continue
+		}
+		instances = append(instances, instance)
+	}
+
+	// dubbo-admin等治理工具通过configurators下的override规则禁用提供者或调整权重
+	overrides, _, err := conn.Children(fmt.Sprintf("/dubbo/%s/configurators", serviceName))
+	if err != nil && err != zk.ErrNoNode {
+		return nil, fmt.Errorf("获取服务动态配置失败: %v", err)
+	}
+	for _, override := range overrides {
+		applyProviderOverride(instances, override)
+	}
+	return instances, nil
+}
+
+// parseProviderInstance 解析ZooKeeper中的提供者URL，如 dubbo://ip:port/服务?application=...&release=...
+func parseProviderInstance(serviceName, providerURL string) (ProviderInstance, error) {
+	decodedURL, err := url.QueryUnescape(providerURL)
+	if err != nil {
+		return ProviderInstance{}, fmt.Errorf("URL解码失败: %v", err)
+	}
+	parsed, err := url.Parse(decodedURL)
+	if err != nil || parsed.Host == "" {
+		return ProviderInstance{}, fmt.Errorf("无效的提供者URL格式: %s", decodedURL)
+	}
+
+	query := parsed.Query()
+	instance := ProviderInstance{
+		Service:       serviceName,
+		Address:       parsed.Host,
+		Application:   query.Get("application"),
+		Version:       query.Get("version"),
+		Group:         query.Get("group"),
+		DubboVersion:  query.Get("dubbo"),
+		Release:       query.Get("release"),
+		Weight:        defaultProviderWeight,
+		Healthy:       true, // 提供者URL是临时节点，会话断开后由ZooKeeper删除
+		Enabled:       query.Get("enabled") != "false" && query.Get("disabled") != "true",
+		Timeout:       query.Get("timeout"),
+		Serialization: firstNonEmpty(query.Get("serialization"), query.Get("prefer.serialization")),
+	}
+	if weight, err := strconv.ParseFloat(query.Get("weight"), 64); err == nil {
+		instance.Weight = weight
+	}
+	return instance, nil
+}
+
+// applyProviderOverride 将 override://ip:port/服务?disabled=true&weight=50 形式的动态配置应用到匹配的提供者
+// 地址为0.0.0.0时作用于所有提供者，端口为0时作用于该主机的所有端口；已停用的规则和消费端规则忽略
+func applyProviderOverride(instances []ProviderInstance, overrideURL string) {
+	decodedURL, err := url.QueryUnescape(overrideURL)
+	if err != nil {
+		return
+	}
+	parsed, err := url.Parse(decodedURL)
+	if err != nil || parsed.Scheme != "override" {
+		return
+	}
+	query := parsed.Query()
+	if query.Get("enabled") == "false" || query.Get("side") == "consumer" {
+		return
+	}
+
+	host, port := parsed.Hostname(), parsed.Port()
+	anyHost := host == "" || host == "0.0.0.0" || query.Get("anyhost") == "true"
+	for i := range instances {
+		instance := &instances[i]
+		instanceHost, instancePort, _ := net.SplitHostPort(instance.Address)
+		if !anyHost && host != instanceHost {
+			continue
+		}
+		if port != "" && port != "0" && port != instancePort {
+			continue
+		}
+		if application := query.Get("application"); application != "" && application != instance.Application {
+			continue
+		}
+
+		if disabled := query.Get("disabled"); disabled != "" {
+			instance.Enabled = disabled != "true"
+		}
+		if weight, err := strconv.ParseFloat(query.Get("weight"), 64); err == nil {
+			instance.Weight = weight
+		}
+		if timeout := query.Get("timeout"); timeout != "" {
+			instance.Timeout = timeout
+		}
+	}
+}
+
+// nacosProviderInstances 查询Nacos中服务的实例，服务名可以是接口名、providers:接口:版本:分组 或其他Nacos服务名
+// 指定接口名时包含该接口所有版本和分组的实例
+func (c *RealDubboClient) nacosProviderInstances(serviceName string) ([]ProviderInstance, error) {
+	if c.nacosClient == nil {
+		return nil, fmt.Errorf("Nacos客户端未初始化")
+	}
+
+	names := []string{serviceName}
+	if _, _, _, ok := parseNacosServiceName(serviceName); !ok {
+		serviceList, err := c.nacosClient.GetServiceList()
+		if err != nil {
+			return nil, fmt.Errorf("获取Nacos服务列表失败: %v", err)
+		}
+		names = names[:0]
+		for _, name := range serviceList.Services {
+			if iface, _, _, ok := parseNacosServiceName(name); ok && iface == serviceName {
+				names = append(names, name)
+			}
+		}
+		// 不是Dubbo接口时按Nacos服务名查询
+		if len(names) == 0 {
+			names = append(names, serviceName)
+		}
+	}
+
+	var instances []ProviderInstance
+	for _, name := range names {
+		detail, err := c.nacosClient.GetServiceDetail(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, host := range detail.Hosts {
+			instances = append(instances, nacosProviderInstance(name, host))
+		}
+	}
+	return instances, nil
+}
+
+// nacosProviderInstance 将Nacos实例转换为提供者，Dubbo将提供者URL的参数写入实例元数据
+func nacosProviderInstance(serviceName string, host NacosHost) ProviderInstance {
+	iface, version, group, _ := parseNacosServiceName(serviceName)
+	metadata := host.Metadata
+	instance := ProviderInstance{
+		Service:       firstNonEmpty(metadata["interface"], iface, serviceName),
+		Address:       net.JoinHostPort(host.IP, strconv.Itoa(host.Port)),
+		Application:   metadata["application"],
+		Version:       firstNonEmpty(metadata["version"], version),
+		Group:         firstNonEmpty(metadata["group"], group),
+		DubboVersion:  metadata["dubbo"],
+		Release:       metadata["release"],
+		Weight:        host.Weight,
+		Healthy:       host.Healthy,
+		Enabled:       host.Enabled && metadata["disabled"] != "true",
+		Timeout:       metadata["timeout"],
+		Serialization: firstNonEmpty(metadata["serialization"], metadata["prefer.serialization"]),
+	}
+	// 以Nacos实例权重为准，实例没有权重时才使用提供者URL中的权重
+	if instance.Weight == 0 {
+		if weight, err := strconv.ParseFloat(metadata["weight"], 64); err == nil {
+			instance.Weight = weight
+		}
+	}
+	return instance
+}
+
+// markStrayWeights 标记权重与同一服务、版本和分组的多数实例不同的提供者
+// 出现次数最多的权重不唯一时没有多数，该组中的提供者全部标记
+func markStrayWeights(instances []ProviderInstance) {
+	key := func(instance ProviderInstance) string {
+		return instance.Service + "\x00" + instance.Version + "\x00" + instance.Group
+	}
+	counts := make(map[string]map[float64]int)
+	for _, instance := range instances {
+		if counts[key(instance)] == nil {
+			counts[key(instance)] = make(map[float64]int)
+		}
+		counts[key(instance)][instance.Weight]++
+	}
+	for i := range instances {
+		instance := &instances[i]
+		weights := counts[key(*instance)]
+		if len(weights) < 2 {
+			continue
+		}
+		most, tied := 0, false
+		for _, count := range weights {
+			switch {
+			case count > most:
+				most, tied = count, false
+			case count == most:
+				tied = true
+			}
+		}
+		instance.StrayWeight = tied || weights[instance.Weight] != most
+	}
+}
+
+// probeProviders 并发探测提供者端口，无法连接的提供者标记为不健康
+func probeProviders(instances []ProviderInstance, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for i := range instances {
+		wg.Add(1)
+		go func(instance *ProviderInstance) {
+			defer wg.Done()
+			conn, err := net.DialTimeout("tcp", instance.Address, timeout)
+			if err != nil {
+				instance.Healthy = false
+				instance.ProbeError = err.Error()
+				return
+			}
+			conn.Close()
+		}(&instances[i])
+	}
+	wg.Wait()
+}
+
+// compareReleases 按数字逐段比较版本号，如 2.7.8 < 2.7.23 < 3.0.0，非数字的部分忽略
+func compareReleases(a, b string) int {
+	split := func(release string) []int {
+		var parts []int
+		for _, field := range strings.FieldsFunc(release, func(r rune) bool { return r < '0' || r > '9' }) {
+			part, _ := strconv.Atoi(field)
+			parts = append(parts, part)
+		}
+		return parts
+	}
+	left, right := split(a), split(b)
+	for i := 0; i < len(left) || i < len(right); i++ {
+		var l, r int
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		if l != r {
+			if l < r {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCompareReleases(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.7.8", "2.7.23", -1},
+		{"2.7.23", "3.0.0", -1},
+		{"3.0.0", "2.7.23", 1},
+		{"2.7.15", "2.7.15", 0},
+		{"2.7", "2.7.0", 0},
+		{"2.7.1", "2.7", 1},
+		{"2.6.0", "2.0.2", 1}, // Dubbo 2.6的dubbo参数为框架版本，2.7之后为协议版本
+		{"3.1.0-SNAPSHOT", "3.1.0", 0},
+		{"", "2.7.0", -1},
+	}
+	for _, tt := range tests {
+		if got := compareReleases(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareReleases(%q, %q) = %d，期望 %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestApplyProviderOverride(t *testing.T) {
+	newInstances := func() []ProviderInstance {
+		return []ProviderInstance{
+			{Address: "10.0.0.1:20880", Application: "order", Weight: defaultProviderWeight, Enabled: true, Timeout: "3000"},
+			{Address: "10.0.0.1:20881", Application: "order", Weight: defaultProviderWeight, Enabled: true, Timeout: "3000"},
+			{Address: "10.0.0.2:20880", Application: "order-admin", Weight: defaultProviderWeight, Enabled: true, Timeout: "3000"},
+		}
+	}
+	type state struct {
+		weight  float64
+		enabled bool
+		timeout string
+	}
+	unchanged := state{defaultProviderWeight, true, "3000"}
+
+	tests := []struct {
+		name     string
+		override string
+		want     []state
+	}{
+		{
+			name:     "禁用指定地址",
+			override: "override://10.0.0.1:20880/com.example.OrderService?category=configurators&disabled=true",
+			want:     []state{{defaultProviderWeight, false, "3000"}, unchanged, unchanged},
+		},
+		{
+			name:     "端口为0时作用于主机的所有端口",
+			override: "override://10.0.0.1:0/com.example.OrderService?weight=50",
+			want:     []state{{50, true, "3000"}, {50, true, "3000"}, unchanged},
+		},
+		{
+			name:     "0.0.0.0作用于所有提供者",
+			override: "override://0.0.0.0/com.example.OrderService?timeout=5000",
+			want:     []state{{defaultProviderWeight, true, "5000"}, {defaultProviderWeight, true, "5000"}, {defaultProviderWeight, true, "5000"}},
+		},
+		{
+			name:     "按应用过滤",
+			override: "override://0.0.0.0/com.example.OrderService?application=order-admin&weight=0",
+			want:     []state{unchanged, unchanged, {0, true, "3000"}},
+		},
+		{
+			name:     "已停用的规则不生效",
+			override: "override://10.0.0.1:20880/com.example.OrderService?enabled=false&disabled=true",
+			want:     []state{unchanged, unchanged, unchanged},
+		},
+		{
+			name:     "消费端规则不生效",
+			override: "override://10.0.0.1:20880/com.example.OrderService?side=consumer&weight=10",
+			want:     []state{unchanged, unchanged, unchanged},
+		},
+		{
+			name:     "URL编码的规则",
+			override: url.QueryEscape("override://10.0.0.2:20880/com.example.OrderService?disabled=true&weight=20"),
+			want:     []state{unchanged, unchanged, {20, false, "3000"}},
+		},
+		{
+			name:     "不是override规则",
+			override: "dubbo://10.0.0.1:20880/com.example.OrderService?weight=10",
+			want:     []state{unchanged, unchanged, unchanged},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instances := newInstances()
+			applyProviderOverride(instances, tt.override)
+			for i, instance := range instances {
+				got := state{instance.Weight, instance.Enabled, instance.Timeout}
+				if got != tt.want[i] {
+					t.Errorf("%s: %+v，期望 %+v", instance.Address, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMarkStrayWeights(t *testing.T) {
+	tests := []struct {
+		name      string
+		instances []ProviderInstance
+		want      []bool
+	}{
+		{
+			name: "少数实例权重不同",
+			instances: []ProviderInstance{
+				{Service: "a", Weight: 100}, {Service: "a", Weight: 100}, {Service: "a", Weight: 50},
+			},
+			want: []bool{false, false, true},
+		},
+		{
+			name: "没有多数时全部标记",
+			instances: []ProviderInstance{
+				{Service: "a", Weight: 50}, {Service: "a", Weight: 50}, {Service: "a", Weight: 200}, {Service: "a", Weight: 200},
+			},
+			want: []bool{true, true, true, true},
+		},
+		{
+			name: "不同版本和分组分别比较",
+			instances: []ProviderInstance{
+				{Service: "a", Version: "1.0.0", Weight: 100}, {Service: "a", Version: "2.0.0", Weight: 50},
+				{Service: "a", Group: "gray", Weight: 10}, {Service: "a", Group: "gray", Weight: 10},
+			},
+			want: []bool{false, false, false, false},
+		},
+		{
+			name: "权重一致",
+			instances: []ProviderInstance{
+				{Service: "a", Weight: 100}, {Service: "b", Weight: 50},
+			},
+			want: []bool{false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markStrayWeights(tt.instances)
+			for i, instance := range tt.instances {
+				if instance.StrayWeight != tt.want[i] {
+					t.Errorf("第%d个实例 %+v 的StrayWeight为 %t，期望 %t", i+1, instance, instance.StrayWeight, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNacosProviderInstanceWeight(t *testing.T) {
+	tests := []struct {
+		name     string
+		weight   float64
+		metadata map[string]string
+		want     float64
+	}{
+		{"使用实例权重", 3, map[string]string{"weight": "9"}, 3},
+		{"实例没有权重时使用元数据", 0, map[string]string{"weight": "9"}, 9},
+		{"都没有", 0, nil, 0},
+	}
+	for _, tt := range tests {
+		host := NacosHost{IP: "10.0.0.1", Port: 20880, Weight: tt.weight, Healthy: true, Enabled: true, Metadata: tt.metadata}
+		instance := nacosProviderInstance("providers:com.example.OrderService:1.0.0:", host)
+		if instance.Weight != tt.want {
+			t.Errorf("%s: 权重 %v，期望 %v", tt.name, instance.Weight, tt.want)
+		}
+		if instance.Service != "com.example.OrderService" || instance.Version != "1.0.0" {
+			t.Errorf("%s: 服务 %s 版本 %s", tt.name, instance.Service, instance.Version)
+		}
+	}
+}
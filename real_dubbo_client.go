@@ -10,7 +10,7 @@ import (
 	"golang.org/x/text/transform"
 	"io"
 	"net"
-	"net/url"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -199,9 +199,16 @@ func (sp *StreamProcessor) StartProcessing() {
 			select {
 			case <-sp.ctx.Done():
 				return
-			case data := <-sp.processorCh:
+			case data, ok := <-sp.processorCh:
+				if !ok {
+					return
+				}
 				result := sp.processChunk(data)
-				sp.resultCh <- result
+				select {
+				case sp.resultCh <- result:
+				case <-sp.ctx.Done():
+					return
+				}
 				// 回收缓冲区
 				sp.bufferPool.Put(data[:cap(data)])
 			}
@@ -235,9 +242,9 @@ func (sp *StreamProcessor) PutBuffer(buf []byte) {
 
 // Stop 停止处理
 func (sp *StreamProcessor) Stop() {
+	// resultCh不关闭，避免处理协程在Stop之后发送结果时panic
 	sp.cancel()
 	close(sp.processorCh)
-	close(sp.resultCh)
 }
 
 // MemoryManager 内存管理器
@@ -319,7 +326,8 @@ type AsyncProcessor struct {
 // AsyncTask 异步任务
 type AsyncTask struct {
 	ID       string
-	Data     interface{}
+	Context  context.Context                                 // 任务上下文，为空时使用处理器的上下文
+	Run      func(ctx context.Context) (interface{}, error) // 任务执行函数
 	Callback func(interface{}, error)
 	Timeout  time.Duration
 }
@@ -396,28 +404,41 @@ func (ap *AsyncProcessor) dispatcher() {
 
 // processTask 处理任务
 func (ap *AsyncProcessor) processTask(task AsyncTask) {
-	ctx, cancel := context.WithTimeout(context.Background(), task.Timeout)
+	parent := task.Context
+	if parent == nil {
+		parent = ap.ctx
+	}
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if task.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, task.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
 	defer cancel()
-	
-	done := make(chan struct{})
-	var result interface{}
-	var err error
-	
-	go func() {
-		defer close(done)
-		// 这里可以根据任务类型进行不同的处理
-		result = task.Data
-	}()
-	
-	select {
-	case <-done:
+
+	// 任务在出队前已被取消，不再执行
+	if ctx.Err() != nil {
 		if task.Callback != nil {
-			task.Callback(result, err)
+			task.Callback(nil, fmt.Errorf("任务已取消: %s", task.ID))
 		}
-	case <-ctx.Done():
+		return
+	}
+
+	if task.Run == nil {
 		if task.Callback != nil {
-			task.Callback(nil, fmt.Errorf("任务超时: %s", task.ID))
+			task.Callback(nil, fmt.Errorf("任务未定义执行函数: %s", task.ID))
 		}
+		return
+	}
+
+	// Run需要自行响应ctx的取消，这里等待其返回以保证并发数不超过工作协程数
+	result, err := task.Run(ctx)
+	if err == nil && ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("任务超时: %s", task.ID)
+	}
+	if task.Callback != nil {
+		task.Callback(result, err)
 	}
 }
 
@@ -433,6 +454,21 @@ func (ap *AsyncProcessor) SubmitTask(task AsyncTask) error {
 	}
 }
 
+// QueueLength 返回排队中的任务数
+func (ap *AsyncProcessor) QueueLength() int {
+	return len(ap.taskQueue)
+}
+
+// QueueCapacity 返回任务队列容量
+func (ap *AsyncProcessor) QueueCapacity() int {
+	return cap(ap.taskQueue)
+}
+
+// WorkerCount 返回工作协程数
+func (ap *AsyncProcessor) WorkerCount() int {
+	return ap.workerCount
+}
+
 // Stop 停止异步处理器
 func (ap *AsyncProcessor) Stop() {
 	ap.cancel()
@@ -455,9 +491,14 @@ type OptimizedDubboConfig struct {
 
 // NewOptimizedDubboConfig 创建优化的Dubbo配置
 func NewOptimizedDubboConfig(base *DubboConfig) *OptimizedDubboConfig {
+	maxPayloadSize := 50 * 1024 * 1024 // 50MB
+	if base != nil && base.MaxPayloadSize > 0 {
+		maxPayloadSize = base.MaxPayloadSize
+	}
+
 	return &OptimizedDubboConfig{
 		DubboConfig:       base,
-		MaxPayloadSize:    50 * 1024 * 1024, // 50MB
+		MaxPayloadSize:    maxPayloadSize,
 		ChunkSize:         8192,              // 8KB
 		MaxChunks:         1000,              // 最大1000个分块
 		CompressionLevel:  6,                 // gzip压缩级别
@@ -469,17 +510,26 @@ func NewOptimizedDubboConfig(base *DubboConfig) *OptimizedDubboConfig {
 	}
 }
 
+// telnet调用支持的字符集
+const (
+	CharsetGBK     = "GBK"
+	CharsetGB18030 = "GB18030"
+	CharsetUTF8    = "UTF-8"
+)
+
 // RealDubboClient 简化的真实Dubbo客户端实现
 type RealDubboClient struct {
 	config              *DubboConfig
 	optimizedConfig     *OptimizedDubboConfig
 	connected           bool
-	conn                net.Conn
+	providerAddress     string // dubbo直连模式下的服务提供者地址，每次调用时从连接池获取连接
 	chunkedTransferMgr  *ChunkedTransferManager
 	streamProcessor     *StreamProcessor
 	memoryManager       *MemoryManager
-	asyncProcessor      *AsyncProcessor
 	nacosClient         *NacosClient // 添加Nacos客户端
+	startTimings        InvokeTimings // 启动阶段耗时，计入之后第一次调用
+	timings             InvokeTimings // 最近一次调用的各阶段耗时
+	provider            string        // 最近一次调用的服务提供者地址
 }
 
 
@@ -516,24 +566,21 @@ func NewRealDubboClient(cfg *DubboConfig) (*RealDubboClient, error) {
 	// 创建内存管理器
 	memoryManager := NewMemoryManager(optimizedConfig.BufferPoolSize)
 
-	// 创建异步处理器
-	asyncProcessor := NewAsyncProcessor(optimizedConfig.WorkerCount)
-	asyncProcessor.Start()
-
 	realClient := &RealDubboClient{
 		config:             cfg,
 		optimizedConfig:    optimizedConfig,
 		chunkedTransferMgr: chunkedMgr,
 		streamProcessor:    streamProcessor,
 		memoryManager:      memoryManager,
-		asyncProcessor:     asyncProcessor,
 	}
 
-	// 尝试连接到注册中心
+	// 尝试连接到注册中心，dubbo/direct模式下连接耗时已单独计入Dial
+	startTime := time.Now()
 	err := realClient.start()
 	if err != nil {
 		return nil, fmt.Errorf("启动Dubbo客户端失败: %v", err)
 	}
+	realClient.startTimings.Connect = round3(msSince(startTime) - realClient.startTimings.Dial)
 
 	return realClient, nil
 }
@@ -586,102 +633,34 @@ func (c *RealDubboClient) parseRegistryURL() (*RegistryURL, error) {
 
 // connectToZookeeper 连接到ZooKeeper注册中心
 func (c *RealDubboClient) connectToZookeeper(address string) error {
-	// 连接到ZooKeeper获取服务提供者信息
-	zkConn, events, err := zk.Connect([]string{address}, time.Second*10)
-	if err != nil {
+	// 建立或复用ZooKeeper会话，调用时从中获取服务提供者信息
+	if _, err := acquireZooKeeper(address); err != nil {
 		return fmt.Errorf("连接ZooKeeper注册中心失败: %v", err)
 	}
-	defer zkConn.Close()
-
-	// 等待连接建立，最多等待10秒
-	timeout := time.After(10 * time.Second)
-	for {
-		select {
-		case event := <-events:
-			if event.State == zk.StateHasSession {
-				fmt.Printf("成功连接到ZooKeeper注册中心: %s\n", address)
-				c.connected = true
-				fmt.Printf("ZooKeeper注册中心连接就绪，将在调用时获取服务提供者\n")
-				return nil
-			}
-		case <-timeout:
-			return fmt.Errorf("ZooKeeper连接超时，当前状态: %v", zkConn.State())
-		}
-	}
+	fmt.Printf("成功连接到ZooKeeper注册中心: %s\n", address)
+	c.connected = true
+	fmt.Printf("ZooKeeper注册中心连接就绪，将在调用时获取服务提供者\n")
+	return nil
 }
 
 // getProviderFromZooKeeper 从ZooKeeper获取服务提供者地址
+// 按版本、分组和标签过滤后，按负载均衡策略选择一个提供者
 func (c *RealDubboClient) getProviderFromZooKeeper(serviceName string) (string, error) {
-	// 解析注册中心地址
-	registryURL, err := c.parseRegistryURL()
-	if err != nil {
-		return "", fmt.Errorf("解析注册中心地址失败: %v", err)
-	}
-
-	// 连接到ZooKeeper
-	zkConn, _, err := zk.Connect([]string{registryURL.Address}, time.Second*10)
-	if err != nil {
-		return "", fmt.Errorf("连接ZooKeeper失败: %v", err)
-	}
-	defer zkConn.Close()
-
-	// 构建服务路径
-	servicePath := fmt.Sprintf("/dubbo/%s/providers", serviceName)
-	fmt.Printf("查找服务提供者路径: %s\n", servicePath)
-
-	// 检查路径是否存在
-	exists, _, err := zkConn.Exists(servicePath)
-	if err != nil {
-		return "", fmt.Errorf("检查服务路径失败: %v", err)
-	}
-	if !exists {
-		return "", fmt.Errorf("服务 %s 在ZooKeeper中不存在", serviceName)
-	}
-
-	// 获取提供者列表
-	providers, _, err := zkConn.Children(servicePath)
+	candidates, err := c.getProviderCandidates(serviceName)
 	if err != nil {
-		return "", fmt.Errorf("获取服务提供者列表失败: %v", err)
+		return "", err
 	}
-
-	if len(providers) == 0 {
+	if len(candidates) == 0 {
 		return "", fmt.Errorf("服务 %s 没有可用的提供者", serviceName)
 	}
 
-	// 解析第一个提供者的地址
-	providerURL := providers[0]
-	fmt.Printf("找到服务提供者: %s\n", providerURL)
-
-	// 解析URL获取地址
-	address, err := c.parseProviderURL(providerURL)
-	if err != nil {
-		return "", fmt.Errorf("解析提供者URL失败: %v", err)
+	selected := selectProvider(candidates)
+	if selected == nil {
+		return "", fmt.Errorf("服务 %s 没有匹配 版本=%q 分组=%q 标签=%q 的提供者", serviceName, c.config.Version, c.config.Group, c.config.Tag)
 	}
+	fmt.Printf("找到服务提供者: %s\n", selected.URL)
 
-	return address, nil
-}
-
-// parseProviderURL 解析提供者URL获取地址
-func (c *RealDubboClient) parseProviderURL(providerURL string) (string, error) {
-	// 首先进行URL解码
-	decodedURL, err := url.QueryUnescape(providerURL)
-	if err != nil {
-		return "", fmt.Errorf("URL解码失败: %v", err)
-	}
-	
-	fmt.Printf("解码后的URL: %s\n", decodedURL)
-	
-	// Dubbo提供者URL格式: dubbo://ip:port/serviceName?version=1.0.0&...
-	if strings.HasPrefix(decodedURL, "dubbo://") {
-		// 移除协议前缀
-		urlPath := strings.TrimPrefix(decodedURL, "dubbo://")
-		// 提取地址部分（ip:port）
-		parts := strings.Split(urlPath, "/")
-		if len(parts) > 0 {
-			return parts[0], nil
-		}
-	}
-	return "", fmt.Errorf("无效的提供者URL格式: %s", decodedURL)
+	return selected.Address, nil
 }
 
 // connectToNacos 连接到Nacos注册中心
@@ -692,12 +671,15 @@ func (c *RealDubboClient) connectToNacos(address string) error {
 		namespace = c.config.Namespace
 	}
 	
-	// 创建Nacos客户端
-	c.nacosClient = NewNacosClient(address, namespace, "DEFAULT_GROUP")
+	// 创建Nacos客户端，密码可以是 secret:// 引用的凭据，连接时才解密
+	password, err := resolveSecret(c.config.Password)
+	if err != nil {
+		return fmt.Errorf("读取注册中心密码失败: %v", err)
+	}
+	c.nacosClient = NewNacosClientWithAuth(address, namespace, "DEFAULT_GROUP", c.config.Username, password)
 	
 	// 测试连接
-	err := c.nacosClient.TestConnection()
-	if err != nil {
+	if err := c.nacosClient.TestConnection(); err != nil {
 		return fmt.Errorf("连接Nacos注册中心失败: %v", err)
 	}
 
@@ -709,27 +691,30 @@ func (c *RealDubboClient) connectToNacos(address string) error {
 // connectToDubboRegistry 连接到Dubbo协议接口（直连模式）
 func (c *RealDubboClient) connectToDubboRegistry(address string) error {
 	// dubbo://协议表示直连到dubbo服务提供者
-	// 尝试连接到Dubbo服务提供者
-	conn, err := net.DialTimeout("tcp", address, c.config.Timeout)
-	if err != nil {
-		return fmt.Errorf("连接Dubbo服务提供者失败: %v", err)
-	}
-
-	c.conn = conn
-	c.connected = true
-	fmt.Printf("成功连接到Dubbo服务提供者: %s\n", address)
-	return nil
+	return c.connectToDirect(address)
 }
 
 // connectToDirect 直连模式连接到服务提供者
+// 只验证提供者可以连接并将连接归还连接池，调用时再按地址获取，客户端不长期占用连接池的名额
 func (c *RealDubboClient) connectToDirect(address string) error {
-	// 尝试连接到Dubbo服务提供者
-	conn, err := net.DialTimeout("tcp", address, c.config.Timeout)
+	timeout := c.config.Timeout
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	pool := getConnectionPool()
+	dialStart := time.Now()
+	conn, err := pool.Acquire(ctx, "dubbo", address)
 	if err != nil {
 		return fmt.Errorf("连接Dubbo服务提供者失败: %v", err)
 	}
+	c.startTimings.Dial = msSince(dialStart)
+	c.startTimings.Reused = conn.Reused()
+	pool.Release(conn)
 
-	c.conn = conn
+	c.providerAddress = address
 	c.connected = true
 	fmt.Printf("成功连接到Dubbo服务提供者: %s\n", address)
 	return nil
@@ -737,6 +722,12 @@ func (c *RealDubboClient) connectToDirect(address string) error {
 
 // GenericInvoke 泛化调用
 func (c *RealDubboClient) GenericInvoke(serviceName, methodName string, paramTypes []string, params []interface{}) (interface{}, error) {
+	return c.GenericInvokeContext(context.Background(), serviceName, methodName, paramTypes, params)
+}
+
+// GenericInvokeContext 支持取消的泛化调用
+// 调用总耗时受ctx和配置的超时时间共同约束，ctx取消时会关闭到服务提供者的连接
+func (c *RealDubboClient) GenericInvokeContext(ctx context.Context, serviceName, methodName string, paramTypes []string, params []interface{}) (interface{}, error) {
 	if !c.connected {
 		return nil, fmt.Errorf("客户端未连接")
 	}
@@ -749,71 +740,97 @@ func (c *RealDubboClient) GenericInvoke(serviceName, methodName string, paramTyp
 		return nil, fmt.Errorf("方法名不能为空")
 	}
 
-	// 对于ZooKeeper模式，需要先获取服务提供者地址并建立连接
-	registryURL, err := c.parseRegistryURL()
-	if err == nil && registryURL.Protocol == "zookeeper" {
-		// 如果当前没有连接到实际的Dubbo服务提供者，先获取地址并连接
-		if c.conn == nil {
-			providerAddress, err := c.getProviderFromZooKeeper(serviceName)
-			if err != nil {
-				return nil, fmt.Errorf("从ZooKeeper获取服务提供者失败: %v", err)
-			}
+	// 使用配置的超时时间约束整个调用过程
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
 
-			// 连接到实际的Dubbo服务提供者
-			conn, err := net.DialTimeout("tcp", providerAddress, c.config.Timeout)
-			if err != nil {
-				return nil, fmt.Errorf("连接Dubbo服务提供者失败 %s: %v", providerAddress, err)
-			}
+	// 记录各阶段耗时，启动阶段的耗时只计入第一次调用
+	c.timings = c.startTimings
+	c.timings.Server = -1
+	c.startTimings = InvokeTimings{}
+	c.provider = ""
+	defer c.timings.sum()
 
-			c.conn = conn
-			fmt.Printf("成功连接到Dubbo服务提供者: %s\n", providerAddress)
+	// 获取到服务提供者的连接，调用结束后根据会话是否对齐决定能否复用
+	conn, release, err := c.acquireProviderConn(ctx, serviceName)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, c.contextError(ctx)
 		}
+		return nil, err
 	}
+	synced := false
+	defer func() { release(synced) }()
+	sendStart := time.Now()
 
 	// 构建dubbo invoke命令，支持各种参数类型
-	paramStr, err := c.formatParameters(params)
+	invokeCmd, gbkBytes, _, err := c.buildInvokePayload(serviceName, methodName, params)
 	if err != nil {
-		return nil, fmt.Errorf("参数格式化失败: %v", err)
+		return nil, err
 	}
-
-	// 构建invoke命令
-	invokeCmd := fmt.Sprintf("invoke %s.%s(%s)\n", serviceName, methodName, paramStr)
 	fmt.Printf("[DUBBO CLIENT] 发送命令: %s", invokeCmd)
+	if len(c.config.Attachments) > 0 {
+		fmt.Printf("[DUBBO CLIENT] telnet协议不支持传递attachments，已忽略: %s\n", strings.Join(sortedStringKeys(c.config.Attachments), ", "))
+	}
 
-	// 将UTF-8编码的命令转换为GBK编码后发送
-	// 因为很多Java Dubbo服务端默认使用GBK编码处理中文字符
-	gbkBytes, err := c.convertToGBK(invokeCmd)
-	if err != nil {
-		fmt.Printf("[DUBBO CLIENT] GBK编码转换失败，使用UTF-8: %v\n", err)
-		gbkBytes = []byte(invokeCmd)
-	} else {
-		fmt.Printf("[DUBBO CLIENT] 命令已转换为GBK编码\n")
+	// ctx取消时关闭连接，使阻塞中的读写立即返回
+	stopWatch := c.watchContext(ctx, conn)
+	defer stopWatch()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
 	}
 
 	// 发送invoke命令
-	_, err = c.conn.Write(gbkBytes)
+	_, err = conn.Write(gbkBytes)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, c.contextError(ctx)
+		}
 		return nil, fmt.Errorf("发送invoke命令失败: %v", err)
 	}
+	c.timings.Send = msSince(sendStart)
 
-	// 增加初始读取超时，给服务端更多时间响应
-	initialTimeout := time.Duration(30 * time.Second)
-	c.conn.SetReadDeadline(time.Now().Add(initialTimeout))
-	
 	// 使用传统方式读取完整响应数据，避免分块限制导致数据截断
+	// 配置了结果存储时，超过阈值的响应边读取边写入结果文件，内存中只保留末尾部分
 	var responseBuffer bytes.Buffer
+	var spool *responseSpool
+	var received int
+	defer func() {
+		if spool != nil {
+			spool.abort()
+		}
+	}()
 	tempBuffer := make([]byte, 4096)
+	waitStart := time.Now()
+	var readStart time.Time
+	elapsedSeen := false
+	defer func() {
+		if readStart.IsZero() {
+			c.timings.Wait = msSince(waitStart)
+		}
+	}()
 	
 	for {
-		n, err := c.conn.Read(tempBuffer)
+		n, err := conn.Read(tempBuffer)
+		if n > 0 && readStart.IsZero() {
+			readStart = time.Now()
+			c.timings.Wait = round3(float64(readStart.Sub(waitStart).Microseconds()) / 1000)
+		}
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, c.contextError(ctx)
+			}
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-				if responseBuffer.Len() > 0 {
+				if received > 0 {
 					break // 已读取数据，超时退出
 				}
 				return nil, fmt.Errorf("读取响应超时: %v", err)
 			}
-			if responseBuffer.Len() > 0 {
+			if received > 0 {
 				break // 已读取数据，连接关闭或其他错误退出
 			}
 			return nil, fmt.Errorf("读取响应失败: %v", err)
@@ -822,25 +839,85 @@ func (c *RealDubboClient) GenericInvoke(serviceName, methodName string, paramTyp
 		if n == 0 {
 			break
 		}
+		received += n
+
+		// 超过最大响应大小时中止读取，连接上残留的数据使其不可复用
+		if c.optimizedConfig != nil && c.optimizedConfig.MaxPayloadSize > 0 &&
+			received > c.optimizedConfig.MaxPayloadSize {
+			return nil, fmt.Errorf("响应大小超过限制: 已读取 %d 字节，上限 %d 字节 (可通过 --max-payload 调整)",
+				received, c.optimizedConfig.MaxPayloadSize)
+		}
+
+		// recent 为已读取数据的末尾，至少包含本次读取的数据或spool保留的末尾部分
+		var recent []byte
+		if spool != nil {
+			if err := spool.write(tempBuffer[:n]); err != nil {
+				return nil, err
+			}
+			recent = spool.tail
+		} else {
+			responseBuffer.Write(tempBuffer[:n])
+			recent = responseBuffer.Bytes()
+			if c.config.ResultSpool != nil && c.config.SpoolThreshold > 0 && responseBuffer.Len() > c.config.SpoolThreshold && !hasPromptTail(recent) {
+				if spool, err = c.newResponseSpool(recent); err != nil {
+					return nil, err
+				}
+				fmt.Printf("[DUBBO CLIENT] 响应超过 %d 字节，写入结果文件\n", c.config.SpoolThreshold)
+				responseBuffer = bytes.Buffer{}
+				recent = spool.tail
+			}
+		}
 		
-		responseBuffer.Write(tempBuffer[:n])
-		
-		// 检查是否读取完整（包含dubbo>提示符或其他结束标识）
-		responseText := responseBuffer.String()
-		if strings.Contains(responseText, "dubbo>") || 
-		   strings.Contains(responseText, "elapsed:") {
+		// 以dubbo>提示符作为响应结束标识，保证同一会话上的下一条命令与响应对齐
+		if hasPromptTail(recent) {
+			synced = true
 			break
 		}
 		
-		// 设置较短的读取超时，避免无限等待
-		c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		// 设置较短的读取超时，避免无限等待，但不超过调用截止时间
+		// 已收到elapsed时只需短暂等待提示符，只在新读取的数据中查找
+		if from := len(recent) - n - len("elapsed:"); from > 0 {
+			recent = recent[from:]
+		}
+		if bytes.Contains(recent, []byte("elapsed:")) {
+			elapsedSeen = true
+		}
+		idleTimeout := 2 * time.Second
+		if elapsedSeen {
+			idleTimeout = 300 * time.Millisecond
+		}
+		idleDeadline := time.Now().Add(idleTimeout)
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(idleDeadline) {
+			idleDeadline = deadline
+		}
+		conn.SetReadDeadline(idleDeadline)
 	}
 
-	// 重置读取超时
-	c.conn.SetReadDeadline(time.Now().Add(c.config.Timeout))
+	// 清除读写超时，连接可继续复用
+	conn.SetDeadline(time.Time{})
+	if !readStart.IsZero() {
+		c.timings.Read = msSince(readStart)
+	}
+	parseStart := time.Now()
+	defer func() { c.timings.Parse = msSince(parseStart) }()
+
+	// 写入结果文件的响应直接返回结果句柄，开头部分用于判断是否为错误信息，错误时只返回首行
+	if spool != nil {
+		c.timings.Server = parseServerElapsed(string(spool.tail))
+		if isTelnetErrorResponse(spool.head) {
+			return nil, fmt.Errorf("调用失败: %s", telnetFirstLine(spool.head))
+		}
+		stored, err := spool.finish()
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("[DUBBO CLIENT] 响应已写入结果文件: %s (%d 字节)\n", stored.ID, stored.Size)
+		return stored, nil
+	}
 	
 	// 获取完整的响应文本
 	responseText := responseBuffer.String()
+	c.timings.Server = parseServerElapsed(responseText)
 	fmt.Printf("[DUBBO CLIENT] 完整响应文本: %s\n", responseText)
 	
 	// 尝试将响应从GBK编码转换为UTF-8
@@ -854,11 +931,7 @@ func (c *RealDubboClient) GenericInvoke(serviceName, methodName string, paramTyp
 	}
 	
 	// 检查是否包含错误信息
-	if strings.Contains(utf8ResponseText, "Failed to invoke") || 
-	   strings.Contains(utf8ResponseText, "error") ||
-	   strings.Contains(utf8ResponseText, "No such service") ||
-	   strings.Contains(utf8ResponseText, "No provider") ||
-	   strings.Contains(utf8ResponseText, "Service not found") {
+	if isTelnetErrorResponse(utf8ResponseText) {
 		return nil, fmt.Errorf("调用失败: %s", utf8ResponseText)
 	}
 
@@ -885,6 +958,262 @@ func (c *RealDubboClient) GenericInvoke(serviceName, methodName string, paramTyp
 	return cleanedResponse, nil
 }
 
+// telnetErrorPrefixes invoke命令失败时telnet响应首行的开头
+var telnetErrorPrefixes = []string{
+	"Failed to invoke",
+	"No such service",
+	"No such method",
+	"No provider",
+	"Service not found",
+	"Invalid json argument",
+	"Invalid parameters",
+	"Please input method name",
+}
+
+// telnetNotFoundPrefixes 服务或方法不存在时telnet响应首行的开头
+var telnetNotFoundPrefixes = []string{"No such service", "No such method", "No provider", "Service not found"}
+
+// telnetExceptionPattern 服务端抛出异常时响应首行为异常类名，如 java.lang.IllegalStateException: xxx
+var telnetExceptionPattern = regexp.MustCompile(`^([A-Za-z_$][\w$]*\.)+[A-Za-z_$][\w$]*(Exception|Error)(:|$)`)
+
+// responseSpoolTail 写入结果文件时暂留在内存中的末尾字节数，响应结束后从中去掉elapsed和提示符
+const responseSpoolTail = 256
+
+// responseSpool 超过阈值的响应边读取边转换为UTF-8写入结果文件，内存中只保留开头和末尾部分
+type responseSpool struct {
+	writer  *ResultWriter
+	decoder io.WriteCloser
+	head    string // 响应开头，用于判断是否为错误信息
+	tail    []byte // 尚未写入的末尾部分
+}
+
+// newResponseSpool 创建结果文件并写入已读取的数据
+func (c *RealDubboClient) newResponseSpool(data []byte) (*responseSpool, error) {
+	writer, err := c.config.ResultSpool.Create()
+	if err != nil {
+		return nil, err
+	}
+	head := data[:min(len(data), 1024)]
+	headText, err := c.convertToUTF8(head)
+	if err != nil {
+		headText = string(head)
+	}
+	spool := &responseSpool{
+		writer:  writer,
+		decoder: c.utf8Writer(writer),
+		head:    headText,
+	}
+	if err := spool.write(data); err != nil {
+		spool.abort()
+		return nil, err
+	}
+	return spool, nil
+}
+
+// write 写入新读取的数据，末尾responseSpoolTail字节暂不写入
+func (s *responseSpool) write(data []byte) error {
+	s.tail = append(s.tail, data...)
+	if extra := len(s.tail) - responseSpoolTail; extra > 0 {
+		if _, err := s.decoder.Write(s.tail[:extra]); err != nil {
+			return fmt.Errorf("写入结果文件失败: %v", err)
+		}
+		s.tail = append(s.tail[:0], s.tail[extra:]...)
+	}
+	return nil
+}
+
+// finish 去掉末尾的elapsed行和提示符后写入剩余部分，返回结果句柄
+func (s *responseSpool) finish() (*StoredResult, error) {
+	body := bytes.TrimRight(s.tail, " \t\r\n")
+	body = bytes.TrimSuffix(body, []byte(telnetPrompt))
+	if index := bytes.LastIndex(body, []byte("\nelapsed:")); index >= 0 {
+		body = body[:index]
+	}
+	body = bytes.TrimRight(body, " \t\r\n")
+	if _, err := s.decoder.Write(body); err != nil {
+		return nil, fmt.Errorf("写入结果文件失败: %v", err)
+	}
+	if err := s.decoder.Close(); err != nil {
+		return nil, fmt.Errorf("写入结果文件失败: %v", err)
+	}
+	return s.writer.Commit()
+}
+
+// abort 放弃写入并删除结果文件，finish之后调用不做任何操作
+func (s *responseSpool) abort() {
+	s.writer.Abort()
+}
+
+// utf8Writer 将响应从配置的字符集转换为UTF-8后写入w，Close时写入剩余的数据
+func (c *RealDubboClient) utf8Writer(w io.Writer) io.WriteCloser {
+	switch normalizeCharset(c.config.Charset) {
+	case CharsetUTF8:
+		return nopWriteCloser{w}
+	case CharsetGB18030:
+		return transform.NewWriter(w, simplifiedchinese.GB18030.NewDecoder())
+	default:
+		return transform.NewWriter(w, simplifiedchinese.GBK.NewDecoder())
+	}
+}
+
+// nopWriteCloser 为io.Writer添加空的Close方法
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// isTelnetErrorResponse 判断telnet响应是否为调用失败的错误信息
+// 只检查首行：错误信息以固定的前缀或异常类名开头，结果JSON中出现的 error 等字样不算失败
+func isTelnetErrorResponse(text string) bool {
+	line := telnetFirstLine(text)
+	return hasAnyPrefix(line, telnetErrorPrefixes) || telnetExceptionPattern.MatchString(line)
+}
+
+// isTelnetNotFoundResponse 判断telnet响应是否为服务、方法或提供者不存在
+func isTelnetNotFoundResponse(text string) bool {
+	return hasAnyPrefix(telnetFirstLine(text), telnetNotFoundPrefixes)
+}
+
+// telnetFirstLine 返回响应去掉开头空白后的第一行
+func telnetFirstLine(text string) string {
+	text = strings.TrimLeft(text, " \t\r\n")
+	if index := strings.IndexAny(text, "\r\n"); index >= 0 {
+		text = text[:index]
+	}
+	return text
+}
+
+// hasAnyPrefix 判断字符串是否以任一前缀开头
+func hasAnyPrefix(text string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchContext 监听ctx，取消时关闭连接使阻塞的读写返回，返回的函数用于停止监听
+func (c *RealDubboClient) watchContext(ctx context.Context, conn net.Conn) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// contextError 将ctx的取消原因转换为调用错误
+func (c *RealDubboClient) contextError(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("调用超时: 超过 %v 未收到完整响应", c.config.Timeout)
+	}
+	return fmt.Errorf("调用已取消: %v", ctx.Err())
+}
+
+// acquireProviderConn 获取本次调用使用的提供者连接
+// 从共享连接池按提供者地址获取，ZooKeeper模式下先查询提供者地址；返回的release函数在调用结束时执行，
+// reusable为false时连接会被丢弃
+func (c *RealDubboClient) acquireProviderConn(ctx context.Context, serviceName string) (net.Conn, func(reusable bool), error) {
+	providerAddress := c.providerAddress
+	if providerAddress == "" {
+		registryURL, err := c.parseRegistryURL()
+		if err != nil || registryURL.Protocol != "zookeeper" {
+			return nil, nil, fmt.Errorf("未建立到服务提供者的连接")
+		}
+		lookupStart := time.Now()
+		providerAddress, err = c.getProviderFromZooKeeper(serviceName)
+		c.timings.Lookup = msSince(lookupStart)
+		if err != nil {
+			return nil, nil, fmt.Errorf("从ZooKeeper获取服务提供者失败: %v", err)
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	// 直连模式启动时建立连接的耗时已计入第一次调用
+	startDialed := c.timings.Dial > 0
+	pool := getConnectionPool()
+	dialStart := time.Now()
+	pc, err := pool.Acquire(ctx, "dubbo", providerAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !startDialed {
+		c.timings.Dial = msSince(dialStart)
+		c.timings.Reused = pc.Reused()
+	}
+	c.provider = providerAddress
+	if c.providerAddress == "" {
+		fmt.Printf("成功连接到Dubbo服务提供者: %s\n", providerAddress)
+	}
+
+	return pc, func(reusable bool) {
+		if !reusable {
+			pc.MarkBroken()
+		}
+		pool.Release(pc)
+	}, nil
+}
+
+// LastTimings 返回最近一次调用的各阶段耗时
+func (c *RealDubboClient) LastTimings() *InvokeTimings {
+	timings := c.timings
+	return &timings
+}
+
+// LastProvider 返回最近一次调用的服务提供者地址，未获取到连接时为空
+func (c *RealDubboClient) LastProvider() string {
+	return c.provider
+}
+
+// InvokeFuture 异步调用结果
+type InvokeFuture struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	result interface{}
+	err    error
+}
+
+// Done 返回调用完成时关闭的通道
+func (f *InvokeFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Get 等待调用完成并返回结果
+func (f *InvokeFuture) Get() (interface{}, error) {
+	<-f.done
+	return f.result, f.err
+}
+
+// Cancel 取消尚未完成的调用
+func (f *InvokeFuture) Cancel() {
+	f.cancel()
+}
+
+// InvokeAsync 异步泛化调用，立即返回可等待或取消的InvokeFuture
+// 同一个客户端的连接不支持并发调用，调用方需在Get返回后再发起下一次调用
+func (c *RealDubboClient) InvokeAsync(ctx context.Context, serviceName, methodName string, paramTypes []string, params []interface{}) *InvokeFuture {
+	ctx, cancel := context.WithCancel(ctx)
+	future := &InvokeFuture{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	go func() {
+		defer close(future.done)
+		defer cancel()
+		future.result, future.err = c.GenericInvokeContext(ctx, serviceName, methodName, paramTypes, params)
+	}()
+
+	return future
+}
+
 // ListServices 列出可用服务
 func (c *RealDubboClient) ListServices() ([]string, error) {
 	if !c.connected {
@@ -919,11 +1248,10 @@ func (c *RealDubboClient) getServicesFromZooKeeper() ([]string, error) {
 	}
 
 	// 连接到ZooKeeper
-	conn, _, err := zk.Connect([]string{registryURL.Address}, time.Second*10)
+	conn, err := acquireZooKeeper(registryURL.Address)
 	if err != nil {
 		return nil, fmt.Errorf("连接ZooKeeper失败: %v", err)
 	}
-	defer conn.Close()
 
 	// 扫描Dubbo服务路径
 	services, err := c.scanZooKeeperServices(conn, "/dubbo")
@@ -1007,9 +1335,22 @@ func (c *RealDubboClient) getServicesFromNacos() ([]string, error) {
 
 // getServicesFromDubboRegistry 从Dubbo注册中心获取服务列表
 func (c *RealDubboClient) getServicesFromDubboRegistry() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	conn, release, err := c.acquireProviderConn(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("连接Dubbo服务提供者失败: %v", err)
+	}
+	// 响应未以提示符结束时，连接上可能残留未读数据，不再复用
+	synced := false
+	defer func() { release(synced) }()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
 	// 使用dubbo协议的ls命令获取真实服务列表
 	lsCommand := "ls\n"
-	_, err := c.conn.Write([]byte(lsCommand))
+	_, err = conn.Write([]byte(lsCommand))
 	if err != nil {
 		return nil, fmt.Errorf("发送ls命令失败: %v", err)
 	}
@@ -1019,7 +1360,7 @@ func (c *RealDubboClient) getServicesFromDubboRegistry() ([]string, error) {
 	buffer := make([]byte, 8192)
 	
 	for {
-		n, err := c.conn.Read(buffer)
+		n, err := conn.Read(buffer)
 		if err != nil {
 			// 如果已经读取了数据，继续处理
 			if responseBuffer.Len() > 0 {
@@ -1036,11 +1377,12 @@ func (c *RealDubboClient) getServicesFromDubboRegistry() ([]string, error) {
 		}
 		
 		// 设置较短超时检查更多数据
-		c.conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
 	}
 
 	// 解析响应文本
 	responseText := responseBuffer.String()
+	synced = hasTelnetPrompt(responseText)
 	
 	// 提取服务列表
 	services := c.parseServiceList(responseText)
@@ -1096,15 +1438,32 @@ func (c *RealDubboClient) ListMethods(serviceName string) ([]string, error) {
 		"client":  c.config.Application,
 	}
 
+	// 未建立到服务提供者的连接时（如ZooKeeper模式），返回默认方法列表
+	if c.providerAddress == "" {
+		return c.getDefaultMethods(serviceName), nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	conn, release, err := c.acquireProviderConn(ctx, serviceName)
+	if err != nil {
+		return c.getDefaultMethods(serviceName), nil
+	}
+	// 响应未以提示符结束时，连接上可能残留未读数据，不再复用
+	synced := false
+	defer func() { release(synced) }()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
 	requestData, _ := json.Marshal(request)
-	c.conn.Write(requestData)
+	conn.Write(requestData)
 
 	// 读取响应 - 使用动态缓冲区读取完整数据
 	var responseBuffer bytes.Buffer
 	buffer := make([]byte, 4096)
 	
 	for {
-		n, err := c.conn.Read(buffer)
+		n, err := conn.Read(buffer)
 		if err != nil {
 			// 如果已经读取了数据，尝试解析
 			if responseBuffer.Len() > 0 {
@@ -1122,9 +1481,11 @@ func (c *RealDubboClient) ListMethods(serviceName string) ([]string, error) {
 		}
 		
 		// 设置较短超时检查更多数据
-		c.conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
 	}
 
+	synced = hasTelnetPrompt(responseBuffer.String())
+
 	// 尝试解析方法列表，使用json.Number保持精度
 	decoder := json.NewDecoder(bytes.NewReader(responseBuffer.Bytes()))
 	decoder.UseNumber()
@@ -1163,21 +1524,14 @@ func (c *RealDubboClient) getDefaultMethods(serviceName string) []string {
 
 // Close 关闭客户端
 func (c *RealDubboClient) Close() error {
-	// 停止异步处理器
-	if c.asyncProcessor != nil {
-		c.asyncProcessor.Stop()
-	}
-	
 	// 停止流式处理器
 	if c.streamProcessor != nil {
 		c.streamProcessor.Stop()
 	}
 	
-	// 关闭网络连接
-	if c.conn != nil {
-		c.conn.Close()
-		c.connected = false
-	}
+	// 连接在每次调用结束时已归还连接池
+	c.providerAddress = ""
+	c.connected = false
 	
 	fmt.Println("真实Dubbo客户端已关闭")
 	return nil
@@ -1201,21 +1555,31 @@ func (c *RealDubboClient) Ping() error {
 	return nil
 }
 
-// convertToGBK 将UTF-8字符串转换为GBK编码的字节数组
-func (c *RealDubboClient) convertToGBK(text string) ([]byte, error) {
-	// 将UTF-8字符串转换为GBK编码
-	reader := transform.NewReader(strings.NewReader(text), simplifiedchinese.GBK.NewEncoder())
-	gbkData, err := io.ReadAll(reader)
+// convertToCharset 将UTF-8字符串转换为指定字符集编码的字节数组
+func (c *RealDubboClient) convertToCharset(text, charset string) ([]byte, error) {
+	encoder := simplifiedchinese.GBK.NewEncoder()
+	if charset == CharsetGB18030 {
+		encoder = simplifiedchinese.GB18030.NewEncoder()
+	}
+	reader := transform.NewReader(strings.NewReader(text), encoder)
+	encoded, err := io.ReadAll(reader)
 	if err != nil {
-		return nil, fmt.Errorf("GBK编码转换失败: %v", err)
+		return nil, fmt.Errorf("%s编码转换失败: %v", charset, err)
 	}
-	return gbkData, nil
+	return encoded, nil
 }
 
-// convertToUTF8 将字节数组从GBK编码转换为UTF-8字符串
+// convertToUTF8 将字节数组从配置的字符集(默认GBK)转换为UTF-8字符串
 func (c *RealDubboClient) convertToUTF8(data []byte) (string, error) {
-	// 尝试GBK解码
-	reader := transform.NewReader(bytes.NewReader(data), simplifiedchinese.GBK.NewDecoder())
+	charset := normalizeCharset(c.config.Charset)
+	if charset == CharsetUTF8 {
+		return string(data), nil
+	}
+	decoder := simplifiedchinese.GBK.NewDecoder()
+	if charset == CharsetGB18030 {
+		decoder = simplifiedchinese.GB18030.NewDecoder()
+	}
+	reader := transform.NewReader(bytes.NewReader(data), decoder)
 	utf8Data, err := io.ReadAll(reader)
 	if err != nil {
 		// 如果GBK解码失败，尝试GB18030
@@ -1228,6 +1592,50 @@ func (c *RealDubboClient) convertToUTF8(data []byte) (string, error) {
 	return string(utf8Data), nil
 }
 
+// normalizeCharset 规范化字符集名称，未配置时使用GBK
+func normalizeCharset(charset string) string {
+	switch strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(charset), "-", "")) {
+	case "UTF8":
+		return CharsetUTF8
+	case "GB18030":
+		return CharsetGB18030
+	default:
+		return CharsetGBK
+	}
+}
+
+// validCharset 检查字符集是否受支持，空值表示使用默认的GBK
+func validCharset(charset string) bool {
+	switch strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(charset), "-", "")) {
+	case "", "GBK", "GB18030", "UTF8":
+		return true
+	}
+	return false
+}
+
+// buildInvokePayload 构建invoke命令及实际发送的字节，返回命令文本、编码后的字节和编码名称
+// 默认将UTF-8编码的命令转换为GBK编码后发送，因为很多Java Dubbo服务端默认使用GBK编码处理中文字符，
+// 服务端使用其他编码时通过配置的charset指定
+func (c *RealDubboClient) buildInvokePayload(serviceName, methodName string, params []interface{}) (string, []byte, string, error) {
+	paramStr, err := c.formatParameters(params)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("参数格式化失败: %v", err)
+	}
+
+	invokeCmd := fmt.Sprintf("invoke %s.%s(%s)\n", serviceName, methodName, paramStr)
+
+	charset := normalizeCharset(c.config.Charset)
+	if charset == CharsetUTF8 {
+		return invokeCmd, []byte(invokeCmd), charset, nil
+	}
+	encoded, err := c.convertToCharset(invokeCmd, charset)
+	if err != nil {
+		fmt.Printf("[DUBBO CLIENT] %v，使用UTF-8\n", err)
+		return invokeCmd, []byte(invokeCmd), CharsetUTF8, nil
+	}
+	return invokeCmd, encoded, charset, nil
+}
+
 // formatParameters 格式化参数，支持各种复杂类型
 func (c *RealDubboClient) formatParameters(params []interface{}) (string, error) {
 	if len(params) == 0 {
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestIsTelnetErrorResponse(t *testing.T) {
+	tests := []struct {
+		text     string
+		failed   bool
+		notFound bool
+	}{
+		{`{"errorCount":0,"data":[]}` + "\r\nelapsed: 1 ms.\r\ndubbo>", false, false},
+		{`{"message":"error","success":true}`, false, false},
+		{`"No provider available"`, false, false},
+		{"null\r\nelapsed: 0 ms.\r\ndubbo>", false, false},
+		{"Failed to invoke method getUserById, cause: java.lang.NullPointerException\r\ndubbo>", true, false},
+		{"\r\nNo such service com.example.UserService\r\ndubbo>", true, true},
+		{"No such method getUser in service com.example.UserService\r\ndubbo>", true, true},
+		{"Invalid json argument, cause: syntax error\r\ndubbo>", true, false},
+		{"java.lang.IllegalArgumentException: id must be positive\r\n\tat com.example.UserServiceImpl.getUserById\r\ndubbo>", true, false},
+		{"com.example.BizError\r\ndubbo>", true, false},
+		{"java.lang.String\r\ndubbo>", false, false},
+	}
+	for _, tt := range tests {
+		if got := isTelnetErrorResponse(tt.text); got != tt.failed {
+			t.Errorf("isTelnetErrorResponse(%q) = %t，期望 %t", tt.text, got, tt.failed)
+		}
+		if got := isTelnetNotFoundResponse(tt.text); got != tt.notFound {
+			t.Errorf("isTelnetNotFoundResponse(%q) = %t，期望 %t", tt.text, got, tt.notFound)
+		}
+	}
+}
+
+func TestGenericInvokeSpool(t *testing.T) {
+	body := `{"data":"` + strings.Repeat("中文", 4000) + `"}`
+	encoded, err := simplifiedchinese.GBK.NewEncoder().String(body + "\r\nelapsed: 3 ms.\r\n" + telnetPrompt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听端口失败: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					if _, err := reader.ReadString('\n'); err != nil {
+						return
+					}
+					// 分多次写入，模拟大响应分段到达
+					for data := []byte(encoded); len(data) > 0; {
+						n := min(len(data), 1000)
+						conn.Write(data[:n])
+						data = data[n:]
+					}
+				}
+			}()
+		}
+	}()
+
+	results, err := NewResultStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("创建结果存储失败: %v", err)
+	}
+	for _, threshold := range []int{1024, 0} {
+		client, err := NewRealDubboClient(&DubboConfig{
+			Registry:       "dubbo://" + listener.Addr().String(),
+			Timeout:        3 * time.Second,
+			ResultSpool:    results,
+			SpoolThreshold: threshold,
+		})
+		if err != nil {
+			t.Fatalf("创建客户端失败: %v", err)
+		}
+		result, err := client.GenericInvoke("com.example.UserService", "getUserById", []string{"java.lang.Long"}, []interface{}{1})
+		client.Close()
+		if err != nil {
+			t.Fatalf("调用失败: %v", err)
+		}
+
+		if threshold == 0 {
+			if result != body {
+				t.Errorf("未配置阈值时应返回完整响应，实际为 %T", result)
+			}
+			continue
+		}
+		stored, ok := result.(*StoredResult)
+		if !ok {
+			t.Fatalf("超过阈值的响应应写入结果文件，实际为 %T", result)
+		}
+		data, err := results.ReadRange(stored.ID, 0, stored.Size)
+		if err != nil || string(data) != body {
+			t.Errorf("结果文件内容不正确 (%d 字节，%v)", len(data), err)
+		}
+		if timings := client.LastTimings(); timings.Server != 3 {
+			t.Errorf("服务端耗时为 %v，期望 3", timings.Server)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+)
+
+// zkSessionTimeout ZooKeeper会话超时和首次建立会话的等待时间
+const zkSessionTimeout = 10 * time.Second
+
+// zkSessions 进程内共享的ZooKeeper会话，按注册中心地址索引
+// 同一进程内的多次调用复用会话，shell和Web服务不必每次调用都重新连接注册中心
+var zkSessions = struct {
+	sync.Mutex
+	conns map[string]*zk.Conn
+}{conns: make(map[string]*zk.Conn)}
+
+// acquireZooKeeper 返回到指定地址的ZooKeeper会话，首次使用时建立连接并等待会话就绪
+// 会话断开后由zk客户端自动重连，调用方不需要关闭返回的连接
+func acquireZooKeeper(address string) (*zk.Conn, error) {
+	zkSessions.Lock()
+	defer zkSessions.Unlock()
+
+	if conn, ok := zkSessions.conns[address]; ok {
+		return conn, nil
+	}
+
+	conn, events, err := zk.Connect([]string{address}, zkSessionTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.After(zkSessionTimeout)
+	for {
+		select {
+		case event := <-events:
+			if event.State != zk.StateHasSession {
+				continue
+			}
+			zkSessions.conns[address] = conn
+			return conn, nil
+		case <-timeout:
+			state := conn.State()
+			conn.Close()
+			return nil, fmt.Errorf("ZooKeeper连接超时，当前状态: %v", state)
+		}
+	}
+}
+
+// closeZooKeeperSessions 关闭所有共享的ZooKeeper会话
+func closeZooKeeperSessions() {
+	zkSessions.Lock()
+	defer zkSessions.Unlock()
+
+	for address, conn := range zkSessions.conns {
+		conn.Close()
+		delete(zkSessions.conns, address)
+	}
+}
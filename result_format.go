@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// 支持的结果输出格式
+const (
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputTable = "table"
+	OutputRaw   = "raw"
+	OutputCSV   = "csv"
+)
+
+// validOutputFormat 判断输出格式是否受支持
+func validOutputFormat(format string) bool {
+	switch format {
+	case OutputJSON, OutputYAML, OutputTable, OutputRaw, OutputCSV:
+		return true
+	}
+	return false
+}
+
+// redirectLogsToStderr 将日志输出重定向到标准错误，返回原标准输出和恢复函数
+// 客户端内部的日志直接写入os.Stdout和color.Output，重定向后标准输出只保留调用结果
+func redirectLogsToStderr() (io.Writer, func()) {
+	stdout := os.Stdout
+	colorOutput := color.Output
+
+	os.Stdout = os.Stderr
+	color.Output = os.Stderr
+
+	return stdout, func() {
+		os.Stdout = stdout
+		color.Output = colorOutput
+	}
+}
+
+// discardLogs 丢弃客户端内部的日志，返回原标准输出和恢复函数
+func discardLogs() (io.Writer, func(), error) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("打开 %s 失败: %v", os.DevNull, err)
+	}
+
+	stdout := os.Stdout
+	colorOutput := color.Output
+
+	os.Stdout = devNull
+	color.Output = devNull
+
+	return stdout, func() {
+		os.Stdout = stdout
+		color.Output = colorOutput
+		devNull.Close()
+	}, nil
+}
+
+// runQuietly 执行fn，verbose为false时丢弃fn执行期间客户端内部的日志
+func runQuietly(verbose bool, fn func() error) error {
+	if verbose {
+		return fn()
+	}
+	_, restore, err := discardLogs()
+	if err != nil {
+		return fn()
+	}
+	defer restore()
+	return fn()
+}
+
+// decodeResult 将客户端返回的JSON字符串解析为对象，数字保持为json.Number以免丢失精度
+// 不是有效JSON时原样返回
+func decodeResult(result interface{}) interface{} {
+	text, ok := result.(string)
+	if !ok {
+		return result
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil || decoder.More() {
+		return text
+	}
+	return parsed
+}
+
+// queryResult 按JSONPath/jq风格的表达式过滤结果
+// 支持 $ 或 . 开头的路径，如 .data.items[0].name、$.data.items[*].skuId、.items[].id、.["key"]
+// 路径中包含 [*] 或 [] 时返回所有匹配值组成的数组
+func queryResult(value interface{}, expr string) (interface{}, error) {
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimPrefix(expr, "$")
+	if expr == "" || expr == "." {
+		return value, nil
+	}
+
+	segments, err := parseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []interface{}{value}
+	wildcard := false
+	for _, segment := range segments {
+		var next []interface{}
+		for _, item := range current {
+			switch {
+			case segment.wildcard:
+				wildcard = true
+				switch v := item.(type) {
+				case []interface{}:
+					next = append(next, v...)
+				case map[string]interface{}:
+					for _, key := range sortedKeys(v) {
+						next = append(next, v[key])
+					}
+				}
+			case segment.index != nil:
+				if list, ok := item.([]interface{}); ok {
+					index := *segment.index
+					if index < 0 {
+						index += len(list)
+					}
+					if index >= 0 && index < len(list) {
+						next = append(next, list[index])
+					}
+				}
+			default:
+				if obj, ok := item.(map[string]interface{}); ok {
+					if fieldValue, exists := obj[segment.key]; exists {
+						next = append(next, fieldValue)
+					}
+				}
+			}
+		}
+		current = next
+	}
+
+	if wildcard {
+		if current == nil {
+			current = []interface{}{}
+		}
+		return current, nil
+	}
+	if len(current) == 0 {
+		return nil, fmt.Errorf("查询路径 %s 没有匹配的结果", expr)
+	}
+	return current[0], nil
+}
+
+// querySegment 查询路径中的一段
+type querySegment struct {
+	key      string
+	index    *int
+	wildcard bool
+}
+
+// parseQuery 解析查询路径
+func parseQuery(expr string) ([]querySegment, error) {
+	var segments []querySegment
+	for i := 0; i < len(expr); {
+		switch expr[i] {
+		case '.':
+			i++
+			if i < len(expr) && expr[i] == '*' {
+				segments = append(segments, querySegment{wildcard: true})
+				i++
+				continue
+			}
+			start := i
+			for i < len(expr) && expr[i] != '.' && expr[i] != '[' {
+				i++
+			}
+			if i > start {
+				segments = append(segments, querySegment{key: expr[start:i]})
+			}
+		case '[':
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("无效的查询路径 %s: 缺少 ]", expr)
+			}
+			inner := strings.TrimSpace(expr[i+1 : i+end])
+			i += end + 1
+
+			switch {
+			case inner == "" || inner == "*":
+				segments = append(segments, querySegment{wildcard: true})
+			case strings.HasPrefix(inner, "'") || strings.HasPrefix(inner, "\""):
+				segments = append(segments, querySegment{key: strings.Trim(inner, "'\"")})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("无效的数组下标 %s", inner)
+				}
+				segments = append(segments, querySegment{index: &index})
+			}
+		default:
+			// 允许省略开头的点，如 data.items
+			start := i
+			for i < len(expr) && expr[i] != '.' && expr[i] != '[' {
+				i++
+			}
+			segments = append(segments, querySegment{key: expr[start:i]})
+		}
+	}
+	return segments, nil
+}
+
+// writeResult 按指定格式输出结果
+func writeResult(w io.Writer, value interface{}, format string) error {
+	switch format {
+	case OutputJSON, "":
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case OutputYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(yamlValue(value)); err != nil {
+			return fmt.Errorf("YAML序列化失败: %v", err)
+		}
+		return encoder.Close()
+	case OutputRaw:
+		_, err := fmt.Fprintln(w, cellText(value))
+		return err
+	case OutputTable:
+		return writeTable(w, value)
+	case OutputCSV:
+		return writeCSV(w, value)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s (可选: json, yaml, table, raw, csv)", format)
+	}
+}
+
+// tabularRows 将结果转换为表头和行：对象数组每个对象一行，单个对象为一行，标量数组为单列
+func tabularRows(value interface{}) ([]string, [][]string) {
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	default:
+		items = []interface{}{v}
+	}
+
+	// 表头取所有对象字段的并集
+	columnSet := make(map[string]bool)
+	hasScalar := false
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			for key := range obj {
+				columnSet[key] = true
+			}
+		} else {
+			hasScalar = true
+		}
+	}
+
+	columns := make([]string, 0, len(columnSet))
+	for key := range columnSet {
+		columns = append(columns, key)
+	}
+	sort.Strings(columns)
+	if hasScalar {
+		columns = append([]string{"value"}, columns...)
+	}
+
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		row := make([]string, len(columns))
+		obj, isObject := item.(map[string]interface{})
+		for i, column := range columns {
+			switch {
+			case isObject:
+				if fieldValue, exists := obj[column]; exists {
+					row[i] = cellText(fieldValue)
+				}
+			case column == "value":
+				row[i] = cellText(item)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows
+}
+
+// writeTable 以对齐的表格输出结果
+func writeTable(w io.Writer, value interface{}) error {
+	columns, rows := tabularRows(value)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeCSV 以CSV输出结果
+func writeCSV(w io.Writer, value interface{}) error {
+	columns, rows := tabularRows(value)
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("CSV输出失败: %v", err)
+	}
+	return nil
+}
+
+// cellText 单元格文本：字符串原样输出，其他值输出紧凑的JSON
+func cellText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// yamlValue 将json.Number转换为YAML数字，超出int64范围的整数保持为字符串以免丢失精度
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if intValue, err := v.Int64(); err == nil {
+			return intValue
+		}
+		if isIntegerString(v.String()) {
+			return v.String()
+		}
+		if floatValue, err := v.Float64(); err == nil {
+			return floatValue
+		}
+		return v.String()
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = yamlValue(item)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = yamlValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
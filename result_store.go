@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// resultSequence 结果序号，保证同一时刻写入的结果ID不重复
+var resultSequence atomic.Uint64
+
+// resultIDPattern 结果ID的格式，读取前校验，避免通过ID访问结果目录之外的文件
+var resultIDPattern = regexp.MustCompile(`^res-[0-9]+(-[0-9]+)?$`)
+
+// StoredResult 写入结果文件的调用结果
+type StoredResult struct {
+	ID        string    `json:"id"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+	path      string
+}
+
+// ResultStore 大结果文件存储，与调用历史保存在同一目录下，历史记录通过resultId引用
+// 保留时间与历史记录一致，超过保留时间的结果会被清理
+type ResultStore struct {
+	dir       string
+	retention time.Duration
+	cleaned   atomic.Int64 // 上次清理的时间(UnixNano)
+}
+
+// ResultWriter 边读取边写入的结果文件，Commit之后才能通过ID读取
+type ResultWriter struct {
+	store *ResultStore
+	id    string
+	file  *os.File
+	size  int64
+	done  bool
+}
+
+// NewResultStore 创建结果存储，retention为0时不按时间清理
+func NewResultStore(dir string, retention time.Duration) (*ResultStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建结果目录失败: %v", err)
+	}
+	return &ResultStore{dir: dir, retention: retention}, nil
+}
+
+// Create 创建结果文件，写入过程中的文件使用.part后缀，不会被读取
+func (rs *ResultStore) Create() (*ResultWriter, error) {
+	rs.cleanup()
+
+	id := fmt.Sprintf("res-%d-%d", time.Now().UnixNano(), resultSequence.Add(1))
+	file, err := os.OpenFile(rs.partPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("创建结果文件失败: %v", err)
+	}
+	return &ResultWriter{store: rs, id: id, file: file}, nil
+}
+
+// Save 将结果写入文件并返回句柄
+func (rs *ResultStore) Save(data []byte) (*StoredResult, error) {
+	writer, err := rs.Create()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Abort()
+		return nil, err
+	}
+	return writer.Commit()
+}
+
+// Write 追加写入结果
+func (w *ResultWriter) Write(data []byte) (int, error) {
+	n, err := w.file.Write(data)
+	w.size += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("写入结果文件失败: %v", err)
+	}
+	return n, nil
+}
+
+// Commit 完成写入，返回结果句柄
+func (w *ResultWriter) Commit() (*StoredResult, error) {
+	if w.done {
+		return nil, fmt.Errorf("结果文件已关闭: %s", w.id)
+	}
+	w.done = true
+	partPath := w.store.partPath(w.id)
+	if err := w.file.Close(); err != nil {
+		os.Remove(partPath)
+		return nil, fmt.Errorf("写入结果文件失败: %v", err)
+	}
+	path := w.store.resultPath(w.id)
+	if err := os.Rename(partPath, path); err != nil {
+		os.Remove(partPath)
+		return nil, fmt.Errorf("写入结果文件失败: %v", err)
+	}
+	return &StoredResult{ID: w.id, Size: w.size, CreatedAt: time.Now(), path: path}, nil
+}
+
+// Abort 放弃写入并删除文件，Commit之后调用不做任何操作
+func (w *ResultWriter) Abort() {
+	if w.done {
+		return
+	}
+	w.done = true
+	w.file.Close()
+	os.Remove(w.store.partPath(w.id))
+}
+
+// Get 获取结果句柄
+func (rs *ResultStore) Get(id string) (*StoredResult, error) {
+	if !resultIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("无效的结果ID: %s", id)
+	}
+	path := rs.resultPath(id)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("结果不存在或已过期: %s", id)
+	}
+	return &StoredResult{ID: id, Size: info.Size(), CreatedAt: info.ModTime(), path: path}, nil
+}
+
+// ReadRange 读取结果文件中从offset开始的最多limit个字节
+func (rs *ResultStore) ReadRange(id string, offset, limit int64) ([]byte, error) {
+	stored, err := rs.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if offset < 0 || offset > stored.Size {
+		return nil, fmt.Errorf("无效的偏移量: %d (结果大小 %d 字节)", offset, stored.Size)
+	}
+
+	file, err := os.Open(stored.path)
+	if err != nil {
+		return nil, fmt.Errorf("打开结果文件失败: %v", err)
+	}
+	defer file.Close()
+
+	if offset+limit > stored.Size {
+		limit = stored.Size - offset
+	}
+	data := make([]byte, limit)
+	n, err := file.ReadAt(data, offset)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("读取结果文件失败: %v", err)
+	}
+	return data[:n], nil
+}
+
+// Open 打开结果文件用于完整下载
+func (rs *ResultStore) Open(id string) (*os.File, *StoredResult, error) {
+	stored, err := rs.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(stored.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("打开结果文件失败: %v", err)
+	}
+	return file, stored, nil
+}
+
+// Clear 删除所有结果文件，正在写入的文件除外
+func (rs *ResultStore) Clear() {
+	rs.removeFiles(func(id string, partial bool, info os.FileInfo) bool { return !partial })
+}
+
+// Retain 只保留历史记录仍在引用的结果，删除其他结果文件和异常退出时残留的未写完的文件
+// 在开始处理请求之前调用；1小时内的未写完文件可能属于其他Web服务进程，予以保留
+func (rs *ResultStore) Retain(ids map[string]bool) {
+	now := time.Now()
+	rs.removeFiles(func(id string, partial bool, info os.FileInfo) bool {
+		if partial {
+			return now.Sub(info.ModTime()) > time.Hour
+		}
+		return !ids[id]
+	})
+}
+
+// cleanup 删除超过保留时间的结果文件，每分钟最多扫描一次目录
+func (rs *ResultStore) cleanup() {
+	if rs.retention <= 0 {
+		return
+	}
+	now := time.Now()
+	last := rs.cleaned.Load()
+	if now.UnixNano()-last < int64(time.Minute) || !rs.cleaned.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	rs.removeFiles(func(id string, partial bool, info os.FileInfo) bool {
+		return now.Sub(info.ModTime()) > rs.retention
+	})
+}
+
+// removeFiles 删除结果目录中满足条件的结果文件，partial表示未写完的文件
+func (rs *ResultStore) removeFiles(remove func(id string, partial bool, info os.FileInfo) bool) {
+	entries, err := os.ReadDir(rs.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		id := strings.TrimSuffix(strings.TrimSuffix(name, ".json"), ".part")
+		if id == name || !resultIDPattern.MatchString(id) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if remove(id, strings.HasSuffix(name, ".part"), info) {
+			os.Remove(filepath.Join(rs.dir, name))
+		}
+	}
+}
+
+// resultPath 结果文件路径
+func (rs *ResultStore) resultPath(id string) string {
+	return filepath.Join(rs.dir, id+".json")
+}
+
+// partPath 写入过程中的结果文件路径
+func (rs *ResultStore) partPath(id string) string {
+	return filepath.Join(rs.dir, id+".part")
+}
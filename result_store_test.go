@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResultStoreWrite(t *testing.T) {
+	store, err := NewResultStore(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("创建结果存储失败: %v", err)
+	}
+
+	writer, err := store.Create()
+	if err != nil {
+		t.Fatalf("创建结果文件失败: %v", err)
+	}
+	writer.Write([]byte(`{"name":`))
+	// 写入完成之前不能读取
+	if _, err := store.Get(writer.id); err == nil {
+		t.Error("未写完的结果不应可以读取")
+	}
+	writer.Write([]byte(`"张三"}`))
+	stored, err := writer.Commit()
+	if err != nil {
+		t.Fatalf("写入结果失败: %v", err)
+	}
+	writer.Abort()
+
+	data, err := store.ReadRange(stored.ID, 8, 100)
+	if err != nil || string(data) != `"张三"}` || stored.Size != 17 {
+		t.Errorf("读取结果为 %q (%v)，大小 %d", data, err, stored.Size)
+	}
+	if _, err := store.ReadRange(stored.ID, 18, 1); err == nil {
+		t.Error("超出结果大小的偏移量应返回错误")
+	}
+
+	// 放弃写入的结果不留下文件
+	aborted, err := store.Create()
+	if err != nil {
+		t.Fatalf("创建结果文件失败: %v", err)
+	}
+	aborted.Write([]byte("partial"))
+	aborted.Abort()
+	if entries, _ := os.ReadDir(store.dir); len(entries) != 1 {
+		t.Errorf("结果目录中有 %d 个文件，期望 1", len(entries))
+	}
+
+	for _, id := range []string{"../history", "res-1/../../x", ""} {
+		if _, err := store.Get(id); err == nil {
+			t.Errorf("无效的结果ID %q 应返回错误", id)
+		}
+	}
+}
+
+func TestResultStoreRetain(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewResultStore(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("创建结果存储失败: %v", err)
+	}
+	referenced, _ := store.Save([]byte("1"))
+	orphan, _ := store.Save([]byte("2"))
+
+	// 异常退出时残留的未写完文件
+	stale, _ := store.Create()
+	stale.file.Close()
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(store.partPath(stale.id), old, old)
+	writing, _ := store.Create()
+	defer writing.Abort()
+
+	store.Retain(map[string]bool{referenced.ID: true})
+	if _, err := store.Get(referenced.ID); err != nil {
+		t.Errorf("历史记录引用的结果被删除: %v", err)
+	}
+	if _, err := store.Get(orphan.ID); err == nil {
+		t.Error("未被引用的结果应已删除")
+	}
+	if _, err := os.Stat(store.partPath(stale.id)); !os.IsNotExist(err) {
+		t.Error("残留的未写完文件应已删除")
+	}
+	if _, err := os.Stat(store.partPath(writing.id)); err != nil {
+		t.Errorf("正在写入的文件被删除: %v", err)
+	}
+
+	// 超过保留时间的结果在下次写入时清理，每分钟最多清理一次
+	os.Chtimes(filepath.Join(dir, referenced.ID+".json"), old, old)
+	store.cleaned.Store(0)
+	if _, err := store.Save([]byte("3")); err != nil {
+		t.Fatalf("写入结果失败: %v", err)
+	}
+	if _, err := store.Get(referenced.ID); err == nil {
+		t.Error("超过保留时间的结果应已删除")
+	}
+
+	store.Clear()
+	if _, err := os.Stat(store.partPath(writing.id)); err != nil {
+		t.Errorf("清空时正在写入的文件被删除: %v", err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(matches) > 0 {
+		t.Errorf("清空后仍有结果文件: %v", matches)
+	}
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// defaultSearchLimit 默认返回的搜索结果数量
+const defaultSearchLimit = 20
+
+// SearchHit 搜索命中的服务或方法，Method为空表示命中服务名
+type SearchHit struct {
+	Service        string           `json:"service"`
+	Method         string           `json:"method,omitempty"`
+	ParameterTypes []string         `json:"parameterTypes,omitempty"`
+	ReturnType     string           `json:"returnType,omitempty"`
+	Variants       []ServiceVariant `json:"variants,omitempty"`
+	Profile        string           `json:"profile,omitempty"`
+	Registry       string           `json:"registry"`
+	Namespace      string           `json:"namespace,omitempty"`
+	Score          int              `json:"score"`
+}
+
+// SearchSource 参与搜索的注册中心
+type SearchSource struct {
+	Profile   string    `json:"profile,omitempty"`
+	Registry  string    `json:"registry"`
+	Namespace string    `json:"namespace,omitempty"`
+	Services  int       `json:"services"`
+	Pending   int       `json:"pending"` // 方法尚未缓存、只能按服务名搜索的服务数
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+	Offline   bool      `json:"offline,omitempty"` // 注册中心不可用，使用缓存的服务目录
+	Error     string    `json:"error,omitempty"`
+}
+
+// SearchResult 搜索结果，按得分从高到低排序
+type SearchResult struct {
+	Query   string         `json:"query"`
+	Total   int            `json:"total"`
+	Hits    []SearchHit    `json:"hits"`
+	Sources []SearchSource `json:"sources"`
+}
+
+// SearchTarget 要搜索的注册中心，Profile为空表示命令行参数或当前环境
+type SearchTarget struct {
+	Profile string
+	Config  *DubboConfig
+}
+
+// SearchOptions 搜索选项
+type SearchOptions struct {
+	Limit   int  // 最多返回的结果数，0表示不限制
+	Refresh bool // 重新获取服务列表和所有服务的方法
+}
+
+// SearchRegistries 在多个注册中心的服务目录中模糊搜索服务名、方法名和参数类型
+// 服务列表过期时从注册中心刷新，方法使用缓存，Refresh时重新获取所有服务的方法
+func SearchRegistries(targets []SearchTarget, query string, options SearchOptions) (*SearchResult, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("搜索关键字不能为空")
+	}
+
+	result := &SearchResult{Query: query, Hits: []SearchHit{}, Sources: []SearchSource{}}
+	for _, target := range targets {
+		catalog, source := loadSearchCatalog(target, options.Refresh)
+		result.Sources = append(result.Sources, source)
+		if catalog == nil {
+			continue
+		}
+		for _, hit := range searchCatalog(catalog, terms) {
+			hit.Profile = target.Profile
+			hit.Registry = catalog.Registry
+			hit.Namespace = catalog.Namespace
+			result.Hits = append(result.Hits, hit)
+		}
+	}
+
+	sort.SliceStable(result.Hits, func(i, j int) bool {
+		a, b := result.Hits[i], result.Hits[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Service)+len(a.Method) != len(b.Service)+len(b.Method) {
+			return len(a.Service)+len(a.Method) < len(b.Service)+len(b.Method)
+		}
+		return a.Service+"."+a.Method < b.Service+"."+b.Method
+	})
+	result.Total = len(result.Hits)
+	if options.Limit > 0 && len(result.Hits) > options.Limit {
+		result.Hits = result.Hits[:options.Limit]
+	}
+	return result, nil
+}
+
+// loadSearchCatalog 加载注册中心的服务目录，过期或要求刷新时从注册中心获取，获取失败时使用缓存
+// 既没有缓存也无法连接注册中心时返回nil，原因记录在SearchSource中
+func loadSearchCatalog(target SearchTarget, refresh bool) (*ServiceCatalog, SearchSource) {
+	source := SearchSource{
+		Profile:   target.Profile,
+		Registry:  target.Config.Registry,
+		Namespace: target.Config.Namespace,
+	}
+	catalog, err := LoadServiceCatalog(target.Config)
+	if err != nil {
+		source.Error = err.Error()
+		return nil, source
+	}
+
+	if refresh || !catalog.Fresh(catalogTTL()) {
+		err = refreshSearchCatalog(catalog, target.Config, refresh)
+		if err != nil && catalog.Empty() {
+			source.Error = err.Error()
+			return nil, source
+		}
+		if err != nil {
+			source.Offline = true
+			source.Error = err.Error()
+		} else if err := catalog.Save(); err != nil {
+			source.Error = err.Error()
+		}
+	}
+
+	source.Services = len(catalog.Services)
+	source.UpdatedAt = catalog.UpdatedAt
+	for _, service := range catalog.Services {
+		if service.MethodsAt.IsZero() {
+			source.Pending++
+		}
+	}
+	return catalog, source
+}
+
+// refreshSearchCatalog 从注册中心获取服务列表，withMethods时同时获取所有服务的方法
+func refreshSearchCatalog(catalog *ServiceCatalog, config *DubboConfig, withMethods bool) error {
+	client, err := NewDubboClient(config)
+	if err != nil {
+		return fmt.Errorf("创建Dubbo客户端失败: %v", err)
+	}
+	defer client.Close()
+
+	if err := catalog.RefreshServices(client); err != nil {
+		return fmt.Errorf("获取服务列表失败: %v", err)
+	}
+	if withMethods {
+		for _, name := range catalog.ServiceNames() {
+			catalog.RefreshMethods(client, name)
+		}
+	}
+	return nil
+}
+
+// searchCatalog 在一个服务目录中搜索，每个关键字都要命中服务名，或命中方法的服务名、方法名或参数类型之一
+// 方法的结果至少要有一个关键字命中方法名或参数类型，只命中服务名时归入服务的结果
+func searchCatalog(catalog *ServiceCatalog, terms []string) []SearchHit {
+	var hits []SearchHit
+	for _, service := range searchableServices(catalog) {
+		name, variants := service.Name, service.Variants
+
+		serviceScores := make([]int, len(terms))
+		serviceMatched := true
+		for i, term := range terms {
+			serviceScores[i] = fuzzyScore(term, name)
+			if serviceScores[i] == 0 {
+				serviceMatched = false
+			}
+		}
+		if serviceMatched {
+			hits = append(hits, SearchHit{Service: name, Variants: variants, Score: sumScores(serviceScores)})
+		}
+
+		for _, method := range service.Methods {
+			score, ok := scoreMethod(terms, serviceScores, method)
+			if !ok {
+				continue
+			}
+			hits = append(hits, SearchHit{
+				Service:        name,
+				Method:         method.Name,
+				ParameterTypes: method.ParameterTypes,
+				ReturnType:     method.ReturnType,
+				Variants:       variants,
+				Score:          score,
+			})
+		}
+	}
+	return hits
+}
+
+// searchableServices 返回按接口名合并的服务，Nacos中每个版本和分组注册为 providers:接口:版本:分组 形式的单独服务
+func searchableServices(catalog *ServiceCatalog) []CatalogService {
+	services := make([]CatalogService, 0, len(catalog.Services))
+	index := make(map[string]int)
+	for _, service := range catalog.Services {
+		iface, version, group, ok := parseNacosServiceName(service.Name)
+		if !ok {
+			index[service.Name] = len(services)
+			services = append(services, service)
+			continue
+		}
+		if len(service.Variants) == 0 {
+			service.Variants = []ServiceVariant{{Version: version, Group: group}}
+		}
+		if i, exists := index[iface]; exists {
+			merged := &services[i]
+			merged.Variants = append(merged.Variants, service.Variants...)
+			if len(merged.Methods) == 0 {
+				merged.Methods = service.Methods
+			}
+			continue
+		}
+		service.Name = iface
+		index[iface] = len(services)
+		services = append(services, service)
+	}
+	return services
+}
+
+// scoreMethod 计算方法的得分，服务名和参数类型的得分减半，使方法名的命中排在前面
+func scoreMethod(terms []string, serviceScores []int, method MethodDefinition) (int, bool) {
+	total := 0
+	ownMatch := false
+	for i, term := range terms {
+		best := serviceScores[i] / 2
+		if score := fuzzyScore(term, method.Name); score > 0 && score >= best {
+			best = score
+			ownMatch = true
+		}
+		for _, paramType := range method.ParameterTypes {
+			if score := fuzzyScore(term, paramType) / 2; score > 0 && score >= best {
+				best = score
+				ownMatch = true
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		total += best
+	}
+	return total, ownMatch
+}
+
+// sumScores 关键字得分之和
+func sumScores(scores []int) int {
+	total := 0
+	for _, score := range scores {
+		total += score
+	}
+	return total
+}
+
+// fuzzyScore 计算关键字(小写)与文本的匹配得分，0表示不匹配
+// 依次为: 完全相同、与简单类名相同、简单类名前缀、单词开头的子串、子串、按顺序出现的字符(如 usvc 匹配 UserService)
+// 顺序字符匹配时，落在单词开头或连续的字符得分更高，过于分散的匹配视为不匹配
+func fuzzyScore(term, text string) int {
+	lower := strings.ToLower(text)
+	simple := lower
+	if dot := strings.LastIndexAny(lower, ".$"); dot >= 0 {
+		simple = lower[dot+1:]
+	}
+
+	switch {
+	case lower == term:
+		return 1000
+	case simple == term:
+		return 900
+	case strings.HasPrefix(simple, term):
+		return 800
+	}
+	if index := strings.Index(lower, term); index >= 0 {
+		if wordStart([]rune(text), len([]rune(lower[:index]))) {
+			return 600
+		}
+		return 400
+	}
+
+	runes := []rune(text)
+	lowerRunes := []rune(lower)
+	termRunes := []rune(term)
+	score, matched, last := 0, 0, -2
+	for i := 0; i < len(lowerRunes) && matched < len(termRunes); i++ {
+		if lowerRunes[i] != termRunes[matched] {
+			continue
+		}
+		switch {
+		case wordStart(runes, i):
+			score += 4
+		case i == last+1:
+			score += 3
+		default:
+			score++
+		}
+		last = i
+		matched++
+	}
+	if matched < len(termRunes) || score < 2*len(termRunes) {
+		return 0
+	}
+	// 顺序字符匹配的得分低于子串匹配
+	if score = 100 + score*10; score > 399 {
+		score = 399
+	}
+	return score
+}
+
+// wordStart 判断位置i是否为单词开头: 文本开头、分隔符之后或驼峰的大写字母
+func wordStart(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	previous, current := runes[i-1], runes[i]
+	if strings.ContainsRune(".$_-:/<>, ", previous) {
+		return true
+	}
+	return unicode.IsUpper(current) && !unicode.IsUpper(previous)
+}
+
+// handleSearch 处理 /api/search，在一个或多个环境的注册中心中模糊搜索服务和方法
+// 参数: q 关键字，profiles 逗号分隔的环境(all表示所有环境，为空时使用当前环境)，limit 结果数，refresh=true 重新获取所有方法
+func (ws *WebServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		ws.writeError(w, "只支持GET方法")
+		return
+	}
+
+	query := r.URL.Query()
+	options := SearchOptions{Limit: defaultSearchLimit, Refresh: query.Get("refresh") == "true"}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			ws.writeError(w, fmt.Sprintf("limit参数无效: %s", value))
+			return
+		}
+		options.Limit = limit
+	}
+
+	var names []string
+	for _, name := range strings.Split(query.Get("profiles"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	targets, err := searchTargets(ws.configPath, names, func(config *DubboConfig) {
+		config.Registry = firstNonEmpty(config.Registry, ws.registry)
+		config.Application = firstNonEmpty(config.Application, ws.app)
+		config.MetadataFile = ws.metadataFile
+	})
+	if err == nil && len(names) == 0 {
+		config := &DubboConfig{Registry: ws.registry, Application: ws.app, Timeout: 5 * time.Second, MetadataFile: ws.metadataFile}
+		err = ws.applyProfile("", config)
+		targets = []SearchTarget{{Profile: ws.profile, Config: config}}
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		ws.writeError(w, err.Error())
+		return
+	}
+
+	var result *SearchResult
+	err = runQuietly(false, func() error {
+		result, err = SearchRegistries(targets, query.Get("q"), options)
+		return err
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		ws.writeError(w, err.Error())
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(map[string]interface{}{
+		"success": true,
+		"result":  result,
+	})
+}
+
+// searchTargets 按环境名生成搜索目标，names为 all 时搜索配置文件中的所有环境
+// defaults 为环境中未配置的注册中心和应用名填入默认值
+func searchTargets(configPath string, names []string, defaults func(config *DubboConfig)) ([]SearchTarget, error) {
+	if len(names) == 1 && names[0] == "all" {
+		cm := NewConfigManagerAt(configPath)
+		if !cm.Exists() {
+			return nil, fmt.Errorf("配置文件 %s 不存在，请先执行 dubbo-invoke config init", cm.GetConfigPath())
+		}
+		if err := cm.LoadConfig(); err != nil {
+			return nil, err
+		}
+		names = cm.ProfileNames()
+		if len(names) == 0 {
+			return nil, fmt.Errorf("配置文件 %s 中没有环境", cm.GetConfigPath())
+		}
+	}
+
+	targets := make([]SearchTarget, 0, len(names))
+	for _, name := range names {
+		profile, err := LoadActiveProfile(configPath, name)
+		if err != nil {
+			return nil, err
+		}
+		profile.Explicit = true
+		config := &DubboConfig{
+			Registry:    profile.Registry,
+			Application: profile.App,
+			Timeout:     5 * time.Second,
+		}
+		defaults(config)
+		profile.Apply(config)
+		targets = append(targets, SearchTarget{Profile: name, Config: config})
+	}
+	return targets, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// search命令 - 在一个或多个注册中心中模糊搜索服务和方法
+func newSearchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search <关键字>...",
+		Short: "模糊搜索服务名、方法名和参数类型",
+		Long: `在注册中心的服务目录中模糊搜索服务名、方法名和参数类型，结果按匹配程度排序
+
+多个关键字之间为"且"的关系；关键字可以是名称的一部分，也可以是按顺序出现的字符，如 usvc 匹配 UserService
+服务和方法来自 ~/.dubbo-invoke/cache 下的服务目录缓存，服务列表过期时自动刷新，
+尚未缓存方法的服务只能按服务名搜索，执行 cache refresh 或使用 --refresh 获取所有服务的方法
+
+示例:
+  dubbo-invoke search user
+  dubbo-invoke search order create
+  dubbo-invoke search UserReq --profiles test,prod
+  dubbo-invoke search usvc --all-profiles -o json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runSearchCommand,
+	}
+
+	cmd.Flags().StringSlice("profiles", nil, "搜索的环境，逗号分隔，默认为当前环境")
+	cmd.Flags().BoolP("all-profiles", "A", false, "搜索配置文件中的所有环境")
+	cmd.Flags().Bool("refresh", false, "重新获取服务列表和所有服务的方法(服务较多时较慢)")
+	cmd.Flags().IntP("limit", "n", defaultSearchLimit, "最多显示的结果数，0表示不限制")
+	cmd.Flags().StringP("output", "o", "table", "输出格式: table|json")
+	cmd.Flags().String("metadata", "", "服务定义元数据文件(JSON)，用于获取方法和参数类型")
+
+	return cmd
+}
+
+// runSearchCommand 执行搜索并输出结果
+func runSearchCommand(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	output, _ := cmd.Flags().GetString("output")
+	if output != "table" && output != "json" {
+		return fmt.Errorf("不支持的输出格式: %s (可选: table, json)", output)
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+
+	targets, err := searchTargetsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if refresh {
+		color.Cyan("正在从 %d 个注册中心获取服务和方法...", len(targets))
+	}
+
+	var result *SearchResult
+	err = runQuietly(verbose, func() error {
+		result, err = SearchRegistries(targets, strings.Join(args, " "), SearchOptions{Limit: limit, Refresh: refresh})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		return writeResult(os.Stdout, result, OutputJSON)
+	}
+	printSearchResult(result)
+	return nil
+}
+
+// searchTargetsFromFlags 按 --profiles 和 --all-profiles 生成搜索目标，都未指定时使用命令行参数和当前环境
+func searchTargetsFromFlags(cmd *cobra.Command) ([]SearchTarget, error) {
+	configPath, _ := cmd.Flags().GetString("config")
+	names, _ := cmd.Flags().GetStringSlice("profiles")
+	if all, _ := cmd.Flags().GetBool("all-profiles"); all {
+		names = []string{"all"}
+	}
+
+	if len(names) == 0 {
+		target := SearchTarget{Config: catalogConfig(cmd)}
+		if profile := activeProfile(cmd); profile != nil {
+			target.Profile = profile.Name
+		}
+		return []SearchTarget{target}, nil
+	}
+
+	registry, _ := cmd.Flags().GetString("registry")
+	appName, _ := cmd.Flags().GetString("app")
+	metadataFile, _ := cmd.Flags().GetString("metadata")
+	return searchTargets(configPath, names, func(config *DubboConfig) {
+		config.Registry = firstNonEmpty(config.Registry, registry)
+		config.Application = firstNonEmpty(config.Application, appName)
+		config.MetadataFile = metadataFile
+	})
+}
+
+// printSearchResult 以表格输出搜索结果和各注册中心的状态
+func printSearchResult(result *SearchResult) {
+	for _, source := range result.Sources {
+		name := searchSourceName(source.Profile, source.Registry, source.Namespace)
+		switch {
+		case source.Offline:
+			color.Yellow("%s: %s，使用%s缓存的服务目录", name, source.Error, describeAge(source.UpdatedAt))
+		case source.Error != "" && source.Services == 0:
+			color.Red("%s: %s", name, source.Error)
+		case source.Error != "":
+			color.Yellow("%s: %s", name, source.Error)
+		}
+	}
+
+	if len(result.Hits) == 0 {
+		color.Yellow("没有匹配 %q 的服务或方法", result.Query)
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "服务/方法\t版本/分组\t注册中心")
+		for _, hit := range result.Hits {
+			name := hit.Service
+			if hit.Method != "" {
+				name += "." + hit.Method + "(" + strings.Join(hit.ParameterTypes, ", ") + ")"
+			}
+			variants := make([]string, len(hit.Variants))
+			for i, variant := range hit.Variants {
+				variants[i] = firstNonEmpty(variant.String(), "-")
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", name, firstNonEmpty(strings.Join(variants, ", "), "-"),
+				searchSourceName(hit.Profile, hit.Registry, hit.Namespace))
+		}
+		tw.Flush()
+		if len(result.Hits) < result.Total {
+			color.Cyan("显示前 %d 个，共 %d 个结果，使用 --limit 查看更多", len(result.Hits), result.Total)
+		}
+	}
+
+	pending := 0
+	for _, source := range result.Sources {
+		pending += source.Pending
+	}
+	if pending > 0 {
+		color.Cyan("%d 个服务的方法尚未缓存，只按服务名搜索；执行 dubbo-invoke cache refresh 或使用 --refresh 后可以搜索方法和参数类型", pending)
+	}
+}
+
+// searchSourceName 描述结果所属的注册中心和命名空间，有环境名时放在前面
+func searchSourceName(profile, registry, namespace string) string {
+	name := registry
+	if namespace != "" {
+		name += "@" + namespace
+	}
+	if profile != "" {
+		name = "[" + profile + "] " + name
+	}
+	return name
+}
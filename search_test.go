@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		term, text string
+		want       int
+	}{
+		{"com.example.userservice", "com.example.UserService", 1000},
+		{"userservice", "com.example.UserService", 900},
+		{"user", "com.example.UserService", 800},
+		{"service", "com.example.UserService", 600}, // 驼峰单词开头
+		{"example", "com.example.UserService", 600}, // 分隔符之后
+		{"serv", "getUserServer", 600},
+		{"erserv", "com.example.UserService", 400},
+		{"order", "com.example.UserService", 0},
+	}
+	for _, tt := range tests {
+		if got := fuzzyScore(tt.term, tt.text); got != tt.want {
+			t.Errorf("fuzzyScore(%q, %q) = %d，期望 %d", tt.term, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyScoreSubsequence(t *testing.T) {
+	// 按顺序出现的字符得分低于子串匹配，落在单词开头的匹配得分更高
+	usvc := fuzzyScore("usvc", "com.example.UserService")
+	if usvc <= 0 || usvc >= 400 {
+		t.Fatalf("usvc 匹配 UserService 的得分为 %d，应在 (0, 400) 之间", usvc)
+	}
+	camel := fuzzyScore("gubi", "getUserById")
+	if lower := fuzzyScore("gubi", "getUserbyid"); lower <= 0 || lower >= camel {
+		t.Errorf("gubi 匹配 getUserbyid 的得分 %d 应大于0且低于匹配 getUserById 的得分 %d", lower, camel)
+	}
+	// 过于分散的匹配视为不匹配
+	if got := fuzzyScore("ace", "com.example.UserService"); got != 0 {
+		t.Errorf("ace 匹配 UserService 的得分为 %d，应为0", got)
+	}
+}
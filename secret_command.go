@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// secret命令 - 管理加密保存的凭据
+func newSecretCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secret",
+		Short: "管理加密保存的注册中心凭据",
+		Long: `管理加密保存的注册中心密码等凭据，凭据文件位于 ~/.dubbo-invoke/secrets.json，
+配置文件中通过 password: secret://<名称> 引用，调用时才解密，不会出现在输出、历史记录和 config show 中
+
+凭据使用AES-256-GCM加密，密钥有两种来源:
+  密钥文件: 默认方式，首次保存凭据时生成 ~/.dubbo-invoke/secret.key，可以通过 DUBBO_INVOKE_KEY_FILE 放到其他位置
+  主密码:   通过 secret init --passphrase 创建，使用时在终端输入，或通过 DUBBO_INVOKE_PASSPHRASE 环境变量提供
+
+示例:
+  dubbo-invoke secret set nacos-prod
+  echo "$NACOS_PASSWORD" | dubbo-invoke secret set nacos-prod -d "生产Nacos"
+  dubbo-invoke config set profiles.prod.password secret://nacos-prod
+  dubbo-invoke secret list
+  dubbo-invoke secret rm nacos-prod`,
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "创建凭据文件，默认使用密钥文件，指定 --passphrase 时使用主密码",
+		Args:  cobra.NoArgs,
+		RunE:  runSecretInitCommand,
+	}
+	initCmd.Flags().Bool("passphrase", false, "使用主密码派生密钥，不生成密钥文件")
+	cmd.AddCommand(initCmd)
+
+	setCmd := &cobra.Command{
+		Use:   "set <name> [value]",
+		Short: "保存凭据，不指定值时在终端中输入(不回显)或从标准输入读取",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  runSecretSetCommand,
+	}
+	setCmd.Flags().StringP("description", "d", "", "凭据说明")
+	cmd.AddCommand(setCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "列出凭据及引用它们的配置项，不显示凭据的值",
+		Args:  cobra.NoArgs,
+		RunE:  runSecretListCommand,
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "rm <name>",
+		Short: "删除凭据",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runSecretRemoveCommand,
+	})
+
+	return cmd
+}
+
+// openSecretStore 打开默认位置的凭据文件
+func openSecretStore() (*SecretStore, error) {
+	path, err := secretStorePath()
+	if err != nil {
+		return nil, err
+	}
+	return OpenSecretStore(path)
+}
+
+// initSecretStore 创建凭据文件并输出密钥的保存位置
+func initSecretStore(store *SecretStore, usePassphrase bool) error {
+	if err := store.Init(usePassphrase); err != nil {
+		return err
+	}
+	color.Green("已创建凭据文件: %s", store.Path())
+	if usePassphrase {
+		color.Cyan("凭据使用主密码加密，请牢记主密码，遗忘后无法找回")
+		return nil
+	}
+	keyPath, _ := secretKeyFilePath()
+	color.Cyan("凭据使用密钥文件 %s 加密，请妥善保管，丢失后无法解密", keyPath)
+	return nil
+}
+
+// runSecretInitCommand 创建凭据文件
+func runSecretInitCommand(cmd *cobra.Command, args []string) error {
+	usePassphrase, _ := cmd.Flags().GetBool("passphrase")
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	return initSecretStore(store, usePassphrase)
+}
+
+// runSecretSetCommand 保存凭据，凭据文件不存在时自动创建
+// 设置了 DUBBO_INVOKE_PASSPHRASE 时使用主密码，否则使用密钥文件
+func runSecretSetCommand(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	name := args[0]
+	if err := validateSecretName(name); err != nil {
+		return err
+	}
+	description, _ := cmd.Flags().GetString("description")
+
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	if !store.Exists() {
+		if err := initSecretStore(store, os.Getenv("DUBBO_INVOKE_PASSPHRASE") != ""); err != nil {
+			return err
+		}
+	} else if err := store.Unlock(); err != nil {
+		return err
+	}
+
+	var value string
+	if len(args) > 1 {
+		value = args[1]
+		color.Yellow("提示: 命令行中的值可能保存在shell历史中，建议省略值在终端中输入")
+	} else if value, err = readSecretValue(name); err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("凭据的值不能为空")
+	}
+
+	if err := store.Set(name, value, description); err != nil {
+		return err
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	color.Green("已保存凭据 %s", name)
+	color.Cyan("在配置文件中引用: dubbo-invoke config set profiles.<环境>.password %s%s", secretRefPrefix, name)
+	return nil
+}
+
+// runSecretListCommand 列出凭据，只读取名称和说明，不需要解密
+func runSecretListCommand(cmd *cobra.Command, args []string) error {
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	names := store.Names()
+	if len(names) == 0 {
+		color.Yellow("没有保存任何凭据，可以通过 dubbo-invoke secret set <名称> 保存")
+		return nil
+	}
+
+	refs := secretReferencesByName(cmd)
+	source := "密钥文件"
+	if store.KeySource() == secretKeyPassphrase {
+		source = "主密码"
+	}
+	color.Cyan("凭据文件: %s (加密方式: %s)", store.Path(), source)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "名称\t更新时间\t引用\t说明")
+	for _, name := range names {
+		entry := store.Entry(name)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, entry.UpdatedAt.Format("2006-01-02 15:04:05"),
+			strings.Join(refs[name], ", "), entry.Description)
+	}
+	return tw.Flush()
+}
+
+// runSecretRemoveCommand 删除凭据，仍被配置文件引用时提示
+func runSecretRemoveCommand(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	name := args[0]
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Remove(name); err != nil {
+		return err
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	color.Green("已删除凭据 %s", name)
+	if refs := secretReferencesByName(cmd)[name]; len(refs) > 0 {
+		color.Yellow("配置项 %s 仍引用该凭据，请修改后再调用", strings.Join(refs, ", "))
+	}
+	return nil
+}
+
+// secretReferencesByName 读取配置文件中的凭据引用，按凭据名称分组，配置文件有误时尽量读取
+func secretReferencesByName(cmd *cobra.Command) map[string][]string {
+	configFile, _ := cmd.Flags().GetString("config")
+	cm := NewConfigManagerAt(configFile)
+	refs := make(map[string][]string)
+	if _, _, err := cm.readDocument(); err != nil || cm.doc == nil {
+		return refs
+	}
+	for _, ref := range configSecretReferences(cm.doc.Content[0]) {
+		refs[ref.Name] = append(refs[ref.Name], ref.Key)
+	}
+	return refs
+}
@@ -0,0 +1,525 @@
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// secretRefPrefix 配置文件中引用凭据的前缀，如 password: secret://nacos-prod
+const secretRefPrefix = "secret://"
+
+// 密钥来源
+const (
+	secretKeyPassphrase = "passphrase" // 由主密码派生
+	secretKeyFile       = "keyfile"    // 随机生成的密钥文件
+)
+
+// secretCheckText 用于校验密钥是否正确的固定内容
+const secretCheckText = "dubbo-invoke"
+
+// secretNamePattern 凭据名称
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// secretPasswordPattern 匹配URL和日志中的密码参数，用于隐藏密码
+var secretPasswordPattern = regexp.MustCompile(`(?i)(password=)[^&\s"']*`)
+
+// SecretEntry 一条加密保存的凭据
+type SecretEntry struct {
+	Value       string    `json:"value"` // base64(nonce + AES-GCM密文)
+	Description string    `json:"description,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// secretFile 凭据文件的内容，只有凭据的值是加密的
+type secretFile struct {
+	Version   int                     `json:"version"`
+	KeySource string                  `json:"keySource"`
+	Salt      string                  `json:"salt,omitempty"` // 主密码派生密钥使用的盐
+	Check     string                  `json:"check"`          // 加密的固定内容，用于校验主密码或密钥文件
+	Secrets   map[string]*SecretEntry `json:"secrets"`
+}
+
+// SecretStore 加密保存注册中心密码等凭据，默认位于 ~/.dubbo-invoke/secrets.json
+// 密钥由主密码(DUBBO_INVOKE_PASSPHRASE 或交互输入)通过scrypt派生，或保存在密钥文件中
+type SecretStore struct {
+	path string
+	file secretFile
+	key  []byte // 解锁后的AES-256密钥
+}
+
+var (
+	defaultSecretStore     *SecretStore
+	defaultSecretStoreLock sync.Mutex
+)
+
+// secretStorePath 凭据文件路径
+func secretStorePath() (string, error) {
+	dir, err := dubboInvokeHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "secrets.json"), nil
+}
+
+// secretKeyFilePath 密钥文件路径，可以通过 DUBBO_INVOKE_KEY_FILE 环境变量放到其他位置
+func secretKeyFilePath() (string, error) {
+	if path := os.Getenv("DUBBO_INVOKE_KEY_FILE"); path != "" {
+		return path, nil
+	}
+	dir, err := dubboInvokeHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "secret.key"), nil
+}
+
+// isSecretRef 是否为凭据引用
+func isSecretRef(value string) bool {
+	return strings.HasPrefix(value, secretRefPrefix)
+}
+
+// validateSecretName 校验凭据名称
+func validateSecretName(name string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("凭据名称 %q 无效，只能包含字母、数字、点、下划线和连字符", name)
+	}
+	return nil
+}
+
+// maskPassword 隐藏密码用于展示，凭据引用不含密码，原样展示
+func maskPassword(value string) string {
+	if value == "" || isSecretRef(value) {
+		return value
+	}
+	return "******"
+}
+
+// redactSecrets 隐藏文本中URL参数形式的密码，用于输出请求地址和错误信息
+func redactSecrets(text string) string {
+	return secretPasswordPattern.ReplaceAllString(text, "${1}******")
+}
+
+// resolveSecret 解析配置中的密码，secret://<名称> 从凭据文件中读取，其他值原样返回
+func resolveSecret(value string) (string, error) {
+	if !isSecretRef(value) {
+		return value, nil
+	}
+	name := strings.TrimPrefix(value, secretRefPrefix)
+	if err := validateSecretName(name); err != nil {
+		return "", err
+	}
+	store, err := unlockDefaultSecretStore()
+	if err != nil {
+		return "", err
+	}
+	return store.Get(name)
+}
+
+// unlockDefaultSecretStore 打开并解锁默认位置的凭据文件，解锁后在进程内复用，只需输入一次主密码
+func unlockDefaultSecretStore() (*SecretStore, error) {
+	defaultSecretStoreLock.Lock()
+	defer defaultSecretStoreLock.Unlock()
+	if defaultSecretStore != nil {
+		return defaultSecretStore, nil
+	}
+
+	path, err := secretStorePath()
+	if err != nil {
+		return nil, err
+	}
+	store, err := OpenSecretStore(path)
+	if err != nil {
+		return nil, err
+	}
+	if !store.Exists() {
+		return nil, fmt.Errorf("凭据文件 %s 不存在，请先执行 dubbo-invoke secret set 保存凭据", path)
+	}
+	if err := store.Unlock(); err != nil {
+		return nil, err
+	}
+	defaultSecretStore = store
+	return store, nil
+}
+
+// OpenSecretStore 读取凭据文件，不解密；文件不存在时返回空的凭据文件
+func OpenSecretStore(path string) (*SecretStore, error) {
+	store := &SecretStore{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取凭据文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &store.file); err != nil {
+		return nil, fmt.Errorf("解析凭据文件 %s 失败: %v", path, err)
+	}
+	if store.file.Secrets == nil {
+		store.file.Secrets = make(map[string]*SecretEntry)
+	}
+	return store, nil
+}
+
+// Exists 凭据文件是否已创建
+func (s *SecretStore) Exists() bool {
+	return s.file.Version > 0
+}
+
+// Path 凭据文件路径
+func (s *SecretStore) Path() string {
+	return s.path
+}
+
+// KeySource 密钥来源: passphrase 或 keyfile
+func (s *SecretStore) KeySource() string {
+	return s.file.KeySource
+}
+
+// Init 创建凭据文件，usePassphrase 为 true 时使用主密码，否则生成密钥文件
+func (s *SecretStore) Init(usePassphrase bool) error {
+	if s.Exists() {
+		return fmt.Errorf("凭据文件 %s 已存在", s.path)
+	}
+	s.file = secretFile{Version: 1, Secrets: make(map[string]*SecretEntry)}
+
+	if usePassphrase {
+		passphrase, err := readPassphrase(true)
+		if err != nil {
+			return err
+		}
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("生成随机数失败: %v", err)
+		}
+		s.file.KeySource = secretKeyPassphrase
+		s.file.Salt = base64.StdEncoding.EncodeToString(salt)
+		if s.key, err = derivePassphraseKey(passphrase, salt); err != nil {
+			return err
+		}
+	} else {
+		s.file.KeySource = secretKeyFile
+		key, err := loadOrCreateKeyFile()
+		if err != nil {
+			return err
+		}
+		s.key = key
+	}
+
+	check, err := s.encrypt("", secretCheckText)
+	if err != nil {
+		return err
+	}
+	s.file.Check = check
+	return s.Save()
+}
+
+// Unlock 读取密钥并校验，主密码错误或密钥文件不匹配时返回错误
+func (s *SecretStore) Unlock() error {
+	if s.key != nil {
+		return nil
+	}
+	var key []byte
+	switch s.file.KeySource {
+	case secretKeyPassphrase:
+		passphrase, err := readPassphrase(false)
+		if err != nil {
+			return err
+		}
+		salt, err := base64.StdEncoding.DecodeString(s.file.Salt)
+		if err != nil {
+			return fmt.Errorf("凭据文件 %s 已损坏: %v", s.path, err)
+		}
+		if key, err = derivePassphraseKey(passphrase, salt); err != nil {
+			return err
+		}
+	case secretKeyFile:
+		keyPath, err := secretKeyFilePath()
+		if err != nil {
+			return err
+		}
+		if key, err = readKeyFile(keyPath); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("凭据文件 %s 的密钥来源 %q 无效", s.path, s.file.KeySource)
+	}
+
+	s.key = key
+	if check, err := s.decrypt("", s.file.Check); err != nil || check != secretCheckText {
+		s.key = nil
+		if s.file.KeySource == secretKeyPassphrase {
+			return fmt.Errorf("主密码错误，无法解密凭据文件 %s", s.path)
+		}
+		return fmt.Errorf("密钥文件与凭据文件 %s 不匹配", s.path)
+	}
+	return nil
+}
+
+// Names 凭据名称，按名称排序
+func (s *SecretStore) Names() []string {
+	names := make([]string, 0, len(s.file.Secrets))
+	for name := range s.file.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Entry 返回凭据的描述信息，不解密
+func (s *SecretStore) Entry(name string) *SecretEntry {
+	return s.file.Secrets[name]
+}
+
+// Get 解密凭据
+func (s *SecretStore) Get(name string) (string, error) {
+	entry := s.file.Secrets[name]
+	if entry == nil {
+		return "", fmt.Errorf("凭据 %s 不存在，请先执行 dubbo-invoke secret set %s", name, name)
+	}
+	if err := s.Unlock(); err != nil {
+		return "", err
+	}
+	value, err := s.decrypt(name, entry.Value)
+	if err != nil {
+		return "", fmt.Errorf("解密凭据 %s 失败: %v", name, err)
+	}
+	return value, nil
+}
+
+// Set 加密保存凭据，需要先解锁
+func (s *SecretStore) Set(name, value, description string) error {
+	if err := validateSecretName(name); err != nil {
+		return err
+	}
+	if err := s.Unlock(); err != nil {
+		return err
+	}
+	encrypted, err := s.encrypt(name, value)
+	if err != nil {
+		return err
+	}
+	if description == "" && s.file.Secrets[name] != nil {
+		description = s.file.Secrets[name].Description
+	}
+	s.file.Secrets[name] = &SecretEntry{Value: encrypted, Description: description, UpdatedAt: time.Now()}
+	return nil
+}
+
+// Remove 删除凭据，不需要解锁
+func (s *SecretStore) Remove(name string) error {
+	if s.file.Secrets[name] == nil {
+		return fmt.Errorf("凭据 %s 不存在", name)
+	}
+	delete(s.file.Secrets, name)
+	return nil
+}
+
+// Save 写入凭据文件，先写临时文件再重命名，只允许当前用户读取
+func (s *SecretStore) Save() error {
+	data, err := json.MarshalIndent(s.file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化凭据失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("创建凭据目录失败: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".secrets-*.json")
+	if err != nil {
+		return fmt.Errorf("写入凭据文件失败: %v", err)
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入凭据文件失败: %v", err)
+	}
+	return nil
+}
+
+// encrypt 使用AES-256-GCM加密，凭据名称作为附加数据，防止密文被挪用到其他名称下
+func (s *SecretStore) encrypt(name, value string) (string, error) {
+	gcm, err := s.aead()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %v", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt 解密 encrypt 生成的密文
+func (s *SecretStore) decrypt(name, value string) (string, error) {
+	gcm, err := s.aead()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("密文格式无效")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("密文校验失败")
+	}
+	return string(plain), nil
+}
+
+// aead 使用解锁后的密钥创建AES-GCM
+func (s *SecretStore) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, fmt.Errorf("初始化加密失败: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// derivePassphraseKey 由主密码派生AES-256密钥
+func derivePassphraseKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %v", err)
+	}
+	return key, nil
+}
+
+// readPassphrase 读取主密码，优先使用 DUBBO_INVOKE_PASSPHRASE 环境变量，否则在终端中输入(不回显)
+func readPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv("DUBBO_INVOKE_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("需要主密码解密凭据，请设置 DUBBO_INVOKE_PASSPHRASE 环境变量")
+	}
+	passphrase, err := promptHidden("请输入主密码: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("主密码不能为空")
+	}
+	if confirm {
+		again, err := promptHidden("请再次输入主密码: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("两次输入的主密码不一致")
+		}
+	}
+	return passphrase, nil
+}
+
+// promptHidden 在终端中输入内容，不回显，提示输出到标准错误
+func promptHidden(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("读取输入失败: %v", err)
+	}
+	return string(data), nil
+}
+
+// readSecretValue 读取凭据的值：终端中不回显输入，否则从标准输入读取一行，便于脚本通过管道传入
+func readSecretValue(name string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return promptHidden(fmt.Sprintf("请输入凭据 %s 的值: ", name))
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("读取标准输入失败: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// loadOrCreateKeyFile 读取密钥文件，不存在时生成随机密钥
+func loadOrCreateKeyFile() ([]byte, error) {
+	path, err := secretKeyFilePath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return readKeyFile(path)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("生成密钥失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("创建密钥目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("写入密钥文件失败: %v", err)
+	}
+	return key, nil
+}
+
+// readKeyFile 读取base64编码的密钥文件
+func readKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("密钥文件 %s 不存在，无法解密凭据，可以通过 DUBBO_INVOKE_KEY_FILE 指定密钥文件位置", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败: %v", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("密钥文件 %s 格式无效", path)
+	}
+	return key, nil
+}
+
+// secretReference 配置文件中对凭据的引用
+type secretReference struct {
+	Name string // 凭据名称
+	Key  string // 引用凭据的配置项
+	Line int
+}
+
+// configSecretReferences 查找配置文件中通过 secret:// 引用的凭据
+func configSecretReferences(root *yaml.Node) []secretReference {
+	var refs []secretReference
+	add := func(key string, node *yaml.Node) {
+		if node != nil && node.Kind == yaml.ScalarNode && isSecretRef(node.Value) {
+			refs = append(refs, secretReference{Name: strings.TrimPrefix(node.Value, secretRefPrefix), Key: key, Line: node.Line})
+		}
+	}
+	add("registry.password", yamlLookupPath(root, []string{"registry", "password"}))
+	if profiles := yamlMappingValue(root, "profiles"); profiles != nil && profiles.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(profiles.Content); i += 2 {
+			name := profiles.Content[i].Value
+			add("profiles."+name+".password", yamlMappingValue(profiles.Content[i+1], "password"))
+		}
+	}
+	return refs
+}
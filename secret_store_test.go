@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretStoreRoundTrip(t *testing.T) {
+	tests := []struct {
+		name          string
+		usePassphrase bool
+	}{
+		{"密钥文件", false},
+		{"主密码", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("DUBBO_INVOKE_HOME", dir)
+			t.Setenv("DUBBO_INVOKE_KEY_FILE", "")
+			t.Setenv("DUBBO_INVOKE_PASSPHRASE", "correct horse")
+			path := filepath.Join(dir, "secrets.json")
+
+			store, err := OpenSecretStore(path)
+			if err != nil {
+				t.Fatalf("打开凭据文件失败: %v", err)
+			}
+			if err := store.Init(tt.usePassphrase); err != nil {
+				t.Fatalf("创建凭据文件失败: %v", err)
+			}
+			if err := store.Set("nacos-prod", "p@ss:word", "生产Nacos"); err != nil {
+				t.Fatalf("保存凭据失败: %v", err)
+			}
+			if err := store.Save(); err != nil {
+				t.Fatalf("写入凭据文件失败: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(data), "p@ss:word") {
+				t.Error("凭据文件中不应出现明文")
+			}
+
+			reopened, err := OpenSecretStore(path)
+			if err != nil {
+				t.Fatalf("打开凭据文件失败: %v", err)
+			}
+			if got, err := reopened.Get("nacos-prod"); err != nil || got != "p@ss:word" {
+				t.Errorf("读取凭据为 %q (%v)", got, err)
+			}
+			if entry := reopened.Entry("nacos-prod"); entry == nil || entry.Description != "生产Nacos" {
+				t.Errorf("凭据描述为 %+v", entry)
+			}
+
+			// 密文挪用到其他名称下无法解密
+			reopened.file.Secrets["zk-prod"] = reopened.file.Secrets["nacos-prod"]
+			if _, err := reopened.Get("zk-prod"); err == nil {
+				t.Error("挪用到其他名称下的密文应解密失败")
+			}
+
+			if err := reopened.Remove("nacos-prod"); err != nil {
+				t.Fatalf("删除凭据失败: %v", err)
+			}
+			if _, err := reopened.Get("nacos-prod"); err == nil {
+				t.Error("删除后读取凭据应返回错误")
+			}
+		})
+	}
+}
+
+func TestSecretStoreWrongKey(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DUBBO_INVOKE_HOME", dir)
+	t.Setenv("DUBBO_INVOKE_PASSPHRASE", "correct horse")
+	path := filepath.Join(dir, "secrets.json")
+
+	store, _ := OpenSecretStore(path)
+	if err := store.Init(true); err != nil {
+		t.Fatalf("创建凭据文件失败: %v", err)
+	}
+
+	t.Setenv("DUBBO_INVOKE_PASSPHRASE", "wrong")
+	reopened, err := OpenSecretStore(path)
+	if err != nil {
+		t.Fatalf("打开凭据文件失败: %v", err)
+	}
+	if err := reopened.Unlock(); err == nil || !strings.Contains(err.Error(), "主密码错误") {
+		t.Errorf("主密码错误时应返回错误，实际 %v", err)
+	}
+	if err := reopened.Set("nacos-prod", "secret", ""); err == nil {
+		t.Error("未解锁时不应能保存凭据")
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"nacos://127.0.0.1:8848?username=nacos&password=s3cret&namespace=dev", "nacos://127.0.0.1:8848?username=nacos&password=******&namespace=dev"},
+		{`登录失败: "Password=abc"`, `登录失败: "Password=******"`},
+		{"zookeeper://127.0.0.1:2181", "zookeeper://127.0.0.1:2181"},
+	}
+	for _, tt := range tests {
+		if got := redactSecrets(tt.text); got != tt.want {
+			t.Errorf("redactSecrets(%q) = %q，期望 %q", tt.text, got, tt.want)
+		}
+	}
+	if got := maskPassword("secret://nacos-prod"); got != "secret://nacos-prod" {
+		t.Errorf("凭据引用应原样展示，实际 %q", got)
+	}
+}
@@ -0,0 +1,1240 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// shellCommands shell的内置命令，用于补全
+var shellCommands = []string{"cd", "desc", "exit", "group", "help", "ls", "methods", "output", "quit",
+	"reload", "services", "set", "tag", "timeout", "unset", "use", "vars", "version"}
+
+// shellVarPattern 调用参数中引用的会话变量，如 $last、$user.data.id、$list[0]
+var shellVarPattern = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)((?:\.[A-Za-z_][A-Za-z0-9_]*|\[[^\]]*\])*)`)
+
+// 终端中按下Ctrl+C时x/term直接结束输入，读取前将其转换为 keyShellInterrupt+回车，只放弃当前输入
+const (
+	keyCtrlC          = 3
+	keyShellInterrupt = 7
+)
+
+// shellLine 非终端模式下读取的一行输入
+type shellLine struct {
+	text string
+	err  error
+}
+
+// Shell 交互式调用会话，只连接一次注册中心，会话内复用ZooKeeper会话和服务提供者连接
+type Shell struct {
+	config    *DubboConfig
+	client    *DubboClient
+	profile   string
+	verbose   bool
+	noHistory bool
+	output    string
+
+	services  []string        // 注册中心中的服务，reload时刷新
+	catalog   *ServiceCatalog // 本地服务目录缓存，注册中心不可用时离线浏览
+	offline   bool            // 服务列表来自缓存
+	attempted map[string]bool // 本次会话已尝试获取方法的服务，reload前不再重复获取
+	pkg       string          // 当前所在的包
+	service   string          // 当前使用的服务
+	vars      map[string]interface{}
+
+	terminal    *term.Terminal // 标准输入不是终端时为nil
+	fd          int
+	interrupted bool
+	lines       chan shellLine
+	interrupts  chan os.Signal
+}
+
+// shell命令 - 交互式调用
+func newShellCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shell",
+		Short: "交互式调用Dubbo服务，支持服务、方法和参数补全",
+		Long: `启动交互式shell，只连接一次注册中心，之后的调用复用ZooKeeper会话和到服务提供者的连接
+
+按Tab补全命令、服务、方法和参数骨架，括号未闭合时可以继续输入多行JSON
+调用成功的结果保存在 $last 中，可以在之后的参数中引用，如 getOrder($last.data.orderId)
+
+示例:
+  dubbo-invoke shell
+  dubbo-invoke shell -P test -V 1.0.0
+
+  dubbo> use com.example.UserService
+  dubbo UserService> getUserById(123)
+  dubbo UserService> updateUser($last.data)
+  dubbo UserService> cd ..
+  dubbo com.example> OrderService.getOrder({"orderId": 1})
+
+也可以通过管道执行脚本:
+  dubbo-invoke shell < calls.txt`,
+		Args: cobra.NoArgs,
+		RunE: runShellCommand,
+	}
+
+	cmd.Flags().StringP("version", "V", "", "服务版本")
+	cmd.Flags().StringP("group", "g", "", "服务分组")
+	cmd.Flags().String("tag", "", "服务标签，用于过滤服务提供者")
+	cmd.Flags().String("metadata", "", "服务定义元数据文件(JSON)，用于补全方法、参数骨架和校验参数")
+	cmd.Flags().StringP("output", "o", "json", "结果输出格式: json|yaml|table|raw|csv")
+	cmd.Flags().Bool("no-history", false, "不将调用写入历史记录")
+
+	return cmd
+}
+
+// runShellCommand 启动交互式shell
+func runShellCommand(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	registry, _ := cmd.Flags().GetString("registry")
+	appName, _ := cmd.Flags().GetString("app")
+	timeout, _ := cmd.Flags().GetInt("timeout")
+	version, _ := cmd.Flags().GetString("version")
+	group, _ := cmd.Flags().GetString("group")
+	tag, _ := cmd.Flags().GetString("tag")
+	metadataFile, _ := cmd.Flags().GetString("metadata")
+	output, _ := cmd.Flags().GetString("output")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	noHistory, _ := cmd.Flags().GetBool("no-history")
+
+	if !validOutputFormat(output) {
+		return fmt.Errorf("不支持的输出格式: %s (可选: json, yaml, table, raw, csv)", output)
+	}
+
+	config := &DubboConfig{
+		Registry:       registry,
+		Application:    appName,
+		Timeout:        time.Duration(timeout) * time.Millisecond,
+		Version:        version,
+		Group:          group,
+		Tag:            tag,
+		MetadataFile:   metadataFile,
+		MaxPayloadSize: maxPayloadBytes(cmd),
+	}
+	profile := activeProfile(cmd)
+	profile.Apply(config)
+
+	shell := &Shell{
+		config:     config,
+		verbose:    verbose,
+		noHistory:  noHistory,
+		output:     output,
+		attempted:  make(map[string]bool),
+		vars:       make(map[string]interface{}),
+		fd:         int(os.Stdin.Fd()),
+		interrupts: make(chan os.Signal, 1),
+	}
+	if profile != nil {
+		shell.profile = profile.Name
+	}
+
+	// 会话期间自行处理Ctrl+C：调用中取消本次调用，等待输入时放弃当前输入
+	signal.Notify(shell.interrupts, os.Interrupt)
+	defer signal.Stop(shell.interrupts)
+	defer closeZooKeeperSessions()
+	defer getConnectionPool().Close()
+
+	color.Cyan("连接注册中心: %s", config.Registry)
+	err := shell.quietly(func() error {
+		client, err := NewDubboClient(config)
+		shell.client = client
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("创建Dubbo客户端失败: %v", err)
+	}
+	defer shell.quietly(shell.client.Close)
+
+	if shell.catalog, err = LoadServiceCatalog(config); err != nil {
+		return err
+	}
+
+	if err := shell.reload(); err != nil && shell.offline {
+		color.Yellow("获取服务列表失败: %v", err)
+		color.Yellow("使用%s缓存的服务目录离线浏览 (%d 个服务)，reload 重试", describeAge(shell.catalog.UpdatedAt), len(shell.services))
+	} else if err != nil {
+		color.Yellow("获取服务列表失败: %v，仍然可以通过 服务.方法(参数) 调用", err)
+	} else {
+		color.Green("已连接，共 %d 个服务，输入 help 查看帮助，按Tab补全", len(shell.services))
+	}
+
+	return shell.Run()
+}
+
+// Run 循环读取并执行输入，直到输入 exit 或 Ctrl+D
+func (s *Shell) Run() error {
+	if term.IsTerminal(s.fd) {
+		s.terminal = term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{&interruptReader{r: os.Stdin}, os.Stdout}, "")
+		s.terminal.AutoCompleteCallback = s.complete
+	} else {
+		s.lines = make(chan shellLine)
+		go readShellLines(os.Stdin, s.lines)
+	}
+
+	for {
+		input, err := s.readInput()
+		if err == io.EOF {
+			if s.terminal != nil {
+				fmt.Println()
+			}
+			return nil
+		}
+		if err != nil {
+			color.Red("错误: %v", err)
+			continue
+		}
+
+		command := strings.Fields(input)
+		if len(command) > 0 && (command[0] == "exit" || command[0] == "quit") {
+			return nil
+		}
+		if err := s.execute(input); err != nil {
+			color.Red("错误: %v", err)
+		}
+	}
+}
+
+// interruptReader 将终端输入中的Ctrl+C转换为 keyShellInterrupt+回车
+type interruptReader struct {
+	r       io.Reader
+	pending []byte
+}
+
+func (ir *interruptReader) Read(p []byte) (int, error) {
+	if len(ir.pending) == 0 {
+		buf := make([]byte, len(p))
+		n, err := ir.r.Read(buf)
+		if n == 0 {
+			return 0, err
+		}
+		ir.pending = bytes.ReplaceAll(buf[:n], []byte{keyCtrlC}, []byte{keyShellInterrupt, '\r'})
+	}
+	n := copy(p, ir.pending)
+	ir.pending = ir.pending[n:]
+	return n, nil
+}
+
+// readShellLines 逐行读取非终端的标准输入，如通过管道传入的脚本
+func readShellLines(r io.Reader, lines chan<- shellLine) {
+	reader := bufio.NewReader(r)
+	for {
+		text, err := reader.ReadString('\n')
+		text = strings.TrimRight(text, "\r\n")
+		if err == nil || text != "" {
+			lines <- shellLine{text: text}
+		}
+		if err != nil {
+			lines <- shellLine{err: err}
+			return
+		}
+	}
+}
+
+// readInput 读取一条输入，括号未闭合时继续读取下一行
+func (s *Shell) readInput() (string, error) {
+	var buffer []string
+	prompt := s.prompt()
+	for {
+		line, err := s.readLine(prompt)
+		if err == errShellInterrupted {
+			return "", nil
+		}
+		if err == io.EOF && len(buffer) > 0 {
+			return "", fmt.Errorf("输入不完整，括号或引号未闭合")
+		}
+		if err != nil {
+			return "", err
+		}
+
+		buffer = append(buffer, line)
+		input := strings.Join(buffer, "\n")
+		if inputComplete(input) {
+			return input, nil
+		}
+		prompt = "... "
+	}
+}
+
+// errShellInterrupted 输入时按下了Ctrl+C
+var errShellInterrupted = fmt.Errorf("输入已取消")
+
+// readLine 读取一行输入，终端中只在读取时切换到原始模式，调用的输出不受影响
+func (s *Shell) readLine(prompt string) (string, error) {
+	if s.terminal == nil {
+		select {
+		case line := <-s.lines:
+			return line.text, line.err
+		case <-s.interrupts:
+			return "", io.EOF
+		}
+	}
+
+	if width, height, err := term.GetSize(s.fd); err == nil && width > 0 {
+		s.terminal.SetSize(width, height)
+	}
+	s.terminal.SetPrompt(prompt)
+	state, err := term.MakeRaw(s.fd)
+	if err != nil {
+		return "", fmt.Errorf("切换终端模式失败: %v", err)
+	}
+	s.interrupted = false
+	line, err := s.terminal.ReadLine()
+	term.Restore(s.fd, state)
+	if s.interrupted {
+		return "", errShellInterrupted
+	}
+	return line, err
+}
+
+// prompt 返回提示符，包含当前环境和所在位置
+func (s *Shell) prompt() string {
+	location := s.pkg
+	if s.service != "" {
+		location = simpleServiceName(s.service)
+	}
+	if s.profile != "" {
+		location = strings.TrimSpace("[" + s.profile + "] " + location)
+	}
+	if location == "" {
+		return "dubbo> "
+	}
+	return "dubbo " + location + "> "
+}
+
+// inputComplete 判断输入的括号和引号是否已闭合
+func inputComplete(input string) bool {
+	depth := 0
+	inQuotes, escaped := false, false
+	for _, ch := range input {
+		switch {
+		case escaped:
+			escaped = false
+		case ch == '\\' && inQuotes:
+			escaped = true
+		case ch == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case ch == '(' || ch == '{' || ch == '[':
+			depth++
+		case ch == ')' || ch == '}' || ch == ']':
+			depth--
+		}
+	}
+	return depth <= 0 && !inQuotes
+}
+
+// execute 执行一条输入
+func (s *Shell) execute(input string) error {
+	input = strings.TrimSpace(input)
+	if input == "" || strings.HasPrefix(input, "#") {
+		return nil
+	}
+	if isInvocation(input) {
+		return s.invokeExpression(input)
+	}
+	if strings.HasPrefix(input, "$") {
+		value, err := s.evalVariable(input)
+		if err != nil {
+			return err
+		}
+		return writeResult(os.Stdout, value, s.output)
+	}
+
+	name := strings.Fields(input)[0]
+	arg := strings.TrimSpace(input[len(name):])
+	switch name {
+	case "help":
+		printShellHelp()
+	case "services":
+		for _, service := range s.services {
+			if arg == "" || containsFold(service, arg) {
+				fmt.Println(service)
+			}
+		}
+	case "ls":
+		return s.list(arg)
+	case "cd":
+		return s.changeDirectory(arg)
+	case "use":
+		if arg == "" {
+			if s.service == "" {
+				return fmt.Errorf("未选择服务，用法: use <服务>")
+			}
+			fmt.Println(s.service)
+			return nil
+		}
+		service, err := s.resolveService(arg)
+		if err != nil {
+			return err
+		}
+		s.service, s.pkg = service, packageOf(service)
+	case "methods":
+		service := s.service
+		if arg != "" {
+			var err error
+			if service, err = s.resolveService(arg); err != nil {
+				return err
+			}
+		}
+		if service == "" {
+			return fmt.Errorf("未选择服务，用法: methods <服务>")
+		}
+		return s.printMethods(service)
+	case "desc":
+		return s.describe(arg)
+	case "vars":
+		s.printVariables()
+	case "set":
+		return s.setVariable(arg)
+	case "unset":
+		name := strings.TrimPrefix(arg, "$")
+		if _, ok := s.vars[name]; !ok {
+			return fmt.Errorf("变量 $%s 未定义", name)
+		}
+		delete(s.vars, name)
+	case "version":
+		s.config.Version = s.setting("版本", s.config.Version, arg)
+	case "group":
+		s.config.Group = s.setting("分组", s.config.Group, arg)
+	case "tag":
+		s.config.Tag = s.setting("标签", s.config.Tag, arg)
+	case "timeout":
+		if arg == "" {
+			fmt.Printf("超时: %dms\n", s.config.Timeout.Milliseconds())
+			return nil
+		}
+		millis, err := strconv.Atoi(strings.TrimSuffix(arg, "ms"))
+		if err != nil || millis <= 0 {
+			return fmt.Errorf("无效的超时时间: %s，单位为毫秒", arg)
+		}
+		s.config.Timeout = time.Duration(millis) * time.Millisecond
+	case "output":
+		if arg == "" {
+			fmt.Printf("输出格式: %s\n", s.output)
+			return nil
+		}
+		if !validOutputFormat(arg) {
+			return fmt.Errorf("不支持的输出格式: %s (可选: json, yaml, table, raw, csv)", arg)
+		}
+		s.output = arg
+	case "reload":
+		s.catalog.ExpireMethods()
+		s.attempted = make(map[string]bool)
+		if err := s.reload(); err != nil {
+			return fmt.Errorf("获取服务列表失败: %v", err)
+		}
+		color.Green("共 %d 个服务", len(s.services))
+	default:
+		return fmt.Errorf("未知命令: %s，输入 help 查看帮助", name)
+	}
+	return nil
+}
+
+// printShellHelp 输出shell帮助
+func printShellHelp() {
+	fmt.Println(`调用:
+  方法(参数...)               调用当前服务的方法，如 getUserById(123)
+  服务.方法(参数...)          调用指定服务的方法，服务名可以是相对当前包的名称
+  $变量[.路径]                输出变量，如 $last.data.items[0]
+  参数中的 $变量[.路径] 会替换为变量的值，括号未闭合时可以继续输入多行
+
+导航:
+  ls [路径]                   列出包和服务，已选择服务时列出方法
+  cd <路径>                   进入包或服务，支持 ..、/ 和相对路径，不指定路径时回到根
+  use <服务>                  选择服务，可以只写类名
+  services [关键字]           列出所有服务
+  methods [服务]              列出方法及参数类型
+  desc <方法>                 查看方法签名和参数骨架
+
+设置:
+  version|group|tag [值|-]    查看或设置版本、分组、标签，- 表示清除
+  timeout [毫秒]              查看或设置超时时间
+  output [格式]               查看或设置输出格式: json|yaml|table|raw|csv
+  set <变量> <值>             设置变量，值可以是JSON或引用其他变量
+  unset <变量>                删除变量
+  vars                        列出变量
+  reload                      刷新服务列表和方法缓存
+
+  Tab 补全命令、服务、方法和参数骨架，Ctrl+C 放弃当前输入或取消正在进行的调用
+  exit、quit 或 Ctrl+D 退出`)
+}
+
+// setting 查看或修改调用设置，值为 - 时清除
+func (s *Shell) setting(label, current, value string) string {
+	switch value {
+	case "":
+		fmt.Printf("%s: %s\n", label, firstNonEmpty(current, "(未设置)"))
+		return current
+	case "-":
+		return ""
+	}
+	return value
+}
+
+// quietly 执行fn，非详细模式下丢弃客户端内部的日志
+func (s *Shell) quietly(fn func() error) error {
+	return runQuietly(s.verbose, fn)
+}
+
+// reload 重新获取服务列表并更新服务目录缓存，失败时使用缓存的服务列表
+func (s *Shell) reload() error {
+	err := s.quietly(func() error {
+		return s.catalog.RefreshServices(s.client)
+	})
+	s.services = s.catalog.ServiceNames()
+	s.offline = err != nil && !s.catalog.Empty()
+	if err != nil {
+		return err
+	}
+	s.quietly(s.catalog.Save)
+	return nil
+}
+
+// loadMethods 获取服务的方法，优先使用服务目录缓存；没有服务定义时使用提供者注册的方法名
+func (s *Shell) loadMethods(service string) ([]MethodDefinition, map[string]*TypeDefinition) {
+	cached := s.catalog.Service(service)
+	if cached != nil && (s.offline || s.attempted[service] || cached.MethodsFresh(catalogTTL())) {
+		return cached.Methods, cached.TypeMap()
+	}
+
+	s.attempted[service] = true
+	err := s.quietly(func() error {
+		if _, err := s.catalog.RefreshMethods(s.client, service); err != nil {
+			return err
+		}
+		return s.catalog.Save()
+	})
+	if err != nil && s.verbose {
+		color.Yellow("获取服务 %s 的方法失败: %v", service, err)
+	}
+	if cached = s.catalog.Service(service); cached == nil {
+		return nil, nil
+	}
+	return cached.Methods, cached.TypeMap()
+}
+
+// methodParamTypes 按服务目录中的方法元数据确定参数类型
+// 没有元数据或参数个数相同的重载不止一个时返回nil，由调用时按参数值推断
+func (s *Shell) methodParamTypes(service, method string, argCount int) []string {
+	methods, _ := s.loadMethods(service)
+	var types []string
+	matched := 0
+	for _, definition := range methods {
+		if definition.Name == method && definition.ParameterTypes != nil && len(definition.ParameterTypes) == argCount {
+			types = definition.ParameterTypes
+			matched++
+		}
+	}
+	if matched != 1 {
+		return nil
+	}
+	return types
+}
+
+// resolveService 将输入的服务名解析为完整服务名
+// 依次匹配完整服务名、相对当前包的服务名和唯一的类名；服务列表中没有时按完整服务名使用
+func (s *Shell) resolveService(name string) (string, error) {
+	name = strings.Trim(strings.TrimSpace(name), "/")
+	if name == "" {
+		return "", fmt.Errorf("服务名不能为空")
+	}
+	if s.hasService(name) {
+		return name, nil
+	}
+	if s.pkg != "" && s.hasService(s.pkg+"."+name) {
+		return s.pkg + "." + name, nil
+	}
+
+	var matches []string
+	for _, service := range s.services {
+		if strings.HasSuffix(service, "."+name) {
+			matches = append(matches, service)
+		}
+	}
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1:
+		return "", fmt.Errorf("服务 %s 不唯一: %s", name, strings.Join(matches, ", "))
+	case len(s.services) == 0 && s.pkg != "" && !strings.Contains(name, "."):
+		return s.pkg + "." + name, nil
+	case strings.Contains(name, ".") || len(s.services) == 0:
+		// 服务列表中没有的服务可能是刚注册的，按完整服务名调用
+		return name, nil
+	}
+	return "", fmt.Errorf("服务 %s 不存在，可以执行 reload 刷新服务列表", name)
+}
+
+// hasService 判断服务列表中是否有该服务
+func (s *Shell) hasService(name string) bool {
+	index := sort.SearchStrings(s.services, name)
+	return index < len(s.services) && s.services[index] == name
+}
+
+// hasPackage 判断是否有服务位于该包下
+func (s *Shell) hasPackage(pkg string) bool {
+	index := sort.SearchStrings(s.services, pkg+".")
+	return index < len(s.services) && strings.HasPrefix(s.services[index], pkg+".")
+}
+
+// changeDirectory 进入包或服务
+func (s *Shell) changeDirectory(arg string) error {
+	if arg == "" || arg == "/" {
+		s.pkg, s.service = "", ""
+		return nil
+	}
+
+	location := s.pkg
+	if s.service != "" {
+		location = s.service
+	}
+	path := joinServicePath(location, arg)
+	switch {
+	case path == "":
+		s.pkg, s.service = "", ""
+	case s.hasService(path):
+		s.pkg, s.service = packageOf(path), path
+	case s.hasPackage(path) || len(s.services) == 0:
+		// 没有服务列表时(如直连模式)不检查路径
+		s.pkg, s.service = path, ""
+	default:
+		// 相对路径不存在时按完整服务名或类名查找
+		service, err := s.resolveService(arg)
+		if err != nil || (!s.hasService(service) && len(s.services) > 0) {
+			return fmt.Errorf("路径 %s 不存在", arg)
+		}
+		s.pkg, s.service = packageOf(service), service
+	}
+	return nil
+}
+
+// joinServicePath 按cd的规则拼接路径，支持 .. 和以 / 开头的绝对路径，包名之间可以用 . 或 / 分隔
+func joinServicePath(location, arg string) string {
+	var segments []string
+	if !strings.HasPrefix(arg, "/") && location != "" {
+		segments = strings.Split(location, ".")
+	}
+	for _, part := range strings.Split(arg, "/") {
+		if part == ".." {
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+			continue
+		}
+		for _, segment := range strings.Split(part, ".") {
+			if segment != "" {
+				segments = append(segments, segment)
+			}
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+// packageOf 返回服务所在的包
+func packageOf(service string) string {
+	if index := strings.LastIndex(service, "."); index >= 0 {
+		return service[:index]
+	}
+	return ""
+}
+
+// simpleServiceName 返回服务的类名
+func simpleServiceName(service string) string {
+	return service[strings.LastIndex(service, ".")+1:]
+}
+
+// children 返回包下一级的子包(以 . 结尾)和服务
+func (s *Shell) children(pkg string) []string {
+	prefix := ""
+	if pkg != "" {
+		prefix = pkg + "."
+	}
+	seen := make(map[string]bool)
+	var entries []string
+	for _, service := range s.services {
+		if !strings.HasPrefix(service, prefix) {
+			continue
+		}
+		rest := service[len(prefix):]
+		if index := strings.Index(rest, "."); index >= 0 {
+			rest = rest[:index+1]
+		}
+		if !seen[rest] {
+			seen[rest] = true
+			entries = append(entries, rest)
+		}
+	}
+	return entries
+}
+
+// list 列出包下的子包和服务，已选择服务时列出方法
+func (s *Shell) list(arg string) error {
+	location := s.pkg
+	if s.service != "" {
+		location = s.service
+	}
+	path := joinServicePath(location, arg)
+	if s.hasService(path) || (arg == "" && s.service != "") {
+		return s.printMethods(path)
+	}
+	if path != "" && !s.hasPackage(path) && len(s.services) > 0 {
+		return fmt.Errorf("路径 %s 不存在", arg)
+	}
+	for _, entry := range s.children(path) {
+		if strings.HasSuffix(entry, ".") {
+			color.Cyan("%s", entry)
+		} else {
+			fmt.Println(entry)
+		}
+	}
+	return nil
+}
+
+// printMethods 列出服务的方法及参数类型
+func (s *Shell) printMethods(service string) error {
+	methods, _ := s.loadMethods(service)
+	if len(methods) == 0 {
+		return fmt.Errorf("没有找到服务 %s 的方法，注册中心和元数据中都没有方法信息", service)
+	}
+	for _, method := range methods {
+		fmt.Println(methodSignature(method))
+	}
+	return nil
+}
+
+// methodSignature 返回方法签名，没有参数类型元数据时只有方法名
+func methodSignature(method MethodDefinition) string {
+	if method.ParameterTypes == nil && method.ReturnType == "" {
+		return method.Name
+	}
+	signature := fmt.Sprintf("%s(%s)", method.Name, strings.Join(method.ParameterTypes, ", "))
+	if method.ReturnType != "" {
+		signature += " " + method.ReturnType
+	}
+	return signature
+}
+
+// describe 输出方法的签名和参数骨架
+func (s *Shell) describe(arg string) error {
+	service, method := s.service, arg
+	if index := strings.LastIndex(arg, "."); index >= 0 {
+		var err error
+		if service, err = s.resolveService(arg[:index]); err != nil {
+			return err
+		}
+		method = arg[index+1:]
+	}
+	if service == "" || method == "" {
+		return fmt.Errorf("用法: desc <方法> 或 desc <服务>.<方法>")
+	}
+
+	methods, types := s.loadMethods(service)
+	found := false
+	for _, definition := range methods {
+		if definition.Name != method {
+			continue
+		}
+		found = true
+		color.Cyan("%s", methodSignature(definition))
+		if definition.ParameterTypes != nil {
+			fmt.Printf("  %s(%s)\n", method, parameterSkeleton(definition.ParameterTypes, types))
+		}
+	}
+	if !found {
+		return fmt.Errorf("服务 %s 中没有方法 %s", service, method)
+	}
+	return nil
+}
+
+// parameterSkeleton 按参数类型生成参数骨架，有类型定义的参数列出所有字段
+func parameterSkeleton(paramTypes []string, types map[string]*TypeDefinition) string {
+	values := make([]string, len(paramTypes))
+	for i, paramType := range paramTypes {
+		values[i] = typeSkeleton(paramType, types, make(map[string]bool))
+	}
+	return strings.Join(values, ", ")
+}
+
+// typeSkeleton 生成单个类型的JSON骨架，字段按名称排序，class放在最前
+func typeSkeleton(javaType string, types map[string]*TypeDefinition, visiting map[string]bool) string {
+	javaType = strings.TrimSpace(javaType)
+	if definition, ok := types[javaType]; ok {
+		if len(definition.Enums) > 0 {
+			return strconv.Quote(definition.Enums[0])
+		}
+		if len(definition.Properties) > 0 && !visiting[javaType] {
+			visiting[javaType] = true
+			defer delete(visiting, javaType)
+			fields := []string{`"class":` + strconv.Quote(javaType)}
+			names := make([]string, 0, len(definition.Properties))
+			for name := range definition.Properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fields = append(fields, strconv.Quote(name)+":"+typeSkeleton(definition.Properties[name], types, visiting))
+			}
+			return "{" + strings.Join(fields, ",") + "}"
+		}
+	}
+
+	if strings.HasSuffix(javaType, "[]") {
+		return "[" + typeSkeleton(strings.TrimSuffix(javaType, "[]"), types, visiting) + "]"
+	}
+	baseType, typeArgs := splitGenericType(javaType)
+	switch baseType {
+	case "short", "byte", "java.lang.Short", "java.lang.Byte", "java.math.BigDecimal", "java.math.BigInteger":
+		return "0"
+	case "char", "java.lang.Character":
+		return `""`
+	case "java.lang.Object":
+		return "null"
+	}
+	if strings.HasSuffix(baseType, "Set") || strings.HasSuffix(baseType, "Collection") {
+		if len(typeArgs) == 1 {
+			return "[" + typeSkeleton(typeArgs[0], types, visiting) + "]"
+		}
+		return "[]"
+	}
+
+	ti := NewTypeInferrer()
+	switch paramType := ti.InferType(baseType); paramType {
+	case TypeString:
+		return `""`
+	case TypeArray:
+		if len(typeArgs) == 1 {
+			return "[" + typeSkeleton(typeArgs[0], types, visiting) + "]"
+		}
+		return "[]"
+	case TypeMap:
+		return "{}"
+	case TypeObject:
+		return `{"class":` + strconv.Quote(baseType) + "}"
+	default:
+		data, _ := json.Marshal(ti.GenerateDefaultValue(paramType, baseType))
+		return string(data)
+	}
+}
+
+// isInvocation 判断输入是否为调用表达式，如 method(...) 或 Service.method(...)
+func isInvocation(input string) bool {
+	index := strings.Index(input, "(")
+	return index > 0 && !strings.ContainsAny(input[:index], " \t$") && strings.HasSuffix(input, ")")
+}
+
+// invokeExpression 解析并执行调用表达式
+func (s *Shell) invokeExpression(input string) error {
+	index := strings.Index(input, "(")
+	target, argsText := input[:index], input[index+1:len(input)-1]
+
+	service, method := s.service, target
+	if dot := strings.LastIndex(target, "."); dot >= 0 {
+		var err error
+		if service, err = s.resolveService(target[:dot]); err != nil {
+			return err
+		}
+		method = target[dot+1:]
+	} else if service == "" {
+		return fmt.Errorf("未选择服务，请先执行 use <服务>，或以 服务.方法(参数) 的形式调用")
+	}
+
+	argsText, err := s.substituteVariables(argsText)
+	if err != nil {
+		return err
+	}
+	params, err := parseParams(parseParametersFromExpression(argsText), nil)
+	if err != nil {
+		return fmt.Errorf("解析参数失败: %v", err)
+	}
+	return s.invoke(service, method, params)
+}
+
+// invoke 调用方法并输出结果，成功的结果保存到 $last
+func (s *Shell) invoke(service, method string, params []interface{}) error {
+	// 丢弃等待输入时收到的中断，调用中按下Ctrl+C只取消本次调用
+	select {
+	case <-s.interrupts:
+	default:
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.interrupts:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	types := s.methodParamTypes(service, method, len(params))
+	var result interface{}
+	var duration int64
+	var invokeErr error
+	err := s.quietly(func() error {
+		if err := ValidateInvokeArguments(s.config, service, method, types, params); err != nil {
+			return err
+		}
+		start := time.Now()
+		result, invokeErr = s.client.GenericInvokeContext(ctx, service, method, types, params)
+		duration = time.Since(start).Milliseconds()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !s.noHistory {
+		// 没有元数据时记录按参数值推断出的类型，与实际发送的一致，重新调用时不必再推断
+		if types == nil {
+			_, types, _ = s.client.prepareParams(nil, params)
+		}
+		saveCLIHistory(s.config, s.profile, service, method, types, params, result, invokeErr, duration, nil, nil)
+	}
+	if invokeErr != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("调用已取消")
+		}
+		return fmt.Errorf("调用失败: %v", invokeErr)
+	}
+
+	value := decodeResult(result)
+	s.vars["last"] = value
+	if provider := s.client.LastProvider(); provider != "" {
+		color.Green("调用成功 (%dms, 提供者 %s):", duration, provider)
+	} else {
+		color.Green("调用成功 (%dms):", duration)
+	}
+	if s.output == OutputRaw {
+		return writeResult(os.Stdout, result, s.output)
+	}
+	return writeResult(os.Stdout, value, s.output)
+}
+
+// evalVariable 求值变量引用，如 $last.data.items[0]
+func (s *Shell) evalVariable(ref string) (interface{}, error) {
+	match := shellVarPattern.FindStringSubmatch(ref)
+	if match == nil || len(match[0]) != len(ref) {
+		return nil, fmt.Errorf("无效的变量引用: %s", ref)
+	}
+	value, ok := s.vars[match[1]]
+	if !ok {
+		return nil, fmt.Errorf("变量 $%s 未定义", match[1])
+	}
+	if match[2] == "" {
+		return value, nil
+	}
+	return queryResult(value, match[2])
+}
+
+// substituteVariables 将参数中引号外的变量引用替换为变量值的JSON
+func (s *Shell) substituteVariables(text string) (string, error) {
+	var builder strings.Builder
+	inQuotes, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case ch == '\\' && inQuotes:
+			escaped = true
+		case ch == '"':
+			inQuotes = !inQuotes
+		case ch == '$' && !inQuotes:
+			ref := shellVarPattern.FindString(text[i:])
+			if ref == "" {
+				break
+			}
+			value, err := s.evalVariable(ref)
+			if err != nil {
+				return "", err
+			}
+			data, err := marshalCompact(value)
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(data)
+			i += len(ref) - 1
+			continue
+		}
+		builder.WriteByte(ch)
+	}
+	return builder.String(), nil
+}
+
+// marshalCompact 将值序列化为单行JSON，不转义HTML字符
+func marshalCompact(value interface{}) (string, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", fmt.Errorf("序列化变量失败: %v", err)
+	}
+	return strings.TrimSpace(buffer.String()), nil
+}
+
+// setVariable 设置变量，值按JSON解析，不是有效JSON时作为字符串
+func (s *Shell) setVariable(arg string) error {
+	fields := strings.Fields(arg)
+	if len(fields) < 2 {
+		return fmt.Errorf("用法: set <变量> <值>")
+	}
+	name := strings.TrimPrefix(fields[0], "$")
+	if shellVarPattern.FindString("$"+name) != "$"+name || strings.ContainsAny(name, ".[") {
+		return fmt.Errorf("无效的变量名: %s", fields[0])
+	}
+
+	text, err := s.substituteVariables(strings.TrimSpace(arg[len(fields[0]):]))
+	if err != nil {
+		return err
+	}
+	s.vars[name] = decodeResult(text)
+	return nil
+}
+
+// printVariables 列出变量，较长的值截断显示
+func (s *Shell) printVariables() {
+	names := make([]string, 0, len(s.vars))
+	for name := range s.vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, _ := marshalCompact(s.vars[name])
+		if runes := []rune(data); len(runes) > 80 {
+			data = string(runes[:77]) + "..."
+		}
+		fmt.Printf("$%s = %s\n", name, data)
+	}
+}
+
+// complete 按Tab时补全命令、服务、方法、变量和参数骨架
+func (s *Shell) complete(line string, pos int, key rune) (string, int, bool) {
+	if key == keyShellInterrupt {
+		s.interrupted = true
+		return "", 0, true
+	}
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	prefix, suffix := line[:pos], line[pos:]
+	if strings.HasSuffix(prefix, "(") && (suffix == "" || suffix == ")") && isInvocation(prefix+")") {
+		return s.completeSkeleton(prefix)
+	}
+
+	start := strings.LastIndexAny(prefix, " \t(,") + 1
+	word := prefix[start:]
+	var candidates []string
+	switch {
+	case strings.HasPrefix(word, "$"):
+		for name := range s.vars {
+			candidates = append(candidates, "$"+name)
+		}
+	case start == 0:
+		candidates = append(candidates, s.expressionCandidates(word)...)
+		for _, command := range shellCommands {
+			candidates = append(candidates, command+" ")
+		}
+	case len(strings.Fields(prefix)) > 0:
+		switch strings.Fields(prefix)[0] {
+		case "cd", "ls", "use", "methods":
+			candidates = s.pathCandidates(word)
+		case "desc":
+			candidates = s.expressionCandidates(word)
+			for i := range candidates {
+				candidates[i] = strings.TrimSuffix(candidates[i], "(")
+			}
+		case "output":
+			candidates = []string{OutputJSON, OutputYAML, OutputTable, OutputRaw, OutputCSV}
+		case "unset":
+			for name := range s.vars {
+				candidates = append(candidates, name)
+			}
+		}
+	}
+
+	var matched []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matched = append(matched, candidate)
+		}
+	}
+	sort.Strings(matched)
+	if len(matched) == 0 {
+		return "", 0, false
+	}
+
+	completion := commonPrefix(matched)
+	if len(matched) > 1 && completion == word {
+		s.showCandidates(matched)
+		return "", 0, false
+	}
+	newLine := prefix[:start] + completion + suffix
+	return newLine, start + len(completion), true
+}
+
+// expressionCandidates 补全调用表达式：当前服务的方法、服务.方法，以及相对当前包的子包和服务
+func (s *Shell) expressionCandidates(word string) []string {
+	var candidates []string
+	if dot := strings.LastIndex(word, "."); dot >= 0 {
+		if service := word[:dot]; s.hasService(service) || (s.pkg != "" && s.hasService(s.pkg+"."+service)) {
+			resolved, _ := s.resolveService(service)
+			for _, method := range s.methodNames(resolved) {
+				candidates = append(candidates, service+"."+method+"(")
+			}
+		}
+	} else if s.service != "" {
+		for _, method := range s.methodNames(s.service) {
+			candidates = append(candidates, method+"(")
+		}
+	}
+	for _, path := range s.pathCandidates(word) {
+		if !strings.HasSuffix(path, ".") {
+			// 服务后接 . 继续补全方法
+			path += "."
+		}
+		candidates = append(candidates, path)
+	}
+	return candidates
+}
+
+// pathCandidates 补全相对当前包的子包和服务，没有匹配时按完整服务名补全
+func (s *Shell) pathCandidates(word string) []string {
+	var candidates []string
+	for _, base := range []string{s.pkg, ""} {
+		dir := packageOf(word)
+		pkg := base
+		if dir != "" {
+			pkg = strings.TrimPrefix(base+"."+dir, ".")
+		}
+		if pkg != "" && !s.hasPackage(pkg) {
+			continue
+		}
+		for _, entry := range s.children(pkg) {
+			candidates = append(candidates, strings.TrimPrefix(dir+"."+entry, "."))
+		}
+		if len(candidates) > 0 || base == "" {
+			break
+		}
+	}
+	return candidates
+}
+
+// methodNames 返回服务的方法名，重载的方法只返回一次
+func (s *Shell) methodNames(service string) []string {
+	methods, _ := s.loadMethods(service)
+	var names []string
+	for i, method := range methods {
+		if i == 0 || method.Name != methods[i-1].Name {
+			names = append(names, method.Name)
+		}
+	}
+	return names
+}
+
+// completeSkeleton 在 方法( 之后补全参数骨架，有重载时列出所有签名并使用第一个
+func (s *Shell) completeSkeleton(prefix string) (string, int, bool) {
+	target := strings.TrimSpace(prefix[:len(prefix)-1])
+	service, method := s.service, target
+	if dot := strings.LastIndex(target, "."); dot >= 0 {
+		resolved, err := s.resolveService(target[:dot])
+		if err != nil {
+			return "", 0, false
+		}
+		service, method = resolved, target[dot+1:]
+	}
+	if service == "" {
+		return "", 0, false
+	}
+
+	methods, types := s.loadMethods(service)
+	var overloads []MethodDefinition
+	for _, definition := range methods {
+		if definition.Name == method && definition.ParameterTypes != nil {
+			overloads = append(overloads, definition)
+		}
+	}
+	if len(overloads) == 0 {
+		s.terminal.Write([]byte(fmt.Sprintf("没有 %s.%s 的参数类型元数据，可以通过 --metadata 指定元数据文件\n", simpleServiceName(service), method)))
+		return "", 0, false
+	}
+	if len(overloads) > 1 {
+		signatures := make([]string, len(overloads))
+		for i, overload := range overloads {
+			signatures[i] = methodSignature(overload)
+		}
+		s.terminal.Write([]byte(strings.Join(signatures, "\n") + "\n"))
+	}
+
+	newLine := prefix + parameterSkeleton(overloads[0].ParameterTypes, types) + ")"
+	return newLine, len(newLine), true
+}
+
+// showCandidates 在输入行上方分列显示候选项
+func (s *Shell) showCandidates(candidates []string) {
+	const limit = 200
+	total := len(candidates)
+	if total > limit {
+		candidates = candidates[:limit]
+	}
+
+	width := 80
+	if w, _, err := term.GetSize(s.fd); err == nil && w > 0 {
+		width = w
+	}
+	column := 0
+	for _, candidate := range candidates {
+		if len(candidate) > column {
+			column = len(candidate)
+		}
+	}
+	column += 2
+	perLine := width / column
+	if perLine < 1 {
+		perLine = 1
+	}
+
+	var buffer strings.Builder
+	for i, candidate := range candidates {
+		buffer.WriteString(candidate)
+		if (i+1)%perLine == 0 || i == len(candidates)-1 {
+			buffer.WriteString("\n")
+		} else {
+			buffer.WriteString(strings.Repeat(" ", column-len(candidate)))
+		}
+	}
+	if total > limit {
+		fmt.Fprintf(&buffer, "... 共 %d 个\n", total)
+	}
+	s.terminal.Write([]byte(buffer.String()))
+}
+
+// commonPrefix 返回候选项的公共前缀
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+)
+
+// reportResultLimit 报告中单个用例结果的最大长度，超过后截断
+const reportResultLimit = 64 * 1024
+
+// junitTestSuites JUnit XML报告根节点
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite JUnit XML中的测试套件
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase JUnit XML中的测试用例
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitMessage JUnit XML中的失败、错误或跳过信息
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnitReport 输出JUnit XML格式的报告
+func WriteJUnitReport(w io.Writer, report *TestSuiteReport) error {
+	suite := junitTestSuite{
+		Name:      report.Name,
+		Tests:     report.Total,
+		Failures:  report.Failed,
+		Errors:    report.Errors,
+		Skipped:   report.Skipped,
+		Time:      seconds(report.Duration),
+		Timestamp: report.StartedAt.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, result := range report.Cases {
+		testCase := junitTestCase{
+			Name:      result.Name,
+			ClassName: result.Service,
+			Time:      seconds(result.Duration),
+		}
+		if result.Result != nil {
+			testCase.SystemOut = truncateReportText(cellText(result.Result))
+		}
+
+		switch result.Status {
+		case CaseStatusFailed:
+			var details []string
+			for _, assertion := range result.Assertions {
+				if !assertion.Passed {
+					details = append(details, fmt.Sprintf("%s: %s", assertion.Expression, assertion.Message))
+				}
+			}
+			testCase.Failure = &junitMessage{Message: result.Error, Type: "AssertionError", Text: strings.Join(details, "\n")}
+		case CaseStatusError:
+			testCase.Error = &junitMessage{Message: result.Error, Type: "InvokeError", Text: result.Error}
+		case CaseStatusSkipped:
+			testCase.Skipped = &junitMessage{Message: result.Error}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	root := junitTestSuites{
+		Name:     report.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return fmt.Errorf("生成JUnit报告失败: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteJSONReport 输出JSON格式的报告
+func WriteJSONReport(w io.Writer, report *TestSuiteReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("生成JSON报告失败: %v", err)
+	}
+	return nil
+}
+
+// WriteHTMLReport 输出不依赖外部资源的HTML报告
+func WriteHTMLReport(w io.Writer, report *TestSuiteReport) error {
+	if err := htmlReportTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("生成HTML报告失败: %v", err)
+	}
+	return nil
+}
+
+// writeReportFile 将报告写入文件
+func writeReportFile(file string, report *TestSuiteReport, write func(io.Writer, *TestSuiteReport) error) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("创建报告文件失败: %v", err)
+	}
+	if err := write(f, report); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("写入报告文件失败: %v", err)
+	}
+	return nil
+}
+
+// seconds 将毫秒格式化为JUnit使用的秒数
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
+
+// truncateReportText 截断过长的结果文本
+func truncateReportText(text string) string {
+	if len(text) <= reportResultLimit {
+		return text
+	}
+	return previewBytes([]byte(text), reportResultLimit) + fmt.Sprintf("\n... (已截断，共 %d 字节)", len(text))
+}
+
+// prettyReportJSON 格式化结果用于HTML报告
+func prettyReportJSON(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return truncateReportText(text)
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return cellText(value)
+	}
+	return truncateReportText(string(data))
+}
+
+// htmlReportTemplate HTML报告模板，样式内联以便单文件分发
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"json": prettyReportJSON,
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="UTF-8">
+<title>{{.Name}} - 测试报告</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "Microsoft YaHei", sans-serif; margin: 0; padding: 24px; background: #f5f7fa; color: #333; }
+h1 { margin: 0 0 8px; font-size: 22px; }
+.meta { color: #666; font-size: 13px; margin-bottom: 16px; }
+.summary { display: flex; gap: 12px; margin-bottom: 20px; }
+.stat { background: #fff; border-radius: 6px; padding: 12px 18px; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+.stat b { display: block; font-size: 22px; }
+.passed { color: #2e7d32; } .failed { color: #c62828; } .error { color: #ad1457; } .skipped { color: #ef6c00; }
+details { background: #fff; border-radius: 6px; margin-bottom: 8px; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+summary { cursor: pointer; padding: 10px 14px; display: flex; gap: 12px; align-items: center; }
+.badge { font-size: 12px; font-weight: bold; text-transform: uppercase; width: 64px; }
+.name { flex: 1; } .target { color: #888; font-size: 12px; } .time { color: #666; font-size: 12px; }
+.body { padding: 0 14px 14px; font-size: 13px; }
+.body h4 { margin: 12px 0 4px; font-size: 13px; }
+pre { background: #f0f2f5; padding: 8px; border-radius: 4px; overflow: auto; max-height: 400px; margin: 0; white-space: pre-wrap; word-break: break-all; }
+ul { margin: 0; padding-left: 18px; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<div class="meta">{{.File}} · 开始于 {{.StartedAt.Format "2006-01-02 15:04:05"}} · 耗时 {{.Duration}}ms · 并发 {{.Parallel}}</div>
+<div class="summary">
+  <div class="stat"><b>{{.Total}}</b>用例</div>
+  <div class="stat passed"><b>{{.Passed}}</b>通过</div>
+  <div class="stat failed"><b>{{.Failed}}</b>失败</div>
+  <div class="stat error"><b>{{.Errors}}</b>错误</div>
+  <div class="stat skipped"><b>{{.Skipped}}</b>跳过</div>
+</div>
+{{range .Cases}}
+<details{{if ne .Status "passed"}} open{{end}}>
+  <summary>
+    <span class="badge {{.Status}}">{{.Status}}</span>
+    <span class="name">{{.Name}}</span>
+    <span class="target">{{.Service}}.{{.Method}}</span>
+    <span class="time">{{.Duration}}ms</span>
+  </summary>
+  <div class="body">
+    {{if .Error}}<h4>错误</h4><pre class="{{.Status}}">{{.Error}}</pre>{{end}}
+    {{if .Warnings}}<h4>警告</h4><ul>{{range .Warnings}}<li class="skipped">{{.}}</li>{{end}}</ul>{{end}}
+    {{if .Assertions}}<h4>断言</h4><ul>{{range .Assertions}}
+      <li class="{{if .Passed}}passed{{else}}failed{{end}}">{{if .Passed}}✔{{else}}✘{{end}} {{.Expression}}{{if not .Passed}} — {{.Message}}{{end}}</li>{{end}}
+    </ul>{{end}}
+    <h4>注册中心</h4><pre>{{.Registry}}</pre>
+    <h4>参数</h4><pre>{{json .Params}}</pre>
+    {{if .Captured}}<h4>捕获的变量</h4><pre>{{json .Captured}}</pre>{{end}}
+    {{if .Result}}<h4>结果</h4><pre>{{json .Result}}</pre>{{end}}
+    {{if .Timings}}<h4>耗时分解</h4><pre>{{.Timings.String}}</pre>{{end}}
+  </div>
+</details>
+{{end}}
+</body>
+</html>
+`))
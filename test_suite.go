@@ -0,0 +1,605 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// 用例执行状态
+const (
+	CaseStatusPassed  = "passed"  // 调用成功且断言全部通过
+	CaseStatusFailed  = "failed"  // 断言失败
+	CaseStatusError   = "error"   // 调用失败、参数校验失败或变量捕获失败
+	CaseStatusSkipped = "skipped" // 依赖的变量未定义或执行被取消
+)
+
+// varRefPattern 匹配用例中的变量引用，如 ${userId}
+var varRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.]*)\}`)
+
+// TestSuite 测试套件定义，用例中未指定的注册中心、应用、超时等使用套件级别的配置
+type TestSuite struct {
+	Name     string                 `yaml:"name"`
+	Registry string                 `yaml:"registry"`
+	App      string                 `yaml:"app"`
+	Timeout  int                    `yaml:"timeout"` // 调用超时时间，单位毫秒
+	Version  string                 `yaml:"version"`
+	Group    string                 `yaml:"group"`
+	Metadata string                 `yaml:"metadata"` // 服务定义元数据文件，用于调用前校验参数
+	Parallel int                    `yaml:"parallel"` // 并发数，不大于1时按顺序执行
+	Vars     map[string]interface{} `yaml:"vars"`     // 初始变量
+	Cases    []TestCase             `yaml:"cases"`
+}
+
+// TestCase 测试用例，即一次调用及其断言
+type TestCase struct {
+	Name        string            `yaml:"name"`
+	Registry    string            `yaml:"registry"`
+	Service     string            `yaml:"service"`
+	Method      string            `yaml:"method"`
+	Params      []interface{}     `yaml:"params"`
+	Types       []string          `yaml:"types"`
+	Version     string            `yaml:"version"`
+	Group       string            `yaml:"group"`
+	Tag         string            `yaml:"tag"`
+	Timeout     int               `yaml:"timeout"`
+	Attachments map[string]string `yaml:"attachments"`
+	Expect      []string          `yaml:"expect"`
+	MaxTime     int64             `yaml:"maxTime"` // 调用耗时上限，单位毫秒
+	Capture     map[string]string `yaml:"capture"` // 变量名到查询路径的映射，从结果中提取供后续用例使用
+}
+
+// TestCaseResult 用例执行结果
+type TestCaseResult struct {
+	Name       string                 `json:"name"`
+	Service    string                 `json:"service"`
+	Method     string                 `json:"method"`
+	Registry   string                 `json:"registry"`
+	Params     []interface{}          `json:"params"`
+	Status     string                 `json:"status"`
+	Duration   int64                  `json:"duration"` // 单位毫秒
+	StartedAt  time.Time              `json:"startedAt"`
+	Result     interface{}            `json:"result,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Assertions []AssertionResult      `json:"assertions,omitempty"`
+	Captured   map[string]interface{} `json:"captured,omitempty"`
+	Warnings   []string               `json:"warnings,omitempty"`
+	Timings    *InvokeTimings         `json:"timings,omitempty"`
+}
+
+// TestSuiteReport 测试套件执行报告
+type TestSuiteReport struct {
+	Name      string           `json:"name"`
+	File      string           `json:"file"`
+	StartedAt time.Time        `json:"startedAt"`
+	Duration  int64            `json:"duration"` // 单位毫秒
+	Parallel  int              `json:"parallel"`
+	Total     int              `json:"total"`
+	Passed    int              `json:"passed"`
+	Failed    int              `json:"failed"`
+	Errors    int              `json:"errors"`
+	Skipped   int              `json:"skipped"`
+	Cases     []TestCaseResult `json:"cases"`
+}
+
+// Success 所有用例是否都已通过
+func (r *TestSuiteReport) Success() bool {
+	return r.Failed == 0 && r.Errors == 0 && r.Skipped == 0
+}
+
+// LoadTestSuite 从YAML文件加载测试套件
+func LoadTestSuite(file string) (*TestSuite, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取测试套件失败: %v", err)
+	}
+
+	var suite TestSuite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("解析测试套件失败: %v", err)
+	}
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("测试套件 %s 中没有用例", file)
+	}
+	for i := range suite.Cases {
+		tc := &suite.Cases[i]
+		if tc.Service == "" || tc.Method == "" {
+			return nil, fmt.Errorf("第%d个用例缺少service或method", i+1)
+		}
+		if tc.Name == "" {
+			tc.Name = fmt.Sprintf("%s.%s", tc.Service, tc.Method)
+		}
+	}
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	return &suite, nil
+}
+
+// SuiteRunner 测试套件执行器
+type SuiteRunner struct {
+	suite          *TestSuite
+	maxPayloadSize int
+	skipValidation bool
+	progress       io.Writer      // 逐个输出用例结果
+	profile        *ActiveProfile // 当前环境，提供命名空间、认证信息等套件中未配置的项
+
+	varsMu   sync.Mutex
+	vars     map[string]interface{}
+	outputMu sync.Mutex
+	finished int
+}
+
+// NewSuiteRunner 创建测试套件执行器
+func NewSuiteRunner(suite *TestSuite, maxPayloadSize int, skipValidation bool, progress io.Writer) *SuiteRunner {
+	vars := make(map[string]interface{}, len(suite.Vars))
+	for name, value := range suite.Vars {
+		vars[name] = value
+	}
+	return &SuiteRunner{
+		suite:          suite,
+		maxPayloadSize: maxPayloadSize,
+		skipValidation: skipValidation,
+		progress:       progress,
+		vars:           vars,
+	}
+}
+
+// Run 执行所有用例，parallel不大于1时按顺序执行
+// 并发执行时，引用了前面用例捕获的变量的用例会等待对应用例完成
+func (r *SuiteRunner) Run(ctx context.Context, parallel int) *TestSuiteReport {
+	report := &TestSuiteReport{
+		Name:      r.suite.Name,
+		StartedAt: time.Now(),
+		Parallel:  parallel,
+		Cases:     make([]TestCaseResult, len(r.suite.Cases)),
+	}
+
+	if parallel <= 1 {
+		report.Parallel = 1
+		for i := range r.suite.Cases {
+			report.Cases[i] = r.runCase(ctx, r.suite.Cases[i])
+			r.printProgress(report.Cases[i])
+		}
+	} else {
+		r.runParallel(ctx, parallel, report.Cases)
+	}
+
+	report.Duration = time.Since(report.StartedAt).Milliseconds()
+	for _, result := range report.Cases {
+		report.Total++
+		switch result.Status {
+		case CaseStatusPassed:
+			report.Passed++
+		case CaseStatusFailed:
+			report.Failed++
+		case CaseStatusError:
+			report.Errors++
+		default:
+			report.Skipped++
+		}
+	}
+	return report
+}
+
+// runParallel 并发执行用例，并发数由parallel限制
+func (r *SuiteRunner) runParallel(ctx context.Context, parallel int, results []TestCaseResult) {
+	// 记录每个变量由哪个用例捕获
+	producers := make(map[string]int)
+	for i, tc := range r.suite.Cases {
+		for name := range tc.Capture {
+			if _, exists := producers[name]; !exists {
+				producers[name] = i
+			}
+		}
+	}
+
+	done := make([]chan struct{}, len(r.suite.Cases))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	semaphore := make(chan struct{}, parallel)
+
+	var wg sync.WaitGroup
+	for i := range r.suite.Cases {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			defer close(done[index])
+
+			tc := r.suite.Cases[index]
+			// 先等待依赖的用例完成再占用并发名额，避免互相等待
+			for _, name := range caseVarRefs(tc) {
+				if producer, exists := producers[name]; exists && producer < index {
+					<-done[producer]
+				}
+			}
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+			}
+
+			results[index] = r.runCase(ctx, tc)
+			r.printProgress(results[index])
+		}(i)
+	}
+	wg.Wait()
+}
+
+// runCase 执行单个用例
+func (r *SuiteRunner) runCase(ctx context.Context, tc TestCase) TestCaseResult {
+	result := TestCaseResult{
+		Name:      tc.Name,
+		StartedAt: time.Now(),
+	}
+	if ctx.Err() != nil {
+		result.Status = CaseStatusSkipped
+		result.Error = "执行已取消"
+		return result
+	}
+
+	// 替换变量引用
+	expanded, missing := r.expandCase(tc)
+	result.Service = expanded.Service
+	result.Method = expanded.Method
+	result.Params = expanded.Params
+	if len(missing) > 0 {
+		result.Status = CaseStatusSkipped
+		result.Error = fmt.Sprintf("变量未定义: %s", strings.Join(missing, ", "))
+		return result
+	}
+
+	cfg := r.caseConfig(expanded)
+	result.Registry = cfg.Registry
+	// 用例和环境中的隐式参数与invoke一样交给客户端，协议无法传递时在报告中列出被忽略的键
+	if len(cfg.Attachments) > 0 {
+		result.Warnings = append(result.Warnings, "telnet协议不支持传递attachments，已忽略: "+strings.Join(sortedStringKeys(cfg.Attachments), ", "))
+	}
+
+	// 调用前校验参数
+	if !r.skipValidation {
+		if err := ValidateInvokeArguments(cfg, expanded.Service, expanded.Method, expanded.Types, expanded.Params); err != nil {
+			result.Status = CaseStatusError
+			result.Error = err.Error()
+			return result
+		}
+	}
+
+	client, err := NewDubboClient(cfg)
+	if err != nil {
+		result.Status = CaseStatusError
+		result.Error = fmt.Sprintf("创建Dubbo客户端失败: %v", err)
+		return result
+	}
+	defer client.Close()
+
+	invokeStart := time.Now()
+	rawResult, err := client.GenericInvokeContext(ctx, expanded.Service, expanded.Method, expanded.Types, expanded.Params)
+	result.Duration = time.Since(invokeStart).Milliseconds()
+	result.Timings = client.LastTimings()
+	if err != nil {
+		result.Status = CaseStatusError
+		result.Error = fmt.Sprintf("调用失败: %v", err)
+		return result
+	}
+	decoded := decodeResult(rawResult)
+	result.Result = decoded
+
+	// 断言
+	expects := expanded.Expect
+	if expanded.MaxTime > 0 {
+		expects = append(expects, fmt.Sprintf("duration <= %d", expanded.MaxTime))
+	}
+	result.Assertions = EvaluateAssertions(expects, AssertionContext{Result: decoded, Duration: result.Duration})
+
+	// 捕获变量，断言失败时同样捕获，便于排查
+	if len(expanded.Capture) > 0 {
+		result.Captured = make(map[string]interface{}, len(expanded.Capture))
+		for _, name := range sortedStringKeys(expanded.Capture) {
+			value, err := queryResult(decoded, expanded.Capture[name])
+			if err != nil {
+				result.Status = CaseStatusError
+				result.Error = fmt.Sprintf("捕获变量 %s 失败: %v", name, err)
+				return result
+			}
+			result.Captured[name] = value
+		}
+		r.varsMu.Lock()
+		for name, value := range result.Captured {
+			r.vars[name] = value
+		}
+		r.varsMu.Unlock()
+	}
+
+	if failed := AssertionsFailed(result.Assertions); failed > 0 {
+		result.Status = CaseStatusFailed
+		result.Error = fmt.Sprintf("%d/%d 个断言失败", failed, len(result.Assertions))
+		return result
+	}
+	result.Status = CaseStatusPassed
+	return result
+}
+
+// caseConfig 合并套件和用例的配置
+func (r *SuiteRunner) caseConfig(tc TestCase) *DubboConfig {
+	cfg := &DubboConfig{
+		Registry:       firstNonEmpty(tc.Registry, r.suite.Registry),
+		Application:    r.suite.App,
+		Timeout:        time.Duration(r.suite.Timeout) * time.Millisecond,
+		Version:        firstNonEmpty(tc.Version, r.suite.Version),
+		Group:          firstNonEmpty(tc.Group, r.suite.Group),
+		Tag:            tc.Tag,
+		MetadataFile:   r.suite.Metadata,
+		MaxPayloadSize: r.maxPayloadSize,
+		Attachments:    tc.Attachments,
+	}
+	if tc.Timeout > 0 {
+		cfg.Timeout = time.Duration(tc.Timeout) * time.Millisecond
+	}
+	r.profile.Apply(cfg)
+	return cfg
+}
+
+// expandCase 替换用例中的变量引用，返回替换后的用例和未定义的变量
+// 变量依次从套件变量、前面用例捕获的变量和环境变量中查找
+func (r *SuiteRunner) expandCase(tc TestCase) (TestCase, []string) {
+	missingSet := make(map[string]bool)
+	lookup := func(name string) (interface{}, bool) {
+		r.varsMu.Lock()
+		value, exists := r.vars[name]
+		r.varsMu.Unlock()
+		if exists {
+			return value, true
+		}
+		if envValue, exists := os.LookupEnv(name); exists {
+			return envValue, true
+		}
+		missingSet[name] = true
+		return nil, false
+	}
+	expandString := func(text string) string {
+		return varRefPattern.ReplaceAllStringFunc(text, func(ref string) string {
+			value, ok := lookup(varRefPattern.FindStringSubmatch(ref)[1])
+			if !ok {
+				return ref
+			}
+			return cellText(value)
+		})
+	}
+
+	expanded := tc
+	expanded.Registry = expandString(tc.Registry)
+	expanded.Service = expandString(tc.Service)
+	expanded.Method = expandString(tc.Method)
+	expanded.Version = expandString(tc.Version)
+	expanded.Group = expandString(tc.Group)
+	expanded.Tag = expandString(tc.Tag)
+
+	expanded.Params = make([]interface{}, len(tc.Params))
+	for i, param := range tc.Params {
+		expanded.Params[i] = expandValue(param, varRefPattern, lookup, expandString)
+	}
+	expanded.Types = make([]string, len(tc.Types))
+	for i, paramType := range tc.Types {
+		expanded.Types[i] = expandString(paramType)
+	}
+	expanded.Expect = make([]string, len(tc.Expect))
+	for i, expect := range tc.Expect {
+		expanded.Expect[i] = expandString(expect)
+	}
+	if tc.Attachments != nil {
+		expanded.Attachments = make(map[string]string, len(tc.Attachments))
+		for key, value := range tc.Attachments {
+			expanded.Attachments[key] = expandString(value)
+		}
+	}
+
+	missing := make([]string, 0, len(missingSet))
+	for name := range missingSet {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	return expanded, missing
+}
+
+// expandValue 递归替换参数中的变量引用，pattern的第一个分组为变量名
+// 整个字符串就是一个变量引用时保留变量原本的类型，否则按文本替换
+func expandValue(value interface{}, pattern *regexp.Regexp, lookup func(string) (interface{}, bool), expandString func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		if match := pattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			if varValue, ok := lookup(match[1]); ok {
+				return convertJSONNumber(varValue)
+			}
+			return v
+		}
+		return expandString(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = expandValue(item, pattern, lookup, expandString)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = expandValue(item, pattern, lookup, expandString)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// caseVarRefs 返回用例中引用的所有变量名
+func caseVarRefs(tc TestCase) []string {
+	data, err := yaml.Marshal(tc)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, match := range varRefPattern.FindAllStringSubmatch(string(data), -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// printProgress 输出单个用例的结果
+func (r *SuiteRunner) printProgress(result TestCaseResult) {
+	r.outputMu.Lock()
+	defer r.outputMu.Unlock()
+
+	r.finished++
+	prefix := fmt.Sprintf("[%d/%d]", r.finished, len(r.suite.Cases))
+	switch result.Status {
+	case CaseStatusPassed:
+		color.New(color.FgGreen).Fprintf(r.progress, "%s ✔ %s (%dms)\n", prefix, result.Name, result.Duration)
+	case CaseStatusSkipped:
+		color.New(color.FgYellow).Fprintf(r.progress, "%s - %s 已跳过: %s\n", prefix, result.Name, result.Error)
+	default:
+		color.New(color.FgRed).Fprintf(r.progress, "%s ✘ %s (%dms): %s\n", prefix, result.Name, result.Duration, result.Error)
+		for _, assertion := range result.Assertions {
+			if !assertion.Passed {
+				color.New(color.FgRed).Fprintf(r.progress, "      ✘ %s\n        %s\n", assertion.Expression, assertion.Message)
+			}
+		}
+	}
+	for _, warning := range result.Warnings {
+		color.New(color.FgYellow).Fprintf(r.progress, "      ⚠ %s\n", warning)
+	}
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// sortedStringKeys 返回按字母排序的键
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// test命令 - 执行YAML测试套件
+func newTestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test <suite.yaml>",
+		Short: "执行YAML测试套件",
+		Long: `按顺序或并发执行测试套件中的调用，校验断言并生成报告，任一用例未通过时以非0状态退出
+
+示例:
+  dubbo-invoke test release-check.yaml
+  dubbo-invoke test release-check.yaml --parallel 4 --junit report.xml --html report.html`,
+		Args: cobra.ExactArgs(1),
+		RunE: runTestCommand,
+	}
+
+	cmd.Flags().Int("parallel", 0, "并发数，覆盖套件中的parallel，不大于1时按顺序执行")
+	cmd.Flags().String("junit", "", "JUnit XML报告输出文件")
+	cmd.Flags().String("json", "", "JSON报告输出文件")
+	cmd.Flags().String("html", "", "HTML报告输出文件")
+	cmd.Flags().Bool("no-validate", false, "跳过调用前的参数校验")
+
+	return cmd
+}
+
+// runTestCommand 执行测试套件
+func runTestCommand(cmd *cobra.Command, args []string) error {
+	suite, err := LoadTestSuite(args[0])
+	if err != nil {
+		return err
+	}
+
+	// 套件中未指定的配置使用命令行参数
+	if suite.Registry == "" {
+		suite.Registry, _ = cmd.Flags().GetString("registry")
+	}
+	if suite.App == "" {
+		suite.App, _ = cmd.Flags().GetString("app")
+	}
+	if suite.Timeout <= 0 {
+		suite.Timeout, _ = cmd.Flags().GetInt("timeout")
+	}
+	parallel := suite.Parallel
+	if cmd.Flags().Changed("parallel") {
+		parallel, _ = cmd.Flags().GetInt("parallel")
+	}
+	junitFile, _ := cmd.Flags().GetString("junit")
+	jsonFile, _ := cmd.Flags().GetString("json")
+	htmlFile, _ := cmd.Flags().GetString("html")
+	noValidate, _ := cmd.Flags().GetBool("no-validate")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+
+	color.Green("执行测试套件: %s (%d 个用例)", suite.Name, len(suite.Cases))
+
+	// 非详细模式下丢弃客户端日志，只输出用例结果
+	var progress io.Writer = os.Stdout
+	if !verbose {
+		var restore func()
+		progress, restore, err = discardLogs()
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	runner := NewSuiteRunner(suite, maxPayloadBytes(cmd), noValidate, progress)
+	runner.profile = activeProfile(cmd)
+	report := runner.Run(cmd.Context(), parallel)
+	report.File = args[0]
+
+	summary := fmt.Sprintf("共 %d 个用例: 通过 %d, 失败 %d, 错误 %d, 跳过 %d, 耗时 %dms",
+		report.Total, report.Passed, report.Failed, report.Errors, report.Skipped, report.Duration)
+	if report.Success() {
+		color.New(color.FgGreen).Fprintln(progress, summary)
+	} else {
+		color.New(color.FgRed).Fprintln(progress, summary)
+	}
+
+	// 生成报告
+	writers := []struct {
+		file  string
+		write func(io.Writer, *TestSuiteReport) error
+	}{
+		{junitFile, WriteJUnitReport},
+		{jsonFile, WriteJSONReport},
+		{htmlFile, WriteHTMLReport},
+	}
+	for _, writer := range writers {
+		if writer.file == "" {
+			continue
+		}
+		if err := writeReportFile(writer.file, report, writer.write); err != nil {
+			return err
+		}
+		fmt.Fprintf(progress, "报告已写入: %s\n", writer.file)
+	}
+
+	if !report.Success() {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d/%d 个用例未通过", report.Total-report.Passed, report.Total)
+	}
+	return nil
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -221,9 +223,13 @@ func (ti *TypeInferrer) ValidateParameters(params []Parameter) []string {
 			errors = append(errors, fmt.Sprintf("必填参数 '%s' 不能为空", param.Name))
 		}
 		
-		// 类型验证
+		// 类型验证，有Java类型时按类型逐层校验
 		if param.Value != nil {
-			if err := ti.validateParameterType(param.Value, param.Type); err != nil {
+			if param.JavaType != "" {
+				for _, issue := range ti.validateValue(param.Name, param.Value, param.JavaType, nil, 0) {
+					errors = append(errors, fmt.Sprintf("参数 '%s' 类型错误: %s", param.Name, issue))
+				}
+			} else if err := ti.validateParameterType(param.Value, param.Type); err != nil {
 				errors = append(errors, fmt.Sprintf("参数 '%s' 类型错误: %v", param.Name, err))
 			}
 		}
@@ -232,6 +238,347 @@ func (ti *TypeInferrer) ValidateParameters(params []Parameter) []string {
 	return errors
 }
 
+// ValidationIssue 参数校验问题，Path为参数中的JSON路径，如 arg[0].items[2].skuId
+type ValidationIssue struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String 格式化为 "路径: 问题"
+func (vi ValidationIssue) String() string {
+	return vi.Path + ": " + vi.Message
+}
+
+// ValidateArguments 按方法元数据校验参数个数和每个参数的结构
+func (ti *TypeInferrer) ValidateArguments(args []interface{}, metadata *MethodMetadata) []ValidationIssue {
+	if len(args) != len(metadata.ParamTypes) {
+		return []ValidationIssue{{
+			Path:    "args",
+			Message: fmt.Sprintf("expected %d arguments (%s), got %d", len(metadata.ParamTypes), strings.Join(metadata.ParamTypes, ", "), len(args)),
+		}}
+	}
+
+	var issues []ValidationIssue
+	for i, arg := range args {
+		issues = append(issues, ti.validateValue(fmt.Sprintf("arg[%d]", i), arg, metadata.ParamTypes[i], metadata.Types, 0)...)
+	}
+	return issues
+}
+
+// validateValue 按Java类型校验值的JSON结构，types提供DTO和枚举的定义
+func (ti *TypeInferrer) validateValue(path string, value interface{}, javaType string, types map[string]*TypeDefinition, depth int) []ValidationIssue {
+	if depth > 32 {
+		return nil
+	}
+	issue := func(format string, args ...interface{}) []ValidationIssue {
+		return []ValidationIssue{{Path: path, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	javaType = strings.TrimSpace(javaType)
+	baseType, typeArgs := splitGenericType(javaType)
+	name := baseType[strings.LastIndex(baseType, ".")+1:]
+	kind := jsonKind(value)
+
+	if value == nil {
+		if isPrimitiveType(baseType) {
+			return issue("expected %s, got null", name)
+		}
+		return nil
+	}
+
+	// 数组类型
+	if strings.HasSuffix(baseType, "[]") {
+		return ti.validateElements(path, value, strings.TrimSuffix(baseType, "[]"), types, depth, javaType)
+	}
+
+	switch name {
+	case "String", "CharSequence":
+		if kind != "string" {
+			return issue("expected %s, got %s", name, kind)
+		}
+	case "char", "Character":
+		if str, ok := value.(string); !ok || len([]rune(str)) != 1 {
+			return issue("expected %s, got %s", name, describeValue(value))
+		}
+	case "int", "Integer":
+		return checkIntegerRange(path, name, value, math.MinInt32, math.MaxInt32)
+	case "short", "Short":
+		return checkIntegerRange(path, name, value, math.MinInt16, math.MaxInt16)
+	case "byte", "Byte":
+		return checkIntegerRange(path, name, value, math.MinInt8, math.MaxInt8)
+	case "long", "Long":
+		// 超过15位的整数在解析时会转为字符串以保持精度，数字字符串同样有效
+		if str, ok := value.(string); ok {
+			if _, err := strconv.ParseInt(str, 10, 64); err != nil {
+				if isIntegerString(str) {
+					return issue("value %s out of range for %s", str, name)
+				}
+				return issue("expected %s, got string", name)
+			}
+			return nil
+		}
+		return checkIntegerRange(path, name, value, math.MinInt64, math.MaxInt64)
+	case "BigInteger":
+		if str, ok := value.(string); ok && isIntegerString(str) {
+			return nil
+		}
+		if kind != "integer" {
+			return issue("expected %s, got %s", name, kind)
+		}
+	case "float", "Float", "double", "Double":
+		if kind != "integer" && kind != "number" {
+			return issue("expected %s, got %s", name, kind)
+		}
+	case "BigDecimal", "Number":
+		if str, ok := value.(string); ok {
+			if _, err := strconv.ParseFloat(str, 64); err != nil {
+				return issue("expected %s, got string", name)
+			}
+			return nil
+		}
+		if kind != "integer" && kind != "number" {
+			return issue("expected %s, got %s", name, kind)
+		}
+	case "boolean", "Boolean":
+		if kind != "boolean" {
+			return issue("expected %s, got %s", name, kind)
+		}
+	case "Date", "LocalDate", "LocalDateTime", "LocalTime", "Timestamp", "Instant":
+		// 日期可以是格式化字符串或时间戳
+		if kind != "string" && kind != "integer" {
+			return issue("expected %s, got %s", name, kind)
+		}
+	case "Object", "Serializable":
+		return nil
+	case "List", "ArrayList", "LinkedList", "Collection", "Set", "HashSet", "LinkedHashSet", "TreeSet", "Iterable":
+		elementType := "java.lang.Object"
+		if len(typeArgs) > 0 {
+			elementType = typeArgs[0]
+		}
+		return ti.validateElements(path, value, elementType, types, depth, javaType)
+	case "Map", "HashMap", "LinkedHashMap", "TreeMap", "ConcurrentHashMap":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return issue("expected %s, got %s", name, kind)
+		}
+		if len(typeArgs) < 2 {
+			return nil
+		}
+		var issues []ValidationIssue
+		for _, key := range sortedKeys(obj) {
+			issues = append(issues, ti.validateValue(path+"."+key, obj[key], typeArgs[1], types, depth+1)...)
+		}
+		return issues
+	default:
+		return ti.validateDefinedType(path, value, baseType, name, types, depth)
+	}
+
+	return nil
+}
+
+// validateElements 校验数组及其元素
+func (ti *TypeInferrer) validateElements(path string, value interface{}, elementType string, types map[string]*TypeDefinition, depth int, javaType string) []ValidationIssue {
+	items, ok := value.([]interface{})
+	if !ok {
+		baseType, _ := splitGenericType(javaType)
+		return []ValidationIssue{{Path: path, Message: fmt.Sprintf("expected %s, got %s", baseType[strings.LastIndex(baseType, ".")+1:], jsonKind(value))}}
+	}
+	var issues []ValidationIssue
+	for i, item := range items {
+		issues = append(issues, ti.validateValue(fmt.Sprintf("%s[%d]", path, i), item, elementType, types, depth+1)...)
+	}
+	return issues
+}
+
+// validateDefinedType 校验DTO和枚举，没有类型定义时只检查是否为对象或枚举名
+func (ti *TypeInferrer) validateDefinedType(path string, value interface{}, javaType, name string, types map[string]*TypeDefinition, depth int) []ValidationIssue {
+	kind := jsonKind(value)
+	definition := types[javaType]
+
+	if definition == nil {
+		if kind != "object" && kind != "string" && !strings.HasPrefix(javaType, "java.") {
+			return []ValidationIssue{{Path: path, Message: fmt.Sprintf("expected %s, got %s", name, kind)}}
+		}
+		return nil
+	}
+
+	// 枚举只能是定义中的常量名
+	if len(definition.Enums) > 0 {
+		str, ok := value.(string)
+		if !ok {
+			return []ValidationIssue{{Path: path, Message: fmt.Sprintf("expected %s, got %s", name, kind)}}
+		}
+		for _, constant := range definition.Enums {
+			if constant == str {
+				return nil
+			}
+		}
+		return []ValidationIssue{{Path: path, Message: fmt.Sprintf("expected one of %s constants [%s], got %q", name, strings.Join(definition.Enums, ", "), str)}}
+	}
+
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return []ValidationIssue{{Path: path, Message: fmt.Sprintf("expected %s, got %s", name, kind)}}
+	}
+
+	var issues []ValidationIssue
+	for _, field := range definition.Required {
+		if fieldValue, exists := obj[field]; !exists || fieldValue == nil {
+			issues = append(issues, ValidationIssue{Path: path + "." + field, Message: fmt.Sprintf("required field of %s is missing", name)})
+		}
+	}
+	for _, field := range sortedKeys(obj) {
+		fieldType, defined := definition.Properties[field]
+		if !defined {
+			continue
+		}
+		issues = append(issues, ti.validateValue(path+"."+field, obj[field], fieldType, types, depth+1)...)
+	}
+	return issues
+}
+
+// checkIntegerRange 校验整数及其取值范围
+func checkIntegerRange(path, name string, value interface{}, min, max int64) []ValidationIssue {
+	kind := jsonKind(value)
+	if kind != "integer" {
+		return []ValidationIssue{{Path: path, Message: fmt.Sprintf("expected %s, got %s", name, kind)}}
+	}
+
+	var intValue int64
+	switch v := value.(type) {
+	case int:
+		intValue = int64(v)
+	case int32:
+		intValue = int64(v)
+	case int64:
+		intValue = v
+	case float64:
+		if v < float64(min) || v > float64(max) {
+			return []ValidationIssue{{Path: path, Message: fmt.Sprintf("value %v out of range for %s", v, name)}}
+		}
+		intValue = int64(v)
+	case json.Number:
+		parsed, err := v.Int64()
+		if err != nil {
+			return []ValidationIssue{{Path: path, Message: fmt.Sprintf("value %s out of range for %s", v, name)}}
+		}
+		intValue = parsed
+	default:
+		return nil
+	}
+
+	if intValue < min || intValue > max {
+		return []ValidationIssue{{Path: path, Message: fmt.Sprintf("value %d out of range for %s [%d, %d]", intValue, name, min, max)}}
+	}
+	return nil
+}
+
+// splitGenericType 拆分泛型类型，如 java.util.Map<java.lang.String, com.x.Item> 返回基础类型和类型参数
+func splitGenericType(javaType string) (string, []string) {
+	start := strings.Index(javaType, "<")
+	if start < 0 || !strings.HasSuffix(javaType, ">") {
+		return javaType, nil
+	}
+
+	baseType := strings.TrimSpace(javaType[:start])
+	inner := javaType[start+1 : len(javaType)-1]
+
+	// 按顶层逗号拆分，嵌套泛型中的逗号不拆分
+	var typeArgs []string
+	level, last := 0, 0
+	for i, ch := range inner {
+		switch ch {
+		case '<':
+			level++
+		case '>':
+			level--
+		case ',':
+			if level == 0 {
+				typeArgs = append(typeArgs, strings.TrimSpace(inner[last:i]))
+				last = i + 1
+			}
+		}
+	}
+	typeArgs = append(typeArgs, strings.TrimSpace(inner[last:]))
+	return baseType, typeArgs
+}
+
+// isPrimitiveType 判断是否为Java基本类型，基本类型的参数不能为null
+func isPrimitiveType(javaType string) bool {
+	switch javaType {
+	case "int", "long", "short", "byte", "boolean", "double", "float", "char":
+		return true
+	}
+	return false
+}
+
+// isIntegerString 判断字符串是否为整数
+func isIntegerString(str string) bool {
+	str = strings.TrimPrefix(str, "-")
+	if str == "" {
+		return false
+	}
+	for _, ch := range str {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonKind 返回值对应的JSON类型名
+func jsonKind(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "integer"
+	case float32:
+		if float64(v) == math.Trunc(float64(v)) {
+			return "integer"
+		}
+		return "number"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case json.Number:
+		if _, err := v.Int64(); err == nil || isIntegerString(string(v)) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return reflect.TypeOf(value).Kind().String()
+	}
+}
+
+// describeValue 描述值的类型，字符串附带内容
+func describeValue(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return fmt.Sprintf("string %q", str)
+	}
+	return jsonKind(value)
+}
+
+// sortedKeys 返回排序后的键，保证校验结果顺序稳定
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // validateParameterType 验证参数类型
 func (ti *TypeInferrer) validateParameterType(value interface{}, expectedType ParameterType) error {
 	valueType := reflect.TypeOf(value)
@@ -2,15 +2,19 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -18,50 +22,82 @@ import (
 
 // CallHistory 调用历史记录
 type CallHistory struct {
-	ID          string        `json:"id"`
-	ServiceName string        `json:"serviceName"`
-	MethodName  string        `json:"methodName"`
-	Parameters  []interface{} `json:"parameters"`
-	Types       []string      `json:"types"`
-	Registry    string        `json:"registry"`
-	App         string        `json:"app"`
-	Success     bool          `json:"success"`
-	Timestamp   time.Time     `json:"timestamp"`
-	Result      string        `json:"result"`
-	Duration    int64         `json:"duration"` // 调用耗时，单位毫秒
-	Namespace   string        `json:"namespace"`
+	ID          string            `json:"id"`
+	ServiceName string            `json:"serviceName"`
+	MethodName  string            `json:"methodName"`
+	Parameters  []interface{}     `json:"parameters"`
+	Types       []string          `json:"types"`
+	Registry    string            `json:"registry"`
+	App         string            `json:"app"`
+	Success     bool              `json:"success"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Result      string            `json:"result"`
+	Duration    int64             `json:"duration"` // 调用耗时，单位毫秒
+	Namespace   string            `json:"namespace"`
+	Version     string            `json:"version,omitempty"`
+	Group       string            `json:"group,omitempty"`
+	Tag         string            `json:"tag,omitempty"`
+	Timeout     int               `json:"timeout,omitempty"`    // 调用超时时间，单位毫秒
+	ResultID    string            `json:"resultId,omitempty"`   // 大结果的结果文件句柄，Result中仅保存预览
+	Timings     *InvokeTimings    `json:"timings,omitempty"`    // 各阶段耗时
+	Expect      []string          `json:"expect,omitempty"`     // 调用时携带的断言
+	Assertions  []AssertionResult `json:"assertions,omitempty"` // 断言结果
+	Source      string            `json:"source,omitempty"`     // 记录来源: web 或 cli
+	Profile     string            `json:"profile,omitempty"`    // 调用时使用的环境
+
+	ResultTruncated bool `json:"resultTruncated,omitempty"` // Result超过保存上限，只保存了开头部分
 }
 
 // WebServer Web服务器结构
 type WebServer struct {
-	port     int
-	registry string
-	app      string
-	timeout  int
-	history  []CallHistory // 调用历史记录
+	port           int
+	registry       string
+	app            string
+	timeout        int
+	maxPayload     int           // 最大响应大小(字节)
+	spoolThreshold int           // 结果超过该大小(字节)时写入结果文件
+	metadataFile   string        // 服务定义元数据文件，用于调用前校验参数
+	recordFile     string        // 调用录制文件，为空时不录制
+	history        *HistoryStore // 持久化的调用历史，与CLI共用
+	retention      HistoryRetention
+	jobs           *JobManager      // 后台调用任务
+	results        *ResultStore     // 大结果文件存储，保留时间与历史记录一致
+	collectionsDir string           // 集合目录，为空时使用 ~/.dubbo-invoke/collections
+	collections    *CollectionStore // 保存的请求集合
+	configPath     string           // 配置文件路径，为空时使用 ~/.dubbo-invoke/config.yaml
+	profile        string           // 启动时通过 --profile 指定的环境，请求未指定环境时使用
 }
 
 // InvokeRequest Web调用请求
 type InvokeRequest struct {
-	ServiceName string          `json:"serviceName"`
-	MethodName  string          `json:"methodName"`
-	Parameters  json.RawMessage `json:"parameters"` // 使用json.RawMessage支持多种类型
-	Types       []string        `json:"types"`
-	Registry    string          `json:"registry"`
-	App         string          `json:"app"`
-	Timeout     int             `json:"timeout"`
-	Group       string          `json:"group"`
-	Version     string          `json:"version"`
-	Namespace   string          `json:"namespace"`
+	ServiceName    string          `json:"serviceName"`
+	MethodName     string          `json:"methodName"`
+	Parameters     json.RawMessage `json:"parameters"` // 使用json.RawMessage支持多种类型
+	Types          []string        `json:"types"`
+	Registry       string          `json:"registry"`
+	App            string          `json:"app"`
+	Timeout        int             `json:"timeout"`
+	Group          string          `json:"group"`
+	Version        string          `json:"version"`
+	Tag            string          `json:"tag"`
+	Namespace      string          `json:"namespace"`
+	SkipValidation bool            `json:"skipValidation"` // 跳过调用前的参数校验
+	Expect         []string        `json:"expect"`         // 结果断言，如 $.success == true、duration < 500
+	Profile        string          `json:"profile,omitempty"` // 使用的环境，提供认证信息、隐式参数和字符集等
 }
 
 // InvokeResponse Web调用响应
 type InvokeResponse struct {
-	Success  bool        `json:"success"`
-	Data     interface{} `json:"data"`
-	Error    string      `json:"error"`
-	Message  string      `json:"message"`
-	Duration int64       `json:"duration"` // 后端处理耗时，单位毫秒
+	Success    bool              `json:"success"`
+	Data       interface{}       `json:"data"`
+	Error      string            `json:"error"`
+	Message    string            `json:"message"`
+	Duration   int64             `json:"duration"`             // 后端处理耗时，单位毫秒
+	ResultID   string            `json:"resultId,omitempty"`   // 大结果的结果文件句柄，通过/api/results/{id}分段获取
+	Size       int64             `json:"size,omitempty"`       // 大结果的大小(字节)
+	Preview    string            `json:"preview,omitempty"`    // 大结果的开头部分
+	Timings    *InvokeTimings    `json:"timings,omitempty"`    // 各阶段耗时
+	Assertions []AssertionResult `json:"assertions,omitempty"` // 断言结果，请求中带有expect时返回
 }
 
 // ListServicesResponse 服务列表响应
@@ -89,12 +125,19 @@ func newWebCommand() *cobra.Command {
   dubbo-invoke web                                    # 默认端口8080
   dubbo-invoke web --port 9090                       # 指定端口
   dubbo-invoke web --registry nacos://127.0.0.1:8848 # 指定注册中心
-  dubbo-invoke web --timeout 30000                   # 设置超时时间`,
+  dubbo-invoke web --timeout 30000                   # 设置超时时间
+  dubbo-invoke web --record session.yaml             # 录制所有调用，供replay命令回放`,
 		RunE: runWebCommand,
 	}
 
 	cmd.Flags().IntP("port", "p", 8080, "Web服务器端口")
 	cmd.Flags().IntP("timeout", "t", 30000, "调用超时时间(毫秒)")
+	cmd.Flags().Int("spool-threshold", 1024, "结果超过该大小(KB)时边读取边写入结果文件，仅返回预览和句柄")
+	cmd.Flags().String("metadata", "", "服务定义元数据文件(JSON)，用于调用前校验参数")
+	cmd.Flags().String("record", "", "将每次调用的请求、服务提供者和响应追加到录制文件(YAML)")
+	cmd.Flags().Int("history-max", defaultHistoryMaxEntries, "最多保留的历史记录条数，0表示不限制")
+	cmd.Flags().Int("history-days", int(defaultHistoryMaxAge/(24*time.Hour)), "历史记录保留天数，0表示不限制")
+	cmd.Flags().String("collections-dir", "", "请求集合目录，默认为 ~/.dubbo-invoke/collections")
 
 	return cmd
 }
@@ -105,21 +148,84 @@ func runWebCommand(cmd *cobra.Command, args []string) error {
 	registry, _ := cmd.Flags().GetString("registry")
 	app, _ := cmd.Flags().GetString("app")
 	timeout, _ := cmd.Flags().GetInt("timeout")
+	spoolThreshold, _ := cmd.Flags().GetInt("spool-threshold")
+	metadataFile, _ := cmd.Flags().GetString("metadata")
+	recordFile, _ := cmd.Flags().GetString("record")
+	historyMax, _ := cmd.Flags().GetInt("history-max")
+	historyDays, _ := cmd.Flags().GetInt("history-days")
+	collectionsDir, _ := cmd.Flags().GetString("collections-dir")
+	configPath, _ := cmd.Flags().GetString("config")
+	profile, _ := cmd.Flags().GetString("profile")
 
 	server := &WebServer{
-		port:     port,
-		registry: registry,
-		app:      app,
-		timeout:  timeout,
+		port:           port,
+		registry:       registry,
+		app:            app,
+		timeout:        timeout,
+		maxPayload:     maxPayloadBytes(cmd),
+		spoolThreshold: spoolThreshold * 1024,
+		metadataFile:   metadataFile,
+		recordFile:     recordFile,
+		collectionsDir: collectionsDir,
+		configPath:     configPath,
+		profile:        profile,
+		retention: HistoryRetention{
+			MaxEntries: historyMax,
+			MaxAge:     time.Duration(historyDays) * 24 * time.Hour,
+		},
+	}
+
+	// 页面发起的调用无法输入主密码，配置文件引用了凭据时在启动时解锁
+	if refs := secretReferencesByName(cmd); len(refs) > 0 {
+		if _, err := unlockDefaultSecretStore(); err != nil {
+			color.Yellow("解锁凭据失败，使用 secret:// 密码的环境将无法连接注册中心: %v", err)
+		}
 	}
 
-	return server.Start()
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return server.Start(ctx)
 }
 
-// Start 启动Web服务器
-func (ws *WebServer) Start() error {
-	// 初始化历史记录
-	ws.history = make([]CallHistory, 0)
+// Start 启动Web服务器，ctx取消（如收到Ctrl+C）时停止服务
+func (ws *WebServer) Start(ctx context.Context) error {
+	// 打开持久化的历史记录，与CLI的invoke共用同一个文件
+	history, err := OpenDefaultHistoryStore(ws.retention)
+	if err != nil {
+		return err
+	}
+	ws.history = history
+
+	// 打开请求集合目录
+	collections, err := OpenCollectionStore(ws.collectionsDir)
+	if err != nil {
+		return err
+	}
+	ws.collections = collections
+
+	// 打开大结果存储，保留时间与历史记录一致，删除历史记录不再引用的结果
+	home, err := dubboInvokeHome()
+	if err != nil {
+		return err
+	}
+	results, err := NewResultStore(filepath.Join(home, "results"), ws.retention.MaxAge)
+	if err != nil {
+		return err
+	}
+	resultIDs := make(map[string]bool)
+	for _, entry := range ws.history.Search(HistoryQuery{}).Items {
+		if entry.ResultID != "" {
+			resultIDs[entry.ResultID] = true
+		}
+	}
+	results.Retain(resultIDs)
+	ws.results = results
+
+	// 初始化后台任务队列，并发数和队列长度与优化配置保持一致
+	ws.jobs = NewJobManager(NewOptimizedDubboConfig(&DubboConfig{}), ws.invokeForJob, time.Duration(ws.timeout)*time.Millisecond)
+	defer ws.jobs.Stop()
 
 	// 设置路由
 	http.HandleFunc("/", ws.handleIndex)
@@ -128,7 +234,18 @@ func (ws *WebServer) Start() error {
 	http.HandleFunc("/api/methods", ws.handleMethods)
 	http.HandleFunc("/api/example", ws.handleExample)
 	http.HandleFunc("/api/history", ws.handleHistory)
+	http.HandleFunc("/api/history/diff", ws.handleHistoryDiff)
+	http.HandleFunc("/api/history/export", ws.handleHistoryExport)
+	http.HandleFunc("/api/history/rerun", ws.handleHistoryRerun)
 	http.HandleFunc("/api/clear-history", ws.handleClearHistory)
+	http.HandleFunc("/api/jobs", ws.handleJobs)
+	http.HandleFunc("/api/jobs/", ws.handleJob)
+	http.HandleFunc("/api/results/", ws.handleResult)
+	http.HandleFunc("/api/diff", ws.handleDiff)
+	http.HandleFunc("/api/collections", ws.handleCollections)
+	http.HandleFunc("/api/collections/", ws.handleCollection)
+	http.HandleFunc("/api/profiles", ws.handleProfiles)
+	http.HandleFunc("/api/search", ws.handleSearch)
 
 	// 添加静态文件服务
 	http.Handle("/test_download.html", http.HandlerFunc(ws.handleStaticFile))
@@ -160,6 +277,10 @@ func (ws *WebServer) Start() error {
 
 	// 启动Web服务器
 	server := &http.Server{Addr: addr}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		color.Red("❌ Web服务器启动失败: %v", err)
 		return err
@@ -203,64 +324,48 @@ func (ws *WebServer) handleInvoke(w http.ResponseWriter, r *http.Request) {
 
 	color.Cyan("[WEB] 解析请求成功 - 服务: %s, 方法: %s, 参数: %s", req.ServiceName, req.MethodName, string(req.Parameters))
 
-	// 解析参数，保持Long类型精度
-	var params []interface{}
-	if len(req.Parameters) > 0 {
-		// 尝试解析为参数数组
-		var paramArray []interface{}
-		decoder := json.NewDecoder(strings.NewReader(string(req.Parameters)))
-		decoder.UseNumber()
-		err := decoder.Decode(&paramArray)
-		if err == nil {
-			// 成功解析为数组
-			params = convertJSONNumbers(paramArray)
-			color.Green("[WEB] 解析为多参数格式，参数数量: %d", len(params))
-		} else {
-			// 如果不是数组格式，尝试解析为单个参数
-			var singleParam interface{}
-			decoder = json.NewDecoder(strings.NewReader(string(req.Parameters)))
-			decoder.UseNumber()
-			err = decoder.Decode(&singleParam)
-			if err == nil {
-				params = []interface{}{convertJSONNumber(singleParam)}
-				color.Green("[WEB] 解析为单参数格式，参数数量: 1")
-			} else {
-				// 如果都失败了，作为字符串处理
-				params = []interface{}{string(req.Parameters)}
-				color.Yellow("[WEB] 参数解析失败，作为字符串处理: %s", string(req.Parameters))
-			}
-		}
+	// dryRun只返回调用计划，不发送请求也不记录历史
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun")); dryRun {
+		ws.handleDryRun(w, req)
+		return
 	}
 
+	ws.serveInvoke(w, r, req)
+}
+
+// serveInvoke 执行调用、记录历史并写回响应，供页面调用和历史记录重新调用共用
+func (ws *WebServer) serveInvoke(w http.ResponseWriter, r *http.Request, req InvokeRequest) {
+	// 解析参数，保持Long类型精度
+	params := parseRequestParameters(req.Parameters)
+
 	color.Blue("[WEB] 开始执行Dubbo调用: %s.%s", req.ServiceName, req.MethodName)
 	// 记录开始时间
 	startTime := time.Now()
-	// 执行调用
-	result, err := ws.executeInvoke(req)
+	// 执行调用，浏览器断开连接时通过请求上下文取消调用
+	if req.Timeout <= 0 {
+		req.Timeout = ws.timeout
+	}
+	result, timings, err := ws.executeInvoke(r.Context(), req, true)
 	// 计算耗时
 	duration := time.Since(startTime).Milliseconds()
 	color.Cyan("[WEB] 调用耗时: %d ms", duration)
 
-	// 保存调用历史
-	history := CallHistory{
-		ID:          fmt.Sprintf("%d", time.Now().UnixNano()),
-		ServiceName: req.ServiceName,
-		MethodName:  req.MethodName,
-		Parameters:  safeCopyParameters(params), // 使用解析后的参数数组，保持Long类型精度
-		Types:       req.Types,
-		Registry:    req.Registry,
-		App:         req.App,
-		Success:     err == nil,
-		Timestamp:   time.Now(),
-		Duration:    duration,
-		Namespace:   req.Namespace,
+	// 结果只序列化一次，同时用于历史记录和响应；超过阈值时写入结果文件
+	var output invokeOutput
+	var assertions []AssertionResult
+	if err == nil {
+		output = ws.prepareResult(result)
+		assertions = EvaluateAssertions(req.Expect, AssertionContext{Result: ws.assertionTarget(req, result), Duration: duration})
 	}
 
+	// 保存调用历史
+	ws.saveHistory(req, params, output, timings, assertions, err, duration)
+
 	if err != nil {
+		if r.Context().Err() != nil {
+			color.Yellow("[WEB] 客户端已断开，调用被取消: %v", r.Context().Err())
+		}
 		color.Red("[WEB] 调用失败: %v", err)
-		history.Result = err.Error()
-		ws.history = append(ws.history, history)
-		color.Cyan("[WEB] 已保存失败调用历史, 历史记录总数: %d", len(ws.history))
 		// 直接返回原始错误信息，不进行JSON包装
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		w.WriteHeader(http.StatusBadRequest)
@@ -268,35 +373,30 @@ func (ws *WebServer) handleInvoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 保存成功结果，对结果中的大整数进行安全处理
-	safeResult := safeCopyValue(result)
 	color.Green("[WEB] 调用成功，结果已进行安全处理")
 
-	// 使用自定义编码器来处理大整数，确保它们在JSON序列化过程中不会丢失精度
-	// 创建一个自定义的JSON编码器，使用SetEscapeHTML(false)来避免HTML转义
-	var resultBuffer bytes.Buffer
-	encoder := json.NewEncoder(&resultBuffer)
-	encoder.SetEscapeHTML(false)
-
-	if jsonErr := encoder.Encode(safeResult); jsonErr == nil {
-		// 去除末尾的换行符
-		resultStr := strings.TrimSuffix(resultBuffer.String(), "\n")
-		history.Result = resultStr
-		color.Cyan("[WEB] 结果序列化成功, 长度: %d 字符", len(history.Result))
-	} else {
-		history.Result = fmt.Sprintf("%v", safeResult)
-		color.Yellow("[WEB] 结果序列化失败，使用字符串格式: %v", jsonErr)
-	}
-	ws.history = append(ws.history, history)
-	color.Cyan("[WEB] 已保存成功调用历史, 历史记录总数: %d", len(ws.history))
-
 	// 成功时返回标准的InvokeResponse格式，确保结果中的大整数已安全处理
 	response := InvokeResponse{
 		Success:  true,
-		Data:     safeResult, // 使用安全处理后的结果
+		Data:     output.data, // 使用安全处理后的结果
 		Error:    "",
 		Message:  "调用成功",
 		Duration: duration,
+		Timings:  timings,
+	}
+	if output.stored != nil {
+		response.Data = nil
+		response.ResultID = output.stored.ID
+		response.Size = output.stored.Size
+		response.Preview = output.preview
+		response.Message = "调用成功，结果较大已写入结果文件，请通过resultId分段获取"
+	}
+	if len(assertions) > 0 {
+		response.Assertions = assertions
+		if failed := AssertionsFailed(assertions); failed > 0 {
+			response.Message = fmt.Sprintf("调用成功，%d/%d 个断言失败", failed, len(assertions))
+			color.Red("[WEB] %d/%d 个断言失败", failed, len(assertions))
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -315,12 +415,13 @@ func (ws *WebServer) handleList(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	// 处理POST请求的JSON数据
-	var registry, app, namespace string
+	var registry, app, namespace, profile string
 	if r.Method == "POST" {
 		var requestData struct {
 			Registry  string `json:"registry"`
 			App       string `json:"app"`
 			Namespace string `json:"namespace"`
+			Profile   string `json:"profile"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
 			color.Red("[WEB] 解析请求数据失败: %v", err)
@@ -334,11 +435,13 @@ func (ws *WebServer) handleList(w http.ResponseWriter, r *http.Request) {
 		registry = requestData.Registry
 		app = requestData.App
 		namespace = requestData.Namespace
+		profile = requestData.Profile
 	} else {
 		// 处理GET请求的查询参数
 		registry = r.URL.Query().Get("registry")
 		app = r.URL.Query().Get("app")
 		namespace = r.URL.Query().Get("namespace")
+		profile = r.URL.Query().Get("profile")
 	}
 
 	if registry == "" {
@@ -355,6 +458,11 @@ func (ws *WebServer) handleList(w http.ResponseWriter, r *http.Request) {
 		Timeout:     time.Duration(ws.timeout) * time.Millisecond,
 		Namespace:   namespace,
 	}
+	// 注册中心的认证信息来自所选环境
+	if err := ws.applyProfile(profile, config); err != nil {
+		json.NewEncoder(w).Encode(ListServicesResponse{Success: false, Error: err.Error()})
+		return
+	}
 	color.Cyan("[WEB] 创建Dubbo客户端配置: 注册中心=%s, 应用=%s, 超时=%dms", config.Registry, config.Application, ws.timeout)
 
 	// 创建真实的dubbo客户端
@@ -509,26 +617,253 @@ func (ws *WebServer) parseParameter(param string) (interface{}, error) {
 	return result, nil
 }
 
-// executeInvoke 执行调用
-func (ws *WebServer) executeInvoke(req InvokeRequest) (interface{}, error) {
-	color.Blue("[WEB] 开始执行Dubbo调用: %s.%s", req.ServiceName, req.MethodName)
-	color.Cyan("[WEB] 调用参数: Registry=%s, App=%s, Timeout=%dms", req.Registry, req.App, req.Timeout)
+// parseRequestParameters 解析请求中的参数，保持Long类型精度
+func parseRequestParameters(raw json.RawMessage) []interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
 
-	// 创建Dubbo客户端配置
-	cfg := &DubboConfig{
+	// 尝试解析为参数数组
+	var paramArray []interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&paramArray); err == nil {
+		// 成功解析为数组
+		color.Green("[WEB] 解析为多参数格式，参数数量: %d", len(paramArray))
+		return convertJSONNumbers(paramArray)
+	}
+
+	// 如果不是数组格式，尝试解析为单个参数
+	var singleParam interface{}
+	decoder = json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&singleParam); err == nil {
+		color.Green("[WEB] 解析为单参数格式，参数数量: 1")
+		return []interface{}{convertJSONNumber(singleParam)}
+	}
+
+	// 如果都失败了，作为字符串处理
+	color.Yellow("[WEB] 参数解析失败，作为字符串处理: %s", string(raw))
+	return []interface{}{string(raw)}
+}
+
+// resultPreviewSize 大结果预览的字节数
+const resultPreviewSize = 4096
+
+// invokeOutput 序列化后的调用结果
+type invokeOutput struct {
+	data    json.RawMessage // 完整结果JSON，结果写入结果文件时为空
+	stored  *StoredResult   // 结果超过阈值时的结果文件句柄
+	preview string          // 结果文件的开头部分
+}
+
+// prepareResult 对结果中的大整数进行安全处理并序列化，超过阈值时写入结果文件
+// 读取响应时已写入结果文件的结果只读取开头部分作为预览
+func (ws *WebServer) prepareResult(result interface{}) invokeOutput {
+	if stored, ok := result.(*StoredResult); ok {
+		preview, err := ws.results.ReadRange(stored.ID, 0, resultPreviewSize)
+		if err != nil {
+			color.Yellow("[WEB] 读取结果预览失败: %v", err)
+		}
+		return invokeOutput{stored: stored, preview: string(trimPartialRune(preview))}
+	}
+
+	// 使用自定义编码器来处理大整数，确保它们在JSON序列化过程中不会丢失精度
+	// 创建一个自定义的JSON编码器，使用SetEscapeHTML(false)来避免HTML转义
+	safeResult := safeCopyValue(result)
+	var resultBuffer bytes.Buffer
+	encoder := json.NewEncoder(&resultBuffer)
+	encoder.SetEscapeHTML(false)
+
+	var data []byte
+	if jsonErr := encoder.Encode(safeResult); jsonErr == nil {
+		// 去除末尾的换行符
+		data = bytes.TrimSuffix(resultBuffer.Bytes(), []byte("\n"))
+		color.Cyan("[WEB] 结果序列化成功, 长度: %d 字符", len(data))
+	} else {
+		data, _ = json.Marshal(fmt.Sprintf("%v", safeResult))
+		color.Yellow("[WEB] 结果序列化失败，使用字符串格式: %v", jsonErr)
+	}
+
+	if ws.results == nil || ws.spoolThreshold <= 0 || len(data) <= ws.spoolThreshold {
+		return invokeOutput{data: data}
+	}
+
+	stored, err := ws.results.Save(data)
+	if err != nil {
+		color.Yellow("[WEB] 大结果写入结果文件失败，直接返回: %v", err)
+		return invokeOutput{data: data}
+	}
+	color.Cyan("[WEB] 结果大小 %d 字节超过阈值 %d 字节，已写入结果文件: %s", stored.Size, ws.spoolThreshold, stored.ID)
+
+	return invokeOutput{
+		stored:  stored,
+		preview: previewBytes(data, resultPreviewSize),
+	}
+}
+
+// assertionTarget 返回断言使用的结果，已写入结果文件的响应只在有断言时读取并解析
+func (ws *WebServer) assertionTarget(req InvokeRequest, result interface{}) interface{} {
+	stored, ok := result.(*StoredResult)
+	if !ok || len(req.Expect) == 0 {
+		return result
+	}
+	file, _, err := ws.results.Open(stored.ID)
+	if err != nil {
+		color.Yellow("[WEB] 读取断言使用的结果失败: %v", err)
+		return nil
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		color.Yellow("[WEB] 读取断言使用的结果失败: %v", err)
+		return nil
+	}
+	var parsed interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&parsed); err != nil {
+		return string(data)
+	}
+	return convertJSONNumber(parsed)
+}
+
+// previewBytes 截取开头的最多n个字节，不截断UTF-8字符
+func previewBytes(data []byte, n int) string {
+	if len(data) <= n {
+		return string(data)
+	}
+	for n > 0 && !utf8.RuneStart(data[n]) {
+		n--
+	}
+	return string(data[:n])
+}
+
+// trimPartialRune 去掉末尾不完整的UTF-8字符
+func trimPartialRune(data []byte) []byte {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return data[:i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+// saveHistory 保存调用历史
+func (ws *WebServer) saveHistory(req InvokeRequest, params []interface{}, output invokeOutput, timings *InvokeTimings, assertions []AssertionResult, err error, duration int64) CallHistory {
+	history := CallHistory{
+		ID:          fmt.Sprintf("%d", time.Now().UnixNano()),
+		ServiceName: req.ServiceName,
+		MethodName:  req.MethodName,
+		Parameters:  safeCopyParameters(params), // 使用解析后的参数数组，保持Long类型精度
+		Types:       req.Types,
 		Registry:    req.Registry,
-		Application: req.App,
-		Timeout:     time.Duration(req.Timeout) * time.Millisecond,
+		App:         req.App,
+		Success:     err == nil,
+		Timestamp:   time.Now(),
+		Duration:    duration,
+		Namespace:   req.Namespace,
+		Version:     req.Version,
+		Group:       req.Group,
+		Tag:         req.Tag,
+		Timeout:     req.Timeout,
+		Timings:     timings,
+		Expect:      req.Expect,
+		Assertions:  assertions,
+		Source:      "web",
+		Profile:     req.Profile,
 	}
-	color.Green("[WEB] Dubbo客户端配置创建成功")
 
-	// 解析字符串参数为interface{}类型
+	switch {
+	case err != nil:
+		history.Result = err.Error()
+	case output.stored != nil:
+		history.Result = output.preview
+		history.ResultID = output.stored.ID
+	default:
+		history.Result = string(output.data)
+	}
+
+	if err := ws.history.Add(history); err != nil {
+		color.Yellow("[WEB] 保存调用历史失败: %v", err)
+	} else {
+		color.Cyan("[WEB] 已保存调用历史, 历史记录总数: %d", ws.history.Count())
+	}
+
+	return history
+}
+
+// handleDryRun 解析注册中心和服务提供者，返回调用计划
+func (ws *WebServer) handleDryRun(w http.ResponseWriter, req InvokeRequest) {
+	if req.Timeout <= 0 {
+		req.Timeout = ws.timeout
+	}
+
+	params, err := decodeInvokeParameters(req.Parameters)
+	if err != nil {
+		ws.writeError(w, err.Error())
+		return
+	}
+	cfg, err := ws.invokeConfig(req)
+	if err != nil {
+		ws.writeError(w, err.Error())
+		return
+	}
+	// 与CLI的dry-run一样，参数校验只使用元数据文件和本地缓存的服务目录
+	if !req.SkipValidation {
+		if err := ValidateInvokeArgumentsOffline(cfg, req.ServiceName, req.MethodName, req.Types, params); err != nil {
+			ws.writeError(w, err.Error())
+			return
+		}
+	}
+
+	plan, err := PlanInvoke(cfg, req.ServiceName, req.MethodName, req.Types, params)
+	if err != nil {
+		color.Red("[WEB] 生成调用计划失败: %v", err)
+		ws.writeError(w, fmt.Sprintf("生成调用计划失败: %v", err))
+		return
+	}
+	color.Yellow("[DUBBO CMD] (dry-run) %s", strings.TrimSpace(plan.Command))
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(InvokeResponse{
+		Success: true,
+		Data:    plan,
+		Message: "dry-run: 未发送任何请求",
+	})
+}
+
+// invokeConfig 根据调用请求和所选环境创建Dubbo客户端配置
+func (ws *WebServer) invokeConfig(req InvokeRequest) (*DubboConfig, error) {
+	cfg := &DubboConfig{
+		Registry:       req.Registry,
+		Application:    req.App,
+		Timeout:        time.Duration(req.Timeout) * time.Millisecond,
+		Version:        req.Version,
+		Group:          req.Group,
+		Tag:            req.Tag,
+		Namespace:      req.Namespace,
+		MetadataFile:   ws.metadataFile,
+		MaxPayloadSize: ws.maxPayload,
+	}
+	if err := ws.applyProfile(req.Profile, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// decodeInvokeParameters 将请求中的参数数组解析为调用参数，保持Long类型精度
+func decodeInvokeParameters(raw json.RawMessage) ([]interface{}, error) {
 	color.Blue("[WEB] 开始解析调用参数")
 	var params []interface{}
-	if len(req.Parameters) > 0 {
+	if len(raw) > 0 {
 		// 尝试解析为参数数组
 		var paramArray []interface{}
-		decoder := json.NewDecoder(strings.NewReader(string(req.Parameters)))
+		decoder := json.NewDecoder(strings.NewReader(string(raw)))
 		decoder.UseNumber()
 		err := decoder.Decode(&paramArray)
 		if err != nil {
@@ -541,6 +876,41 @@ func (ws *WebServer) executeInvoke(req InvokeRequest) (interface{}, error) {
 		color.Green("[WEB] 解析参数完成，参数数量: %d", len(params))
 	}
 	color.Green("[WEB] 参数解析完成，最终参数数量: %d", len(params))
+	return params, nil
+}
+
+// executeInvoke 执行调用，ctx取消时中止调用并关闭到服务提供者的连接
+// spool为true时超过阈值的响应边读取边写入结果文件，返回*StoredResult
+func (ws *WebServer) executeInvoke(ctx context.Context, req InvokeRequest, spool bool) (interface{}, *InvokeTimings, error) {
+	color.Blue("[WEB] 开始执行Dubbo调用: %s.%s", req.ServiceName, req.MethodName)
+	color.Cyan("[WEB] 调用参数: Registry=%s, App=%s, Timeout=%dms", req.Registry, req.App, req.Timeout)
+
+	// 创建Dubbo客户端配置
+	cfg, err := ws.invokeConfig(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	color.Green("[WEB] Dubbo客户端配置创建成功")
+
+	// 录制调用时需要完整结果，不写入结果文件
+	if spool && ws.recordFile == "" && ws.spoolThreshold > 0 {
+		cfg.ResultSpool = ws.results
+		cfg.SpoolThreshold = ws.spoolThreshold
+	}
+
+	// 解析字符串参数为interface{}类型
+	params, err := decodeInvokeParameters(req.Parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 发送前按方法元数据校验参数
+	if !req.SkipValidation {
+		if err := ValidateInvokeArguments(cfg, req.ServiceName, req.MethodName, req.Types, params); err != nil {
+			color.Red("[WEB] %v", err)
+			return nil, nil, err
+		}
+	}
 
 	// 构建并打印dubbo invoke命令，方便用户验证
 	invokeCmd := ws.buildDubboInvokeCommand(req.ServiceName, req.MethodName, params)
@@ -551,20 +921,34 @@ func (ws *WebServer) executeInvoke(req InvokeRequest) (interface{}, error) {
 	realClient, err := NewRealDubboClient(cfg)
 	if err != nil {
 		color.Red("[WEB] 真实Dubbo客户端创建失败: %v", err)
-		return nil, fmt.Errorf("无法连接到Dubbo注册中心: %v", err)
+		return nil, nil, fmt.Errorf("无法连接到Dubbo注册中心: %v", err)
 	}
 	color.Green("[WEB] 真实Dubbo客户端创建成功")
 	defer realClient.Close()
 
 	// 执行真实的泛化调用
 	color.Blue("[WEB] 开始执行真实Dubbo调用")
-	result, err := realClient.GenericInvoke(req.ServiceName, req.MethodName, req.Types, params)
+	invokeStart := time.Now()
+	result, err := realClient.GenericInvokeContext(ctx, req.ServiceName, req.MethodName, req.Types, params)
+	timings := realClient.LastTimings()
+	if ws.recordFile != "" {
+		recordInteraction(ws.recordFile, newInteraction(cfg, req.ServiceName, req.MethodName, req.Types, params,
+			realClient.LastProvider(), result, err, time.Since(invokeStart).Milliseconds()))
+	}
+	color.Cyan("[WEB] 调用阶段耗时: %s", timings)
 	if err != nil {
 		color.Red("[WEB] 真实调用失败: %v", err)
-		return nil, fmt.Errorf("真实调用失败: %v", err)
+		return nil, timings, fmt.Errorf("真实调用失败: %v", err)
 	}
 	color.Green("[WEB] 真实调用成功")
 
+	// 结果的JSON解析计入parse阶段
+	parseStart := time.Now()
+	defer func() {
+		timings.Parse = round3(timings.Parse + msSince(parseStart))
+		timings.sum()
+	}()
+
 	// 检查result是否为JSON字符串，如果是则解析为对象
 	if resultStr, ok := result.(string); ok {
 		// 尝试解析JSON字符串为对象，使用UseNumber()保持大整数精度
@@ -584,7 +968,7 @@ func (ws *WebServer) executeInvoke(req InvokeRequest) (interface{}, error) {
 
 	// 直接返回原始结果，不进行额外的数据包装处理
 	color.Green("[WEB] 返回原始结果，数据类型: %T", result)
-	return result, nil
+	return result, timings, nil
 }
 
 // buildDubboInvokeCommand 构建dubbo invoke命令，用于调试和验证
@@ -616,24 +1000,62 @@ func (ws *WebServer) handleHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 返回最近的50条历史记录
-	historyCount := len(ws.history)
-	start := 0
-	if historyCount > 50 {
-		start = historyCount - 50
+	query, err := parseHistoryQuery(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		ws.writeError(w, err.Error())
+		return
 	}
-
-	recentHistory := ws.history[start:]
+	page := ws.history.Search(query)
 
 	response := map[string]interface{}{
 		"success": true,
-		"history": recentHistory,
-		"total":   historyCount,
+		"history": page.Items,
+		"total":   page.Total,
+		"offset":  page.Offset,
+		"limit":   page.Limit,
 	}
 
 	json.NewEncoder(w).Encode(response)
 }
 
+// handleHistoryRerun 按历史记录重新调用: POST /api/history/rerun?id=<id>
+func (ws *WebServer) handleHistoryRerun(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != "POST" {
+		ws.writeError(w, "只支持POST方法")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	entry, ok := ws.history.Get(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		ws.writeError(w, fmt.Sprintf("历史记录不存在: %s", id))
+		return
+	}
+
+	req, err := invokeRequestFromHistory(entry)
+	if err != nil {
+		ws.writeError(w, err.Error())
+		return
+	}
+	if req.Registry == "" {
+		req.Registry = ws.registry
+	}
+	if req.App == "" {
+		req.App = ws.app
+	}
+	if req.Timeout <= 0 {
+		req.Timeout = ws.timeout
+	}
+
+	color.Blue("[WEB] 重新调用历史记录 %s: %s.%s", id, req.ServiceName, req.MethodName)
+	ws.serveInvoke(w, r, req)
+}
+
 // handleClearHistory 处理清空历史记录
 func (ws *WebServer) handleClearHistory(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -644,8 +1066,12 @@ func (ws *WebServer) handleClearHistory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// 清空历史记录
-	ws.history = make([]CallHistory, 0)
+	// 清空历史记录及其引用的大结果文件
+	if err := ws.history.Clear(); err != nil {
+		ws.writeError(w, err.Error())
+		return
+	}
+	ws.results.Clear()
 
 	response := map[string]interface{}{
 		"success": true,
@@ -655,6 +1081,220 @@ func (ws *WebServer) handleClearHistory(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
+// invokeForJob 执行后台任务中的单次调用，并记录调用历史
+func (ws *WebServer) invokeForJob(ctx context.Context, req InvokeRequest) (interface{}, error) {
+	if req.Registry == "" {
+		req.Registry = ws.registry
+	}
+	if req.App == "" {
+		req.App = ws.app
+	}
+	if req.Timeout <= 0 {
+		req.Timeout = ws.timeout
+	}
+
+	params := parseRequestParameters(req.Parameters)
+	startTime := time.Now()
+	result, timings, err := ws.executeInvoke(ctx, req, true)
+	duration := time.Since(startTime).Milliseconds()
+
+	var output invokeOutput
+	var assertions []AssertionResult
+	if err == nil {
+		output = ws.prepareResult(result)
+		assertions = EvaluateAssertions(req.Expect, AssertionContext{Result: ws.assertionTarget(req, result), Duration: duration})
+	}
+	ws.saveHistory(req, params, output, timings, assertions, err, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	// 断言失败时任务按失败处理
+	if failed := AssertionsFailed(assertions); failed > 0 {
+		messages := make([]string, 0, failed)
+		for _, assertion := range assertions {
+			if !assertion.Passed {
+				messages = append(messages, fmt.Sprintf("%s: %s", assertion.Expression, assertion.Message))
+			}
+		}
+		return nil, fmt.Errorf("%d/%d 个断言失败: %s", failed, len(assertions), strings.Join(messages, "; "))
+	}
+
+	// 大结果在任务中只保留句柄和预览
+	if output.stored != nil {
+		return map[string]interface{}{
+			"resultId": output.stored.ID,
+			"size":     output.stored.Size,
+			"preview":  output.preview,
+		}, nil
+	}
+	return result, nil
+}
+
+// handleJobs 处理后台任务的提交(POST)和列表查询(GET)
+func (ws *WebServer) handleJobs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	switch r.Method {
+	case "OPTIONS":
+		w.WriteHeader(http.StatusOK)
+	case "GET":
+		response := map[string]interface{}{
+			"success": true,
+			"jobs":    ws.jobs.List(),
+			"stats":   ws.jobs.Stats(),
+		}
+		json.NewEncoder(w).Encode(response)
+	case "POST":
+		var body struct {
+			Invocations []InvokeRequest `json:"invocations"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			ws.writeError(w, fmt.Sprintf("请求解析失败: %v", err))
+			return
+		}
+
+		job, err := ws.jobs.Submit(body.Invocations)
+		if err != nil {
+			color.Red("[WEB] 提交后台任务失败: %v", err)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			ws.writeError(w, fmt.Sprintf("提交任务失败: %v", err))
+			return
+		}
+		color.Green("[WEB] 已提交后台任务 %s, 调用数量: %d", job.ID, job.Total)
+
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"job":     job,
+		})
+	default:
+		ws.writeError(w, "只支持GET和POST方法")
+	}
+}
+
+// handleJob 处理单个后台任务的状态查询(GET)和取消(DELETE)
+func (ws *WebServer) handleJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/jobs/")
+	if id == "" {
+		ws.handleJobs(w, r)
+		return
+	}
+
+	var job *Job
+	var err error
+	switch r.Method {
+	case "OPTIONS":
+		w.WriteHeader(http.StatusOK)
+		return
+	case "GET":
+		job, err = ws.jobs.Get(id)
+	case "DELETE":
+		job, err = ws.jobs.Cancel(id)
+		if err == nil {
+			color.Yellow("[WEB] 已取消后台任务 %s", id)
+		}
+	default:
+		ws.writeError(w, "只支持GET和DELETE方法")
+		return
+	}
+
+	if err != nil {
+		if _, getErr := ws.jobs.Get(id); getErr == nil {
+			w.WriteHeader(http.StatusConflict)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		ws.writeError(w, err.Error())
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(map[string]interface{}{
+		"success": true,
+		"job":     job,
+	})
+}
+
+// handleResult 分段获取写入结果文件的大结果
+// GET /api/results/{id}?offset=0&limit=1048576 返回指定字节范围；?download=1 下载完整文件（支持Range请求头）
+func (ws *WebServer) handleResult(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != "GET" {
+		ws.writeError(w, "只支持GET方法")
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/results/")
+	query := r.URL.Query()
+
+	if query.Get("download") != "" {
+		file, stored, err := ws.results.Open(id)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer file.Close()
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", stored.ID))
+		http.ServeContent(w, r, stored.ID+".json", stored.CreatedAt, file)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	offset, _ := strconv.ParseInt(query.Get("offset"), 10, 64)
+	limit, _ := strconv.ParseInt(query.Get("limit"), 10, 64)
+	if limit <= 0 || limit > 8*1024*1024 {
+		limit = 1024 * 1024
+	}
+	// 至少容纳一个完整的UTF-8字符
+	if limit < utf8.UTFMax {
+		limit = utf8.UTFMax
+	}
+
+	stored, err := ws.results.Get(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		ws.writeError(w, err.Error())
+		return
+	}
+	chunk, err := ws.results.ReadRange(id, offset, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		ws.writeError(w, err.Error())
+		return
+	}
+
+	// 分段边界落在UTF-8字符之间，被截断的字符留到下一段
+	if offset+int64(len(chunk)) < stored.Size {
+		chunk = trimPartialRune(chunk)
+	}
+
+	nextOffset := offset + int64(len(chunk))
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(map[string]interface{}{
+		"success":    true,
+		"id":         stored.ID,
+		"size":       stored.Size,
+		"offset":     offset,
+		"length":     len(chunk),
+		"nextOffset": nextOffset,
+		"eof":        nextOffset >= stored.Size,
+		"chunk":      string(chunk),
+	})
+}
+
 // handleMethods 处理获取服务方法列表
 func (ws *WebServer) handleMethods(w http.ResponseWriter, r *http.Request) {
 
@@ -695,6 +1335,10 @@ func (ws *WebServer) handleMethods(w http.ResponseWriter, r *http.Request) {
 		Application: app,
 		Timeout:     time.Duration(timeout) * time.Millisecond,
 	}
+	if err := ws.applyProfile(r.URL.Query().Get("profile"), config); err != nil {
+		ws.writeError(w, err.Error())
+		return
+	}
 
 	client, err := NewRealDubboClient(config)
 	if err != nil {
@@ -706,6 +1350,7 @@ func (ws *WebServer) handleMethods(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(response)
 		return
 	}
+	defer client.Close()
 
 	// 检查连接状态
 	if !client.IsConnected() {
@@ -1132,6 +1777,26 @@ const indexHTML = `<!DOCTYPE html>
         .icon-btn.clear:hover {
             background-color: #ffebee;
         }
+        .compare-btn {
+            float: right;
+            background: none;
+            border: 1px solid #dadce0;
+            border-radius: 4px;
+            cursor: pointer;
+            font-size: 12px;
+            padding: 0 6px;
+            color: #5f6368;
+        }
+        .compare-btn:hover {
+            background-color: #e3f2fd;
+        }
+        .rerun-btn {
+            margin-right: 4px;
+        }
+        .service-item.compare-base {
+            border-left: 3px solid #1a73e8;
+            background-color: #e8f0fe;
+        }
         .result-panel h2::before {
             content: '📊'; /* 图表图标 - 调用结果 */
         }
@@ -1411,6 +2076,11 @@ const indexHTML = `<!DOCTYPE html>
                     <div class="panel service-call-panel">
                         <h2>服务调用</h2>
 
+                        <div class="form-group" id="profileGroup" style="display: none;">
+                            <label for="profile">环境:</label>
+                            <select id="profile" onchange="onProfileChange()"></select>
+                            <div id="profileInfo" style="font-size: 12px; color: #6c757d; margin-top: 4px;"></div>
+                        </div>
                         <div class="form-group">
                             <label for="callFormat">调用格式:</label>
                             <select id="callFormat" onchange="toggleCallFormat()">
@@ -1480,6 +2150,10 @@ const indexHTML = `<!DOCTYPE html>
                             <label for="types">参数类型 (可选，逗号分隔):</label>
                             <input type="text" id="types" placeholder="java.lang.Long,java.lang.String">
                         </div>
+                        <div class="form-group">
+                            <label for="expectations">结果断言 (可选，每行一个):</label>
+                            <textarea id="expectations" placeholder="$.success == true&#10;len($.data) > 0&#10;duration < 500" style="min-height: 60px;"></textarea>
+                        </div>
                         <div class="btn-group">
                             <button class="btn" onclick="invokeService()">🚀 调用服务</button>
                             <button class="btn btn-secondary" onclick="generateExample()">📝 生成示例</button>
@@ -1511,7 +2185,13 @@ const indexHTML = `<!DOCTYPE html>
                         <h2>
                             <span>最近调用历史</span>
                             <div class="history-actions">
-                                <button class="icon-btn download" onclick="downloadHistory()" title="下载日志">
+                                <select id="historyExportFormat" title="导出格式" style="padding: 2px 4px; font-size: 12px;">
+                                    <option value="har">HAR</option>
+                                    <option value="markdown">Markdown</option>
+                                    <option value="csv">CSV</option>
+                                    <option value="json">JSON</option>
+                                </select>
+                                <button class="icon-btn download" onclick="downloadHistory()" title="按当前搜索条件导出历史">
                                     📥
                                 </button>
                                 <button class="icon-btn clear" onclick="clearHistory()" title="清空日志">
@@ -1519,11 +2199,19 @@ const indexHTML = `<!DOCTYPE html>
                                 </button>
                             </div>
                         </h2>
+                        <div style="margin-bottom: 10px;">
+                            <input type="text" id="historySearch" placeholder="搜索服务、方法、参数或结果..."
+                                   style="width: 100%; padding: 8px; border: 1px solid #ddd; border-radius: 4px; font-size: 14px;"
+                                   oninput="searchHistory()">
+                        </div>
                         <div id="historyList" class="service-list history-list">
                             <div style="padding: 20px; text-align: center; color: #6c757d;">
                                 <p>暂无调用历史</p>
                             </div>
                         </div>
+                        <div style="display: none; text-align: center; margin-top: 10px;" id="historyPagination">
+                            <button class="btn btn-secondary" onclick="loadHistory(true)" style="padding: 8px 16px; font-size: 14px;">加载更多历史</button>
+                        </div>
                     </div>
                 </div>
             </div>
@@ -1543,6 +2231,13 @@ const indexHTML = `<!DOCTYPE html>
                     正在调用服务...
                 </div>
                 <div id="result" class="result" style="display: none;"></div>
+                <div id="resultMore" style="display: none; margin-top: 8px; font-size: 13px; color: #666;">
+                    <span id="resultMoreInfo"></span>
+                    <button class="btn btn-secondary" id="resultMoreBtn" onclick="loadMoreResult()" style="margin-left: 8px;">加载更多</button>
+                    <a id="resultDownload" href="#" style="margin-left: 8px;">下载完整结果</a>
+                </div>
+                <div id="assertionResult" class="result" style="display: none; margin-top: 10px;"></div>
+                <div id="historyDiffResult" class="result" style="display: none; margin-top: 10px;"></div>
             </div>
         </div>
     </div>
@@ -1810,8 +2505,9 @@ const indexHTML = `<!DOCTYPE html>
                 serviceName: serviceName, methodName: methodName,
                 parameters: parameters,
                 types: types ? types.split(',').map(t => t.trim()) : [],
-                registry: registry, app: '{{.App}}', timeout: 10000,
-                namespace: namespace
+                registry: registry, app: '{{.App}}', timeout: profileTimeout(),
+                namespace: namespace, profile: currentProfile(),
+                expect: document.getElementById('expectations').value.split('\n').map(e => e.trim()).filter(e => e)
             };
             showLoading(true);
             const startTime = Date.now(); // 记录前端调用开始时间
@@ -1893,7 +2589,7 @@ const indexHTML = `<!DOCTYPE html>
                 registry = registryAddress;
             }
             
-            fetch('/api/list?registry=' + encodeURIComponent(registry) + '&app={{.App}}&timeout=10000')
+            fetch('/api/list?registry=' + encodeURIComponent(registry) + '&app={{.App}}&timeout=10000&profile=' + encodeURIComponent(currentProfile()))
             .then(response => response.json())
             .then(data => {
                 if (data.success) { displayServices(data.services); }
@@ -2024,7 +2720,7 @@ const indexHTML = `<!DOCTYPE html>
                 return;
             }
             
-            fetch('/api/methods?serviceName=' + encodeURIComponent(serviceName) + '&registry=' + encodeURIComponent(registry) + '&app={{.App}}&timeout=10000')
+            fetch('/api/methods?serviceName=' + encodeURIComponent(serviceName) + '&registry=' + encodeURIComponent(registry) + '&app={{.App}}&timeout=10000&profile=' + encodeURIComponent(currentProfile()))
             .then(response => response.json())
             .then(data => {
                 if (data.success) {
@@ -2078,8 +2774,18 @@ const indexHTML = `<!DOCTYPE html>
             const result = document.getElementById('result');
             result.className = 'result ' + (data.success ? 'success' : 'error');
             
-            // 如果是成功调用，显示data字段的内容；如果是失败，显示error信息
-            if (data.success && data.data !== undefined) {
+            // 结果较大时只返回预览和resultId，其余部分通过/api/results分段获取
+            spooledResult = null;
+            document.getElementById('resultMore').style.display = 'none';
+            if (data.success && data.resultId) {
+                result.textContent = data.preview || '';
+                spooledResult = {
+                    id: data.resultId,
+                    size: data.size,
+                    nextOffset: new TextEncoder().encode(data.preview || '').length
+                };
+                updateResultMore();
+            } else if (data.success && data.data !== undefined) {
                 // 格式化显示数据，提供优雅的输出格式
                 if (typeof data.data === 'string') {
                     try {
@@ -2115,6 +2821,8 @@ const indexHTML = `<!DOCTYPE html>
                 result.textContent = JSON.stringify(data, null, 2);
             }
             
+            displayAssertions(data.assertions);
+            
             // 更新结果面板标题的状态指示器
             const resultPanelTitle = document.querySelector('.result-panel h2');
             if (resultPanelTitle) {
@@ -2153,6 +2861,87 @@ const indexHTML = `<!DOCTYPE html>
             setTimeout(loadHistory, 500);
         }
         
+        // 写入结果文件的大结果的分段读取状态
+        let spooledResult = null;
+        
+        // 更新大结果的已加载进度
+        function updateResultMore() {
+            const more = document.getElementById('resultMore');
+            if (!spooledResult) {
+                more.style.display = 'none';
+                return;
+            }
+            const loaded = Math.min(spooledResult.nextOffset, spooledResult.size);
+            const done = loaded >= spooledResult.size;
+            document.getElementById('resultMoreInfo').textContent = done ?
+                '结果已全部加载 (' + formatBytes(spooledResult.size) + ')' :
+                '结果较大，已显示 ' + formatBytes(loaded) + ' / ' + formatBytes(spooledResult.size);
+            document.getElementById('resultMoreBtn').style.display = done ? 'none' : 'inline-block';
+            document.getElementById('resultDownload').href = '/api/results/' + encodeURIComponent(spooledResult.id) + '?download=1';
+            more.style.display = 'block';
+        }
+        
+        // 加载大结果的下一段并追加显示
+        async function loadMoreResult() {
+            if (!spooledResult) {
+                return;
+            }
+            const current = spooledResult;
+            const button = document.getElementById('resultMoreBtn');
+            button.disabled = true;
+            try {
+                const response = await fetch('/api/results/' + encodeURIComponent(current.id) + '?offset=' + current.nextOffset);
+                const chunk = await response.json();
+                if (!chunk.success) {
+                    throw new Error(chunk.error || '获取结果失败');
+                }
+                // 加载期间已显示了新的调用结果
+                if (spooledResult !== current) {
+                    return;
+                }
+                document.getElementById('result').textContent += chunk.chunk;
+                current.nextOffset = chunk.eof ? current.size : chunk.nextOffset;
+                updateResultMore();
+            } catch (error) {
+                document.getElementById('resultMoreInfo').textContent = '获取结果失败: ' + error.message;
+            } finally {
+                button.disabled = false;
+            }
+        }
+        
+        // 格式化字节数
+        function formatBytes(size) {
+            if (size >= 1024 * 1024) {
+                return (size / 1024 / 1024).toFixed(1) + ' MB';
+            }
+            if (size >= 1024) {
+                return (size / 1024).toFixed(1) + ' KB';
+            }
+            return size + ' B';
+        }
+        
+        // 显示断言结果
+        function displayAssertions(assertions) {
+            const assertionResult = document.getElementById('assertionResult');
+            if (!assertions || assertions.length === 0) {
+                assertionResult.style.display = 'none';
+                return;
+            }
+            const failed = assertions.filter(a => !a.passed).length;
+            const lines = [failed === 0 ?
+                '断言全部通过 (' + assertions.length + '/' + assertions.length + ')' :
+                '断言失败 (' + failed + '/' + assertions.length + '):'];
+            assertions.forEach(a => {
+                lines.push((a.passed ? '  ✔ ' : '  ✘ ') + a.expression);
+                if (!a.passed && a.message) {
+                    lines.push('      ' + a.message);
+                }
+            });
+            assertionResult.className = 'result ' + (failed === 0 ? 'success' : 'error');
+            assertionResult.textContent = lines.join('\n');
+            assertionResult.style.display = 'block';
+        }
+        
         // 处理对象中的大整数，确保它们以字符串形式显示
         function processLargeIntegers(obj) {
             if (obj === null || obj === undefined) {
@@ -2208,23 +2997,28 @@ const indexHTML = `<!DOCTYPE html>
             return obj;
         }
         function downloadHistory() {
-            fetch('/api/history')
-            .then(response => response.json())
-            .then(data => {
-                if (data.success && data.history) {
-                    const blob = new Blob([JSON.stringify(data.history, null, 2)], 
-                        { type: 'application/json' });
-                    const url = URL.createObjectURL(blob);
-                    const a = document.createElement('a');
-                    a.href = url;
-                    a.download = 'dubbo-invoke-history-' + new Date().toISOString().slice(0,19).replace(/:/g, '-') + '.json';
-                    document.body.appendChild(a);
-                    a.click();
-                    document.body.removeChild(a);
-                    URL.revokeObjectURL(url);
-                } else {
-                    alert('下载失败: ' + (data.error || '无历史数据'));
+            // 按当前搜索条件由服务端生成报告
+            const format = document.getElementById('historyExportFormat').value;
+            const keyword = document.getElementById('historySearch').value.trim();
+            fetch('/api/history/export?format=' + format + '&q=' + encodeURIComponent(keyword))
+            .then(response => {
+                // 出错时返回JSON错误信息，不带Content-Disposition
+                if (!response.headers.get('Content-Disposition')) {
+                    return response.json().then(data => { throw new Error(data.error || '无历史数据'); });
                 }
+                const disposition = response.headers.get('Content-Disposition') || '';
+                const match = disposition.match(/filename="([^"]+)"/);
+                return response.blob().then(blob => ({ blob: blob, filename: match ? match[1] : 'dubbo-invoke-history.' + format }));
+            })
+            .then(file => {
+                const url = URL.createObjectURL(file.blob);
+                const a = document.createElement('a');
+                a.href = url;
+                a.download = file.filename;
+                document.body.appendChild(a);
+                a.click();
+                document.body.removeChild(a);
+                URL.revokeObjectURL(url);
             })
             .catch(error => { alert('下载失败: ' + error.message); });
         }
@@ -2248,24 +3042,55 @@ const indexHTML = `<!DOCTYPE html>
                 .catch(error => { alert('清空失败: ' + error.message); });
             }
         }
-        function loadHistory() {
-            fetch('/api/history')
+        // 历史记录分页加载，服务端按时间倒序返回
+        const historyPageSize = 50;
+        let historyOffset = 0;
+        let historySearchTimer = null;
+        function searchHistory() {
+            clearTimeout(historySearchTimer);
+            historySearchTimer = setTimeout(() => loadHistory(), 300);
+        }
+        function loadHistory(more) {
+            const offset = more === true ? historyOffset : 0;
+            const keyword = document.getElementById('historySearch').value.trim();
+            fetch('/api/history?limit=' + historyPageSize + '&offset=' + offset + '&q=' + encodeURIComponent(keyword))
             .then(response => response.json())
             .then(data => {
-                if (data.success) { displayHistory(data.history); }
+                if (data.success) {
+                    historyOffset = offset + data.history.length;
+                    displayHistory(data.history, offset > 0);
+                    document.getElementById('historyPagination').style.display = historyOffset < data.total ? 'block' : 'none';
+                }
                 else { alert('加载历史记录失败: ' + data.error); }
             })
             .catch(error => { alert('加载历史记录失败: ' + error.message); });
         }
-        function displayHistory(history) {
+        function rerunHistory(item) {
+            showLoading(true);
+            const startTime = Date.now();
+            fetch('/api/history/rerun?id=' + encodeURIComponent(item.id), { method: 'POST' })
+            .then(response => response.ok ? response.json() : response.text().then(text => ({ success: false, error: text })))
+            .then(data => {
+                showLoading(false);
+                data.totalTime = Date.now() - startTime;
+                fillFromHistory(item);
+                displayResult(data);
+            })
+            .catch(error => {
+                showLoading(false);
+                displayResult({ success: false, error: '网络错误: ' + error.message, totalTime: Date.now() - startTime });
+            });
+        }
+        function displayHistory(history, append) {
             const historyList = document.getElementById('historyList');
-            historyList.innerHTML = '';
-            if (!history || history.length === 0) {
+            if (!append) {
+                historyList.innerHTML = '';
+            }
+            if (!append && (!history || history.length === 0)) {
                 historyList.innerHTML = '<div style="padding: 20px; text-align: center; color: #6c757d;"><i>暂无调用历史</i></div>';
                 return;
             }
-            // 按时间倒序显示最近的记录
-            history.reverse().forEach(item => {
+            (history || []).forEach(item => {
                 const historyItem = document.createElement('div');
                 historyItem.className = 'service-item';
                 const timestamp = new Date(item.timestamp).toLocaleString();
@@ -2298,13 +3123,26 @@ const indexHTML = `<!DOCTYPE html>
                     paramDisplay = '<div style="font-size: 0.75em; margin-top: 2px; color: #9aa0a6;">无参数</div>';
                 }
                 
+                if (item.id === compareBaseId) {
+                    historyItem.classList.add('compare-base');
+                }
                 historyItem.innerHTML = 
+                    '<button class="compare-btn" title="选择两条记录对比结果">⇄</button>' +
+                    '<button class="compare-btn rerun-btn" title="按该记录重新调用">↻</button>' +
                     '<div class="service-name" style="max-width: 100%; overflow: hidden; text-overflow: ellipsis; white-space: nowrap;" title="' + fullServiceName + '">' + fullServiceName + '</div>' +
                     '<div style="font-size: 0.8em; margin-top: 3px; color: #5f6368; max-width: 100%; white-space: nowrap; overflow: hidden; text-overflow: ellipsis;">' +
                         '<span class="' + statusClass + '">' + status + '</span> ' + timestamp +
                     '</div>' +
                     paramDisplay;
                 historyItem.onclick = () => fillFromHistory(item);
+                historyItem.querySelector('.compare-btn').onclick = (event) => {
+                    event.stopPropagation();
+                    compareHistory(item, historyItem);
+                };
+                historyItem.querySelector('.rerun-btn').onclick = (event) => {
+                    event.stopPropagation();
+                    rerunHistory(item);
+                };
                 historyList.appendChild(historyItem);
             });
 
@@ -2315,6 +3153,53 @@ const indexHTML = `<!DOCTYPE html>
                 '.error-text { color: #e53935; }';
             document.head.appendChild(style);
         }
+        // 对比的基准记录，再选择另一条记录时请求两者结果的结构化差异
+        let compareBaseId = null;
+        function compareHistory(item, element) {
+            if (compareBaseId === null || compareBaseId === item.id) {
+                document.querySelectorAll('.compare-base').forEach(el => el.classList.remove('compare-base'));
+                compareBaseId = compareBaseId === item.id ? null : item.id;
+                if (compareBaseId !== null) {
+                    element.classList.add('compare-base');
+                }
+                return;
+            }
+
+            const baseId = compareBaseId;
+            compareBaseId = null;
+            document.querySelectorAll('.compare-base').forEach(el => el.classList.remove('compare-base'));
+            fetch('/api/history/diff?a=' + encodeURIComponent(baseId) + '&b=' + encodeURIComponent(item.id))
+            .then(response => response.json())
+            .then(data => {
+                if (data.success) { displayHistoryDiff(data); }
+                else { alert('对比失败: ' + data.error); }
+            })
+            .catch(error => { alert('对比失败: ' + error.message); });
+        }
+        function displayHistoryDiff(data) {
+            const diffResult = document.getElementById('historyDiffResult');
+            const describe = side => side.serviceName + '.' + side.methodName + ' @ ' +
+                new Date(side.timestamp).toLocaleString() + ' (' + side.duration + 'ms)';
+            const format = value => value === undefined ? 'null' : JSON.stringify(value);
+            const lines = ['对比 A: ' + describe(data.a), '     B: ' + describe(data.b), ''];
+            if (data.identical) {
+                lines.push('两次结果一致');
+            } else {
+                (data.diffs || []).forEach(d => {
+                    switch (d.kind) {
+                        case 'added': lines.push('+ ' + d.path + ': ' + format(d.new)); break;
+                        case 'removed': lines.push('- ' + d.path + ': ' + format(d.old)); break;
+                        case 'type_changed': lines.push('! ' + d.path + ': ' + d.oldType + ' → ' + d.newType + ' (' + format(d.old) + ' → ' + format(d.new) + ')'); break;
+                        default: lines.push('~ ' + d.path + ': ' + format(d.old) + ' → ' + format(d.new));
+                    }
+                });
+                lines.push('', '共 ' + data.diffs.length + ' 处差异');
+            }
+            diffResult.className = 'result ' + (data.identical ? 'success' : 'error');
+            diffResult.textContent = lines.join('\n');
+            diffResult.style.display = 'block';
+            diffResult.scrollIntoView({ behavior: 'smooth', block: 'nearest' });
+        }
         function fillFromHistory(item) {
             // 填充表单字段
             const serviceNameEl = document.getElementById('serviceName');
@@ -2363,6 +3248,10 @@ const indexHTML = `<!DOCTYPE html>
                 }
             }
             
+            // 填充断言
+            const expectationsEl = document.getElementById('expectations');
+            if (expectationsEl) expectationsEl.value = (item.expect || []).join('\n');
+            
             // 处理参数类型
             const typesEl = document.getElementById('types');
             if (typesEl) {
@@ -2672,6 +3561,7 @@ const indexHTML = `<!DOCTYPE html>
                 body: JSON.stringify({
                     registry: registry,
                     namespace: namespace,
+                    profile: currentProfile(),
                     app: document.getElementById('app') ? document.getElementById('app').value : 'dubbo-invoke-cli'
                 })
             })
@@ -2723,7 +3613,70 @@ const indexHTML = `<!DOCTYPE html>
                  '</div>';
          }
         
-        window.onload = function() { loadHistory(); };
+        // 环境来自与CLI共用的配置文件，选择环境后填入注册中心和命名空间，认证信息、隐式参数和字符集由服务端按环境补充
+        let profiles = {};
+        function loadProfiles() {
+            fetch('/api/profiles')
+            .then(response => response.json())
+            .then(data => {
+                if (!data.success || !data.profiles || data.profiles.length === 0) return;
+                const select = document.getElementById('profile');
+                select.innerHTML = '';
+                profiles = {};
+                data.profiles.forEach(profile => {
+                    profiles[profile.name] = profile;
+                    const option = document.createElement('option');
+                    option.value = profile.name;
+                    option.textContent = profile.description ? profile.name + ' - ' + profile.description : profile.name;
+                    select.appendChild(option);
+                });
+                if (data.current && profiles[data.current]) {
+                    select.value = data.current;
+                }
+                document.getElementById('profileGroup').style.display = 'block';
+                onProfileChange();
+            })
+            .catch(error => console.warn('加载环境失败:', error));
+        }
+        function currentProfile() {
+            const select = document.getElementById('profile');
+            return select && profiles[select.value] ? select.value : '';
+        }
+        function profileTimeout() {
+            const profile = profiles[currentProfile()];
+            return profile && profile.timeoutMs ? profile.timeoutMs : 10000;
+        }
+        function onProfileChange() {
+            const profile = profiles[currentProfile()];
+            const info = document.getElementById('profileInfo');
+            if (!profile) { info.textContent = ''; return; }
+            if (profile.registry) {
+                let registryType = 'zookeeper';
+                let registryAddress = profile.registry;
+                const index = profile.registry.indexOf('://');
+                if (index > 0) {
+                    registryType = profile.registry.substring(0, index);
+                    registryAddress = profile.registry.substring(index + 3);
+                }
+                document.getElementById('registryType').value = registryType;
+                document.getElementById('registryTypeExpr').value = registryType;
+                onRegistryTypeChange();
+                onRegistryTypeChangeExpr();
+                document.getElementById('registryAddress').value = registryAddress;
+                document.getElementById('registryAddressExpr').value = registryAddress;
+            }
+            document.getElementById('namespace').value = profile.namespace || 'public';
+            document.getElementById('namespaceExpr').value = profile.namespace || 'public';
+            const details = [];
+            if (profile.version) details.push('版本 ' + profile.version);
+            if (profile.group) details.push('分组 ' + profile.group);
+            if (profile.timeout) details.push('超时 ' + profile.timeout);
+            if (profile.username) details.push('用户 ' + profile.username);
+            if (profile.charset) details.push('字符集 ' + profile.charset);
+            if (profile.attachments) details.push(Object.keys(profile.attachments).length + ' 个隐式参数');
+            info.textContent = details.join('，');
+        }
+        window.onload = function() { loadHistory(); loadProfiles(); };
     </script>
 </body>
 </html>`